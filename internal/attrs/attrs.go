@@ -4,18 +4,54 @@
 package attrs
 
 import (
+	"encoding/json"
 	"fmt"
 	"math"
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/dustin/go-humanize"
+	"github.com/tidwall/gjson"
 
+	"github.com/staranto/tfctl/internal/driller"
 	"github.com/staranto/tfctl/internal/log"
 )
 
+// jsonPathRegex matches the j(path) transform directive, which parses a string
+// value as JSON and drills into it with path, e.g. j(Statement[0].Effect).
+var jsonPathRegex = regexp.MustCompile(`j\(([^)]*)\)`)
+
+// substRegex matches the s/pattern/replacement/ transform directive, which
+// replaces the matches of a regular expression in a string value. A / in the
+// pattern or replacement is escaped as \/.
+var substRegex = regexp.MustCompile(`s/((?:\\.|[^\\/])*)/((?:\\.|[^\\/])*)/`)
+
+// splitRegex matches the split(delim)[index] transform directive, which
+// splits a string value on delim and keeps the segment at index. A negative
+// index counts from the end.
+var splitRegex = regexp.MustCompile(`split\(([^)]*)\)\[(-?\d+)\]`)
+
+// substCache holds the compiled pattern of each s/// directive, or nil if it
+// is invalid, so each pattern is compiled, and reported, once.
+var substCache sync.Map
+
+// substPattern returns the compiled pattern, or nil if it is invalid.
+func substPattern(pattern string) *regexp.Regexp {
+	if re, ok := substCache.Load(pattern); ok {
+		return re.(*regexp.Regexp)
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		log.Errorf("invalid regex: %s", pattern)
+		re = nil
+	}
+	substCache.Store(pattern, re)
+	return re
+}
+
 // Attr represents each of the keys to be included in the output. These are
 // typically identified by the JSON attributes key, thus the name.
 type Attr struct {
@@ -35,19 +71,64 @@ type Attr struct {
 // transformed result.
 func (a *Attr) Transform(value interface{}) interface{} {
 
-	// TODO Currently only string values can be transformed.
+	// Substitutions and splits are set aside before anything else so their
+	// patterns and delimiters are not mistaken for the other directives. They
+	// apply after the time and before the case and length transforms, splits
+	// first, each in the order given. The same goes for the path of a JSON
+	// directive, of which the last wins, as with the others.
+	spec := a.TransformSpec
+	substs := substRegex.FindAllStringSubmatch(spec, -1)
+	spec = substRegex.ReplaceAllString(spec, "")
+	splits := splitRegex.FindAllStringSubmatch(spec, -1)
+	spec = splitRegex.ReplaceAllString(spec, "")
+	jsonPaths := jsonPathRegex.FindAllStringSubmatch(spec, -1)
+	spec = jsonPathRegex.ReplaceAllString(spec, "")
+
+	// b formats a byte count as KiB, MiB and so on. It is the one transform
+	// that applies to numbers as well as numeric strings.
+	bytes := strings.Contains(spec, "b")
+
+	// TODO Currently only string values, and numbers under b, can be
+	// transformed.
 	result, ok := value.(string)
 	if !ok {
-		if mapValue, ok := value.(map[string]interface{}); ok {
+		if human, ok := humanBytes(value); ok && bytes {
+			log.Tracef("bytes: result=%s", human)
+			result = human
+		} else if mapValue, ok := value.(map[string]interface{}); ok {
 			log.Tracef("map value: value=%v", value)
 			return mapValue
+		} else {
+			log.Tracef("non-string value: value=%v", value)
+			return value
+		}
+	}
+
+	// Parse a string-encoded JSON value and drill into it first. A number is
+	// kept as a string for b to format.
+	if jsonPaths != nil && gjson.Valid(result) {
+		drilled := driller.Driller(result, jsonPaths[len(jsonPaths)-1][1])
+		log.Tracef("json drilled: result=%s", drilled.Raw)
+		switch {
+		case drilled.Type == gjson.String:
+			result = drilled.Str
+		case drilled.Type == gjson.Number && bytes:
+			result = drilled.Raw
+		default:
+			return driller.Value(drilled)
+		}
+	}
+
+	// Format a numeric string as a byte count. Anything else is left as is.
+	if bytes {
+		if human, ok := humanBytes(result); ok {
+			log.Tracef("bytes: result=%s", human)
+			result = human
 		}
-		log.Tracef("non-string value: value=%v", value)
-		return value
 	}
 
 	// Convert UTC time to local or time ago.
-	if strings.ContainsAny(a.TransformSpec, "tT") {
+	if strings.ContainsAny(spec, "tT") {
 		now := time.Now()
 		tz, _ := now.In(time.Local).Zone()
 		if tz == "" {
@@ -62,7 +143,7 @@ func (a *Attr) Transform(value interface{}) interface{} {
 			return result
 		}
 		local := t.In(loc)
-		if strings.Contains(a.TransformSpec, "T") {
+		if strings.Contains(spec, "T") {
 			result = humanize.Time(local)
 			log.Tracef("time ago: result=%s", result)
 		} else {
@@ -71,12 +152,28 @@ func (a *Attr) Transform(value interface{}) interface{} {
 		}
 	}
 
+	// Keep the segment each split(delim)[index] selects. An index out of range
+	// leaves the value unchanged.
+	for _, split := range splits {
+		result = splitSegment(result, split[1], split[2])
+	}
+
+	// Replace the matches of each s/pattern/replacement/. An invalid pattern
+	// leaves the value unchanged.
+	for _, subst := range substs {
+		unescape := strings.NewReplacer(`\/`, "/")
+		if re := substPattern(unescape.Replace(subst[1])); re != nil {
+			result = re.ReplaceAllString(result, unescape.Replace(subst[2]))
+			log.Tracef("subst: result=%s", result)
+		}
+	}
+
 	// We need to know which case transformation appears last. This covers the
 	// case where there has been a global case transformation prepended to the
 	// attrs transformation and allows the attr's to carry more weight.
 	// IOW... --attrs '*::U,name::l' will be lower case.
-	lastL := strings.LastIndexAny(a.TransformSpec, "lL")
-	lastU := strings.LastIndexAny(a.TransformSpec, "uU")
+	lastL := strings.LastIndexAny(spec, "lL")
+	lastU := strings.LastIndexAny(spec, "uU")
 
 	if lastL > lastU {
 		result = strings.ToLower(result)
@@ -87,11 +184,11 @@ func (a *Attr) Transform(value interface{}) interface{} {
 	}
 
 	// Is it a length-based transformation?
-	if a.TransformSpec != "" {
+	if spec != "" {
 		re := regexp.MustCompile(`-?\d+`)
 		// Same logic as above re: case. This allows a more specific length
 		// transformation to override a global one.
-		match := re.FindAllString(a.TransformSpec, -1)
+		match := re.FindAllString(spec, -1)
 		if len(match) != 0 {
 			// Take the last (overriding) match.
 			l, _ := strconv.Atoi(match[len(match)-1])
@@ -114,6 +211,60 @@ func (a *Attr) Transform(value interface{}) interface{} {
 	return result
 }
 
+// humanBytes formats value, a non-negative number or numeric string, as a
+// byte count in IEC units, e.g. 1536 as "1.5 KiB". ok is false for anything
+// else.
+func humanBytes(value interface{}) (human string, ok bool) {
+	var n float64
+	switch v := value.(type) {
+	case float64:
+		n = v
+	case int:
+		n = float64(v)
+	case int64:
+		n = float64(v)
+	case json.Number:
+		f, err := v.Float64()
+		if err != nil {
+			return "", false
+		}
+		n = f
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil {
+			return "", false
+		}
+		n = f
+	default:
+		return "", false
+	}
+	if n < 0 || math.IsNaN(n) || math.IsInf(n, 0) {
+		return "", false
+	}
+	return humanize.IBytes(uint64(n)), true
+}
+
+// splitSegment splits value on delim and returns the segment at index, counted
+// from the end if negative. An empty delim or an index out of range returns
+// value unchanged.
+func splitSegment(value, delim, index string) string {
+	i, _ := strconv.Atoi(index)
+	if delim == "" {
+		log.Debugf("split: empty delimiter")
+		return value
+	}
+	segments := strings.Split(value, delim)
+	if i < 0 {
+		i += len(segments)
+	}
+	if i < 0 || i >= len(segments) {
+		log.Debugf("split(%s)[%s]: index out of range for %q", delim, index, value)
+		return value
+	}
+	log.Tracef("split: result=%s", segments[i])
+	return segments[i]
+}
+
 // AttrList is a collection of Attr used to shape output fields.
 type AttrList []Attr
 
@@ -177,8 +328,10 @@ specloop:
 		log.Tracef("output set: outputKey=%s", attr.OutputKey)
 
 		attr.TransformSpec = ""
+		// The transform spec is the rest of the spec, so a : can appear in it,
+		// e.g. split(:)[5].
 		if len(fields) > transformIdx {
-			attr.TransformSpec = strings.TrimSpace(fields[transformIdx])
+			attr.TransformSpec = strings.TrimSpace(strings.Join(fields[transformIdx:], ":"))
 		}
 		log.Tracef("transform set: spec=%s", attr.TransformSpec)
 
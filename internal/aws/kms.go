@@ -0,0 +1,122 @@
+// Copyright (c) 2026 Steve Taranto <staranto@gmail.com>.
+// SPDX-License-Identifier: Apache-2.0
+
+package aws
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	awsv2 "github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/staranto/tfctl/internal/log"
+)
+
+// KMSDecrypt decrypts a ciphertext blob produced by a symmetric AWS KMS key
+// and returns the plaintext. The key is identified by the blob itself, so the
+// caller only needs kms:Decrypt on it. The request is signed with cfg's
+// credentials and sent to cfg's region, or to cfg.BaseEndpoint when set.
+//
+// KMS speaks a small JSON protocol, so the call is made directly rather than
+// through the SDK's KMS client.
+func KMSDecrypt(ctx context.Context, cfg awsv2.Config, ciphertext []byte) ([]byte, error) {
+	if cfg.Region == "" {
+		return nil, fmt.Errorf("kms decrypt: no AWS region configured, set AWS_REGION or a profile region")
+	}
+	if cfg.Credentials == nil {
+		return nil, fmt.Errorf("kms decrypt: no AWS credentials configured")
+	}
+
+	endpoint := "https://kms." + cfg.Region + ".amazonaws.com/"
+	if cfg.BaseEndpoint != nil && *cfg.BaseEndpoint != "" {
+		endpoint = *cfg.BaseEndpoint
+	}
+
+	body, err := json.Marshal(map[string][]byte{"CiphertextBlob": ciphertext})
+	if err != nil {
+		return nil, fmt.Errorf("kms decrypt: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("kms decrypt: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "TrentService.Decrypt")
+
+	creds, err := cfg.Credentials.Retrieve(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("kms decrypt: failed to retrieve credentials: %w", err)
+	}
+
+	sum := sha256.Sum256(body)
+	if err := v4.NewSigner().SignHTTP(ctx, creds, req, hex.EncodeToString(sum[:]), "kms", cfg.Region, time.Now()); err != nil {
+		return nil, fmt.Errorf("kms decrypt: failed to sign request: %w", err)
+	}
+
+	var client awsv2.HTTPClient = http.DefaultClient
+	if cfg.HTTPClient != nil {
+		client = cfg.HTTPClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("kms decrypt: %w", err)
+	}
+	defer resp.Body.Close() //nolint:errcheck
+
+	payload, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("kms decrypt: failed to read response: %w", err)
+	}
+	log.Debugf("kms decrypt: status=%d", resp.StatusCode)
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, kmsError(resp.StatusCode, payload)
+	}
+
+	var out struct {
+		Plaintext []byte `json:"Plaintext"`
+	}
+	if err := json.Unmarshal(payload, &out); err != nil {
+		return nil, fmt.Errorf("kms decrypt: failed to parse response: %w", err)
+	}
+	if len(out.Plaintext) == 0 {
+		return nil, fmt.Errorf("kms decrypt: response carried no plaintext")
+	}
+
+	return out.Plaintext, nil
+}
+
+// kmsError turns a KMS error response, such as {"__type":
+// "AccessDeniedException","message":"..."}, into an error naming the
+// exception.
+func kmsError(status int, payload []byte) error {
+	var e struct {
+		Type     string `json:"__type"`
+		Message  string `json:"message"`
+		MessageU string `json:"Message"`
+	}
+	if err := json.Unmarshal(payload, &e); err != nil || e.Type == "" {
+		return fmt.Errorf("kms decrypt: unexpected status %d", status)
+	}
+
+	// The type may be namespaced, e.g. com.amazonaws.kms#InvalidCiphertextException.
+	typ := e.Type[strings.LastIndex(e.Type, "#")+1:]
+	msg := e.Message
+	if msg == "" {
+		msg = e.MessageU
+	}
+	if msg == "" {
+		return fmt.Errorf("kms decrypt: %s", typ)
+	}
+	return fmt.Errorf("kms decrypt: %s: %s", typ, msg)
+}
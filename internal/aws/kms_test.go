@@ -0,0 +1,118 @@
+// Copyright (c) 2026 Steve Taranto <staranto@gmail.com>.
+// SPDX-License-Identifier: Apache-2.0
+// no-cloc
+
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	awsv2 "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// kmsConfig returns a config with static credentials that sends KMS calls to
+// url.
+func kmsConfig(url string) awsv2.Config {
+	return awsv2.Config{
+		Region: "us-east-2",
+		Credentials: awsv2.CredentialsProviderFunc(func(context.Context) (awsv2.Credentials, error) {
+			return awsv2.Credentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "secret"}, nil
+		}),
+		BaseEndpoint: awsv2.String(url),
+	}
+}
+
+// TestKMSDecrypt verifies the Decrypt request is signed for KMS in the
+// configured region and the plaintext is returned.
+func TestKMSDecrypt(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "TrentService.Decrypt", r.Header.Get("X-Amz-Target"))
+		assert.Equal(t, "application/x-amz-json-1.1", r.Header.Get("Content-Type"))
+		assert.Contains(t, r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/")
+		assert.Contains(t, r.Header.Get("Authorization"), "/us-east-2/kms/aws4_request")
+
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		var in struct {
+			CiphertextBlob []byte
+		}
+		assert.NoError(t, json.Unmarshal(body, &in))
+		assert.Equal(t, []byte("wrapped-key"), in.CiphertextBlob)
+
+		w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+		_, _ = w.Write([]byte(`{"KeyId":"arn:aws:kms:us-east-2:111122223333:key/abc","Plaintext":"ZGF0YS1rZXk="}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	plaintext, err := KMSDecrypt(context.Background(), kmsConfig(srv.URL), []byte("wrapped-key"))
+
+	require.NoError(t, err)
+	assert.Equal(t, []byte("data-key"), plaintext)
+}
+
+// TestKMSDecrypt_Errors verifies KMS exceptions and unusable responses are
+// reported.
+func TestKMSDecrypt_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		wantErr string
+	}{
+		{
+			name:    "access denied",
+			status:  http.StatusBadRequest,
+			body:    `{"__type":"AccessDeniedException","message":"not authorized to perform: kms:Decrypt"}`,
+			wantErr: "kms decrypt: AccessDeniedException: not authorized to perform: kms:Decrypt",
+		},
+		{
+			name:    "namespaced type",
+			status:  http.StatusBadRequest,
+			body:    `{"__type":"com.amazonaws.kms#InvalidCiphertextException","Message":"bad blob"}`,
+			wantErr: "kms decrypt: InvalidCiphertextException: bad blob",
+		},
+		{
+			name:    "no error body",
+			status:  http.StatusInternalServerError,
+			body:    `oops`,
+			wantErr: "kms decrypt: unexpected status 500",
+		},
+		{
+			name:    "no plaintext",
+			status:  http.StatusOK,
+			body:    `{"KeyId":"k"}`,
+			wantErr: "kms decrypt: response carried no plaintext",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			t.Cleanup(srv.Close)
+
+			_, err := KMSDecrypt(context.Background(), kmsConfig(srv.URL), []byte("blob"))
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
+
+// TestKMSDecrypt_NoRegion verifies a missing region is reported before any
+// request is made.
+func TestKMSDecrypt_NoRegion(t *testing.T) {
+	cfg := kmsConfig("http://127.0.0.1:1")
+	cfg.Region = ""
+
+	_, err := KMSDecrypt(context.Background(), cfg, []byte("blob"))
+	assert.ErrorContains(t, err, "no AWS region configured")
+}
@@ -6,6 +6,7 @@ package backend
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -16,11 +17,17 @@ import (
 
 	"github.com/staranto/tfctl/internal/backend/cloud"
 	"github.com/staranto/tfctl/internal/backend/local"
+	"github.com/staranto/tfctl/internal/backend/options"
 	"github.com/staranto/tfctl/internal/backend/remote"
 	"github.com/staranto/tfctl/internal/backend/s3"
 	"github.com/staranto/tfctl/internal/meta"
+	"github.com/staranto/tfctl/internal/util"
 )
 
+// ErrNotInitialized is returned when the root directory has not been
+// initialized with terraform init, so its backend can't be told.
+var ErrNotInitialized = errors.New("backend not initialized")
+
 // Type holds common backend resolution context and flags.
 type Type struct {
 	Ctx         context.Context
@@ -34,7 +41,9 @@ type Type struct {
 // Backend abstracts Terraform/OpenTofu backend interactions needed by the
 // application.
 type Backend interface {
-	Runs() ([]*tfe.Run, error)
+	// Runs accepts an optional augmenter function to apply server-side filters.
+	// Only remote backends use this; local and S3 ignore it.
+	Runs(augmenter ...func(context.Context, *cli.Command, *tfe.RunListForOrganizationOptions) error) ([]*tfe.Run, error)
 	// State() returns the CSV~0 state document.
 	State() ([]byte, error)
 	// States() returns the state documents specified by the specs.
@@ -52,29 +61,112 @@ type SelfDiffer interface {
 	DiffStates(ctx context.Context, cmd *cli.Command) ([][]byte, error)
 }
 
+// StateVersionAttributer is implemented by backends that know attributes of
+// their state versions that tfe.StateVersion has no field for, such as the
+// size and storage class of an S3 object version. The attributes are those of
+// the last StateVersions listing, keyed by attribute name.
+type StateVersionAttributer interface {
+	StateVersionAttributes(id string) map[string]any
+}
+
 // NewBackend returns the appropriate Backend implementation for the working
 // directory represented by the resolved root dir in command metadata.
 func NewBackend(ctx context.Context, cmd cli.Command) (Backend, error) {
 	meta := cmd.Metadata["meta"].(meta.Meta)
 	log.Debugf("NewBackend: meta: %v", meta)
 
-	cFile, cErr := os.Stat(filepath.Join(meta.RootDir, ".terraform", "terraform.tfstate"))
-	sFile, sErr := os.Stat(filepath.Join(meta.RootDir, "terraform.tfstate"))
-	eFile, eErr := os.Stat(filepath.Join(meta.RootDir, ".terraform", "environment"))
-	_, _, _ = cFile, sFile, eFile // HACK
+	o := options.Options{Cmd: &cmd, RootDir: meta.RootDir, Env: meta.Env}
+
+	// --env overrides the environment file just like RootDir::<env>.
+	if env := cmd.String("env"); env != "" {
+		o.Env = env
+	}
+
+	return newBackend(ctx, o, meta.Workspace)
+}
+
+// New returns the Backend of the Terraform root directory o.RootDir, the
+// current directory if it is empty, built from o rather than from a tfctl
+// command, so that other programs can query state and runs the way tfctl
+// does. Without init state, local state or an environment file in the root
+// directory, it is a remote backend reaching o.Host and o.Org.
+func New(ctx context.Context, o options.Options) (Backend, error) {
+	if o.RootDir == "" {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return nil, err
+		}
+		o.RootDir = cwd
+	}
+	return newBackend(ctx, o, "")
+}
+
+// newBackend detects the backend of o.RootDir. workspace is the workspace
+// named by RootDir@workspace, if any.
+func newBackend(ctx context.Context, o options.Options, workspace string) (result Backend, err error) {
+	cmd := o.Cmd
+
+	// RootDir@workspace names the workspace itself. Remote and cloud backends
+	// take it like --workspace, and for local and s3 backends the workspace is
+	// the env.
+	localEnv := o.Env
+	if workspace != "" {
+		explain(cmd, "workspace %q from RootDir@workspace", workspace)
+		localEnv = workspace
+	}
+
+	explain(cmd, "root dir %s", o.RootDir)
+	if dir := os.Getenv("TF_DATA_DIR"); dir != "" {
+		explain(cmd, "TF_DATA_DIR=%s relocates the data dir to %s", dir, util.DataDir(o.RootDir))
+	}
+	defer func() {
+		if err != nil {
+			explain(cmd, "detection failed: %v", err)
+			return
+		}
+		explain(cmd, "selected %s", describe(result))
+	}()
+
+	cPath := filepath.Join(util.DataDir(o.RootDir), "terraform.tfstate")
+	sPath := filepath.Join(o.RootDir, "terraform.tfstate")
+	ePath := filepath.Join(util.DataDir(o.RootDir), "environment")
+	_, cErr := os.Stat(cPath)
+	_, sErr := os.Stat(sPath)
+	_, eErr := os.Stat(ePath)
+	explainFile(cmd, cPath, cErr)
+	explainFile(cmd, sPath, sErr)
+	explainFile(cmd, ePath, eErr)
+	if eErr == nil && o.Bool("no-prefixed-workspace-file") {
+		explain(cmd, "%s: ignored (--no-prefixed-workspace-file)", ePath)
+		eErr = os.ErrNotExist
+	}
 
 	// Maybe we're in a non-sq command and just need a naked remote. This will be
-	// when c, s and e are all in error meaning none of them exist.
+	// when c, s and e are all in error meaning none of them exist. A backend
+	// block that a naked remote can't stand in for means terraform init hasn't
+	// been run, which is better said than left to fail later.
 	if cErr != nil && sErr != nil && eErr != nil {
-		return remote.NewBackendRemote(ctx, &cmd, remote.BuckNaked())
+		if block, ok := readBackendBlock(o.RootDir); ok && block.Type != "remote" && block.Type != "cloud" {
+			return nil, fmt.Errorf("%w: %s declares a %s backend but %s doesn't exist; run terraform init in %s",
+				ErrNotInitialized, o.RootDir, block.Type, cPath, o.RootDir)
+		}
+		explain(cmd, "no init state, local state or environment file: using a remote backend "+
+			"built from --host, --org and --workspace")
+		return remote.NewBackendRemote(ctx, cmd,
+			remote.WithOptions(o),
+			remote.BuckNaked(),
+			remote.WithWorkspaceOverride(workspace),
+		)
 	}
 
 	// If terraform.tfstate exists but .terraform/terraform.tfstate doesn't,
 	// infer local backend. This is an empty terraform.backend {} block use case.
 	if cErr != nil && sErr == nil {
-		return local.NewBackendLocal(ctx, &cmd,
-			local.FromRootDir(meta.RootDir),
-			local.WithEnvOverride(meta.Env),
+		explain(cmd, "local state without init state: inferring a local backend")
+		return local.NewBackendLocal(ctx, cmd,
+			local.WithOptions(o),
+			local.FromRootDir(o.RootDir),
+			local.WithEnvOverride(localEnv),
 		)
 	}
 
@@ -82,74 +174,92 @@ func NewBackend(ctx context.Context, cmd cli.Command) (Backend, error) {
 	// .terraform/environment does, we're in a local backend with multi-workspace
 	// configuration. The environment file points to the workspace directory.
 	if cErr != nil && sErr != nil && eErr == nil {
-		return local.NewBackendLocal(ctx, &cmd,
-			local.FromRootDir(meta.RootDir),
-			local.WithEnvOverride(meta.Env),
+		explain(cmd, "environment file without init or local state: inferring a local backend with workspaces")
+		return local.NewBackendLocal(ctx, cmd,
+			local.WithOptions(o),
+			local.FromRootDir(o.RootDir),
+			local.WithEnvOverride(localEnv),
 		)
 	}
 
 	// Peek at the backend type so we can switch on it.
 	// TODO We're double reading the file. Once in peek() and once in the New().
-	typ, err := peek(meta)
+	typ, err := peek(cPath, o.RootDir)
 	if err != nil {
 		return nil, err
 	}
+	explain(cmd, "peeked backend type %q from %s", typ, cPath)
+	if o.Env != "" {
+		explain(cmd, "workspace override %q", o.Env)
+	}
+
+	if stale, ok := StaleInit(o.RootDir); ok && stale {
+		fmt.Fprintf(os.Stderr, "warning: backend block in %s differs from %s; "+
+			"run terraform init to refresh it\n", o.RootDir, cPath)
+	} else if ok {
+		explain(cmd, "backend block matches the init state")
+	}
 
-	var result Backend
 	switch typ {
 	case "cloud":
 		var beCloud *cloud.BackendCloud
-		beCloud, err = cloud.NewBackendCloud(ctx, &cmd,
-			cloud.FromRootDir(meta.RootDir),
-			cloud.WithEnvOverride(meta.Env),
+		beCloud, err = cloud.NewBackendCloud(ctx, cmd,
+			cloud.WithOptions(o),
+			cloud.FromRootDir(o.RootDir),
+			cloud.WithEnvOverride(o.Env),
 		)
 		// Preserve prior behavior: return transformed backend alongside any error
-		result = beCloud.Transform2Remote(ctx, &cmd)
+		explain(cmd, "cloud backend transformed to remote")
+		beRemote := beCloud.Transform2Remote(ctx, cmd)
+		beRemote.WorkspaceOverride = workspace
+		result = beRemote
 	case "local":
-		result, err = local.NewBackendLocal(ctx, &cmd,
-			local.FromRootDir(meta.RootDir),
-			local.WithEnvOverride(meta.Env),
+		result, err = local.NewBackendLocal(ctx, cmd,
+			local.WithOptions(o),
+			local.FromRootDir(o.RootDir),
+			local.WithEnvOverride(localEnv),
 		)
 	case "remote":
-		result, err = remote.NewBackendRemote(ctx, &cmd,
-			remote.FromRootDir(meta.RootDir),
-			remote.WithEnvOverride(meta.Env),
+		result, err = remote.NewBackendRemote(ctx, cmd,
+			remote.WithOptions(o),
+			remote.FromRootDir(o.RootDir),
+			remote.WithEnvOverride(o.Env),
+			remote.WithWorkspaceOverride(workspace),
 			remote.WithSvOverride(),
 		)
 	case "s3":
-		result, err = s3.NewBackendS3(ctx, &cmd,
-			s3.FromRootDir(meta.RootDir),
-			s3.WithEnvOverride(meta.Env),
+		result, err = s3.NewBackendS3(ctx, cmd,
+			s3.WithOptions(o),
+			s3.FromRootDir(o.RootDir),
+			s3.WithEnvOverride(localEnv),
 			s3.WithSvOverride(),
 		)
 	default:
-		return nil, fmt.Errorf("unknown type %s: %w", typ, err)
+		return nil, fmt.Errorf("unsupported backend type %q in %s; tfctl supports cloud, local, remote and s3",
+			typ, cPath)
 	}
 
 	return result, err
 }
 
-// peek returns the backend type by reading the local terraform state file.
-func peek(meta meta.Meta) (string, error) {
-	raw, err := os.ReadFile(filepath.Join(meta.RootDir, ".terraform", "terraform.tfstate"))
+// peek returns the backend type recorded in path, the init state terraform
+// init wrote for rootDir.
+func peek(path string, rootDir string) (string, error) {
+	raw, err := os.ReadFile(path)
 	if err != nil {
-		return "", err
+		return "", fmt.Errorf("failed to read init state: %w", err)
 	}
 
-	var peeker map[string]json.RawMessage
-	if err := json.Unmarshal(raw, &peeker); err != nil {
-		return "", fmt.Errorf("can't peek: %w", err)
+	var state initState
+	if err := json.Unmarshal(raw, &state); err != nil {
+		return "", fmt.Errorf("malformed init state %s: %w; run terraform init in %s to rewrite it",
+			path, err, rootDir)
 	}
-
-	if err := json.Unmarshal(peeker["backend"], &peeker); err != nil {
-		return "", fmt.Errorf("can't peek: %w", err)
-	}
-
-	var typ string
-	if err := json.Unmarshal(peeker["type"], &typ); err != nil {
-		return "", fmt.Errorf("can't peek: %w", err)
+	if state.Backend.Type == "" {
+		return "", fmt.Errorf("%w: %s records no backend type; run terraform init in %s",
+			ErrNotInitialized, path, rootDir)
 	}
-	log.Debugf("type: %s", typ)
+	log.Debugf("type: %s", state.Backend.Type)
 
-	return typ, nil
+	return state.Backend.Type, nil
 }
@@ -0,0 +1,271 @@
+// Copyright (c) 2026 Steve Taranto <staranto@gmail.com>.
+// SPDX-License-Identifier: Apache-2.0
+// no-cloc
+
+package backend
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v3"
+
+	"github.com/staranto/tfctl/internal/backend/local"
+	"github.com/staranto/tfctl/internal/backend/options"
+	"github.com/staranto/tfctl/internal/backend/remote"
+	"github.com/staranto/tfctl/internal/meta"
+)
+
+const localInitState = `{"version":3,"backend":{"type":"local","config":{"path":null,"workspace_dir":null},"hash":1}}`
+
+// writeDataDir writes terraform init's files into dataDir.
+func writeDataDir(t *testing.T, dataDir, initState, environment string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(dataDir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dataDir, "terraform.tfstate"), []byte(initState), 0o600))
+	if environment != "" {
+		require.NoError(t, os.WriteFile(filepath.Join(dataDir, "environment"), []byte(environment+"\n"), 0o600))
+	}
+}
+
+func newTestCommand(rootDir string) cli.Command {
+	return cli.Command{
+		Metadata: map[string]any{
+			"meta": meta.Meta{RootDirSpec: meta.RootDirSpec{RootDir: rootDir}},
+		},
+	}
+}
+
+func TestNewBackend_TFDataDir(t *testing.T) {
+	tests := []struct {
+		name    string
+		dataDir func(rootDir string) (env string, dir string)
+	}{
+		{
+			name: "relative",
+			dataDir: func(rootDir string) (string, string) {
+				return "build/tf", filepath.Join(rootDir, "build", "tf")
+			},
+		},
+		{
+			name: "absolute",
+			dataDir: func(string) (string, string) {
+				dir := filepath.Join(t.TempDir(), "tfdata")
+				return dir, dir
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rootDir := t.TempDir()
+			env, dir := tt.dataDir(rootDir)
+			t.Setenv("TF_DATA_DIR", env)
+			writeDataDir(t, dir, localInitState, "dev")
+
+			stateDir := filepath.Join(rootDir, "terraform.tfstate.d", "dev")
+			require.NoError(t, os.MkdirAll(stateDir, 0o755))
+			require.NoError(t, os.WriteFile(filepath.Join(stateDir, "terraform.tfstate"), []byte(`{"serial":7}`), 0o600))
+
+			typ, err := peek(filepath.Join(dir, "terraform.tfstate"), rootDir)
+			require.NoError(t, err)
+			assert.Equal(t, "local", typ)
+
+			be, err := NewBackend(context.Background(), newTestCommand(rootDir))
+			require.NoError(t, err)
+			lbe, ok := be.(*local.BackendLocal)
+			require.True(t, ok, "got %T", be)
+
+			// The selected workspace is read from the relocated environment file.
+			versions, err := lbe.StateVersions()
+			require.NoError(t, err)
+			require.Len(t, versions, 1)
+			assert.Equal(t, "dev", lbe.EnvOverride)
+			assert.Equal(t, int64(7), versions[0].Serial)
+		})
+	}
+}
+
+func TestNewBackend_TFDataDirIgnoresDefault(t *testing.T) {
+	// With TF_DATA_DIR pointing elsewhere, a stale .terraform is not consulted.
+	rootDir := t.TempDir()
+	writeDataDir(t, filepath.Join(rootDir, ".terraform"), localInitState, "")
+	t.Setenv("TF_DATA_DIR", filepath.Join(t.TempDir(), "empty"))
+
+	be, err := NewBackend(context.Background(), newTestCommand(rootDir))
+	require.NoError(t, err)
+	_, ok := be.(*remote.BackendRemote)
+	assert.True(t, ok, "got %T", be)
+}
+
+func TestNewBackend_NoWorkspaceFile(t *testing.T) {
+	noWorkspaceFile := func(rootDir string, ignore bool) cli.Command {
+		cmd := newTestCommand(rootDir)
+		cmd.Flags = []cli.Flag{&cli.BoolFlag{Name: "no-prefixed-workspace-file", Value: ignore}}
+		return cmd
+	}
+
+	t.Run("local workspace", func(t *testing.T) {
+		rootDir := t.TempDir()
+		t.Setenv("TF_DATA_DIR", "")
+		writeDataDir(t, filepath.Join(rootDir, ".terraform"), localInitState, "dev")
+		require.NoError(t, os.WriteFile(filepath.Join(rootDir, "terraform.tfstate"), []byte(`{"serial":3}`), 0o600))
+		stateDir := filepath.Join(rootDir, "terraform.tfstate.d", "dev")
+		require.NoError(t, os.MkdirAll(stateDir, 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(stateDir, "terraform.tfstate"), []byte(`{"serial":7}`), 0o600))
+
+		for ignore, want := range map[bool]int64{false: 7, true: 3} {
+			be, err := NewBackend(context.Background(), noWorkspaceFile(rootDir, ignore))
+			require.NoError(t, err)
+			lbe, ok := be.(*local.BackendLocal)
+			require.True(t, ok, "got %T", be)
+
+			versions, err := lbe.StateVersions()
+			require.NoError(t, err)
+			require.NotEmpty(t, versions)
+			assert.Equal(t, want, versions[0].Serial, "ignore %v", ignore)
+		}
+	})
+
+	t.Run("environment file only", func(t *testing.T) {
+		// An environment file alone infers a local backend with workspaces,
+		// unless it is ignored.
+		rootDir := t.TempDir()
+		t.Setenv("TF_DATA_DIR", "")
+		require.NoError(t, os.MkdirAll(filepath.Join(rootDir, ".terraform"), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(rootDir, ".terraform", "environment"), []byte("dev\n"), 0o600))
+
+		be, err := NewBackend(context.Background(), noWorkspaceFile(rootDir, false))
+		require.NoError(t, err)
+		assert.IsType(t, &local.BackendLocal{}, be)
+
+		be, err = NewBackend(context.Background(), noWorkspaceFile(rootDir, true))
+		require.NoError(t, err)
+		assert.IsType(t, &remote.BackendRemote{}, be)
+	})
+}
+
+func TestNew(t *testing.T) {
+	t.Run("local", func(t *testing.T) {
+		rootDir := t.TempDir()
+		writeDataDir(t, filepath.Join(rootDir, ".terraform"), localInitState, "")
+		for _, serial := range []string{"1", "2"} {
+			path := filepath.Join(rootDir, "terraform.tfstate")
+			if serial == "1" {
+				path += ".backup"
+			}
+			require.NoError(t, os.WriteFile(path, []byte(`{"serial":`+serial+`}`), 0o600))
+		}
+
+		be, err := New(context.Background(), options.Options{RootDir: rootDir, Limit: 1})
+		require.NoError(t, err)
+		require.IsType(t, &local.BackendLocal{}, be)
+
+		versions, err := be.StateVersions()
+		require.NoError(t, err)
+		assert.Len(t, versions, 1)
+	})
+
+	t.Run("naked remote", func(t *testing.T) {
+		t.Setenv("TF_TOKEN", "env-token")
+
+		be, err := New(context.Background(), options.Options{
+			RootDir:   t.TempDir(),
+			Host:      "tfe.example.com",
+			Org:       "acme",
+			Workspace: "web",
+			Token:     "opt-token",
+		})
+		require.NoError(t, err)
+		rbe, ok := be.(*remote.BackendRemote)
+		require.True(t, ok, "got %T", be)
+
+		assert.Equal(t, "tfe.example.com", rbe.Host())
+		org, err := rbe.Organization()
+		require.NoError(t, err)
+		assert.Equal(t, "acme", org)
+		ws, err := rbe.WorkspaceName()
+		require.NoError(t, err)
+		assert.Equal(t, "web", ws)
+		token, err := rbe.Token()
+		require.NoError(t, err)
+		assert.Equal(t, "opt-token", token)
+		assert.NotContains(t, rbe.String(), "opt-token")
+	})
+}
+
+func TestNewBackend_NotInitialized(t *testing.T) {
+	tests := []struct {
+		name      string
+		tf        string
+		initState string
+		wantIs    error
+		want      []string
+	}{
+		{
+			name:   "backend block without init",
+			tf:     "terraform {\n  backend \"s3\" {\n    bucket = \"states\"\n  }\n}\n",
+			wantIs: ErrNotInitialized,
+			want:   []string{"declares a s3 backend", filepath.Join(".terraform", "terraform.tfstate") + " doesn't exist", "run terraform init"},
+		},
+		{
+			name:      "init state without backend",
+			initState: `{"version":3}`,
+			wantIs:    ErrNotInitialized,
+			want:      []string{"records no backend type", "run terraform init"},
+		},
+		{
+			name:      "malformed init state",
+			initState: `{"backend":`,
+			want:      []string{"malformed init state", filepath.Join(".terraform", "terraform.tfstate"), "run terraform init"},
+		},
+		{
+			name:      "unsupported backend",
+			initState: `{"version":3,"backend":{"type":"gcs","config":{}}}`,
+			want:      []string{`unsupported backend type "gcs"`, filepath.Join(".terraform", "terraform.tfstate")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("TF_DATA_DIR", "")
+			rootDir := t.TempDir()
+			if tt.tf != "" {
+				require.NoError(t, os.WriteFile(filepath.Join(rootDir, "main.tf"), []byte(tt.tf), 0o600))
+			}
+			if tt.initState != "" {
+				writeDataDir(t, filepath.Join(rootDir, ".terraform"), tt.initState, "")
+			}
+
+			_, err := NewBackend(context.Background(), newTestCommand(rootDir))
+			require.Error(t, err)
+			if tt.wantIs != nil {
+				assert.ErrorIs(t, err, tt.wantIs)
+			} else {
+				assert.NotErrorIs(t, err, ErrNotInitialized)
+			}
+			for _, want := range tt.want {
+				assert.Contains(t, err.Error(), want)
+			}
+		})
+	}
+}
+
+// TestNewBackend_RemoteBlockWithoutInit verifies a remote or cloud block
+// without init state still falls back to a naked remote, which --host, --org
+// and --workspace can complete.
+func TestNewBackend_RemoteBlockWithoutInit(t *testing.T) {
+	t.Setenv("TF_DATA_DIR", "")
+	rootDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(rootDir, "main.tf"),
+		[]byte("terraform {\n  cloud {\n    organization = \"acme\"\n  }\n}\n"), 0o600))
+
+	be, err := NewBackend(context.Background(), newTestCommand(rootDir))
+	require.NoError(t, err)
+	_, ok := be.(*remote.BackendRemote)
+	assert.True(t, ok, "got %T", be)
+}
@@ -12,6 +12,7 @@ import (
 
 	"github.com/urfave/cli/v3"
 
+	"github.com/staranto/tfctl/internal/backend/options"
 	"github.com/staranto/tfctl/internal/backend/remote"
 	"github.com/staranto/tfctl/internal/config"
 )
@@ -19,6 +20,7 @@ import (
 type BackendCloud struct {
 	Ctx              context.Context
 	Cmd              *cli.Command
+	Options          options.Options
 	RootDir          string `json:"-" validate:"dir"`
 	EnvOverride      string
 	Version          int    `json:"version" validate:"gte=4"`
@@ -31,19 +33,59 @@ type BackendCloud struct {
 			Organization string `json:"organization" validate:"required"`
 			Token        any    `json:"token"`
 			Workspaces   struct {
-				Name    string            `json:"name"`
-				Project string            `json:"project"`
-				Tags    map[string]string `json:"-"`
+				Name    string `json:"name"`
+				Project string `json:"project"`
+				Tags    Tags   `json:"tags"`
 			} `json:"workspaces"`
 		} `json:"config"`
 	} `json:"backend"`
 }
 
-// Token retrieves the token from the environment variable, config file, or
-// the credentials file, in that order.
+// Tags are the workspaces tags of a cloud block. Terraform records a list of
+// tag names, tags = ["app"], as a JSON array and key/value tags,
+// tags = { env = "prod" }, as a JSON object.
+type Tags struct {
+	Names    []string
+	Bindings map[string]string
+}
+
+// UnmarshalJSON implements json.Unmarshaler, accepting null, an array of tag
+// names or an object of key/value tags.
+func (t *Tags) UnmarshalJSON(data []byte) error {
+	*t = Tags{}
+
+	switch {
+	case string(data) == "null":
+		return nil
+	case strings.HasPrefix(string(data), "["):
+		return json.Unmarshal(data, &t.Names)
+	default:
+		if err := json.Unmarshal(data, &t.Bindings); err != nil {
+			return fmt.Errorf("workspaces tags must be a list or a map: %w", err)
+		}
+		return nil
+	}
+}
+
+// MarshalJSON implements json.Marshaler, writing the tags back in the form
+// they were read.
+func (t Tags) MarshalJSON() ([]byte, error) {
+	if t.Bindings != nil {
+		return json.Marshal(t.Bindings)
+	}
+	return json.Marshal(t.Names)
+}
+
+// Token retrieves the token from the options, the environment variable, config
+// file, or the credentials file, in that order.
 func (be *BackendCloud) Token() (string, error) {
 	var token string
 
+	// A token given in the options wins outright.
+	if be.Options.Token != "" {
+		return be.Options.Token, nil
+	}
+
 	// Figure out if Token needs to be overridden by an environment variable.
 	// The precedence is:
 	// 1. TF_TOKEN_app_terraform_io
@@ -97,7 +139,13 @@ func (be *BackendCloud) Token() (string, error) {
 }
 
 func (be *BackendCloud) Transform2Remote(ctx context.Context, cmd *cli.Command) *remote.BackendRemote {
-	beRemote := remote.BackendRemote{Ctx: ctx, Cmd: cmd}
+	beRemote := remote.BackendRemote{Ctx: ctx, Cmd: cmd, Options: be.Options}
+
+	// The options fall back to the flags of cmd.
+	opts := be.Options
+	if opts.Cmd == nil {
+		opts.Cmd = cmd
+	}
 
 	beRemote.RootDir = be.RootDir
 	beRemote.Version = be.Version
@@ -108,17 +156,17 @@ func (be *BackendCloud) Transform2Remote(ctx context.Context, cmd *cli.Command)
 
 	host := be.Backend.Config.Hostname
 	if host == "" {
-		host = cmd.String("host")
+		host = opts.String("host")
 	}
 	beRemote.Backend.Config.Hostname = host
 
 	// Organization precedence: --org > terraform.backend{} > tfctl.yaml
 	// Detect if --org is explicitly set to a value different from tfctl.yaml
 	// config so tfctl.yaml doesn't override backend values.
-	flagOrg := cmd.String("org")
+	flagOrg := opts.String("org")
 	// Attempt to read namespaced and global org from tfctl.yaml to infer defaults
 	var cfgOrg string
-	if ns := cmd.Name; ns != "" {
+	if ns := opts.CmdName(); ns != "" {
 		if v, err := config.GetString(ns + ".org"); err == nil {
 			cfgOrg = v
 		}
@@ -140,7 +188,14 @@ func (be *BackendCloud) Transform2Remote(ctx context.Context, cmd *cli.Command)
 	}
 	beRemote.Backend.Config.Organization = org
 
-	beRemote.Backend.Config.Workspaces.Name = be.Backend.Config.Workspaces.Name
+	// A cloud block selects its workspace either by name or by tags, optionally
+	// within a project. The remote backend resolves tags to a workspace, see
+	// BackendRemote.WorkspaceName.
+	workspaces := be.Backend.Config.Workspaces
+	beRemote.Backend.Config.Workspaces.Name = workspaces.Name
+	beRemote.Backend.Config.Workspaces.Project = workspaces.Project
+	beRemote.Backend.Config.Workspaces.Tags = workspaces.Tags.Names
+	beRemote.Backend.Config.Workspaces.TagBindings = workspaces.Tags.Bindings
 	beRemote.Backend.Config.Token, _ = beRemote.Token()
 
 	return &beRemote
@@ -13,6 +13,9 @@ import (
 
 	"github.com/apex/log"
 	"github.com/urfave/cli/v3"
+
+	"github.com/staranto/tfctl/internal/backend/options"
+	"github.com/staranto/tfctl/internal/util"
 )
 
 type BackendCloudOption = func(ctx context.Context, cmd *cli.Command, be *BackendCloud) error
@@ -70,6 +73,15 @@ func WithDefaults() BackendCloudOption {
 	}
 }
 
+// WithOptions sets the options the backend consults before the flags of cmd,
+// see options.Options.
+func WithOptions(o options.Options) BackendCloudOption {
+	return func(ctx context.Context, cmd *cli.Command, be *BackendCloud) error {
+		be.Options = o
+		return nil
+	}
+}
+
 func WithEnvOverride(env string) BackendCloudOption {
 	return func(ctx context.Context, cmd *cli.Command, be *BackendCloud) error {
 		if env != "" {
@@ -80,7 +92,7 @@ func WithEnvOverride(env string) BackendCloudOption {
 }
 
 func (be *BackendCloud) load(_ context.Context, _ *cli.Command) error {
-	tfFile := be.RootDir + "/.terraform/terraform.tfstate"
+	tfFile := filepath.Join(util.DataDir(be.RootDir), "terraform.tfstate")
 	data, err := os.ReadFile(tfFile)
 	if err != nil {
 		return fmt.Errorf("failed to read local config file: %w", err)
@@ -0,0 +1,68 @@
+// Copyright (c) 2026 Steve Taranto <staranto@gmail.com>.
+// SPDX-License-Identifier: Apache-2.0
+// no-cloc
+
+package cloud
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v3"
+)
+
+func TestTags_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		want    Tags
+		wantErr bool
+	}{
+		{name: "null", config: `{"tags":null}`},
+		{name: "absent", config: `{"name":"web"}`},
+		{name: "names", config: `{"tags":["app","prod"]}`, want: Tags{Names: []string{"app", "prod"}}},
+		{name: "key/value", config: `{"tags":{"env":"prod","team":"web"}}`,
+			want: Tags{Bindings: map[string]string{"env": "prod", "team": "web"}}},
+		{name: "invalid", config: `{"tags":"prod"}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var workspaces struct {
+				Tags Tags `json:"tags"`
+			}
+			err := json.Unmarshal([]byte(tt.config), &workspaces)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, workspaces.Tags)
+		})
+	}
+}
+
+func TestTransform2Remote_Tags(t *testing.T) {
+	t.Setenv("TF_TOKEN", "test")
+
+	var be BackendCloud
+	require.NoError(t, json.Unmarshal([]byte(`{"version":4,"backend":{"type":"cloud","config":{
+		"hostname":"app.terraform.io","organization":"acme",
+		"workspaces":{"name":null,"project":"platform","tags":{"env":"prod"}}}}}`), &be))
+
+	cmd := &cli.Command{Name: "sq", Flags: []cli.Flag{
+		&cli.StringFlag{Name: "host"},
+		&cli.StringFlag{Name: "org"},
+	}}
+	remote := be.Transform2Remote(context.Background(), cmd)
+
+	workspaces := remote.Backend.Config.Workspaces
+	assert.Empty(t, workspaces.Name)
+	assert.Equal(t, "platform", workspaces.Project)
+	assert.Empty(t, workspaces.Tags)
+	assert.Equal(t, map[string]string{"env": "prod"}, workspaces.TagBindings)
+	assert.Equal(t, "acme", remote.Backend.Config.Organization)
+}
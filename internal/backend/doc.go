@@ -4,5 +4,6 @@
 
 // Package backend implements multiple Terraform backend integrations (remote,
 // local, and s3) and exposes common behaviors for querying runs, state, and
-// state versions.
+// state versions. NewBackend builds a backend from a tfctl command and New from
+// explicit options, see package options.
 package backend
@@ -0,0 +1,70 @@
+// Copyright (c) 2026 Steve Taranto <staranto@gmail.com>.
+// SPDX-License-Identifier: Apache-2.0
+
+package backend
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/apex/log"
+	"github.com/urfave/cli/v3"
+
+	"github.com/staranto/tfctl/internal/backend/local"
+	"github.com/staranto/tfctl/internal/backend/remote"
+	"github.com/staranto/tfctl/internal/backend/s3"
+)
+
+// explainOut returns where the --explain-backend trace of cmd is written, the
+// root command's ErrWriter, which is stderr unless set otherwise.
+func explainOut(cmd *cli.Command) io.Writer {
+	if w := cmd.Root().ErrWriter; w != nil {
+		return w
+	}
+	return os.Stderr
+}
+
+// explain records one backend detection decision. It is always logged at
+// debug level and, with --explain-backend, also written to explainOut.
+func explain(cmd *cli.Command, format string, args ...any) {
+	msg := fmt.Sprintf(format, args...)
+	log.Debug(msg)
+	if cmd != nil && cmd.Bool("explain-backend") {
+		fmt.Fprintf(explainOut(cmd), "backend: %s\n", msg)
+	}
+}
+
+// explainFile records whether a file that steers detection exists.
+func explainFile(cmd *cli.Command, path string, err error) {
+	if err != nil {
+		explain(cmd, "%s: not found", path)
+		return
+	}
+	explain(cmd, "%s: found", path)
+}
+
+// describe summarizes where the selected backend reads from.
+func describe(be Backend) string {
+	switch be := be.(type) {
+	case *remote.BackendRemote:
+		c := be.Backend.Config
+		workspace := c.Workspaces.Name
+		if workspace == "" && c.Workspaces.Prefix != "" {
+			workspace = c.Workspaces.Prefix + "*"
+		}
+		if be.EnvOverride != "" {
+			workspace = be.EnvOverride
+		}
+		return fmt.Sprintf("remote (host %q, organization %q, workspace %q)", c.Hostname, c.Organization, workspace)
+	case *local.BackendLocal:
+		return fmt.Sprintf("local (path %q, workspace %q)", be.Backend.Config.Path, be.EnvOverride)
+	case *s3.BackendS3:
+		c := be.Backend.Config
+		return fmt.Sprintf("s3 (bucket %q, key %q, region %q, workspace %q)", c.Bucket, c.Key, c.Region, be.EnvOverride)
+	case nil:
+		return "none"
+	default:
+		return fmt.Sprintf("%T", be)
+	}
+}
@@ -0,0 +1,155 @@
+// Copyright (c) 2026 Steve Taranto <staranto@gmail.com>.
+// SPDX-License-Identifier: Apache-2.0
+// no-cloc
+
+package backend
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v3"
+)
+
+// explainCommand returns a test command for rootDir with --explain-backend
+// set, and a buffer capturing the trace.
+func explainCommand(t *testing.T, rootDir string) (cli.Command, *bytes.Buffer) {
+	t.Helper()
+	var buf bytes.Buffer
+	cmd := newTestCommand(rootDir)
+	cmd.ErrWriter = &buf
+	cmd.Flags = []cli.Flag{&cli.BoolFlag{Name: "explain-backend", Value: true}}
+	return cmd, &buf
+}
+
+func TestNewBackend_Explain(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(t *testing.T, rootDir string)
+		want  []string
+	}{
+		{
+			name:  "naked remote",
+			setup: func(*testing.T, string) {},
+			want: []string{
+				"terraform.tfstate: not found",
+				"environment: not found",
+				"no init state, local state or environment file: using a remote backend",
+				"selected remote (host",
+			},
+		},
+		{
+			name: "inferred local",
+			setup: func(t *testing.T, rootDir string) {
+				require.NoError(t, os.WriteFile(filepath.Join(rootDir, "terraform.tfstate"), []byte(`{"serial":1}`), 0o600))
+			},
+			want: []string{
+				"terraform.tfstate: found",
+				"local state without init state: inferring a local backend",
+				"selected local (",
+			},
+		},
+		{
+			name: "local with workspaces",
+			setup: func(t *testing.T, rootDir string) {
+				dataDir := filepath.Join(rootDir, ".terraform")
+				require.NoError(t, os.MkdirAll(dataDir, 0o755))
+				require.NoError(t, os.WriteFile(filepath.Join(dataDir, "environment"), []byte("dev\n"), 0o600))
+			},
+			want: []string{
+				filepath.Join(".terraform", "environment") + ": found",
+				"environment file without init or local state: inferring a local backend with workspaces",
+				"selected local (",
+			},
+		},
+		{
+			name: "peeked local",
+			setup: func(t *testing.T, rootDir string) {
+				writeDataDir(t, filepath.Join(rootDir, ".terraform"), localInitState, "")
+			},
+			want: []string{
+				filepath.Join(".terraform", "terraform.tfstate") + ": found",
+				`peeked backend type "local" from`,
+				"selected local (",
+			},
+		},
+		{
+			name: "cloud transformed to remote",
+			setup: func(t *testing.T, rootDir string) {
+				writeDataDir(t, filepath.Join(rootDir, ".terraform"),
+					`{"version":3,"backend":{"type":"cloud","config":{"hostname":"app.terraform.io","organization":"acme","workspaces":{"name":"web"}},"hash":1}}`, "")
+			},
+			want: []string{
+				`peeked backend type "cloud" from`,
+				"cloud backend transformed to remote",
+				`selected remote (host "app.terraform.io", organization "acme", workspace "web")`,
+			},
+		},
+		{
+			name: "s3",
+			setup: func(t *testing.T, rootDir string) {
+				writeDataDir(t, filepath.Join(rootDir, ".terraform"),
+					`{"version":3,"backend":{"type":"s3","config":{"bucket":"states","key":"app/terraform.tfstate","region":"us-east-2"},"hash":1}}`, "")
+			},
+			want: []string{
+				`peeked backend type "s3" from`,
+				`selected s3 (bucket "states", key "app/terraform.tfstate", region "us-east-2"`,
+			},
+		},
+		{
+			name: "unreadable init state",
+			setup: func(t *testing.T, rootDir string) {
+				writeDataDir(t, filepath.Join(rootDir, ".terraform"), `{`, "")
+			},
+			want: []string{
+				"detection failed: malformed init state",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("TF_DATA_DIR", "")
+			dir := t.TempDir()
+			tt.setup(t, dir)
+			cmd, buf := explainCommand(t, dir)
+
+			_, _ = NewBackend(context.Background(), cmd)
+
+			out := buf.String()
+			assert.Contains(t, out, "backend: root dir "+dir)
+			for _, want := range tt.want {
+				assert.Contains(t, out, want)
+			}
+		})
+	}
+}
+
+func TestNewBackend_ExplainDataDir(t *testing.T) {
+	rootDir := t.TempDir()
+	t.Setenv("TF_DATA_DIR", "build/tf")
+	writeDataDir(t, filepath.Join(rootDir, "build", "tf"), localInitState, "")
+	cmd, buf := explainCommand(t, rootDir)
+
+	_, err := NewBackend(context.Background(), cmd)
+	require.NoError(t, err)
+	assert.Contains(t, buf.String(), "TF_DATA_DIR=build/tf relocates the data dir to "+filepath.Join(rootDir, "build", "tf"))
+}
+
+func TestNewBackend_ExplainOff(t *testing.T) {
+	rootDir := t.TempDir()
+	writeDataDir(t, filepath.Join(rootDir, ".terraform"), localInitState, "")
+
+	var buf bytes.Buffer
+	cmd := newTestCommand(rootDir)
+	cmd.ErrWriter = &buf
+
+	_, err := NewBackend(context.Background(), cmd)
+	require.NoError(t, err)
+	assert.Empty(t, buf.String())
+}
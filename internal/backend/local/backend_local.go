@@ -4,7 +4,6 @@
 package local
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -17,8 +16,10 @@ import (
 	tfe "github.com/hashicorp/go-tfe"
 	"github.com/urfave/cli/v3"
 
+	"github.com/staranto/tfctl/internal/backend/options"
 	"github.com/staranto/tfctl/internal/differ"
 	"github.com/staranto/tfctl/internal/svutil"
+	"github.com/staranto/tfctl/internal/util"
 )
 
 // BackendLocal is a struct that represents a local backend configuration.
@@ -26,6 +27,7 @@ import (
 type BackendLocal struct {
 	Ctx              context.Context
 	Cmd              *cli.Command
+	Options          options.Options
 	RootDir          string `json:"-" validate:"dir"`
 	EnvOverride      string
 	Version          int    `json:"version" validate:"gte=4"`
@@ -44,7 +46,10 @@ func (be *BackendLocal) DiffStates(ctx context.Context, cmd *cli.Command) ([][]b
 	// Fixup diffArgs
 	svSpecs := []string{"CSV~1", "CSV~0"}
 
-	diffArgs := differ.ParseDiffArgs(ctx, cmd)
+	diffArgs, err := differ.ParseDiffArgs(ctx, cmd)
+	if err != nil {
+		return nil, err
+	}
 
 	switch len(diffArgs) {
 	case 0:
@@ -78,17 +83,20 @@ func (be *BackendLocal) DiffStates(ctx context.Context, cmd *cli.Command) ([][]b
 		svSpecs = diffArgs
 	}
 
-	states, _ := be.States(svSpecs[0], svSpecs[1])
+	states, err := be.States(svSpecs[0], svSpecs[1])
+	if err != nil {
+		return nil, fmt.Errorf("failed to get states: %w", err)
+	}
 
 	return states, nil
 }
 
-func (be *BackendLocal) Runs() ([]*tfe.Run, error) {
+func (be *BackendLocal) Runs(augmenter ...func(context.Context, *cli.Command, *tfe.RunListForOrganizationOptions) error) ([]*tfe.Run, error) {
 	return nil, fmt.Errorf("not implemented")
 }
 
 func (be *BackendLocal) State() ([]byte, error) {
-	sv := be.Cmd.String("sv")
+	sv := be.opts().String("sv")
 	states, err := be.States(sv)
 	if err != nil {
 		return nil, err
@@ -106,12 +114,10 @@ func (be *BackendLocal) StateVersions(augmenter ...func(context.Context, *cli.Co
 	var versions []*tfe.StateVersion
 
 	// If there's a .terraform/environment file, we need to use that to
-	// determine the workspace directory.
+	// determine the workspace directory, unless --no-prefixed-workspace-file
+	// says to ignore it.
 	if be.EnvOverride == "" {
-		envFile := filepath.Join(be.RootDir, ".terraform/environment")
-		if envFileData, err := os.ReadFile(envFile); err == nil {
-			be.EnvOverride = string(bytes.TrimSpace(envFileData))
-		}
+		be.EnvOverride = util.Environment(be.RootDir, be.opts().Bool("no-prefixed-workspace-file"))
 	}
 
 	envPath := ""
@@ -182,7 +188,7 @@ func (be *BackendLocal) StateVersions(augmenter ...func(context.Context, *cli.Co
 		})
 	}
 
-	return versions, nil
+	return util.Limit(versions, be.opts().Int("limit")), nil
 }
 
 func (be *BackendLocal) States(specs ...string) ([][]byte, error) {
@@ -13,6 +13,9 @@ import (
 
 	"github.com/apex/log"
 	"github.com/urfave/cli/v3"
+
+	"github.com/staranto/tfctl/internal/backend/options"
+	"github.com/staranto/tfctl/internal/util"
 )
 
 type BackendLocalOption = func(ctx context.Context, cmd *cli.Command, be *BackendLocal) error
@@ -65,6 +68,15 @@ func WithDefaults() BackendLocalOption {
 	}
 }
 
+// WithOptions sets the options the backend consults before the flags of cmd,
+// see options.Options.
+func WithOptions(o options.Options) BackendLocalOption {
+	return func(ctx context.Context, cmd *cli.Command, be *BackendLocal) error {
+		be.Options = o
+		return nil
+	}
+}
+
 func WithEnvOverride(env string) BackendLocalOption {
 	return func(ctx context.Context, cmd *cli.Command, be *BackendLocal) error {
 		if env != "" {
@@ -97,7 +109,7 @@ func WithNoBackend(rootDir string) BackendLocalOption {
 // struct. It is simply a convenience method to make NewConfigLocal more
 // readable.
 func (be *BackendLocal) load(_ context.Context, _ *cli.Command) error {
-	tfFile := be.RootDir + "/.terraform/terraform.tfstate"
+	tfFile := filepath.Join(util.DataDir(be.RootDir), "terraform.tfstate")
 	data, err := os.ReadFile(tfFile)
 	if err != nil {
 		// Deal with a no terraform.backend {} situation. In this case, it looks
@@ -128,3 +140,13 @@ func (be *BackendLocal) load(_ context.Context, _ *cli.Command) error {
 
 	return nil
 }
+
+// opts returns the options the backend consults, falling back to the flags
+// of Cmd unless the options name a command of their own.
+func (be *BackendLocal) opts() *options.Options {
+	o := be.Options
+	if o.Cmd == nil {
+		o.Cmd = be.Cmd
+	}
+	return &o
+}
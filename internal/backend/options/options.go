@@ -0,0 +1,111 @@
+// Copyright (c) 2026 Steve Taranto <staranto@gmail.com>.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package options holds the settings the backends read, so that a backend can
+// be built from explicit values as well as from tfctl's command line.
+package options
+
+import (
+	"github.com/urfave/cli/v3"
+)
+
+// Options are the settings a backend consults before the flags of its
+// command. A zero field defers to the flag of the same name on Cmd, so tfctl
+// sets only what it resolves itself, while a program embedding the backends
+// can leave Cmd nil and set just the fields it needs.
+type Options struct {
+	// Cmd, when set, supplies the flags the fields below leave unset.
+	Cmd *cli.Command
+
+	// RootDir is the Terraform root directory the backend is read from. It
+	// defaults to the current directory.
+	RootDir string
+	// Env selects the workspace env in place of the environment file, like
+	// RootDir::env or --env.
+	Env string
+
+	Host      string // --host
+	Org       string // --org
+	Workspace string // --workspace
+	// Token is the API token of Host, used ahead of TF_TOKEN_<host>, TF_TOKEN
+	// and the credentials file.
+	Token string
+
+	SV              string // --sv
+	Limit           int    // --limit
+	Offline         bool   // --offline
+	NoWorkspaceFile bool   // --no-prefixed-workspace-file
+}
+
+// String returns the named string setting, else the flag of Cmd, else "".
+func (o *Options) String(name string) string {
+	if v := o.field(name); v != "" {
+		return v
+	}
+	if o == nil || o.Cmd == nil {
+		return ""
+	}
+	return o.Cmd.String(name)
+}
+
+// Int returns the named int setting, else the flag of Cmd, else 0.
+func (o *Options) Int(name string) int {
+	if o != nil && name == "limit" && o.Limit != 0 {
+		return o.Limit
+	}
+	if o == nil || o.Cmd == nil {
+		return 0
+	}
+	return o.Cmd.Int(name)
+}
+
+// Bool returns the named bool setting, else the flag of Cmd, else false.
+func (o *Options) Bool(name string) bool {
+	if o != nil {
+		switch {
+		case name == "offline" && o.Offline,
+			name == "no-prefixed-workspace-file" && o.NoWorkspaceFile:
+			return true
+		}
+	}
+	if o == nil || o.Cmd == nil {
+		return false
+	}
+	return o.Cmd.Bool(name)
+}
+
+// IsSet reports whether the named setting was given, either as a field or as
+// a flag of Cmd.
+func (o *Options) IsSet(name string) bool {
+	if o.field(name) != "" {
+		return true
+	}
+	return o != nil && o.Cmd != nil && o.Cmd.IsSet(name)
+}
+
+// CmdName returns the name of Cmd, or "" when there is none.
+func (o *Options) CmdName() string {
+	if o == nil || o.Cmd == nil {
+		return ""
+	}
+	return o.Cmd.Name
+}
+
+// field returns the string field standing in for the named flag, or "".
+func (o *Options) field(name string) string {
+	if o == nil {
+		return ""
+	}
+	switch name {
+	case "host":
+		return o.Host
+	case "org":
+		return o.Org
+	case "workspace":
+		return o.Workspace
+	case "sv":
+		return o.SV
+	default:
+		return ""
+	}
+}
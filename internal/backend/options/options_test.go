@@ -0,0 +1,62 @@
+// Copyright (c) 2026 Steve Taranto <staranto@gmail.com>.
+// SPDX-License-Identifier: Apache-2.0
+// no-cloc
+
+package options
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v3"
+)
+
+func TestOptions_NoCmd(t *testing.T) {
+	o := &Options{Host: "tfe.example.com", Limit: 3, Offline: true}
+
+	assert.Equal(t, "tfe.example.com", o.String("host"))
+	assert.True(t, o.IsSet("host"))
+	assert.Empty(t, o.String("org"))
+	assert.False(t, o.IsSet("org"))
+	assert.Equal(t, 3, o.Int("limit"))
+	assert.True(t, o.Bool("offline"))
+	assert.False(t, o.Bool("deep"))
+	assert.Empty(t, o.CmdName())
+
+	var none *Options
+	assert.Empty(t, none.String("host"))
+	assert.False(t, none.IsSet("host"))
+	assert.Zero(t, none.Int("limit"))
+	assert.False(t, none.Bool("offline"))
+}
+
+func TestOptions_Cmd(t *testing.T) {
+	cmd := &cli.Command{
+		Name: "sq",
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "host"},
+			&cli.StringFlag{Name: "org"},
+			&cli.IntFlag{Name: "limit"},
+			&cli.BoolFlag{Name: "deep"},
+		},
+		Action: func(_ context.Context, cmd *cli.Command) error {
+			// Fields win over flags; unset fields defer to them.
+			o := &Options{Cmd: cmd, Org: "acme"}
+			assert.Equal(t, "flag.example.com", o.String("host"))
+			assert.True(t, o.IsSet("host"))
+			assert.Equal(t, "acme", o.String("org"))
+			assert.True(t, o.IsSet("org"))
+			assert.Equal(t, 5, o.Int("limit"))
+			assert.True(t, o.Bool("deep"))
+			assert.Equal(t, "sq", o.CmdName())
+
+			o.Limit = 2
+			assert.Equal(t, 2, o.Int("limit"))
+			return nil
+		},
+	}
+	require.NoError(t, cmd.Run(context.Background(),
+		[]string{"sq", "--host", "flag.example.com", "--org", "flag", "--limit", "5", "--deep"}))
+}
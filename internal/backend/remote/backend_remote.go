@@ -4,36 +4,46 @@
 package remote
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"maps"
 	"os"
-	"path/filepath"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/apex/log"
 	"github.com/hashicorp/go-tfe"
 	"github.com/urfave/cli/v3"
 
+	"github.com/staranto/tfctl/internal/backend/options"
+	"github.com/staranto/tfctl/internal/cacheutil"
 	"github.com/staranto/tfctl/internal/config"
 	"github.com/staranto/tfctl/internal/differ"
 	"github.com/staranto/tfctl/internal/svutil"
+	"github.com/staranto/tfctl/internal/util"
 )
 
 type BackendRemote struct {
-	Ctx              context.Context
-	Cmd              *cli.Command
-	RootDir          string `json:"-" validate:"dir"`
-	EnvOverride      string
-	SvOverride       string
-	RunList          []*tfe.Run
-	StateVersionList []*tfe.StateVersion
-	Version          int    `json:"version" validate:"gte=4"`
-	TerraformVersion string `json:"terraform_version" validate:"semver"`
-	Backend          struct {
+	Ctx               context.Context
+	Cmd               *cli.Command
+	Options           options.Options
+	RootDir           string `json:"-" validate:"dir"`
+	EnvOverride       string
+	SvOverride        string
+	WorkspaceOverride string
+	Scheme            string                      // the host is reached with, https when empty
+	NewClient         func() (*tfe.Client, error) `json:"-"` // builds Client's client in place of one for the host, see WithClient
+	RunList           []*tfe.Run
+	StateVersionList  []*tfe.StateVersion
+	selectedName      string
+	Version           int    `json:"version" validate:"gte=4"`
+	TerraformVersion  string `json:"terraform_version" validate:"semver"`
+	Backend           struct {
 		Type   string `json:"type" validate:"eq=remote"`
 		Hash   int    `json:"hash"`
 		Config struct {
@@ -43,6 +53,12 @@ type BackendRemote struct {
 			Workspaces   struct {
 				Name   string `json:"name" validate:"required_without=Prefix"`
 				Prefix string `json:"prefix" validate:"required_without=Name"`
+
+				// Project, Tags and TagBindings select the workspace of a
+				// cloud block by tags rather than name, see Transform2Remote.
+				Project     string            `json:"-"`
+				Tags        []string          `json:"-"`
+				TagBindings map[string]string `json:"-"`
 			} `json:"workspaces"`
 		} `json:"config"`
 	} `json:"backend"`
@@ -57,11 +73,22 @@ var (
 	ErrNoCurrentStateVersion         = errors.New("no current state version")
 	ErrURLNotSupported               = errors.New("URL not supported")
 	ErrWorkspaceNameAndPrefixBothSet = errors.New("both workspace name and prefix are set")
+	ErrWorkspaceNotSet               = errors.New("workspace is not set")
+	ErrWorkspaceAmbiguous            = errors.New("workspace is ambiguous")
 )
 
+// clients holds the TFE clients made by Client, keyed by address and token.
+// Creating one pings the host, so a process running several queries, such as
+// tfctl batch, sets each one up once and reuses its connections.
+var clients sync.Map
+
 // Client optionally validates and returns a TFE client to the host specified
 // in the remote backend.
 func (be *BackendRemote) Client(validate ...bool) (*tfe.Client, error) {
+	if be.NewClient != nil {
+		return be.NewClient()
+	}
+
 	beCfg := be.Backend.Config
 
 	// Resolve token using standard precedence (env, config, credentials file).
@@ -70,12 +97,26 @@ func (be *BackendRemote) Client(validate ...bool) (*tfe.Client, error) {
 		return nil, fmt.Errorf("failed to resolve token: %w", err)
 	}
 
-	client, err := tfe.NewClient(&tfe.Config{
-		Address: "https://" + beCfg.Hostname,
-		Token:   token,
-	})
-	if err != nil {
-		return nil, fmt.Errorf("failed to create TFE client: %w", err)
+	scheme := be.Scheme
+	if scheme == "" {
+		scheme = "https"
+	}
+	address := scheme + "://" + beCfg.Hostname
+	key := address + "\x00" + token
+
+	var client *tfe.Client
+	if cached, ok := clients.Load(key); ok {
+		client = cached.(*tfe.Client) //nolint:forcetypeassert
+	} else {
+		client, err = tfe.NewClient(&tfe.Config{
+			Address:    address,
+			Token:      token,
+			HTTPClient: newHTTPClient(),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create TFE client: %w", err)
+		}
+		clients.Store(key, client)
 	}
 
 	if len(validate) > 0 && validate[0] {
@@ -91,7 +132,10 @@ func (be *BackendRemote) DiffStates(ctx context.Context, cmd *cli.Command) ([][]
 	// Fixup diffArgs
 	svSpecs := []string{"CSV~1", "CSV~0"}
 
-	diffArgs := differ.ParseDiffArgs(ctx, cmd)
+	diffArgs, err := differ.ParseDiffArgs(ctx, cmd)
+	if err != nil {
+		return nil, err
+	}
 
 	switch len(diffArgs) {
 	case 0:
@@ -103,13 +147,12 @@ func (be *BackendRemote) DiffStates(ctx context.Context, cmd *cli.Command) ([][]
 			// 	limit = l
 			// }
 
-			var err error
-			be.StateVersionList, err = be.StateVersions( /* TODO limit */ )
+			versions, err := be.StateVersions( /* TODO limit */ )
 			if err != nil {
 				return nil, err
 			}
 
-			selectedVersions := differ.SelectStateVersions(be.StateVersionList)
+			selectedVersions := differ.SelectStateVersions(versions)
 
 			log.Debugf("selectedVersions: %d", len(selectedVersions))
 
@@ -145,8 +188,8 @@ func (be *BackendRemote) Host() string {
 	var host string
 
 	// Precedence 1: --host flag
-	if be.Cmd.IsSet("host") {
-		host = be.Cmd.String("host")
+	if be.opts().IsSet("host") {
+		host = be.opts().String("host")
 		if host != "" {
 			return host
 		}
@@ -178,8 +221,8 @@ func (be *BackendRemote) Organization() (string, error) {
 	var org string
 
 	// Precedence 1: --org flag
-	if be.Cmd.IsSet("org") {
-		org = be.Cmd.String("org")
+	if be.opts().IsSet("org") {
+		org = be.opts().String("org")
 		if org != "" {
 			return org, nil
 		}
@@ -200,9 +243,11 @@ func (be *BackendRemote) Organization() (string, error) {
 	return "", fmt.Errorf("organization is not set (precedence: --org flag > backend.config.organization > tfctl.yaml org). Set --org or backend.config.organization: %w", ErrOrganizationNotSet)
 }
 
-func (be *BackendRemote) Runs() ([]*tfe.Run, error) {
+// Runs implements backend.Backend. It accepts an optional augmenter to apply
+// server-side filters before each API call.
+func (be *BackendRemote) Runs(augmenter ...func(context.Context, *cli.Command, *tfe.RunListForOrganizationOptions) error) ([]*tfe.Run, error) {
 	if len(be.RunList) > 0 {
-		log.Infof("be.RunList: preloaded with %d", len(be.RunList))
+		log.Debugf("be.RunList: preloaded with %d", len(be.RunList))
 		return be.RunList, nil
 	}
 
@@ -214,12 +259,9 @@ func (be *BackendRemote) Runs() ([]*tfe.Run, error) {
 		return nil, err
 	}
 
-	limit := be.Cmd.Int("limit")
-
-	pageSize := 100
-	if limit > 0 && limit < pageSize {
-		pageSize = limit
-	}
+	// --limit caps the rows returned; 0 means no cap.
+	limit := be.opts().Int("limit")
+	pageSize := util.PageSize(limit)
 
 	organization, err := be.Organization()
 	if err != nil {
@@ -239,6 +281,13 @@ func (be *BackendRemote) Runs() ([]*tfe.Run, error) {
 		ListOptions:    tfe.ListOptions{PageNumber: 1, PageSize: pageSize},
 	}
 
+	// Apply augmenter if provided (for server-side filtering)
+	if len(augmenter) > 0 && augmenter[0] != nil {
+		if err := augmenter[0](be.Ctx, be.Cmd, &options); err != nil {
+			return nil, fmt.Errorf("failed to augment run options: %w", err)
+		}
+	}
+
 	var results []*tfe.Run
 
 	// Paginate through the dataset
@@ -250,8 +299,7 @@ func (be *BackendRemote) Runs() ([]*tfe.Run, error) {
 
 		results = append(results, page.Items...)
 
-		if len(results) >= limit {
-			results = results[:limit]
+		if util.Limited(len(results), limit) {
 			break
 		}
 
@@ -263,11 +311,11 @@ func (be *BackendRemote) Runs() ([]*tfe.Run, error) {
 		options.ListOptions.PageNumber++
 	}
 
-	return results, nil
+	return util.Limit(results, limit), nil
 }
 
 func (be *BackendRemote) State() ([]byte, error) {
-	sv := be.Cmd.String("sv")
+	sv := be.opts().String("sv")
 	states, err := be.States(sv)
 	if err != nil {
 		return nil, err
@@ -288,9 +336,15 @@ func (be *BackendRemote) StateVersion(svSpecs ...string) (tfe.StateVersion, erro
 		svSpecs[0] = strings.ToUpper(svSpecs[0])
 	}
 
+	// Offline, CSV~0 is resolved from the cached listing like any other CSV~
+	// offset, since reading the workspace would need the network.
+	if svSpecs[0] == "" && be.offline() {
+		svSpecs[0] = "CSV~0"
+	}
+
 	// If no svid was passed in or it's CSV~0, we'll short circuit this and try to
 	// get the current state version.
-	if svSpecs[0] == "" || svSpecs[0] == "CSV~0" {
+	if (svSpecs[0] == "" || svSpecs[0] == "CSV~0") && !be.offline() {
 		workspace, err := be.Workspace()
 		if err != nil {
 			return tfe.StateVersion{}, fmt.Errorf("failed to get workspace: %w", err)
@@ -305,8 +359,9 @@ func (be *BackendRemote) StateVersion(svSpecs ...string) (tfe.StateVersion, erro
 	} else if strings.HasPrefix(svSpecs[0], "CSV~") {
 		// We've got to search through the state versions to be able to grab the
 		// relative one.
-		if be.StateVersionList == nil {
-			be.StateVersionList, _ = be.StateVersions()
+		versions, err := be.StateVersions()
+		if err != nil {
+			return tfe.StateVersion{}, err
 		}
 
 		parts := strings.Split(svSpecs[0], "~")
@@ -314,15 +369,19 @@ func (be *BackendRemote) StateVersion(svSpecs ...string) (tfe.StateVersion, erro
 		if err != nil {
 			return tfe.StateVersion{}, fmt.Errorf("invalid state version offset: %w", err)
 		}
+		if offset < 0 || offset >= len(versions) {
+			return tfe.StateVersion{}, fmt.Errorf("state version %s not found", svSpecs[0])
+		}
 
-		svSpecs[0] = be.StateVersionList[offset].ID
+		svSpecs[0] = versions[offset].ID
 	} else if serial, err := strconv.ParseInt(svSpecs[0], 10, 64); err == nil {
 		// If we've got an int, find that specific serial number.
-		if be.StateVersionList == nil {
-			be.StateVersionList, _ = be.StateVersions()
+		versions, err := be.StateVersions()
+		if err != nil {
+			return tfe.StateVersion{}, err
 		}
 
-		for _, sv := range be.StateVersionList {
+		for _, sv := range versions {
 			if sv.Serial == serial {
 				svSpecs[0] = sv.ID
 				break
@@ -342,6 +401,10 @@ func (be *BackendRemote) StateVersion(svSpecs ...string) (tfe.StateVersion, erro
 		return stateVersion, nil
 	}
 
+	if be.offline() {
+		return tfe.StateVersion{}, fmt.Errorf("state version %s: %w", svSpecs[0], cacheutil.ErrOfflineMiss)
+	}
+
 	client, err := be.Client()
 	if err != nil {
 		return tfe.StateVersion{}, fmt.Errorf("failed to get TFE client: %w", err)
@@ -364,38 +427,33 @@ func (be *BackendRemote) StateVersion(svSpecs ...string) (tfe.StateVersion, erro
 	return *stateVersion, nil
 }
 
+// stateVersionLimit returns the most state versions StateVersions needs to
+// list: --limit, where 0 means no cap, except for a plain sq or si. Without
+// --diff or --sv those only ever read the current state version, so there's no
+// need to paginate through the whole list, which makes a noticeable
+// difference on slow servers or workspaces with long histories.
+func (be *BackendRemote) stateVersionLimit() int {
+	opts := be.opts()
+	if (opts.CmdName() == "sq" || opts.CmdName() == "si") && opts.String("sv") == "0" && !opts.Bool("diff") {
+		return 1
+	}
+	return opts.Int("limit")
+}
+
 // StateVersions implements backend.Backend. It accepts an optional augmenter
-// to apply server-side filters before each API call.
+// to apply server-side filters before each API call. A plain listing, one the
+// augmenter left unchanged, is memoized in StateVersionList, so however many
+// times StateVersion, States and DiffStates need it, one query lists the state
+// versions at most once.
 func (be *BackendRemote) StateVersions(augmenter ...func(context.Context, *cli.Command, *tfe.StateVersionListOptions) error) ([]*tfe.StateVersion, error) {
-	if len(be.StateVersionList) > 0 {
-		log.Infof("be.StateVersionList: preloaded with %d", len(be.StateVersionList))
-		return be.StateVersionList, nil
+	if be.offline() {
+		return be.offlineStateVersions()
 	}
 
 	be.Backend.Config.Hostname = be.Host()
 
-	client, err := be.Client()
-	if err != nil {
-		log.WithError(err).Error("can't get client")
-		return nil, err
-	}
-
-	// Short-circuit this if we're in sq but not --diff and no sv is passed. This
-	// is the most common sq use case and there's no need to paginate through all
-	// the StateVersion records when we know we're always going to take the first
-	// one. This makes a noticeable performance difference on slow servers or
-	// workspaces with large SV lists.
-	diff := be.Cmd.Bool("diff")
-	sv := be.Cmd.String("sv")
-	limit := be.Cmd.Int("limit")
-	if (be.Cmd.Name == "sq" || be.Cmd.Name == "si") && sv == "0" && !diff {
-		limit = 1
-	}
-
-	pageSize := 100
-	if limit > 0 && limit < pageSize {
-		pageSize = limit
-	}
+	limit := be.stateVersionLimit()
+	pageSize := util.PageSize(limit)
 
 	organization, err := be.Organization()
 	if err != nil {
@@ -412,13 +470,26 @@ func (be *BackendRemote) StateVersions(augmenter ...func(context.Context, *cli.C
 	}
 
 	// Apply augmenter if provided (for server-side filtering)
+	unaugmented := options
 	if len(augmenter) > 0 && augmenter[0] != nil {
 		if err := augmenter[0](be.Ctx, be.Cmd, &options); err != nil {
 			return nil, fmt.Errorf("failed to augment state version options: %w", err)
 		}
 	}
+	plain := options == unaugmented
+
+	if plain && be.StateVersionList != nil {
+		log.Debugf("be.StateVersionList: preloaded with %d", len(be.StateVersionList))
+		return be.StateVersionList, nil
+	}
 
-	var results []*tfe.StateVersion
+	client, err := be.Client()
+	if err != nil {
+		log.WithError(err).Error("can't get client")
+		return nil, err
+	}
+
+	results := []*tfe.StateVersion{}
 
 	// Paginate through the dataset
 	for {
@@ -436,8 +507,7 @@ func (be *BackendRemote) StateVersions(augmenter ...func(context.Context, *cli.C
 
 		results = append(results, page.Items...)
 
-		if len(results) >= limit {
-			results = results[:limit]
+		if util.Limited(len(results), limit) {
 			break
 		}
 
@@ -448,9 +518,10 @@ func (be *BackendRemote) StateVersions(augmenter ...func(context.Context, *cli.C
 		}
 		options.ListOptions.PageNumber++
 	}
+	results = util.Limit(results, limit)
 
 	// Enrich each item by fetching its full details with includes if --deep is enabled.
-	if be.Cmd.Bool("deep") {
+	if be.opts().Bool("deep") {
 		for i := range results {
 			ro := &tfe.StateVersionReadOptions{
 				Include: []tfe.StateVersionIncludeOpt{
@@ -468,12 +539,30 @@ func (be *BackendRemote) StateVersions(augmenter ...func(context.Context, *cli.C
 		}
 	}
 
+	// Server-side filtered listings are partial, so only a plain listing is
+	// memoized and kept for --offline. A listing cut short by the limit only
+	// refreshes the head of the cached one, so a plain sq doesn't truncate the
+	// history an earlier svq cached.
+	if plain {
+		be.StateVersionList = results
+		listing := results
+		if limit > 0 {
+			if cached, ok := ListingCacheReader(be, workspace); ok {
+				listing = mergeListing(results, cached)
+			}
+		}
+		if err := ListingCacheWriter(be, workspace, listing); err != nil {
+			log.WithError(err).Warn("failed to write state version listing to cache")
+		}
+	}
+
 	return results, nil
 }
 
+// States implements backend.Backend. The state body for each resolved version
+// is downloaded concurrently, bounded by the parallelism config key, and
+// returned in spec order.
 func (be *BackendRemote) States(specs ...string) ([][]byte, error) {
-	var results [][]byte
-
 	candidates, err := be.StateVersions()
 	if err != nil {
 		return nil, err
@@ -484,16 +573,18 @@ func (be *BackendRemote) States(specs ...string) ([][]byte, error) {
 	}
 	log.Debugf("versions: %v", versions)
 
+	// Purge once up front rather than in every concurrent download.
+	be.purgeCache()
+
 	// Now pound through the found versions and return each of their state bodies.
-	for _, v := range versions {
-		doc, err := Hitter(be, v.DownloadURL)
+	parallelism, _ := config.GetInt("parallelism", svutil.DefaultParallelism)
+	return svutil.FetchAll(versions, parallelism, func(v *tfe.StateVersion) ([]byte, error) {
+		doc, err := hit(be, v.DownloadURL)
 		if err != nil {
 			return nil, fmt.Errorf("failed to get state: %w", err)
 		}
-		results = append(results, doc.Bytes())
-	}
-
-	return results, nil
+		return doc.Bytes(), nil
+	})
 }
 
 func (be *BackendRemote) String() string {
@@ -501,14 +592,22 @@ func (be *BackendRemote) String() string {
 	if beCopy.Backend.Config.Token != nil {
 		beCopy.Backend.Config.Token = "********"
 	}
+	if beCopy.Options.Token != "" {
+		beCopy.Options.Token = "********"
+	}
 	return fmt.Sprintf("ConfigRemote: %+v", beCopy)
 }
 
-// Token retrieves the token from the environment variable, config file, or
-// the credentials file, in that order.
+// Token retrieves the token from the options, the environment variable, config
+// file, or the credentials file, in that order.
 func (be *BackendRemote) Token() (string, error) {
 	var token string
 
+	// A token given in the options wins outright.
+	if be.Options.Token != "" {
+		return be.Options.Token, nil
+	}
+
 	// Figure out if Token needs to be overridden by an environment variable.
 	// The precedence is:
 	// 1. TF_TOKEN_app_terraform_io
@@ -561,6 +660,38 @@ func (be *BackendRemote) Token() (string, error) {
 	return token, nil
 }
 
+// CredentialHosts returns the hostnames that have a token in the Terraform
+// credentials file, sorted. A missing file yields no hosts and no error.
+func CredentialHosts() ([]string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user home directory: %w", err)
+	}
+
+	data, err := os.ReadFile(home + "/.terraform.d/credentials.tfrc.json")
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read credentials file: %w", err)
+	}
+
+	var creds struct {
+		Credentials map[string]json.RawMessage `json:"credentials"`
+	}
+	if err := json.Unmarshal(data, &creds); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal credentials file: %w", err)
+	}
+
+	hosts := make([]string, 0, len(creds.Credentials))
+	for host := range creds.Credentials {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+
+	return hosts, nil
+}
+
 func (be *BackendRemote) Type() (string, error) {
 	return be.Backend.Type, nil
 }
@@ -601,7 +732,10 @@ func (be *BackendRemote) Workspace() (*tfe.Workspace, error) {
 }
 
 func (be *BackendRemote) WorkspaceName() (string, error) {
-	ws := be.Cmd.String("workspace")
+	ws := be.opts().String("workspace")
+	if ws == "" {
+		ws = be.WorkspaceOverride
+	}
 	if ws != "" {
 		return ws, nil
 	}
@@ -618,20 +752,236 @@ func (be *BackendRemote) WorkspaceName() (string, error) {
 		return workspaces.Name, nil
 	}
 
+	if len(workspaces.Tags) > 0 || len(workspaces.TagBindings) > 0 {
+		return be.taggedWorkspaceName()
+	}
+
 	// This is going to be a "prefixed name". If the environment file exists, this
 	// is a multi-workspace configuration. The contents of that file along with
 	// Prefix are used to determine the actual state file path.
-	var env string
-	envFile := filepath.Join(be.RootDir, ".terraform/environment")
-	if envFileData, err := os.ReadFile(envFile); err == nil {
-		env = string(bytes.TrimSpace(envFileData))
-	}
+	env := util.Environment(be.RootDir, be.noWorkspaceFile())
 
 	if be.EnvOverride != "" {
 		env = be.EnvOverride
 	}
 
+	// Without an env, the workspaces starting with the prefix are listed. A
+	// backend with no prefix, such as one built from --host and --org, would
+	// list the whole organization, so it needs the workspace named instead.
+	if env == "" {
+		if workspaces.Prefix == "" {
+			return "", fmt.Errorf("backend selects no workspace by name, prefix or tags, "+
+				"select one with --workspace: %w", ErrWorkspaceNotSet)
+		}
+		return be.selectWorkspace(fmt.Sprintf("workspace prefix %q", workspaces.Prefix), be.prefixedWorkspaces)
+	}
+
 	name := workspaces.Prefix + env
 	log.Debugf("workspace prefixed name = %s", name)
 	return name, nil
 }
+
+// ForWorkspace returns a copy of be that queries the workspace name, as
+// RootDir@workspace selects it, so each workspace of --all-workspaces is read
+// through its own copy.
+func (be *BackendRemote) ForWorkspace(name string) *BackendRemote {
+	c := *be
+	c.WorkspaceOverride = name
+	c.selectedName = ""
+	c.RunList = nil
+	c.StateVersionList = nil
+	return &c
+}
+
+// WorkspaceNames returns every workspace the backend configuration can
+// select: the straight name, the workspaces starting with the prefix or, for a
+// cloud block, the workspaces carrying its tags. It is what --all-workspaces
+// queries, narrowed by any _name, _project and _tag filters.
+func (be *BackendRemote) WorkspaceNames() ([]string, error) {
+	workspaces := be.Backend.Config.Workspaces
+
+	switch {
+	case workspaces.Name != "":
+		return []string{workspaces.Name}, nil
+	case len(workspaces.Tags) > 0 || len(workspaces.TagBindings) > 0:
+		return be.taggedWorkspaces()
+	case workspaces.Prefix != "":
+		return be.prefixedWorkspaces()
+	default:
+		return nil, fmt.Errorf("backend selects no workspace by name, prefix or tags: %w", ErrWorkspaceNotSet)
+	}
+}
+
+// taggedWorkspaceName resolves the workspace of a cloud block that selects
+// workspaces by tags. The environment file, or RootDir::<env>, holds the full
+// name of the workspace selected with terraform workspace select. Without one,
+// the organization's workspaces carrying the tags, within the project if one
+// is set, are listed and the only match is used.
+func (be *BackendRemote) taggedWorkspaceName() (string, error) {
+	env := util.Environment(be.RootDir, be.noWorkspaceFile())
+	if be.EnvOverride != "" {
+		env = be.EnvOverride
+	}
+	if env != "" && env != "default" {
+		log.Debugf("tagged workspace name from environment: %s", env)
+		return env, nil
+	}
+
+	workspaces := be.Backend.Config.Workspaces
+	what := "workspace tagged " + tagsString(workspaces.Tags, workspaces.TagBindings)
+	return be.selectWorkspace(what, be.taggedWorkspaces)
+}
+
+// selectWorkspace resolves the workspace described by what, e.g. `workspace
+// prefix "app-"`, to the only one list returns. The result is remembered, so
+// the list is made at most once.
+func (be *BackendRemote) selectWorkspace(what string, list func() ([]string, error)) (string, error) {
+	if be.selectedName != "" {
+		return be.selectedName, nil
+	}
+
+	if be.offline() {
+		return "", fmt.Errorf("%s needs --workspace, --env or RootDir::<env> when offline: %w",
+			what, ErrWorkspaceNotSet)
+	}
+
+	names, err := list()
+	if err != nil {
+		return "", err
+	}
+
+	switch len(names) {
+	case 0:
+		return "", fmt.Errorf("%s matches no workspace: %w", what, ErrWorkspaceNotSet)
+	case 1:
+		log.Debugf("selected workspace name: %s", names[0])
+		be.selectedName = names[0]
+		return names[0], nil
+	default:
+		return "", fmt.Errorf("%s matches %d workspaces (%s), select one with --workspace, "+
+			"--env or RootDir::<env>, or query them all with --all-workspaces: %w",
+			what, len(names), quotedNames(names), ErrWorkspaceAmbiguous)
+	}
+}
+
+// maxQuotedNames is the most workspace names quotedNames lists.
+const maxQuotedNames = 5
+
+// quotedNames joins the first maxQuotedNames of names for an error message,
+// counting the rest.
+func quotedNames(names []string) string {
+	if len(names) <= maxQuotedNames {
+		return strings.Join(names, ", ")
+	}
+	return fmt.Sprintf("%s and %d more", strings.Join(names[:maxQuotedNames], ", "), len(names)-maxQuotedNames)
+}
+
+// prefixedWorkspaces lists the organization's workspaces whose names start
+// with the workspace prefix.
+func (be *BackendRemote) prefixedWorkspaces() ([]string, error) {
+	prefix := be.Backend.Config.Workspaces.Prefix
+
+	names, err := be.listWorkspaces(tfe.WorkspaceListOptions{WildcardName: prefix + "*"})
+	if err != nil {
+		return nil, err
+	}
+
+	// The wildcard matches the prefix anywhere on some servers.
+	return slices.DeleteFunc(names, func(name string) bool {
+		return !strings.HasPrefix(name, prefix)
+	}), nil
+}
+
+// taggedWorkspaces lists the organization's workspaces carrying the tag names
+// and key/value tags of a cloud block, within its project if one is set.
+func (be *BackendRemote) taggedWorkspaces() ([]string, error) {
+	workspaces := be.Backend.Config.Workspaces
+
+	options := tfe.WorkspaceListOptions{Tags: strings.Join(workspaces.Tags, ",")}
+	for _, key := range slices.Sorted(maps.Keys(workspaces.TagBindings)) {
+		options.TagBindings = append(options.TagBindings,
+			&tfe.TagBinding{Key: key, Value: workspaces.TagBindings[key]})
+	}
+
+	if workspaces.Project != "" {
+		client, org, err := be.orgClient()
+		if err != nil {
+			return nil, err
+		}
+		projects, err := client.Projects.List(be.Ctx, org, &tfe.ProjectListOptions{Name: workspaces.Project})
+		if err != nil {
+			return nil, FriendlyTFE(err, ErrorContext{
+				Host:      be.Backend.Config.Hostname,
+				Org:       org,
+				Operation: "list projects",
+				Resource:  "project",
+			})
+		}
+		for _, p := range projects.Items {
+			if p.Name == workspaces.Project {
+				options.ProjectID = p.ID
+			}
+		}
+		if options.ProjectID == "" {
+			return nil, fmt.Errorf("project %q: %w", workspaces.Project, ErrNotFound)
+		}
+	}
+
+	return be.listWorkspaces(options)
+}
+
+// listWorkspaces returns the names of the organization's workspaces matching
+// options, paging through all of them.
+func (be *BackendRemote) listWorkspaces(options tfe.WorkspaceListOptions) ([]string, error) {
+	client, org, err := be.orgClient()
+	if err != nil {
+		return nil, err
+	}
+
+	options.ListOptions = tfe.ListOptions{PageNumber: 1, PageSize: util.MaxPageSize}
+
+	var names []string
+	for {
+		page, err := client.Workspaces.List(be.Ctx, org, &options)
+		if err != nil {
+			return nil, FriendlyTFE(err, ErrorContext{
+				Host:      be.Backend.Config.Hostname,
+				Org:       org,
+				Operation: "list workspaces",
+				Resource:  "workspace",
+			})
+		}
+		for _, ws := range page.Items {
+			names = append(names, ws.Name)
+		}
+		if page.Pagination == nil || page.NextPage == 0 {
+			break
+		}
+		options.PageNumber = page.NextPage
+	}
+
+	return names, nil
+}
+
+// orgClient returns the TFE client and the organization to query.
+func (be *BackendRemote) orgClient() (*tfe.Client, string, error) {
+	client, err := be.Client()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get TFE client: %w", err)
+	}
+	org, err := be.Organization()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to resolve organization: %w", err)
+	}
+	return client, org, nil
+}
+
+// tagsString renders tag names and key/value tags for messages, e.g.
+// "app, env=prod".
+func tagsString(names []string, bindings map[string]string) string {
+	tags := slices.Clone(names)
+	for _, key := range slices.Sorted(maps.Keys(bindings)) {
+		tags = append(tags, key+"="+bindings[key])
+	}
+	return strings.Join(tags, ", ")
+}
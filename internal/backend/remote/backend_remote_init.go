@@ -12,9 +12,12 @@ import (
 	"path/filepath"
 
 	"github.com/apex/log"
+	"github.com/hashicorp/go-tfe"
 	"github.com/urfave/cli/v3"
 
+	"github.com/staranto/tfctl/internal/backend/options"
 	"github.com/staranto/tfctl/internal/config"
+	"github.com/staranto/tfctl/internal/util"
 )
 
 type BackendRemoteOption = func(ctx context.Context, cmd *cli.Command, be *BackendRemote) error
@@ -28,13 +31,15 @@ func BuckNaked() BackendRemoteOption {
 		// If host is not set explicitly (we're BuckNaked) *AND* there is a host:
 		// entry in the config, use it.  Otherwise, just fall through and continue
 		// with the default.
-		if !cmd.IsSet("host") {
+		if !be.opts().IsSet("host") {
 			if cfgHost, _ := config.GetString("host"); cfgHost != "" {
-				_ = cmd.Set("host", cfgHost)
+				if cmd != nil {
+					_ = cmd.Set("host", cfgHost)
+				}
 				be.Backend.Config.Hostname = cfgHost
 			}
 		} else {
-			be.Backend.Config.Hostname = cmd.String("host")
+			be.Backend.Config.Hostname = be.opts().String("host")
 			be.Backend.Config.Token, _ = be.Token()
 		}
 
@@ -95,6 +100,25 @@ func WithDefaults() BackendRemoteOption {
 	}
 }
 
+// WithOptions sets the options the backend consults before the flags of cmd,
+// see options.Options. It goes ahead of options reading them, such as
+// BuckNaked.
+func WithOptions(o options.Options) BackendRemoteOption {
+	return func(ctx context.Context, cmd *cli.Command, be *BackendRemote) error {
+		be.Options = o
+		return nil
+	}
+}
+
+// WithClient has the backend build its TFE client with newClient in place of
+// one for its host, such as a client bound to a test server.
+func WithClient(newClient func() (*tfe.Client, error)) BackendRemoteOption {
+	return func(ctx context.Context, cmd *cli.Command, be *BackendRemote) error {
+		be.NewClient = newClient
+		return nil
+	}
+}
+
 func WithEnvOverride(env string) BackendRemoteOption {
 	return func(ctx context.Context, cmd *cli.Command, be *BackendRemote) error {
 		if env != "" {
@@ -104,9 +128,18 @@ func WithEnvOverride(env string) BackendRemoteOption {
 	}
 }
 
+// WithWorkspaceOverride sets the workspace named by RootDir@workspace, used
+// like --workspace when that isn't given.
+func WithWorkspaceOverride(workspace string) BackendRemoteOption {
+	return func(ctx context.Context, cmd *cli.Command, be *BackendRemote) error {
+		be.WorkspaceOverride = workspace
+		return nil
+	}
+}
+
 func WithSvOverride() BackendRemoteOption {
 	return func(ctx context.Context, cmd *cli.Command, be *BackendRemote) error {
-		sv := cmd.String("sv")
+		sv := be.opts().String("sv")
 		if sv != "" {
 			be.SvOverride = sv
 		}
@@ -114,11 +147,21 @@ func WithSvOverride() BackendRemoteOption {
 	}
 }
 
+// opts returns the options the backend consults, falling back to the flags
+// of Cmd unless the options name a command of their own.
+func (be *BackendRemote) opts() *options.Options {
+	o := be.Options
+	if o.Cmd == nil {
+		o.Cmd = be.Cmd
+	}
+	return &o
+}
+
 // load reads the terraform config file and unmarshals it into the BackendRemote
 // struct. It is simply a convenience method to make NewBackendRemote more
 // readable.
 func (be *BackendRemote) load() error {
-	tfFile := be.RootDir + "/.terraform/terraform.tfstate"
+	tfFile := filepath.Join(util.DataDir(be.RootDir), "terraform.tfstate")
 	data, err := os.ReadFile(tfFile)
 	if err != nil {
 		return fmt.Errorf("failed to read local config file: %w", err)
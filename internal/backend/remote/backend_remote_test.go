@@ -0,0 +1,581 @@
+// Copyright (c) 2026 Steve Taranto <staranto@gmail.com>.
+// SPDX-License-Identifier: Apache-2.0
+// no-cloc
+
+package remote
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	tfe "github.com/hashicorp/go-tfe"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v3"
+)
+
+// listServer serves total runs and total state versions for workspace acme/web
+// in pages of the requested size, recording the page size and number of each
+// list request. Single state versions and their state bodies are served too,
+// but not recorded.
+type listServer struct {
+	total int
+
+	mu       sync.Mutex
+	requests []string
+}
+
+func (s *listServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/vnd.api+json")
+
+	var typ, prefix string
+	switch r.URL.Path {
+	case "/api/v2/ping":
+		w.WriteHeader(http.StatusNoContent)
+		return
+	case "/api/v2/organizations/acme/workspaces/web":
+		fmt.Fprint(w, `{"data":{"id":"ws-1","type":"workspaces","attributes":{"name":"web"}}}`)
+		return
+	case "/api/v2/organizations/acme/runs":
+		typ, prefix = "runs", "run"
+	case "/api/v2/state-versions":
+		typ, prefix = "state-versions", "sv"
+	default:
+		if id, ok := strings.CutPrefix(r.URL.Path, "/api/v2/state-versions/"); ok {
+			fmt.Fprintf(w, `{"data":%s}`, s.stateVersion(r, id))
+			return
+		}
+		if id, ok := strings.CutPrefix(r.URL.Path, "/state/"); ok {
+			fmt.Fprintf(w, `{"version":4,"lineage":%q}`, id)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	size, _ := strconv.Atoi(r.URL.Query().Get("page[size]"))
+	page, _ := strconv.Atoi(r.URL.Query().Get("page[number]"))
+	if page == 0 {
+		page = 1
+	}
+
+	s.mu.Lock()
+	s.requests = append(s.requests, fmt.Sprintf("%s:%d@%d", prefix, page, size))
+	s.mu.Unlock()
+
+	pages := (s.total + size - 1) / size
+	var items []string
+	for i := (page-1)*size + 1; i <= min(page*size, s.total); i++ {
+		if prefix == "sv" {
+			items = append(items, s.stateVersion(r, fmt.Sprintf("sv-%d", i)))
+			continue
+		}
+		items = append(items, fmt.Sprintf(`{"id":"%s-%d","type":"%s","attributes":{"serial":%d}}`, prefix, i, typ, s.total-i+1))
+	}
+	next := "null"
+	if page < pages {
+		next = strconv.Itoa(page + 1)
+	}
+	fmt.Fprintf(w, `{"data":[%s],"meta":{"pagination":{"current-page":%d,"next-page":%s,"total-pages":%d,"total-count":%d}}}`,
+		strings.Join(items, ","), page, next, pages, s.total)
+}
+
+// stateVersion returns the JSON:API resource for state version id, sv-N,
+// whose serial counts down from total.
+func (s *listServer) stateVersion(r *http.Request, id string) string {
+	n, _ := strconv.Atoi(strings.TrimPrefix(id, "sv-"))
+	return fmt.Sprintf(`{"id":%q,"type":"state-versions","attributes":{"serial":%d,"hosted-state-download-url":"http://%s/state/%s"}}`,
+		id, s.total-n+1, r.Host, id)
+}
+
+// newListBackend returns a BackendRemote for acme/web on srv, run as the
+// named command with args.
+func newListBackend(t *testing.T, srv *httptest.Server, name string, args ...string) *BackendRemote {
+	t.Helper()
+
+	t.Setenv("TF_TOKEN", "test")
+	t.Setenv("TFCTL_CACHE_DIR", t.TempDir())
+
+	var be *BackendRemote
+	cmd := &cli.Command{
+		Name: name,
+		Flags: []cli.Flag{
+			&cli.IntFlag{Name: "limit"},
+			&cli.StringFlag{Name: "sv", Value: "0"},
+			&cli.BoolFlag{Name: "diff"},
+			&cli.BoolFlag{Name: "offline"},
+			&cli.StringFlag{Name: "workspace"},
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			be = &BackendRemote{Ctx: ctx, Cmd: cmd, Scheme: "http"}
+			be.Backend.Config.Hostname = strings.TrimPrefix(srv.URL, "http://")
+			be.Backend.Config.Organization = "acme"
+			be.Backend.Config.Workspaces.Name = "web"
+			return nil
+		},
+	}
+	require.NoError(t, cmd.Run(context.Background(), append([]string{name}, args...)))
+	return be
+}
+
+// TestBackendRemote_WithClient verifies a backend built WithClient returns the
+// client newClient builds rather than one for its host.
+func TestBackendRemote_WithClient(t *testing.T) {
+	want := &tfe.Client{}
+	be, err := NewBackendRemote(context.Background(), nil, WithClient(func() (*tfe.Client, error) {
+		return want, nil
+	}))
+	require.NoError(t, err)
+	be.Backend.Config.Hostname = "unreachable.invalid"
+
+	got, err := be.Client()
+	require.NoError(t, err)
+	assert.Same(t, want, got)
+}
+
+func TestBackendRemote_Limit(t *testing.T) {
+	tests := []struct {
+		name         string
+		args         []string
+		wantRuns     int
+		wantVersions int
+		wantRequests []string
+	}{
+		{
+			name:         "no cap",
+			wantRuns:     250,
+			wantVersions: 250,
+			wantRequests: []string{"1@100", "2@100", "3@100"},
+		},
+		{
+			name:         "smaller than a page",
+			args:         []string{"--limit", "7"},
+			wantRuns:     7,
+			wantVersions: 7,
+			wantRequests: []string{"1@7"},
+		},
+		{
+			name:         "spanning pages",
+			args:         []string{"--limit", "150"},
+			wantRuns:     150,
+			wantVersions: 150,
+			wantRequests: []string{"1@100", "2@100"},
+		},
+		{
+			name:         "larger than the total",
+			args:         []string{"--limit", "1000"},
+			wantRuns:     250,
+			wantVersions: 250,
+			wantRequests: []string{"1@100", "2@100", "3@100"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ls := &listServer{total: 250}
+			srv := httptest.NewServer(ls)
+			t.Cleanup(srv.Close)
+
+			be := newListBackend(t, srv, "rq", tt.args...)
+
+			runs, err := be.Runs()
+			require.NoError(t, err)
+			require.Len(t, runs, tt.wantRuns)
+			assert.Equal(t, "run-1", runs[0].ID)
+
+			versions, err := be.StateVersions()
+			require.NoError(t, err)
+			require.Len(t, versions, tt.wantVersions)
+			assert.Equal(t, "sv-1", versions[0].ID)
+
+			var want []string
+			for _, prefix := range []string{"run", "sv"} {
+				for _, r := range tt.wantRequests {
+					want = append(want, prefix+":"+r)
+				}
+			}
+			assert.Equal(t, want, ls.requests)
+		})
+	}
+}
+
+func TestBackendRemote_StateVersionLimit(t *testing.T) {
+	ls := &listServer{total: 250}
+	srv := httptest.NewServer(ls)
+	t.Cleanup(srv.Close)
+
+	tests := []struct {
+		command string
+		args    []string
+		want    int
+	}{
+		// A plain sq only needs the current state version.
+		{command: "sq", want: 1},
+		{command: "si", want: 1},
+		{command: "sq", args: []string{"--diff"}, want: 0},
+		{command: "sq", args: []string{"--sv", "3"}, want: 0},
+		{command: "sq", args: []string{"--sv", "3", "--limit", "20"}, want: 20},
+		{command: "svq", want: 0},
+		{command: "svq", args: []string{"--limit", "5"}, want: 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(strings.Join(append([]string{tt.command}, tt.args...), " "), func(t *testing.T) {
+			be := newListBackend(t, srv, tt.command, tt.args...)
+			assert.Equal(t, tt.want, be.stateVersionLimit())
+		})
+	}
+}
+
+func TestBackendRemote_StateVersionsOnce(t *testing.T) {
+	ls := &listServer{total: 5}
+	srv := httptest.NewServer(ls)
+	t.Cleanup(srv.Close)
+
+	be := newListBackend(t, srv, "sq", "--sv", "CSV~1")
+	be.Backend.Config.Token = "test"
+
+	sv, err := be.StateVersion("CSV~1")
+	require.NoError(t, err)
+	assert.Equal(t, "sv-2", sv.ID)
+
+	sv, err = be.StateVersion("3")
+	require.NoError(t, err)
+	assert.Equal(t, "sv-3", sv.ID)
+
+	states, err := be.States("CSV~0", "CSV~4")
+	require.NoError(t, err)
+	require.Len(t, states, 2)
+	assert.Contains(t, string(states[1]), "sv-5")
+
+	// An augmenter that changes nothing still gets the memoized listing.
+	versions, err := be.StateVersions(func(context.Context, *cli.Command, *tfe.StateVersionListOptions) error {
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Len(t, versions, 5)
+
+	assert.Equal(t, []string{"sv:1@100"}, ls.requests)
+
+	// A server-side filter is listed on its own and doesn't replace the memo.
+	_, err = be.StateVersions(func(_ context.Context, _ *cli.Command, opts *tfe.StateVersionListOptions) error {
+		opts.PageSize = 2
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"sv:1@100", "sv:1@2", "sv:2@2", "sv:3@2"}, ls.requests)
+	assert.Len(t, be.StateVersionList, 5)
+}
+
+// TestBackendRemote_ListingCacheCapped verifies the one version a plain sq
+// lists doesn't replace the full listing cached for --offline.
+func TestBackendRemote_ListingCacheCapped(t *testing.T) {
+	ls := &listServer{total: 5}
+	srv := httptest.NewServer(ls)
+	t.Cleanup(srv.Close)
+	cacheDir := t.TempDir()
+
+	run := func(args ...string) *BackendRemote {
+		be := newListBackend(t, srv, "sq", args...)
+		be.Backend.Config.Token = "test"
+		t.Setenv("TFCTL_CACHE_DIR", cacheDir)
+		return be
+	}
+
+	sv, err := run("--sv", "CSV~1").StateVersion("CSV~1")
+	require.NoError(t, err)
+	assert.Equal(t, "sv-2", sv.ID)
+
+	versions, err := run().StateVersions()
+	require.NoError(t, err)
+	require.Len(t, versions, 1)
+
+	sv, err = run("--offline", "--sv", "CSV~1").StateVersion("CSV~1")
+	require.NoError(t, err)
+	assert.Equal(t, "sv-2", sv.ID)
+
+	cached, ok := ListingCacheReader(run(), "web")
+	require.True(t, ok)
+	assert.Len(t, cached, 5)
+}
+
+func TestMergeListing(t *testing.T) {
+	svs := func(ids ...string) []*tfe.StateVersion {
+		var out []*tfe.StateVersion
+		for _, id := range ids {
+			out = append(out, &tfe.StateVersion{ID: id})
+		}
+		return out
+	}
+	ids := func(versions []*tfe.StateVersion) []string {
+		var out []string
+		for _, v := range versions {
+			out = append(out, v.ID)
+		}
+		return out
+	}
+
+	tests := []struct {
+		name          string
+		fresh, cached []string
+		want          []string
+	}{
+		{name: "same head", fresh: []string{"sv-3"}, cached: []string{"sv-3", "sv-2", "sv-1"}, want: []string{"sv-3", "sv-2", "sv-1"}},
+		{name: "new head", fresh: []string{"sv-4", "sv-3"}, cached: []string{"sv-3", "sv-2", "sv-1"}, want: []string{"sv-4", "sv-3", "sv-2", "sv-1"}},
+		{name: "no overlap", fresh: []string{"sv-9"}, cached: []string{"sv-3", "sv-2"}, want: []string{"sv-9"}},
+		{name: "empty", fresh: nil, cached: []string{"sv-1"}, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ids(mergeListing(svs(tt.fresh...), svs(tt.cached...))))
+		})
+	}
+}
+
+// workspaceServer serves the workspaces of organization acme, filtered by
+// wildcard name, tag names, key/value tags and project the way TFE does,
+// counting the list requests.
+func workspaceServer(t *testing.T, requests *int) *httptest.Server {
+	t.Helper()
+
+	type workspace struct {
+		name, project string
+		tags          []string
+		bindings      map[string]string
+	}
+	workspaces := []workspace{
+		{name: "web-prod", project: "prj-1", tags: []string{"app", "prod"}, bindings: map[string]string{"env": "prod"}},
+		{name: "web-dev", project: "prj-1", tags: []string{"app", "dev"}, bindings: map[string]string{"env": "dev"}},
+		{name: "db-prod", project: "prj-2", tags: []string{"db", "prod"}, bindings: map[string]string{"env": "prod"}},
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		q := r.URL.Query()
+
+		switch r.URL.Path {
+		case "/api/v2/ping":
+			w.WriteHeader(http.StatusNoContent)
+		case "/api/v2/organizations/acme/projects":
+			var items []string
+			for id, name := range map[string]string{"prj-1": "platform", "prj-2": "data"} {
+				if q.Get("filter[names]") == name {
+					items = append(items, fmt.Sprintf(`{"id":%q,"type":"projects","attributes":{"name":%q}}`, id, name))
+				}
+			}
+			fmt.Fprintf(w, `{"data":[%s]}`, strings.Join(items, ","))
+		case "/api/v2/organizations/acme/workspaces":
+			*requests++
+			var items []string
+		next:
+			for _, ws := range workspaces {
+				if id := q.Get("filter[project][id]"); id != "" && id != ws.project {
+					continue
+				}
+				if wildcard := q.Get("search[wildcard-name]"); !strings.HasPrefix(ws.name, strings.TrimSuffix(wildcard, "*")) {
+					continue
+				}
+				if tags := q.Get("search[tags]"); tags != "" {
+					for _, tag := range strings.Split(tags, ",") {
+						if !slices.Contains(ws.tags, tag) {
+							continue next
+						}
+					}
+				}
+				for i := 0; q.Has(fmt.Sprintf("filter[tagged][%d][key]", i)); i++ {
+					key := q.Get(fmt.Sprintf("filter[tagged][%d][key]", i))
+					if ws.bindings[key] != q.Get(fmt.Sprintf("filter[tagged][%d][value]", i)) {
+						continue next
+					}
+				}
+				items = append(items, fmt.Sprintf(`{"id":"ws-%[1]s","type":"workspaces","attributes":{"name":%[1]q}}`, ws.name))
+			}
+			fmt.Fprintf(w, `{"data":[%s],"meta":{"pagination":{"current-page":1,"next-page":null,"total-pages":1,"total-count":%d}}}`,
+				strings.Join(items, ","), len(items))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestWorkspaceName_Tags(t *testing.T) {
+	tests := []struct {
+		name         string
+		project      string
+		tags         []string
+		bindings     map[string]string
+		env          string
+		want         string
+		wantErr      error
+		wantRequests int
+	}{
+		{name: "unique tags", tags: []string{"app", "prod"}, want: "web-prod", wantRequests: 1},
+		{name: "ambiguous tags", tags: []string{"app"}, wantErr: ErrWorkspaceAmbiguous, wantRequests: 1},
+		{name: "no match", tags: []string{"app", "db"}, wantErr: ErrWorkspaceNotSet, wantRequests: 1},
+		{name: "key/value tags", bindings: map[string]string{"env": "prod"}, wantErr: ErrWorkspaceAmbiguous, wantRequests: 1},
+		{name: "key/value tags in a project", project: "data", bindings: map[string]string{"env": "prod"}, want: "db-prod", wantRequests: 1},
+		{name: "unknown project", project: "nope", tags: []string{"app"}, wantErr: ErrNotFound},
+		{name: "selected workspace", tags: []string{"app"}, env: "web-dev", want: "web-dev"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests int
+			be := newListBackend(t, workspaceServer(t, &requests), "sq")
+			be.RootDir = t.TempDir()
+			be.EnvOverride = tt.env
+			be.Backend.Config.Workspaces.Name = ""
+			be.Backend.Config.Workspaces.Project = tt.project
+			be.Backend.Config.Workspaces.Tags = tt.tags
+			be.Backend.Config.Workspaces.TagBindings = tt.bindings
+
+			got, err := be.WorkspaceName()
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+
+				// The resolved name is remembered.
+				got, err = be.WorkspaceName()
+				require.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+			assert.Equal(t, tt.wantRequests, requests)
+		})
+	}
+}
+
+func TestWorkspaceName_Prefix(t *testing.T) {
+	tests := []struct {
+		name    string
+		prefix  string
+		env     string
+		want    string
+		wantErr error
+	}{
+		{name: "single match", prefix: "db-", want: "db-prod"},
+		{name: "several matches", prefix: "web-", wantErr: ErrWorkspaceAmbiguous},
+		{name: "no match", prefix: "api-", wantErr: ErrWorkspaceNotSet},
+		{name: "env", prefix: "web-", env: "dev", want: "web-dev"},
+		{name: "no prefix", wantErr: ErrWorkspaceNotSet},
+		{name: "no prefix with env", env: "web-dev", want: "web-dev"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests int
+			be := newListBackend(t, workspaceServer(t, &requests), "svq")
+			be.RootDir = t.TempDir()
+			be.EnvOverride = tt.env
+			be.Backend.Config.Workspaces.Name = ""
+			be.Backend.Config.Workspaces.Prefix = tt.prefix
+
+			got, err := be.WorkspaceName()
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				if tt.prefix == "" {
+					assert.Zero(t, requests, "no prefix must not list the organization")
+				}
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestQuotedNames(t *testing.T) {
+	assert.Equal(t, "a, b", quotedNames([]string{"a", "b"}))
+	assert.Equal(t, "a, b, c, d, e and 2 more", quotedNames([]string{"a", "b", "c", "d", "e", "f", "g"}))
+}
+
+func TestWorkspaceNames(t *testing.T) {
+	var requests int
+	be := newListBackend(t, workspaceServer(t, &requests), "svq")
+
+	names, err := be.WorkspaceNames()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"web"}, names)
+	assert.Zero(t, requests)
+
+	be.Backend.Config.Workspaces.Name = ""
+	be.Backend.Config.Workspaces.Prefix = "web-"
+	names, err = be.WorkspaceNames()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"web-prod", "web-dev"}, names)
+
+	be.Backend.Config.Workspaces.Prefix = ""
+	be.Backend.Config.Workspaces.Tags = []string{"prod"}
+	names, err = be.WorkspaceNames()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"web-prod", "db-prod"}, names)
+
+	be.Backend.Config.Workspaces.Tags = nil
+	_, err = be.WorkspaceNames()
+	require.ErrorIs(t, err, ErrWorkspaceNotSet)
+}
+
+func TestWorkspaceName_NoWorkspaceFile(t *testing.T) {
+	rootDir := t.TempDir()
+	t.Setenv("TF_DATA_DIR", "")
+	require.NoError(t, os.MkdirAll(filepath.Join(rootDir, ".terraform"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(rootDir, ".terraform", "environment"), []byte("dev\n"), 0o600))
+
+	tests := []struct {
+		name              string
+		args              []string
+		envOverride       string
+		workspaceOverride string
+		want              string
+		wantErr           error
+	}{
+		{name: "environment file", want: "app-dev"},
+		{name: "RootDir@workspace", workspaceOverride: "app-prod", want: "app-prod"},
+		{name: "--workspace over RootDir@workspace", args: []string{"--workspace", "app-qa"}, workspaceOverride: "app-prod", want: "app-qa"},
+		{name: "ignored offline", args: []string{"--no-prefixed-workspace-file", "--offline"}, wantErr: ErrWorkspaceNotSet},
+		{name: "ignored with --workspace", args: []string{"--no-prefixed-workspace-file", "--workspace", "app-prod"}, want: "app-prod"},
+		{name: "ignored with RootDir::env", args: []string{"--no-prefixed-workspace-file"}, envOverride: "qa", want: "app-qa"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			var err error
+			cmd := &cli.Command{
+				Name: "svq",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{Name: "no-prefixed-workspace-file"},
+					&cli.BoolFlag{Name: "offline"},
+					&cli.StringFlag{Name: "workspace"},
+				},
+				Action: func(ctx context.Context, cmd *cli.Command) error {
+					be := &BackendRemote{Ctx: ctx, Cmd: cmd, RootDir: rootDir,
+						EnvOverride: tt.envOverride, WorkspaceOverride: tt.workspaceOverride}
+					be.Backend.Config.Workspaces.Prefix = "app-"
+					got, err = be.WorkspaceName()
+					return nil
+				},
+			}
+			require.NoError(t, cmd.Run(context.Background(), append([]string{"svq"}, tt.args...)))
+
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -4,10 +4,17 @@
 package remote
 
 import (
+	"encoding/json"
+	"fmt"
 	"os"
+	"slices"
+
+	"github.com/apex/log"
+	"github.com/hashicorp/go-tfe"
 
 	"github.com/staranto/tfctl/internal/cacheutil"
 	"github.com/staranto/tfctl/internal/config"
+	"github.com/staranto/tfctl/internal/util"
 )
 
 // CacheEntry is provided by cacheutil.Entry; local alias removed to avoid duplication.
@@ -33,16 +40,107 @@ func CacheReader(be *BackendRemote, key string) (*cacheutil.Entry, bool) {
 	return cacheutil.Read([]string{hostname, organization}, key)
 }
 
+// CacheWriter stores data for the given key beneath the same hostname and
+// organization subdirectories used by CacheReader.
 func CacheWriter(be *BackendRemote, key string, data []byte) error {
 	hostname, organization := getOverrides(be)
 	return cacheutil.Write([]string{hostname, organization}, key, data)
 }
 
+// ListingCacheReader returns the last state version listing of workspace that
+// StateVersions cached.
+func ListingCacheReader(be *BackendRemote, workspace string) ([]*tfe.StateVersion, bool) {
+	entry, ok := CacheReader(be, listingKey(workspace))
+	if !ok {
+		return nil, false
+	}
+
+	var versions []*tfe.StateVersion
+	if err := json.Unmarshal(entry.Data, &versions); err != nil {
+		return nil, false
+	}
+	return versions, true
+}
+
+// ListingCacheWriter stores the state version listing of workspace.
+func ListingCacheWriter(be *BackendRemote, workspace string, versions []*tfe.StateVersion) error {
+	data, err := json.Marshal(versions)
+	if err != nil {
+		return err
+	}
+	return CacheWriter(be, listingKey(workspace), data)
+}
+
+// mergeListing returns the capped listing fresh followed by the versions of
+// cached older than the last one it reaches. If the two don't overlap, cached
+// is too stale to extend fresh without a gap and fresh is returned as is.
+func mergeListing(fresh, cached []*tfe.StateVersion) []*tfe.StateVersion {
+	if len(fresh) == 0 {
+		return fresh
+	}
+
+	oldest := fresh[len(fresh)-1].ID
+	for i, sv := range cached {
+		if sv.ID == oldest {
+			return append(slices.Clip(fresh), cached[i+1:]...)
+		}
+	}
+	return fresh
+}
+
+// listingKey returns the cache key of the state version listing of workspace.
+func listingKey(workspace string) string {
+	return "state-versions:" + workspace
+}
+
+// offline reports whether --offline is set, in which case the backend serves
+// only from the cache.
+func (be *BackendRemote) offline() bool {
+	return be.opts().Bool("offline")
+}
+
+// noWorkspaceFile reports whether --no-prefixed-workspace-file is set, in
+// which case the environment file is never read.
+func (be *BackendRemote) noWorkspaceFile() bool {
+	return be.opts().Bool("no-prefixed-workspace-file")
+}
+
+// offlineStateVersions returns the cached state version listing of the
+// workspace in place of listing it from the API, trimmed to --limit.
+func (be *BackendRemote) offlineStateVersions() ([]*tfe.StateVersion, error) {
+	workspace, err := be.WorkspaceName()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get workspace name: %w", err)
+	}
+
+	versions, ok := ListingCacheReader(be, workspace)
+	if !ok {
+		return nil, fmt.Errorf("state versions of workspace %s: %w", workspace, cacheutil.ErrOfflineMiss)
+	}
+
+	return util.Limit(versions, be.opts().Int("limit")), nil
+}
+
+// PurgeCache removes cache files older than the cache.clean config value, in
+// hours.
 func PurgeCache() error {
 	cleanHours, _ := config.GetInt("cache.clean")
 	return cacheutil.Purge(cleanHours)
 }
 
+// purgeCache purges the cache with PurgeCache, logging a failure. Offline, the
+// cache is all there is, so it is left alone.
+func (be *BackendRemote) purgeCache() {
+	if be.offline() {
+		return
+	}
+	if err := PurgeCache(); err != nil {
+		log.WithError(err).Warn("failed to purge cache")
+	}
+}
+
+// getOverrides returns the hostname and organization used as the cache
+// subdirectories, honoring the TFE_HOSTNAME and TFE_ORGANIZATION overrides.
 func getOverrides(be *BackendRemote) (hostname, organization string) {
 	hostname = be.Backend.Config.Hostname
 	if h, ok := os.LookupEnv("TFE_HOSTNAME"); ok {
@@ -0,0 +1,150 @@
+// Copyright (c) 2026 Steve Taranto <staranto@gmail.com>.
+// SPDX-License-Identifier: Apache-2.0
+// no-cloc
+
+package remote
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/go-tfe"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v3"
+
+	"github.com/staranto/tfctl/internal/cacheutil"
+)
+
+// newCacheTestBackend returns a backend for host/org with the cache rooted in
+// a fresh temp dir, which is returned alongside it.
+func newCacheTestBackend(t *testing.T, host, org string) (*BackendRemote, string) {
+	t.Helper()
+	base := t.TempDir()
+	t.Setenv("TFCTL_CACHE_DIR", base)
+	t.Setenv("TFCTL_CACHE", "")
+	for _, k := range []string{"TFE_HOSTNAME", "TFE_ORGANIZATION"} {
+		t.Setenv(k, "")
+		require.NoError(t, os.Unsetenv(k))
+	}
+
+	be := &BackendRemote{}
+	be.Backend.Config.Hostname = host
+	be.Backend.Config.Organization = org
+	return be, base
+}
+
+func hashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestCacheWriter_UsesCacheutilBaseDir(t *testing.T) {
+	be, base := newCacheTestBackend(t, "app.terraform.io", "acme")
+
+	key := "https://app.terraform.io/api/v2/state-versions/sv-123"
+	require.NoError(t, CacheWriter(be, key, []byte(`{"serial":1}`)))
+
+	want := filepath.Join(base, "app.terraform.io", "acme", hashKey(key))
+	assert.FileExists(t, want)
+
+	p, ok := CacheEntryPath(be, key)
+	assert.True(t, ok)
+	assert.Equal(t, want, p)
+
+	entry, ok := CacheReader(be, key)
+	require.True(t, ok)
+	assert.Equal(t, `{"serial":1}`, string(entry.Data))
+	assert.Equal(t, want, entry.Path)
+}
+
+func TestCacheWriter_EnvOverrides(t *testing.T) {
+	be, base := newCacheTestBackend(t, "app.terraform.io", "acme")
+	t.Setenv("TFE_HOSTNAME", "tfe.example.com")
+	t.Setenv("TFE_ORGANIZATION", "globex")
+
+	require.NoError(t, CacheWriter(be, "key", []byte("data")))
+	assert.FileExists(t, filepath.Join(base, "tfe.example.com", "globex", hashKey("key")))
+}
+
+func TestCacheWriter_Disabled(t *testing.T) {
+	be, base := newCacheTestBackend(t, "app.terraform.io", "acme")
+	t.Setenv("TFCTL_CACHE", "0")
+
+	require.NoError(t, CacheWriter(be, "key", []byte("data")))
+	_, ok := CacheReader(be, "key")
+	assert.False(t, ok)
+
+	entries, err := os.ReadDir(base)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+// parsedCmd returns a command with the flags the backend reads, parsed from
+// args.
+func parsedCmd(t *testing.T, args ...string) *cli.Command {
+	t.Helper()
+	var parsed *cli.Command
+	cmd := &cli.Command{
+		Name: "sq",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{Name: "offline"},
+			&cli.IntFlag{Name: "limit"},
+			&cli.StringFlag{Name: "workspace"},
+		},
+		Action: func(_ context.Context, c *cli.Command) error {
+			parsed = c
+			return nil
+		},
+	}
+	require.NoError(t, cmd.Run(context.Background(), append([]string{"sq"}, args...)))
+	return parsed
+}
+
+func TestOfflineStateVersions(t *testing.T) {
+	be, _ := newCacheTestBackend(t, "app.terraform.io", "acme")
+	be.Backend.Config.Workspaces.Name = "web"
+	be.Cmd = parsedCmd(t, "--offline")
+
+	_, err := be.StateVersions()
+	require.ErrorIs(t, err, cacheutil.ErrOfflineMiss)
+	assert.ErrorContains(t, err, "workspace web")
+
+	versions := []*tfe.StateVersion{{ID: "sv-2", Serial: 2}, {ID: "sv-1", Serial: 1}}
+	require.NoError(t, ListingCacheWriter(be, "web", versions))
+
+	got, err := be.StateVersions()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"sv-2", "sv-1"}, []string{got[0].ID, got[1].ID})
+
+	be.Cmd = parsedCmd(t, "--offline", "--limit", "1")
+	got, err = be.StateVersions()
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.Equal(t, "sv-2", got[0].ID)
+}
+
+func TestOfflineStateVersion(t *testing.T) {
+	be, _ := newCacheTestBackend(t, "app.terraform.io", "acme")
+	be.Backend.Config.Workspaces.Name = "web"
+	be.Cmd = parsedCmd(t, "--offline")
+	require.NoError(t, ListingCacheWriter(be, "web", []*tfe.StateVersion{{ID: "sv-2"}, {ID: "sv-1"}}))
+
+	// CSV~0 resolves through the cached listing, but the version itself is
+	// not cached yet.
+	_, err := be.StateVersion()
+	require.ErrorIs(t, err, cacheutil.ErrOfflineMiss)
+	assert.ErrorContains(t, err, "sv-2")
+
+	require.NoError(t, CacheWriter(be, "sv-1", []byte(`{"id":"sv-1","serial":1}`)))
+	sv, err := be.StateVersion("CSV~1")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), sv.Serial)
+
+	_, err = be.StateVersion("CSV~5")
+	assert.ErrorContains(t, err, "state version CSV~5 not found")
+}
@@ -5,26 +5,41 @@ package remote
 
 import (
 	"bytes"
+	"compress/flate"
+	"compress/gzip"
 	"context"
+	"encoding/base64"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"strings"
 
 	"github.com/apex/log"
+	"github.com/hashicorp/go-tfe"
+
+	"github.com/staranto/tfctl/internal/cacheutil"
 )
 
 // TODO Doesn't belong in this package.
 // THINK Needs to take a CacheEntry.
 func Hitter(be *BackendRemote, url string) (bytes.Buffer, error) {
+	be.purgeCache()
+	return hit(be, url)
+}
 
-	if err := PurgeCache(); err != nil {
-		log.WithError(err).Warn("failed to purge cache")
-	}
-
+// hit is Hitter without the cache purge, for States, which purges once before
+// downloading its versions concurrently.
+func hit(be *BackendRemote, url string) (bytes.Buffer, error) {
 	if entry, ok := CacheReader(be, url); ok {
 		log.Debugf("cache hit: %s", entry.Path)
 		return *bytes.NewBuffer(entry.Data), nil
 	}
 
+	if be.offline() {
+		return bytes.Buffer{}, fmt.Errorf("state %s: %w", url, cacheutil.ErrOfflineMiss)
+	}
+
 	ctx := context.Background()
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
@@ -35,21 +50,117 @@ func Hitter(be *BackendRemote, url string) (bytes.Buffer, error) {
 	//nolint:forcetypeassert
 	req.Header.Set("Authorization", "Bearer "+be.Backend.Config.Token.(string))
 
-	http := &http.Client{}
-	resp, err := http.Do(req)
+	resp, err := newHTTPClient().Do(req)
 	if err != nil {
 		return bytes.Buffer{}, fmt.Errorf("failed to execute request: %w", err)
 	}
 	defer resp.Body.Close()
 
-	var doc bytes.Buffer
-	if _, err := doc.ReadFrom(resp.Body); err != nil {
+	// An error body must never be decoded, let alone cached as the state.
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		workspace, _ := be.WorkspaceName()
+		return bytes.Buffer{}, FriendlyTFE(statusError(resp), ErrorContext{
+			Host:      be.Backend.Config.Hostname,
+			Org:       be.Backend.Config.Organization,
+			Workspace: workspace,
+			Operation: "download state",
+			Resource:  "stateversion",
+		})
+	}
+
+	var raw bytes.Buffer
+	if _, err := raw.ReadFrom(resp.Body); err != nil {
 		return bytes.Buffer{}, fmt.Errorf("failed to read response: %w", err)
 	}
 
+	// The transport only undoes the gzip it negotiated itself, so anything
+	// else is left to decodeBody.
+	encoding := resp.Header.Get("Content-Encoding")
+	if resp.Uncompressed {
+		encoding = ""
+	}
+	body, err := decodeBody(raw.Bytes(), encoding)
+	if err != nil {
+		return bytes.Buffer{}, fmt.Errorf("failed to decode response: %w", err)
+	}
+	doc := *bytes.NewBuffer(body)
+
 	if err := CacheWriter(be, url, doc.Bytes()); err != nil {
 		log.WithError(err).Warn("failed to write state to cache")
 	}
 
 	return doc, nil
 }
+
+// statusError returns the error for a response with a non-2xx status, in the
+// form go-tfe reports it, so FriendlyTFE recognizes it.
+func statusError(resp *http.Response) error {
+	switch resp.StatusCode {
+	case http.StatusUnauthorized:
+		return tfe.ErrUnauthorized
+	case http.StatusNotFound:
+		return tfe.ErrResourceNotFound
+	}
+	return errors.New(resp.Status)
+}
+
+// maxBodyDecodes bounds how many encoding layers decodeBody will peel off.
+const maxBodyDecodes = 4
+
+// decodeBody returns the plain state document in body. It first undoes the
+// Content-Encoding named by encoding (gzip or deflate), then keeps peeling off
+// layers it can recognize by content: gzip by its magic bytes, and base64 when
+// the body is not already JSON. A body in neither form is returned unchanged.
+func decodeBody(body []byte, encoding string) ([]byte, error) {
+	var err error
+
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "", "identity":
+	case "gzip", "x-gzip":
+		if body, err = gunzip(body); err != nil {
+			return nil, err
+		}
+	case "deflate":
+		if body, err = io.ReadAll(flate.NewReader(bytes.NewReader(body))); err != nil {
+			return nil, fmt.Errorf("deflate: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported content encoding %q", encoding)
+	}
+
+	for range maxBodyDecodes {
+		trimmed := bytes.TrimSpace(body)
+		switch {
+		case bytes.HasPrefix(trimmed, []byte{0x1f, 0x8b}):
+			if body, err = gunzip(trimmed); err != nil {
+				return nil, err
+			}
+		case len(trimmed) > 0 && trimmed[0] != '{' && trimmed[0] != '[':
+			decoded, err := base64.StdEncoding.DecodeString(string(trimmed))
+			if err != nil {
+				// Not base64 either; let the JSON pipeline report it.
+				return body, nil
+			}
+			body = decoded
+		default:
+			return body, nil
+		}
+	}
+
+	return body, nil
+}
+
+// gunzip decompresses a gzip stream.
+func gunzip(body []byte) ([]byte, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("gzip: %w", err)
+	}
+	defer zr.Close()
+
+	out, err := io.ReadAll(zr)
+	if err != nil {
+		return nil, fmt.Errorf("gzip: %w", err)
+	}
+	return out, nil
+}
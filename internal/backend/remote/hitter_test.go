@@ -0,0 +1,157 @@
+// Copyright (c) 2026 Steve Taranto <staranto@gmail.com>.
+// SPDX-License-Identifier: Apache-2.0
+// no-cloc
+
+package remote
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/staranto/tfctl/internal/cacheutil"
+)
+
+const hitterState = `{"version":4,"serial":7,"resources":[]}`
+
+func gzipBytes(t *testing.T, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	_, err := zw.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+	return buf.Bytes()
+}
+
+func TestHitter_Bodies(t *testing.T) {
+	gz := gzipBytes(t, []byte(hitterState))
+
+	tests := []struct {
+		name     string
+		encoding string
+		body     []byte
+	}{
+		{"plain", "", []byte(hitterState)},
+		{"gzip content-encoding", "gzip", gz},
+		{"gzip without header", "", gz},
+		{"base64", "", []byte(base64.StdEncoding.EncodeToString([]byte(hitterState)))},
+		{"base64 gzip", "", []byte(base64.StdEncoding.EncodeToString(gz) + "\n")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+				if tt.encoding != "" {
+					w.Header().Set("Content-Encoding", tt.encoding)
+				}
+				_, _ = w.Write(tt.body)
+			}))
+			t.Cleanup(srv.Close)
+
+			be, _ := newCacheTestBackend(t, "app.terraform.io", "acme")
+			be.Backend.Config.Token = "secret"
+
+			doc, err := Hitter(be, srv.URL+"/state")
+			require.NoError(t, err)
+			assert.JSONEq(t, hitterState, doc.String())
+
+			// The decoded document is what gets cached.
+			entry, ok := CacheReader(be, srv.URL+"/state")
+			require.True(t, ok)
+			assert.JSONEq(t, hitterState, string(entry.Data))
+		})
+	}
+}
+
+// TestHitter_Status verifies an error response is reported, not decoded or
+// cached as the state, and a transient one is retried.
+func TestHitter_Status(t *testing.T) {
+	tests := []struct {
+		name     string
+		failures int
+		status   int
+		wantErr  error
+	}{
+		{name: "unauthorized", failures: 1, status: http.StatusUnauthorized, wantErr: ErrAuthFailed},
+		{name: "forbidden", failures: 1, status: http.StatusForbidden, wantErr: ErrForbidden},
+		{name: "not found", failures: 1, status: http.StatusNotFound, wantErr: ErrNotFound},
+		{name: "retried", failures: 1, status: http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var hits atomic.Int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if int(hits.Add(1)) <= tt.failures {
+					w.WriteHeader(tt.status)
+					_, _ = w.Write([]byte(`{"errors":[{"status":"` + strconv.Itoa(tt.status) + `"}]}`))
+					return
+				}
+				_, _ = w.Write([]byte(hitterState))
+			}))
+			t.Cleanup(srv.Close)
+
+			be, _ := newCacheTestBackend(t, "app.terraform.io", "acme")
+			be.Backend.Config.Token = "secret"
+
+			doc, err := Hitter(be, srv.URL+"/state")
+			if tt.wantErr == nil {
+				require.NoError(t, err)
+				assert.JSONEq(t, hitterState, doc.String())
+				assert.Equal(t, int32(2), hits.Load())
+				return
+			}
+
+			require.ErrorIs(t, err, tt.wantErr)
+			assert.ErrorContains(t, err, "download state")
+			_, ok := CacheReader(be, srv.URL+"/state")
+			assert.False(t, ok, "an error body must not be cached")
+		})
+	}
+}
+
+func TestDecodeBody_Errors(t *testing.T) {
+	_, err := decodeBody([]byte("not gzip"), "gzip")
+	assert.ErrorContains(t, err, "gzip")
+
+	_, err = decodeBody([]byte(hitterState), "br")
+	assert.ErrorContains(t, err, `unsupported content encoding "br"`)
+
+	// Bodies that are neither JSON nor an encoding pass through untouched.
+	body, err := decodeBody([]byte("<html>oops</html>"), "")
+	require.NoError(t, err)
+	assert.Equal(t, "<html>oops</html>", string(body))
+}
+
+func TestHitter_Offline(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		_, _ = w.Write([]byte(hitterState))
+	}))
+	t.Cleanup(srv.Close)
+
+	be, _ := newCacheTestBackend(t, "app.terraform.io", "acme")
+	be.Backend.Config.Token = "secret"
+	be.Cmd = parsedCmd(t, "--offline")
+
+	_, err := Hitter(be, srv.URL+"/state")
+	require.ErrorIs(t, err, cacheutil.ErrOfflineMiss)
+
+	require.NoError(t, CacheWriter(be, srv.URL+"/state", []byte(hitterState)))
+	doc, err := Hitter(be, srv.URL+"/state")
+	require.NoError(t, err)
+	assert.JSONEq(t, hitterState, doc.String())
+
+	assert.Zero(t, hits.Load(), "offline must never reach the server")
+}
@@ -0,0 +1,155 @@
+// Copyright (c) 2026 Steve Taranto <staranto@gmail.com>.
+// SPDX-License-Identifier: Apache-2.0
+
+package remote
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/apex/log"
+	"github.com/hashicorp/go-cleanhttp"
+
+	"github.com/staranto/tfctl/internal/config"
+)
+
+const (
+	// DefaultRetries is the number of times a request failing with a 429 or
+	// 5xx is retried when the retries config key is not set.
+	DefaultRetries = 3
+
+	// DefaultRetryDelay is the wait, in milliseconds, before the first retry
+	// when the retry_delay config key is not set.
+	DefaultRetryDelay = 500
+
+	// maxRetryBackoff caps the exponential backoff. A Retry-After sent by the
+	// server is honored even when it is longer.
+	maxRetryBackoff = 30 * time.Second
+)
+
+// retryTransport is an http.RoundTripper that retries GET and HEAD requests
+// answered with 429 or a 5xx, waiting delay, 2*delay, 4*delay and so on
+// between attempts unless the response carries a Retry-After. Each list page
+// is a single request, so a retried page is only ever collected once.
+type retryTransport struct {
+	next    http.RoundTripper
+	retries int
+	delay   time.Duration
+
+	// sleep waits for d or until the request is canceled. It is a field so
+	// tests don't have to wait.
+	sleep func(req *http.Request, d time.Duration) error
+}
+
+// newHTTPClient returns a pooled HTTP client whose transient 429s and 5xx are
+// retried per request, see retryTransport. The TFE client and the state
+// downloads both use one.
+func newHTTPClient() *http.Client {
+	c := cleanhttp.DefaultPooledClient()
+	c.Transport = newRetryTransport(c.Transport)
+	return c
+}
+
+// newRetryTransport returns a retryTransport configured from the retries and
+// retry_delay config keys.
+func newRetryTransport(next http.RoundTripper) *retryTransport {
+	retries, _ := config.GetInt("retries", DefaultRetries)
+	delay, _ := config.GetInt("retry_delay", DefaultRetryDelay)
+
+	return &retryTransport{
+		next:    next,
+		retries: max(retries, 0),
+		delay:   time.Duration(max(delay, 0)) * time.Millisecond,
+		sleep:   sleepContext,
+	}
+}
+
+// RoundTrip implements http.RoundTripper. When the retries are exhausted on a
+// 429 an error is returned rather than the response, so go-tfe's own rate limit
+// retries don't start the cycle over. An exhausted 5xx is returned as is.
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		return t.next.RoundTrip(req)
+	}
+
+	for attempt := 0; ; attempt++ {
+		resp, err := t.next.RoundTrip(req)
+		if err != nil || !retryable(resp.StatusCode) {
+			return resp, err
+		}
+
+		if attempt >= t.retries {
+			if resp.StatusCode == http.StatusTooManyRequests && t.retries > 0 {
+				resp.Body.Close()
+				return nil, fmt.Errorf("%s: giving up after %d retries", resp.Status, t.retries)
+			}
+			return resp, nil
+		}
+
+		wait := t.backoff(attempt, resp)
+		resp.Body.Close()
+		log.Debugf("retrying %s %s after %s in %s (%d/%d)",
+			req.Method, req.URL.Path, resp.Status, wait, attempt+1, t.retries)
+
+		if err := t.sleep(req, wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// backoff returns how long to wait before retry attempt+1: the response's
+// Retry-After if it has one, else delay doubled per attempt, capped at
+// maxRetryBackoff.
+func (t *retryTransport) backoff(attempt int, resp *http.Response) time.Duration {
+	if wait, ok := retryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+		return wait
+	}
+
+	wait := t.delay
+	for range attempt {
+		wait *= 2
+		if wait >= maxRetryBackoff {
+			return maxRetryBackoff
+		}
+	}
+	return min(wait, maxRetryBackoff)
+}
+
+// retryable reports whether a response with status is worth retrying.
+func retryable(status int) bool {
+	return status == http.StatusTooManyRequests || status >= http.StatusInternalServerError
+}
+
+// retryAfter parses a Retry-After header, given either in seconds or as an
+// HTTP date relative to now.
+func retryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	if when, err := http.ParseTime(value); err == nil {
+		return max(when.Sub(now), 0), true
+	}
+	return 0, false
+}
+
+// sleepContext waits for d, returning early with the context's error if req
+// is canceled.
+func sleepContext(req *http.Request, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-req.Context().Done():
+		return req.Context().Err()
+	case <-timer.C:
+		return nil
+	}
+}
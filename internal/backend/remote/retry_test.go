@@ -0,0 +1,277 @@
+// Copyright (c) 2026 Steve Taranto <staranto@gmail.com>.
+// SPDX-License-Identifier: Apache-2.0
+// no-cloc
+
+package remote
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	tfe "github.com/hashicorp/go-tfe"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testRetryTransport returns a retryTransport that records its waits instead
+// of sleeping.
+func testRetryTransport(retries int, waits *[]time.Duration) *retryTransport {
+	return &retryTransport{
+		next:    http.DefaultTransport,
+		retries: retries,
+		delay:   100 * time.Millisecond,
+		sleep: func(_ *http.Request, d time.Duration) error {
+			*waits = append(*waits, d)
+			return nil
+		},
+	}
+}
+
+// flakyServer answers the first failures requests with status and the rest
+// with 200, counting every request in hits.
+func flakyServer(t *testing.T, failures int, status int, header http.Header, hits *atomic.Int32) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if int(hits.Add(1)) <= failures {
+			for k, v := range header {
+				w.Header()[k] = v
+			}
+			w.WriteHeader(status)
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestRetryTransport(t *testing.T) {
+	tests := []struct {
+		name      string
+		method    string
+		failures  int
+		status    int
+		header    http.Header
+		retries   int
+		wantCode  int
+		wantErr   string
+		wantHits  int32
+		wantWaits []time.Duration
+	}{
+		{
+			name:      "server error backs off exponentially",
+			method:    http.MethodGet,
+			failures:  3,
+			status:    http.StatusServiceUnavailable,
+			retries:   3,
+			wantCode:  http.StatusOK,
+			wantHits:  4,
+			wantWaits: []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond},
+		},
+		{
+			name:      "rate limit honors Retry-After",
+			method:    http.MethodGet,
+			failures:  1,
+			status:    http.StatusTooManyRequests,
+			header:    http.Header{"Retry-After": {"7"}},
+			retries:   3,
+			wantCode:  http.StatusOK,
+			wantHits:  2,
+			wantWaits: []time.Duration{7 * time.Second},
+		},
+		{
+			name:      "server error exhausted",
+			method:    http.MethodGet,
+			failures:  5,
+			status:    http.StatusBadGateway,
+			retries:   2,
+			wantCode:  http.StatusBadGateway,
+			wantHits:  3,
+			wantWaits: []time.Duration{100 * time.Millisecond, 200 * time.Millisecond},
+		},
+		{
+			name:      "rate limit exhausted",
+			method:    http.MethodGet,
+			failures:  5,
+			status:    http.StatusTooManyRequests,
+			retries:   1,
+			wantErr:   "429 Too Many Requests: giving up after 1 retries",
+			wantHits:  2,
+			wantWaits: []time.Duration{100 * time.Millisecond},
+		},
+		{
+			name:     "disabled",
+			method:   http.MethodGet,
+			failures: 1,
+			status:   http.StatusTooManyRequests,
+			retries:  0,
+			wantCode: http.StatusTooManyRequests,
+			wantHits: 1,
+		},
+		{
+			name:     "client error",
+			method:   http.MethodGet,
+			failures: 1,
+			status:   http.StatusNotFound,
+			retries:  3,
+			wantCode: http.StatusNotFound,
+			wantHits: 1,
+		},
+		{
+			name:     "post",
+			method:   http.MethodPost,
+			failures: 1,
+			status:   http.StatusServiceUnavailable,
+			retries:  3,
+			wantCode: http.StatusServiceUnavailable,
+			wantHits: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var hits atomic.Int32
+			var waits []time.Duration
+			srv := flakyServer(t, tt.failures, tt.status, tt.header, &hits)
+
+			req, err := http.NewRequest(tt.method, srv.URL, nil)
+			require.NoError(t, err)
+
+			resp, err := testRetryTransport(tt.retries, &waits).RoundTrip(req)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+				defer resp.Body.Close()
+				assert.Equal(t, tt.wantCode, resp.StatusCode)
+			}
+			assert.Equal(t, tt.wantHits, hits.Load())
+			assert.Equal(t, tt.wantWaits, waits)
+		})
+	}
+}
+
+func TestRetryTransport_Canceled(t *testing.T) {
+	var hits atomic.Int32
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://app.terraform.io/api/v2/ping", nil)
+	require.NoError(t, err)
+
+	transport := &retryTransport{next: roundTripFunc(func(*http.Request) (*http.Response, error) {
+		hits.Add(1)
+		return &http.Response{StatusCode: http.StatusServiceUnavailable, Body: http.NoBody, Header: http.Header{}}, nil
+	}), retries: 3, delay: time.Hour, sleep: sleepContext}
+
+	_, err = transport.RoundTrip(req)
+	require.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, int32(1), hits.Load())
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestRetryTransport_Backoff(t *testing.T) {
+	transport := &retryTransport{delay: 10 * time.Second}
+	resp := &http.Response{Header: http.Header{}}
+
+	assert.Equal(t, 10*time.Second, transport.backoff(0, resp))
+	assert.Equal(t, 20*time.Second, transport.backoff(1, resp))
+	assert.Equal(t, maxRetryBackoff, transport.backoff(2, resp))
+	assert.Equal(t, maxRetryBackoff, transport.backoff(60, resp))
+
+	// A Retry-After longer than the cap is still honored.
+	resp.Header.Set("Retry-After", "120")
+	assert.Equal(t, 2*time.Minute, transport.backoff(0, resp))
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		value  string
+		want   time.Duration
+		wantOK bool
+	}{
+		{value: "", wantOK: false},
+		{value: "0", want: 0, wantOK: true},
+		{value: "30", want: 30 * time.Second, wantOK: true},
+		{value: "-1", wantOK: false},
+		{value: now.Add(90 * time.Second).Format(http.TimeFormat), want: 90 * time.Second, wantOK: true},
+		{value: now.Add(-time.Minute).Format(http.TimeFormat), want: 0, wantOK: true},
+		{value: "soon", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, ok := retryAfter(tt.value, now)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestRetryTransport_Pagination(t *testing.T) {
+	// Page 2 fails twice mid-pagination. Every organization must still be
+	// listed exactly once.
+	var page2 atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		if r.URL.Path == "/api/v2/ping" {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		page, _ := strconv.Atoi(r.URL.Query().Get("page[number]"))
+		if page == 0 {
+			page = 1
+		}
+		if page == 2 && page2.Add(1) <= 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		next := "null"
+		if page < 3 {
+			next = strconv.Itoa(page + 1)
+		}
+		fmt.Fprintf(w, `{"data":[{"type":"organizations","id":"org-%[1]d","attributes":{"name":"org-%[1]d"}}],`+
+			`"meta":{"pagination":{"current-page":%[1]d,"next-page":%[2]s,"total-pages":3,"total-count":3}}}`, page, next)
+	}))
+	t.Cleanup(srv.Close)
+
+	var waits []time.Duration
+	client, err := tfe.NewClient(&tfe.Config{
+		Address:    srv.URL,
+		Token:      "test",
+		HTTPClient: &http.Client{Transport: testRetryTransport(3, &waits)},
+	})
+	require.NoError(t, err)
+
+	var names []string
+	options := &tfe.OrganizationListOptions{ListOptions: tfe.ListOptions{PageNumber: 1, PageSize: 1}}
+	for {
+		orgs, err := client.Organizations.List(context.Background(), options)
+		require.NoError(t, err)
+		for _, org := range orgs.Items {
+			names = append(names, org.Name)
+		}
+		if orgs.NextPage == 0 {
+			break
+		}
+		options.PageNumber = orgs.NextPage
+	}
+
+	assert.Equal(t, []string{"org-1", "org-2", "org-3"}, names)
+	assert.Equal(t, int32(3), page2.Load())
+	assert.Len(t, waits, 2)
+}
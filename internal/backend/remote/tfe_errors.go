@@ -6,6 +6,8 @@ package remote
 import (
 	"errors"
 	"fmt"
+	"net/http"
+	"strings"
 
 	tfe "github.com/hashicorp/go-tfe"
 )
@@ -19,6 +21,27 @@ type ErrorContext struct {
 	Resource  string // e.g., "organization", "workspace"
 }
 
+// Sentinel errors FriendlyTFE maps common API failures to. Callers can match
+// them with errors.Is regardless of how go-tfe reported the failure, and the
+// original error still matches as well.
+var (
+	ErrAuthFailed  = errors.New("authentication failed")
+	ErrForbidden   = errors.New("permission denied")
+	ErrNotFound    = errors.New("not found")
+	ErrRateLimited = errors.New("rate limited")
+)
+
+// friendlyError is a user-facing message that unwraps to both its sentinel and
+// the underlying error.
+type friendlyError struct {
+	msg  string
+	kind error
+	err  error
+}
+
+func (e *friendlyError) Error() string   { return e.msg }
+func (e *friendlyError) Unwrap() []error { return []error{e.kind, e.err} }
+
 // FriendlyTFE wraps a TFE error with a contextual, user-friendly message while
 // preserving the original error for further inspection via errors.Is/As.
 func FriendlyTFE(err error, ctx ErrorContext) error {
@@ -27,26 +50,77 @@ func FriendlyTFE(err error, ctx ErrorContext) error {
 	}
 
 	host := nonEmpty(ctx.Host, "<unknown>")
+	op := nonEmpty(ctx.Operation, "request")
+	friendly := func(kind error, format string, args ...any) error {
+		return &friendlyError{msg: fmt.Sprintf(format, args...), kind: kind, err: err}
+	}
 
-	// Map well-known go-tfe sentinel errors to friendly text with pseudo-status.
-	switch {
-	case errors.Is(err, tfe.ErrUnauthorized):
-		// No need to append the original sentinel here (would read as "unauthorized").
-		return fmt.Errorf("%s on %s: authentication failed (401). Set %s or TF_TOKEN",
-			nonEmpty(ctx.Operation, "request"), host, hostEnvKey(ctx.Host))
+	switch apiStatus(err) {
+	case http.StatusUnauthorized:
+		return friendly(ErrAuthFailed, "%s on %s: authentication failed (401). The token is taken from %s, "+
+			"the first one set wins; check it is present and not expired",
+			op, host, tokenSources(ctx.Host))
 
-	case errors.Is(err, tfe.ErrResourceNotFound):
+	case http.StatusForbidden:
+		return friendly(ErrForbidden, "%s on %s: permission denied (403) for organization %q workspace %q. "+
+			"The token is valid but lacks access; check the token from %s",
+			op, host, nonEmpty(ctx.Org, "<unknown>"), ctx.Workspace, tokenSources(ctx.Host))
+
+	case http.StatusNotFound:
 		if ctx.Workspace != "" {
-			return fmt.Errorf("%s: workspace %q not found in organization %q on %s (404)",
-				nonEmpty(ctx.Operation, "request"), ctx.Workspace, nonEmpty(ctx.Org, "<unknown>"), host)
+			return friendly(ErrNotFound, "%s: workspace %q not found in organization %q on %s (404). "+
+				"Check --workspace, --org and --host, or that the token can see the workspace",
+				op, ctx.Workspace, nonEmpty(ctx.Org, "<unknown>"), host)
 		}
-		return fmt.Errorf("%s: organization %q not found on %s (404)",
-			nonEmpty(ctx.Operation, "request"), nonEmpty(ctx.Org, "<unknown>"), host)
+		return friendly(ErrNotFound, "%s: organization %q not found on %s (404). "+
+			"Check --org and --host, or that the token can see the organization",
+			op, nonEmpty(ctx.Org, "<unknown>"), host)
+
+	case http.StatusTooManyRequests:
+		return friendly(ErrRateLimited, "%s on %s: rate limited (429) after retrying. "+
+			"Reduce the number of requests with --limit or a server-side --filter, "+
+			"or retry later with backoff",
+			op, host)
 	}
 
 	// Unknown error: provide generic context and wrap
 	return fmt.Errorf("%s on %s for org=%q workspace=%q: %w",
-		nonEmpty(ctx.Operation, "request"), host, ctx.Org, ctx.Workspace, err)
+		op, host, ctx.Org, ctx.Workspace, err)
+}
+
+// apiStatus returns the HTTP status behind a go-tfe error, or 0 if it can't be
+// told. Only 401 and 404 have go-tfe sentinels. Other failures are reported
+// with the error titles of the response, or its status line when it had none,
+// so 403 and 429 are recognized by their text.
+func apiStatus(err error) int {
+	switch {
+	case errors.Is(err, tfe.ErrUnauthorized):
+		return http.StatusUnauthorized
+	case errors.Is(err, tfe.ErrResourceNotFound):
+		return http.StatusNotFound
+	}
+
+	msg := strings.ToLower(err.Error())
+	switch {
+	case strings.HasPrefix(msg, "403 ") || strings.HasPrefix(msg, "forbidden"):
+		return http.StatusForbidden
+	case strings.HasPrefix(msg, "429 ") || strings.Contains(msg, "too many requests") ||
+		strings.Contains(msg, "rate limit"):
+		return http.StatusTooManyRequests
+	}
+	return 0
+}
+
+// tokenSources describes where a token for host is looked up, in the order
+// BackendRemote.Token() tries them, starting with an explicit options.Token,
+// which is named as such since the command line has no flag for it.
+func tokenSources(host string) string {
+	sources := []string{"the Token option of the tfctl Go API"}
+	if key := hostEnvKey(host); key != "" {
+		sources = append(sources, key)
+	}
+	sources = append(sources, "TF_TOKEN", "the backend's token", "~/.terraform.d/credentials.tfrc.json")
+	return strings.Join(sources, ", ")
 }
 
 func hostEnvKey(host string) string {
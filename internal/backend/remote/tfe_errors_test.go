@@ -0,0 +1,138 @@
+// Copyright (c) 2026 Steve Taranto <staranto@gmail.com>.
+// SPDX-License-Identifier: Apache-2.0
+// no-cloc
+
+package remote
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	tfe "github.com/hashicorp/go-tfe"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// apiError returns the error go-tfe reports when reading an organization
+// answers with status and body.
+func apiError(t *testing.T, status int, body string) error {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		if r.URL.Path == "/api/v2/ping" {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+
+	client, err := tfe.NewClient(&tfe.Config{Address: srv.URL, Token: "test"})
+	require.NoError(t, err)
+
+	_, err = client.Organizations.Read(context.Background(), "acme")
+	require.Error(t, err)
+	return err
+}
+
+func TestFriendlyTFE(t *testing.T) {
+	ctx := ErrorContext{Host: "app.terraform.io", Org: "acme", Workspace: "web", Operation: "read workspace"}
+
+	tests := []struct {
+		name     string
+		err      error
+		ctx      ErrorContext
+		sentinel error
+		want     []string
+	}{
+		{
+			name:     "unauthorized",
+			err:      apiError(t, http.StatusUnauthorized, `{"errors":[{"status":"401","title":"unauthorized"}]}`),
+			ctx:      ctx,
+			sentinel: ErrAuthFailed,
+			want:     []string{"read workspace on app.terraform.io: authentication failed (401)", "the Token option of the tfctl Go API, TF_TOKEN_app_terraform_io, TF_TOKEN, the backend's token"},
+		},
+		{
+			name:     "forbidden",
+			err:      apiError(t, http.StatusForbidden, `{"errors":[{"status":"403","title":"forbidden"}]}`),
+			ctx:      ctx,
+			sentinel: ErrForbidden,
+			want:     []string{`permission denied (403) for organization "acme" workspace "web"`},
+		},
+		{
+			name:     "forbidden without payload",
+			err:      apiError(t, http.StatusForbidden, ``),
+			ctx:      ctx,
+			sentinel: ErrForbidden,
+			want:     []string{"permission denied (403)"},
+		},
+		{
+			name:     "workspace not found",
+			err:      apiError(t, http.StatusNotFound, `{"errors":[{"status":"404","title":"not found"}]}`),
+			ctx:      ctx,
+			sentinel: ErrNotFound,
+			want:     []string{`workspace "web" not found in organization "acme" on app.terraform.io (404)`},
+		},
+		{
+			name:     "organization not found",
+			err:      apiError(t, http.StatusNotFound, ``),
+			ctx:      ErrorContext{Host: "tfe.example.com", Org: "acme", Operation: "list workspaces"},
+			sentinel: ErrNotFound,
+			want:     []string{`list workspaces: organization "acme" not found on tfe.example.com (404)`},
+		},
+		{
+			name:     "rate limited by title",
+			err:      errors.New("Too many requests"),
+			ctx:      ctx,
+			sentinel: ErrRateLimited,
+			want:     []string{"rate limited (429)", "--limit"},
+		},
+		{
+			name:     "rate limited by status",
+			err:      errors.New("429 Too Many Requests"),
+			ctx:      ctx,
+			sentinel: ErrRateLimited,
+			want:     []string{"rate limited (429)"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := FriendlyTFE(tt.err, tt.ctx)
+			require.Error(t, got)
+			assert.ErrorIs(t, got, tt.sentinel)
+			assert.ErrorIs(t, got, tt.err)
+			for _, want := range tt.want {
+				assert.Contains(t, got.Error(), want)
+			}
+		})
+	}
+}
+
+func TestFriendlyTFE_KeepsGoTFESentinels(t *testing.T) {
+	err := FriendlyTFE(tfe.ErrUnauthorized, ErrorContext{})
+	assert.ErrorIs(t, err, tfe.ErrUnauthorized)
+	assert.ErrorIs(t, err, ErrAuthFailed)
+	assert.NotContains(t, err.Error(), "TF_TOKEN_,")
+
+	err = FriendlyTFE(tfe.ErrResourceNotFound, ErrorContext{Org: "acme"})
+	assert.ErrorIs(t, err, tfe.ErrResourceNotFound)
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.NotErrorIs(t, err, ErrForbidden)
+}
+
+func TestFriendlyTFE_Other(t *testing.T) {
+	assert.NoError(t, FriendlyTFE(nil, ErrorContext{}))
+
+	cause := errors.New("boom")
+	err := FriendlyTFE(cause, ErrorContext{Host: "h", Org: "o"})
+	assert.EqualError(t, err, `request on h for org="o" workspace="": boom`)
+	assert.ErrorIs(t, err, cause)
+	for _, sentinel := range []error{ErrAuthFailed, ErrForbidden, ErrNotFound, ErrRateLimited} {
+		assert.NotErrorIs(t, err, sentinel)
+	}
+}
@@ -8,8 +8,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"math"
 	"os"
-	"path/filepath"
+	"path"
 	"sort"
 	"strings"
 	"time"
@@ -21,14 +22,21 @@ import (
 	"github.com/hashicorp/go-tfe"
 	"github.com/urfave/cli/v3"
 
-	awsx "github.com/staranto/tfctl/internal/aws"
+	"github.com/staranto/tfctl/internal/backend/options"
+	"github.com/staranto/tfctl/internal/cacheutil"
+	"github.com/staranto/tfctl/internal/config"
 	"github.com/staranto/tfctl/internal/differ"
 	"github.com/staranto/tfctl/internal/svutil"
+	"github.com/staranto/tfctl/internal/util"
 )
 
 type BackendS3 struct {
-	Ctx              context.Context
-	Cmd              *cli.Command
+	Ctx     context.Context
+	Cmd     *cli.Command
+	Options options.Options
+	// Client is the S3 client. It defaults to one built from the AWS
+	// configuration on first use; tests set a fake.
+	Client           S3API
 	RootDir          string `json:"-" validate:"dir"`
 	EnvOverride      string
 	SvOverride       string
@@ -46,13 +54,38 @@ type BackendS3 struct {
 		} `json:"config"`
 		Hash int `json:"hash"`
 	} `json:"backend"`
+
+	// objects holds the object details of the versions of the last
+	// StateVersions listing, keyed by version ID.
+	objects map[string]objectInfo
+}
+
+// objectInfo is what ListObjectVersions reports about a state version's
+// object beyond what tfe.StateVersion holds.
+type objectInfo struct {
+	Size         int64  `json:"size,omitempty"`
+	StorageClass string `json:"storage-class,omitempty"`
+}
+
+// StateVersionAttributes returns the size, in bytes, and storage class of the
+// object of state version id, as of the last StateVersions listing. It is nil
+// for a version that listing didn't include.
+func (be *BackendS3) StateVersionAttributes(id string) map[string]any {
+	info, ok := be.objects[id]
+	if !ok {
+		return nil
+	}
+	return map[string]any{"size": info.Size, "storage-class": info.StorageClass}
 }
 
 func (be *BackendS3) DiffStates(ctx context.Context, cmd *cli.Command) ([][]byte, error) {
 	// Fixup diffArgs
 	svSpecs := []string{"CSV~1", "CSV~0"}
 
-	diffArgs := differ.ParseDiffArgs(ctx, cmd)
+	diffArgs, err := differ.ParseDiffArgs(ctx, cmd)
+	if err != nil {
+		return nil, err
+	}
 
 	switch len(diffArgs) {
 	case 0:
@@ -86,17 +119,20 @@ func (be *BackendS3) DiffStates(ctx context.Context, cmd *cli.Command) ([][]byte
 		svSpecs = diffArgs
 	}
 
-	states, _ := be.States(svSpecs[0], svSpecs[1])
+	states, err := be.States(svSpecs[0], svSpecs[1])
+	if err != nil {
+		return nil, fmt.Errorf("failed to get states: %w", err)
+	}
 
 	return states, nil
 }
 
-func (be *BackendS3) Runs() ([]*tfe.Run, error) {
+func (be *BackendS3) Runs(augmenter ...func(context.Context, *cli.Command, *tfe.RunListForOrganizationOptions) error) ([]*tfe.Run, error) {
 	return nil, fmt.Errorf("not implemented")
 }
 
 func (be *BackendS3) State() ([]byte, error) {
-	sv := be.Cmd.String("sv")
+	sv := be.opts().String("sv")
 	states, err := be.States(sv)
 	if err != nil {
 		return nil, err
@@ -105,37 +141,27 @@ func (be *BackendS3) State() ([]byte, error) {
 }
 
 func (be *BackendS3) StateBody(svID string) ([]byte, error) {
-	if err := PurgeCache(); err != nil {
-		log.WithError(err).Warn("failed to purge cache")
-	}
+	be.purgeCache()
+	return be.stateBody(svID)
+}
 
+// stateBody is StateBody without the cache purge, for States, which purges
+// once before fetching its versions concurrently.
+func (be *BackendS3) stateBody(svID string) ([]byte, error) {
 	if entry, ok := CacheReader(be, svID); ok {
 		return entry.Data, nil
 	}
 
-	var env string
-	// If there's already an envOverride (rootDir::env), use it.
-	if be.EnvOverride != "" {
-		env = be.EnvOverride
-		// Else if we're in a prefixed workspace, get the env from the file.
-	} else if be.Backend.Config.Prefix != "" {
-		envData, err := os.ReadFile(filepath.Join(be.RootDir, ".terraform/environment"))
-		if err == nil {
-			env = string(envData)
-		}
+	if be.offline() {
+		return nil, fmt.Errorf("state version %s: %w", svID, cacheutil.ErrOfflineMiss)
 	}
-	key := filepath.Join(be.Backend.Config.Prefix, env, be.Backend.Config.Key)
 
-	// Build AWS config (inherit env; override region if provided)
-	var cfgOpts []awsx.Option
-	if be.Backend.Config.Region != "" {
-		cfgOpts = append(cfgOpts, awsx.WithRegion(be.Backend.Config.Region))
-	}
-	cfg, err := awsx.LoadAWSConfig(be.Ctx, cfgOpts...)
+	key := be.stateKey()
+
+	svc, err := be.client()
 	if err != nil {
-		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+		return nil, err
 	}
-	svc := awsx.NewS3(cfg)
 	input := &s3v2.GetObjectInput{
 		Bucket:    awsv2.String(be.Backend.Config.Bucket),
 		Key:       awsv2.String(key),
@@ -156,158 +182,302 @@ func (be *BackendS3) StateBody(svID string) ([]byte, error) {
 	return data, nil
 }
 
-// StateVersions implements backend.Backend. It scans be.RootDir for state and
-// backup files, parses them, and creates minimal tfe.StateVersion with ID as
-// filename, CreatedAt from file timestamp, and Serial from the document.
+// StateVersions implements backend.Backend. It lists the object versions of
+// the state file in S3 and creates minimal tfe.StateVersion with ID as the
+// S3 version ID, CreatedAt from LastModified, and Serial from the document.
+// The computed listing is cached for cache.list_ttl seconds (see
+// ListingCacheReader) and serials are cached per version.
 func (be *BackendS3) StateVersions(augmenter ...func(context.Context, *cli.Command, *tfe.StateVersionListOptions) error) ([]*tfe.StateVersion, error) {
-	var env string
-	if be.EnvOverride != "" {
-		env = be.EnvOverride
-	} else if be.Backend.Config.Prefix != "" {
-		envData, err := os.ReadFile(filepath.Join(be.RootDir, ".terraform/environment"))
-		if err == nil {
-			env = string(envData)
+	prefix := be.stateKey()
+
+	// Offline, the last cached listing is used however old it is, and without
+	// probing S3 for a newer version.
+	if be.offline() {
+		listing, ok := ListingCacheReader(be, prefix, math.MaxInt64)
+		if !ok {
+			return nil, fmt.Errorf("state versions of %s: %w", prefix, cacheutil.ErrOfflineMiss)
 		}
+		versions := listing.toStateVersions()
+		be.objects = listing.objects()
+		sortStateVersions(versions)
+		return util.Limit(versions, be.opts().Int("limit")), nil
 	}
-	prefix := filepath.Join(be.Backend.Config.Prefix, env, be.Backend.Config.Key)
 
-	var cfgOpts []awsx.Option
-	if be.Backend.Config.Region != "" {
-		cfgOpts = append(cfgOpts, awsx.WithRegion(be.Backend.Config.Region))
-	}
-	cfg, err := awsx.LoadAWSConfig(be.Ctx, cfgOpts...)
+	svc, err := be.client()
 	if err != nil {
-		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+		return nil, err
 	}
 
-	svc := awsx.NewS3(cfg)
+	// A cached listing is reused while it is younger than cache.list_ttl and S3
+	// still reports the same latest version, so a new apply invalidates it.
+	ttl := listCacheTTL()
+	var combinedVersions []*tfe.StateVersion
+	if listing, ok := ListingCacheReader(be, prefix, ttl); ok {
+		if latest, err := be.latestVersionID(svc, prefix); err == nil && latest == listing.Latest {
+			log.Debugf("s3 listing cache hit: key=%s", prefix)
+			combinedVersions = listing.toStateVersions()
+			be.objects = listing.objects()
+		}
+	}
+
+	if combinedVersions == nil {
+		var latest string
+		combinedVersions, be.objects, latest, err = be.listStateVersions(svc, prefix)
+		if err != nil {
+			return nil, err
+		}
+		if ttl > 0 {
+			if err := ListingCacheWriter(be, prefix, newCachedListing(latest, combinedVersions, be.objects)); err != nil {
+				log.WithError(err).Error("error writing listing to cache")
+			}
+		}
+	}
+
+	sortStateVersions(combinedVersions)
+	for _, v := range skewedStateVersions(combinedVersions) {
+		fmt.Fprintf(os.Stderr, "warning: state version %s (serial %d) is newer than a version "+
+			"with a higher serial; S3 timestamps may be skewed\n", v.ID, v.Serial)
+	}
+
+	currentVersions := []*tfe.StateVersion{}
+
+	for _, v := range combinedVersions {
+		if v.Serial == 0 {
+			break
+		}
+
+		currentVersions = append(currentVersions, v)
+	}
+
+	return util.Limit(currentVersions, be.opts().Int("limit")), nil
+}
+
+// listStateVersions lists every version of the state object at prefix that is
+// newer than its most recent delete marker and resolves each one's serial. It
+// also returns each version's object details, keyed by version ID, and the ID
+// S3 reports as the latest version or delete marker of the object. Serials
+// come from the serial cache when possible, then the body cache, and only then
+// from S3.
+func (be *BackendS3) listStateVersions(svc S3API, prefix string) ([]*tfe.StateVersion, map[string]objectInfo, string, error) {
 	paginator := s3v2.NewListObjectVersionsPaginator(svc, &s3v2.ListObjectVersionsInput{
 		Bucket: awsv2.String(be.Backend.Config.Bucket),
 		Prefix: awsv2.String(prefix),
 	})
 	combinedVersions := []*tfe.StateVersion{}
+	objects := map[string]objectInfo{}
 
 	var allDeleteMarkers []types.DeleteMarkerEntry
 	var allVersions []types.ObjectVersion
 	for paginator.HasMorePages() {
 		page, err := paginator.NextPage(be.Ctx)
 		if err != nil {
-			return nil, fmt.Errorf("failed to list object versions: %w", err)
+			return nil, nil, "", fmt.Errorf("failed to list object versions: %w", err)
 		}
 		allDeleteMarkers = append(allDeleteMarkers, page.DeleteMarkers...)
 		allVersions = append(allVersions, page.Versions...)
 	}
+
+	var latest string
+	var incomplete bool
 	var mostRecentDelete time.Time
 	for _, d := range allDeleteMarkers {
-		// This filters out tflock files. The prefix is literally a prefix so both
-		// the actual state file versions and any lock files they might have, are
-		// returned by the AWS API.
-		if d.Key == nil || *d.Key != prefix {
-			if d.Key != nil {
-				log.Debugf("Throwing away delete marker %s", *d.Key)
-			}
+		if !isStateObject(awsv2.ToString(d.Key), prefix) {
 			continue
 		}
+		if awsv2.ToBool(d.IsLatest) {
+			latest = awsv2.ToString(d.VersionId)
+		}
 		if d.LastModified != nil && d.LastModified.After(mostRecentDelete) {
 			mostRecentDelete = *d.LastModified
 		}
 	}
 
 	for _, v := range allVersions {
-		if v.Key == nil || *v.Key != prefix {
-			if v.Key != nil {
-				log.Debugf("Throwing away %s", *v.Key)
-			}
+		if !isStateObject(awsv2.ToString(v.Key), prefix) {
 			continue
 		}
 
-		if v.LastModified != nil && v.LastModified.Before(mostRecentDelete) {
+		// Guard against nil pointers
+		if v.VersionId == nil || v.LastModified == nil {
 			continue
 		}
 
-		obj, err := svc.GetObject(be.Ctx, &s3v2.GetObjectInput{
-			Bucket:    awsv2.String(be.Backend.Config.Bucket),
-			Key:       awsv2.String(prefix),
-			VersionId: v.VersionId,
-		})
-		if err != nil {
-			log.WithError(err).Error("s3 get object failed")
-			continue
+		if awsv2.ToBool(v.IsLatest) {
+			latest = *v.VersionId
 		}
 
-		var body []byte
-		if v.VersionId == nil {
-			// Shouldn't happen, but skip if no version id
-			_ = obj.Body.Close()
+		if v.LastModified.Before(mostRecentDelete) {
 			continue
 		}
-		entry, ok := CacheReader(be, *v.VersionId)
+
+		serial, ok := SerialCacheReader(be, *v.VersionId)
 		if !ok {
-			body, err = io.ReadAll(obj.Body)
-			obj.Body.Close()
+			body, err := be.versionBody(svc, prefix, *v.VersionId)
 			if err != nil {
+				log.WithError(err).Error("s3 get object failed")
+				// An incomplete listing must not be served from the listing
+				// cache, and an empty latest ID never matches the probe.
+				incomplete = true
 				continue
 			}
-
-			if err := CacheWriter(be, *v.VersionId, body); err != nil {
-				log.WithError(err).Error("error writing to cache")
+			serial = parseSerial(body)
+			if err := SerialCacheWriter(be, *v.VersionId, serial); err != nil {
+				log.WithError(err).Error("error writing serial to cache")
 			}
-		} else {
-			body = entry.Data
 		}
 
-		var doc map[string]interface{}
-		_ = json.Unmarshal(body, &doc)
-		serial := doc["serial"]
-
-		var serialInt int64
-		switch s := serial.(type) {
-		case float64:
-			serialInt = int64(s)
-		case int64:
-			serialInt = s
-		case int:
-			serialInt = int64(s)
-		default:
-			serialInt = 0
+		combinedVersions = append(combinedVersions, &tfe.StateVersion{
+			ID:        *v.VersionId,
+			CreatedAt: *v.LastModified,
+			Serial:    serial,
+		})
+		objects[*v.VersionId] = objectInfo{
+			Size:         awsv2.ToInt64(v.Size),
+			StorageClass: string(v.StorageClass),
 		}
+	}
 
-		// Guard against nil pointers
-		if v.VersionId != nil && v.LastModified != nil {
-			combinedVersions = append(combinedVersions, &tfe.StateVersion{
-				ID:        *v.VersionId,
-				CreatedAt: *v.LastModified,
-				Serial:    serialInt,
-			})
-		}
+	if incomplete {
+		latest = ""
+	}
+	return combinedVersions, objects, latest, nil
+}
 
+// versionBody returns the state body of versionID from the body cache, or
+// fetches it from S3 and caches it.
+func (be *BackendS3) versionBody(svc S3API, prefix string, versionID string) ([]byte, error) {
+	if entry, ok := CacheReader(be, versionID); ok {
+		return entry.Data, nil
 	}
 
-	sort.Slice(combinedVersions, func(i, j int) bool {
-		return combinedVersions[i].CreatedAt.After(combinedVersions[j].CreatedAt)
+	obj, err := svc.GetObject(be.Ctx, &s3v2.GetObjectInput{
+		Bucket:    awsv2.String(be.Backend.Config.Bucket),
+		Key:       awsv2.String(prefix),
+		VersionId: awsv2.String(versionID),
 	})
+	if err != nil {
+		return nil, err
+	}
+	defer obj.Body.Close()
 
-	currentVersions := []*tfe.StateVersion{}
+	body, err := io.ReadAll(obj.Body)
+	if err != nil {
+		return nil, err
+	}
 
-	for _, v := range combinedVersions {
-		if v.Serial == 0 {
-			break
+	if err := CacheWriter(be, versionID, body); err != nil {
+		log.WithError(err).Error("error writing to cache")
+	}
+	return body, nil
+}
+
+// latestVersionID returns the ID of the latest version or delete marker of the
+// state object at prefix using a single one-key listing. The exact key sorts
+// before its lock file, so the first entry belongs to the state object if it
+// has any versions at all.
+func (be *BackendS3) latestVersionID(svc S3API, prefix string) (string, error) {
+	page, err := svc.ListObjectVersions(be.Ctx, &s3v2.ListObjectVersionsInput{
+		Bucket:  awsv2.String(be.Backend.Config.Bucket),
+		Prefix:  awsv2.String(prefix),
+		MaxKeys: awsv2.Int32(1),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to list object versions: %w", err)
+	}
+
+	for _, v := range page.Versions {
+		if awsv2.ToString(v.Key) == prefix && awsv2.ToBool(v.IsLatest) {
+			return awsv2.ToString(v.VersionId), nil
+		}
+	}
+	for _, d := range page.DeleteMarkers {
+		if awsv2.ToString(d.Key) == prefix && awsv2.ToBool(d.IsLatest) {
+			return awsv2.ToString(d.VersionId), nil
 		}
+	}
+	return "", nil
+}
 
-		currentVersions = append(currentVersions, v)
+// lockSuffixes are the key suffixes of the lock objects Terraform and OpenTofu
+// keep beside a state object.
+var lockSuffixes = []string{".tflock", ".lock"}
+
+// isStateObject reports whether key, listed under prefix, is the state object
+// itself. The listing prefix is literally a prefix, so S3 also returns the
+// state's lock file and any other object whose key extends it, such as a
+// backup, and those are skipped.
+func isStateObject(key string, prefix string) bool {
+	for _, suffix := range lockSuffixes {
+		if strings.HasSuffix(key, suffix) {
+			log.Debugf("skipping lock object %s", key)
+			return false
+		}
 	}
+	if key != prefix {
+		log.Debugf("skipping object %s", key)
+		return false
+	}
+	return true
+}
 
-	limit := be.Cmd.Int("limit")
-	if len(currentVersions) > limit {
-		currentVersions = currentVersions[:limit]
+// stateKey returns the key of the state object. In a prefixed workspace it
+// is <workspace_key_prefix>/<env>/<key>, with the env from RootDir::env or the
+// environment file. S3 keys are always joined with /, whatever the OS.
+func (be *BackendS3) stateKey() string {
+	var env string
+	// If there's already an envOverride (rootDir::env), use it.
+	if be.EnvOverride != "" {
+		env = be.EnvOverride
+		// Else if we're in a prefixed workspace, get the env from the file.
+	} else if be.Backend.Config.Prefix != "" {
+		env = util.Environment(be.RootDir, be.noWorkspaceFile())
 	}
+	return path.Join(be.Backend.Config.Prefix, env, be.Backend.Config.Key)
+}
 
-	return currentVersions, nil
+// parseSerial returns the serial of a state document, or 0 if it has none.
+func parseSerial(body []byte) int64 {
+	var doc struct {
+		Serial int64 `json:"serial"`
+	}
+	_ = json.Unmarshal(body, &doc)
+	return doc.Serial
 }
 
-func (be *BackendS3) States(specs ...string) ([][]byte, error) {
-	var results [][]byte
+// sortStateVersions orders versions newest first by LastModified. Versions
+// with identical timestamps are ordered by serial, highest first.
+func sortStateVersions(versions []*tfe.StateVersion) {
+	sort.SliceStable(versions, func(i, j int) bool {
+		if !versions[i].CreatedAt.Equal(versions[j].CreatedAt) {
+			return versions[i].CreatedAt.After(versions[j].CreatedAt)
+		}
+		return versions[i].Serial > versions[j].Serial
+	})
+}
 
+// skewedStateVersions returns the versions in a newest-first list whose serial
+// is lower than that of an older version, which suggests the object timestamps
+// are skewed. Versions without a serial are ignored.
+func skewedStateVersions(versions []*tfe.StateVersion) []*tfe.StateVersion {
+	var skewed []*tfe.StateVersion
+	var maxOlder int64
+	for i := len(versions) - 1; i >= 0; i-- {
+		v := versions[i]
+		if v.Serial == 0 {
+			continue
+		}
+		if v.Serial < maxOlder {
+			skewed = append([]*tfe.StateVersion{v}, skewed...)
+		}
+		maxOlder = max(maxOlder, v.Serial)
+	}
+	return skewed
+}
+
+// States implements backend.Backend. The state body for each resolved version
+// is fetched concurrently, bounded by the parallelism config key, and returned
+// in spec order.
+func (be *BackendS3) States(specs ...string) ([][]byte, error) {
 	candidates, _ := be.StateVersions()
 	versions, err := svutil.Resolve(candidates, specs...)
 	if err != nil {
@@ -315,16 +485,18 @@ func (be *BackendS3) States(specs ...string) ([][]byte, error) {
 	}
 	log.Debugf("versions: %v", versions)
 
+	// Purge once up front rather than in every concurrent fetch.
+	be.purgeCache()
+
 	// Now pound through the found versions and return each of their state bodies.
-	for _, v := range versions {
-		body, err := be.StateBody(v.ID)
+	parallelism, _ := config.GetInt("parallelism", svutil.DefaultParallelism)
+	return svutil.FetchAll(versions, parallelism, func(v *tfe.StateVersion) ([]byte, error) {
+		body, err := be.stateBody(v.ID)
 		if err != nil {
 			return nil, fmt.Errorf("failed to get state: %w", err)
 		}
-		results = append(results, body)
-	}
-
-	return results, nil
+		return body, nil
+	})
 }
 
 func (be *BackendS3) String() string {
@@ -13,6 +13,9 @@ import (
 
 	"github.com/apex/log"
 	"github.com/urfave/cli/v3"
+
+	"github.com/staranto/tfctl/internal/backend/options"
+	"github.com/staranto/tfctl/internal/util"
 )
 
 type BackendS3Option = func(ctx context.Context, cmd *cli.Command, be *BackendS3) error
@@ -70,6 +73,15 @@ func WithDefaults() BackendS3Option {
 	}
 }
 
+// WithOptions sets the options the backend consults before the flags of cmd,
+// see options.Options.
+func WithOptions(o options.Options) BackendS3Option {
+	return func(ctx context.Context, cmd *cli.Command, be *BackendS3) error {
+		be.Options = o
+		return nil
+	}
+}
+
 func WithEnvOverride(env string) BackendS3Option {
 	return func(ctx context.Context, cmd *cli.Command, be *BackendS3) error {
 		if env != "" {
@@ -81,7 +93,7 @@ func WithEnvOverride(env string) BackendS3Option {
 
 func WithSvOverride() BackendS3Option {
 	return func(ctx context.Context, cmd *cli.Command, be *BackendS3) error {
-		sv := cmd.String("sv")
+		sv := be.opts().String("sv")
 		if sv != "" {
 			be.SvOverride = sv
 		}
@@ -90,7 +102,7 @@ func WithSvOverride() BackendS3Option {
 }
 
 func (be *BackendS3) load() error {
-	tfFile := be.RootDir + "/.terraform/terraform.tfstate"
+	tfFile := filepath.Join(util.DataDir(be.RootDir), "terraform.tfstate")
 	data, err := os.ReadFile(tfFile)
 	if err != nil {
 		return fmt.Errorf("failed to read local config file: %w", err)
@@ -111,3 +123,13 @@ func (be *BackendS3) load() error {
 
 	return nil
 }
+
+// opts returns the options the backend consults, falling back to the flags
+// of Cmd unless the options name a command of their own.
+func (be *BackendS3) opts() *options.Options {
+	o := be.Options
+	if o.Cmd == nil {
+		o.Cmd = be.Cmd
+	}
+	return &o
+}
@@ -0,0 +1,484 @@
+// Copyright (c) 2026 Steve Taranto <staranto@gmail.com>.
+// SPDX-License-Identifier: Apache-2.0
+// no-cloc
+
+package s3
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	awsv2 "github.com/aws/aws-sdk-go-v2/aws"
+	s3v2 "github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/hashicorp/go-tfe"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v3"
+
+	"github.com/staranto/tfctl/internal/cacheutil"
+)
+
+func stateVersionIDs(versions []*tfe.StateVersion) []string {
+	ids := make([]string, len(versions))
+	for i, v := range versions {
+		ids[i] = v.ID
+	}
+	return ids
+}
+
+func TestSortStateVersions(t *testing.T) {
+	t0 := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		versions []*tfe.StateVersion
+		want     []string
+	}{
+		{
+			name: "distinct timestamps",
+			versions: []*tfe.StateVersion{
+				{ID: "v1", CreatedAt: t0, Serial: 1},
+				{ID: "v3", CreatedAt: t0.Add(2 * time.Minute), Serial: 3},
+				{ID: "v2", CreatedAt: t0.Add(time.Minute), Serial: 2},
+			},
+			want: []string{"v3", "v2", "v1"},
+		},
+		{
+			name: "tied timestamps fall back to serial",
+			versions: []*tfe.StateVersion{
+				{ID: "v1", CreatedAt: t0, Serial: 1},
+				{ID: "v2", CreatedAt: t0.Add(time.Minute), Serial: 2},
+				{ID: "v4", CreatedAt: t0.Add(time.Minute), Serial: 4},
+				{ID: "v3", CreatedAt: t0.Add(time.Minute), Serial: 3},
+			},
+			want: []string{"v4", "v3", "v2", "v1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sortStateVersions(tt.versions)
+			assert.Equal(t, tt.want, stateVersionIDs(tt.versions))
+		})
+	}
+}
+
+func TestSkewedStateVersions(t *testing.T) {
+	t0 := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		versions []*tfe.StateVersion
+		want     []string
+	}{
+		{
+			name: "consistent",
+			versions: []*tfe.StateVersion{
+				{ID: "v3", CreatedAt: t0.Add(2 * time.Minute), Serial: 3},
+				{ID: "v2", CreatedAt: t0.Add(time.Minute), Serial: 2},
+				{ID: "v1", CreatedAt: t0, Serial: 1},
+			},
+			want: []string{},
+		},
+		{
+			name: "skewed",
+			versions: []*tfe.StateVersion{
+				{ID: "v2", CreatedAt: t0.Add(2 * time.Minute), Serial: 2},
+				{ID: "v3", CreatedAt: t0.Add(time.Minute), Serial: 3},
+				{ID: "v1", CreatedAt: t0, Serial: 1},
+			},
+			want: []string{"v2"},
+		},
+		{
+			name: "missing serials ignored",
+			versions: []*tfe.StateVersion{
+				{ID: "v2", CreatedAt: t0.Add(2 * time.Minute), Serial: 2},
+				{ID: "v0", CreatedAt: t0.Add(time.Minute), Serial: 0},
+				{ID: "v1", CreatedAt: t0, Serial: 1},
+			},
+			want: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, stateVersionIDs(skewedStateVersions(tt.versions)))
+		})
+	}
+}
+
+// newCacheTestBackend returns a backend whose cache lives in a fresh temp dir.
+func newCacheTestBackend(t *testing.T) *BackendS3 {
+	t.Helper()
+	t.Setenv("TFCTL_CACHE_DIR", t.TempDir())
+	t.Setenv("TFCTL_CACHE", "")
+
+	be := &BackendS3{}
+	be.Backend.Config.Bucket = "state-bucket"
+	be.Backend.Config.Key = "terraform.tfstate"
+	return be
+}
+
+func TestListingCache(t *testing.T) {
+	be := newCacheTestBackend(t)
+	t0 := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	versions := []*tfe.StateVersion{
+		{ID: "v2", CreatedAt: t0.Add(time.Minute), Serial: 2},
+		{ID: "v1", CreatedAt: t0, Serial: 1},
+	}
+
+	_, ok := ListingCacheReader(be, "env/terraform.tfstate", time.Minute)
+	assert.False(t, ok)
+
+	objects := map[string]objectInfo{
+		"v2": {Size: 2048, StorageClass: "STANDARD"},
+		"v1": {Size: 1024, StorageClass: "GLACIER"},
+	}
+	require.NoError(t, ListingCacheWriter(be, "env/terraform.tfstate", newCachedListing("v2", versions, objects)))
+
+	listing, ok := ListingCacheReader(be, "env/terraform.tfstate", time.Minute)
+	require.True(t, ok)
+	assert.Equal(t, "v2", listing.Latest)
+	assert.Equal(t, versions, listing.toStateVersions())
+	assert.Equal(t, objects, listing.objects())
+
+	// Listings are keyed by the full object key, so other workspaces miss.
+	_, ok = ListingCacheReader(be, "other/terraform.tfstate", time.Minute)
+	assert.False(t, ok)
+
+	// A zero TTL disables the listing cache.
+	_, ok = ListingCacheReader(be, "env/terraform.tfstate", 0)
+	assert.False(t, ok)
+}
+
+func TestListingCache_Expires(t *testing.T) {
+	be := newCacheTestBackend(t)
+	require.NoError(t, ListingCacheWriter(be, "terraform.tfstate", newCachedListing("v1", nil, nil)))
+
+	sub := []string{be.Backend.Config.Bucket, be.Backend.Config.Prefix, be.Backend.Config.Key}
+	p, ok := cacheutil.EntryPath(sub, "versions:terraform.tfstate")
+	require.True(t, ok)
+	past := time.Now().Add(-10 * time.Minute)
+	require.NoError(t, os.Chtimes(p, past, past))
+
+	_, ok = ListingCacheReader(be, "terraform.tfstate", 5*time.Minute)
+	assert.False(t, ok)
+	_, ok = ListingCacheReader(be, "terraform.tfstate", 15*time.Minute)
+	assert.True(t, ok)
+}
+
+func TestSerialCache(t *testing.T) {
+	be := newCacheTestBackend(t)
+
+	_, ok := SerialCacheReader(be, "v1")
+	assert.False(t, ok)
+
+	require.NoError(t, SerialCacheWriter(be, "v1", 9007199254740993))
+	serial, ok := SerialCacheReader(be, "v1")
+	require.True(t, ok)
+	assert.Equal(t, int64(9007199254740993), serial)
+}
+
+func TestParseSerial(t *testing.T) {
+	assert.Equal(t, int64(42), parseSerial([]byte(`{"version":4,"serial":42}`)))
+	assert.Equal(t, int64(9007199254740993), parseSerial([]byte(`{"serial":9007199254740993}`)))
+	assert.Equal(t, int64(0), parseSerial([]byte(`{"version":4}`)))
+	assert.Equal(t, int64(0), parseSerial([]byte(`{"serial":"42"}`)))
+	assert.Equal(t, int64(0), parseSerial([]byte(`not json`)))
+}
+
+// offlineCmd returns a command parsed with --offline and args set.
+func offlineCmd(t *testing.T, args ...string) *cli.Command {
+	t.Helper()
+	var parsed *cli.Command
+	cmd := &cli.Command{
+		Name:  "sq",
+		Flags: []cli.Flag{&cli.BoolFlag{Name: "offline"}, &cli.IntFlag{Name: "limit"}},
+		Action: func(_ context.Context, c *cli.Command) error {
+			parsed = c
+			return nil
+		},
+	}
+	require.NoError(t, cmd.Run(context.Background(), append([]string{"sq", "--offline"}, args...)))
+	return parsed
+}
+
+func TestOffline(t *testing.T) {
+	be := newCacheTestBackend(t)
+	be.Ctx = context.Background()
+	be.Cmd = offlineCmd(t)
+
+	_, err := be.StateVersions()
+	require.ErrorIs(t, err, cacheutil.ErrOfflineMiss)
+	_, err = be.StateBody("v1")
+	require.ErrorIs(t, err, cacheutil.ErrOfflineMiss)
+
+	// An expired listing is still served offline.
+	t0 := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, ListingCacheWriter(be, "terraform.tfstate", newCachedListing("v2", []*tfe.StateVersion{
+		{ID: "v1", CreatedAt: t0, Serial: 1},
+		{ID: "v2", CreatedAt: t0.Add(time.Minute), Serial: 2},
+	}, map[string]objectInfo{"v2": {Size: 4096, StorageClass: "STANDARD_IA"}})))
+	sub := []string{be.Backend.Config.Bucket, be.Backend.Config.Prefix, be.Backend.Config.Key}
+	p, ok := cacheutil.EntryPath(sub, "versions:terraform.tfstate")
+	require.True(t, ok)
+	past := time.Now().Add(-48 * time.Hour)
+	require.NoError(t, os.Chtimes(p, past, past))
+
+	versions, err := be.StateVersions()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"v2", "v1"}, stateVersionIDs(versions))
+	assert.Equal(t, map[string]any{"size": int64(4096), "storage-class": "STANDARD_IA"}, be.StateVersionAttributes("v2"))
+	assert.Nil(t, be.StateVersionAttributes("v1"))
+
+	require.NoError(t, CacheWriter(be, "v2", []byte(`{"serial":2}`)))
+	body, err := be.StateBody("v2")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"serial":2}`, string(body))
+}
+
+func TestOffline_Limit(t *testing.T) {
+	be := newCacheTestBackend(t)
+	be.Ctx = context.Background()
+
+	t0 := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, ListingCacheWriter(be, "terraform.tfstate", newCachedListing("v3", []*tfe.StateVersion{
+		{ID: "v1", CreatedAt: t0, Serial: 1},
+		{ID: "v2", CreatedAt: t0.Add(time.Minute), Serial: 2},
+		{ID: "v3", CreatedAt: t0.Add(2 * time.Minute), Serial: 3},
+	}, nil)))
+
+	tests := []struct {
+		args []string
+		want []string
+	}{
+		{args: nil, want: []string{"v3", "v2", "v1"}},
+		{args: []string{"--limit", "0"}, want: []string{"v3", "v2", "v1"}},
+		{args: []string{"--limit", "2"}, want: []string{"v3", "v2"}},
+		{args: []string{"--limit", "5"}, want: []string{"v3", "v2", "v1"}},
+	}
+
+	for _, tt := range tests {
+		be.Cmd = offlineCmd(t, tt.args...)
+		versions, err := be.StateVersions()
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, stateVersionIDs(versions), "args %v", tt.args)
+	}
+}
+
+// fakeS3 is an S3API serving a fixed listing and a {"serial":N} body per
+// version. It records the keys it is asked to get.
+type fakeS3 struct {
+	listing *s3v2.ListObjectVersionsOutput
+	serials map[string]int
+	got     []string
+}
+
+func (f *fakeS3) GetObject(_ context.Context, in *s3v2.GetObjectInput, _ ...func(*s3v2.Options)) (*s3v2.GetObjectOutput, error) {
+	f.got = append(f.got, awsv2.ToString(in.Key))
+	serial, ok := f.serials[awsv2.ToString(in.VersionId)]
+	if !ok {
+		return nil, fmt.Errorf("no such version %s", awsv2.ToString(in.VersionId))
+	}
+	body := fmt.Sprintf(`{"serial":%d}`, serial)
+	return &s3v2.GetObjectOutput{Body: io.NopCloser(strings.NewReader(body))}, nil
+}
+
+func (f *fakeS3) ListObjectVersions(_ context.Context, _ *s3v2.ListObjectVersionsInput, _ ...func(*s3v2.Options)) (*s3v2.ListObjectVersionsOutput, error) {
+	return f.listing, nil
+}
+
+func TestListStateVersions_SkipsLocks(t *testing.T) {
+	be := newCacheTestBackend(t)
+	be.Ctx = context.Background()
+
+	t0 := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) *time.Time { ts := t0.Add(d); return &ts }
+	version := func(key, id string, modified *time.Time, latest bool) types.ObjectVersion {
+		return types.ObjectVersion{
+			Key:          awsv2.String(key),
+			VersionId:    awsv2.String(id),
+			LastModified: modified,
+			IsLatest:     awsv2.Bool(latest),
+			Size:         awsv2.Int64(1024),
+			StorageClass: types.ObjectVersionStorageClassStandard,
+		}
+	}
+	marker := func(key, id string, modified *time.Time, latest bool) types.DeleteMarkerEntry {
+		return types.DeleteMarkerEntry{
+			Key:          awsv2.String(key),
+			VersionId:    awsv2.String(id),
+			LastModified: modified,
+			IsLatest:     awsv2.Bool(latest),
+		}
+	}
+
+	fake := &fakeS3{
+		listing: &s3v2.ListObjectVersionsOutput{
+			Versions: []types.ObjectVersion{
+				version("terraform.tfstate", "v3", at(3*time.Minute), true),
+				version("terraform.tfstate", "v2", at(2*time.Minute), false),
+				version("terraform.tfstate", "v1", at(0), false),
+				version("terraform.tfstate.tflock", "l1", at(4*time.Minute), true),
+				version("terraform.tfstate.lock", "l2", at(4*time.Minute), true),
+				version("terraform.tfstate.backup", "b1", at(4*time.Minute), true),
+			},
+			// v1 predates the state's delete marker. The lock's later delete
+			// marker must not hide v2 and v3.
+			DeleteMarkers: []types.DeleteMarkerEntry{
+				marker("terraform.tfstate", "d1", at(time.Minute), false),
+				marker("terraform.tfstate.tflock", "d2", at(5*time.Minute), true),
+			},
+		},
+		serials: map[string]int{"v1": 1, "v2": 2, "v3": 3, "l1": 9, "l2": 9, "b1": 9},
+	}
+	be.Client = fake
+
+	versions, objects, latest, err := be.listStateVersions(fake, "terraform.tfstate")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"v3", "v2"}, stateVersionIDs(versions))
+	assert.Equal(t, []int64{3, 2}, []int64{versions[0].Serial, versions[1].Serial})
+	assert.Equal(t, "v3", latest)
+	assert.Equal(t, map[string]objectInfo{
+		"v3": {Size: 1024, StorageClass: "STANDARD"},
+		"v2": {Size: 1024, StorageClass: "STANDARD"},
+	}, objects)
+	assert.Equal(t, []string{"terraform.tfstate", "terraform.tfstate"}, fake.got)
+
+	versions, err = be.StateVersions()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"v3", "v2"}, stateVersionIDs(versions))
+}
+
+// TestStateVersions_SkewWarning verifies a version newer than one with a
+// higher serial is reported on stderr.
+func TestStateVersions_SkewWarning(t *testing.T) {
+	be := newCacheTestBackend(t)
+	be.Ctx = context.Background()
+
+	t0 := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) *time.Time { ts := t0.Add(d); return &ts }
+	version := func(id string, modified *time.Time, latest bool) types.ObjectVersion {
+		return types.ObjectVersion{
+			Key:          awsv2.String("terraform.tfstate"),
+			VersionId:    awsv2.String(id),
+			LastModified: modified,
+			IsLatest:     awsv2.Bool(latest),
+		}
+	}
+	be.Client = &fakeS3{
+		listing: &s3v2.ListObjectVersionsOutput{
+			Versions: []types.ObjectVersion{
+				version("v2", at(2*time.Minute), true),
+				version("v3", at(time.Minute), false),
+				version("v1", at(0), false),
+			},
+		},
+		serials: map[string]int{"v1": 1, "v2": 2, "v3": 3},
+	}
+
+	stderr := os.Stderr
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	os.Stderr = w
+	t.Cleanup(func() { os.Stderr = stderr })
+
+	_, svErr := be.StateVersions()
+
+	w.Close()
+	os.Stderr = stderr
+	out, err := io.ReadAll(r)
+	require.NoError(t, err)
+	require.NoError(t, svErr)
+
+	assert.Equal(t, "warning: state version v2 (serial 2) is newer than a version with a higher serial; "+
+		"S3 timestamps may be skewed\n", string(out))
+}
+
+func TestIsStateObject(t *testing.T) {
+	assert.True(t, isStateObject("env/terraform.tfstate", "env/terraform.tfstate"))
+	assert.False(t, isStateObject("env/terraform.tfstate.tflock", "env/terraform.tfstate"))
+	assert.False(t, isStateObject("env/terraform.tfstate.lock", "env/terraform.tfstate"))
+	assert.False(t, isStateObject("env/terraform.tfstate.backup", "env/terraform.tfstate"))
+}
+
+func TestListStateVersions_DeleteMarkerCutoff(t *testing.T) {
+	t0 := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) *time.Time { ts := t0.Add(d); return &ts }
+	version := func(id string, modified *time.Time, latest bool) types.ObjectVersion {
+		return types.ObjectVersion{
+			Key:          awsv2.String("terraform.tfstate"),
+			VersionId:    awsv2.String(id),
+			LastModified: modified,
+			IsLatest:     awsv2.Bool(latest),
+		}
+	}
+	marker := func(id string, modified *time.Time, latest bool) types.DeleteMarkerEntry {
+		return types.DeleteMarkerEntry{
+			Key:          awsv2.String("terraform.tfstate"),
+			VersionId:    awsv2.String(id),
+			LastModified: modified,
+			IsLatest:     awsv2.Bool(latest),
+		}
+	}
+
+	tests := []struct {
+		name       string
+		listing    *s3v2.ListObjectVersionsOutput
+		wantIDs    []string
+		wantLatest string
+	}{
+		{
+			name: "no delete markers",
+			listing: &s3v2.ListObjectVersionsOutput{
+				Versions: []types.ObjectVersion{version("v2", at(time.Minute), true), version("v1", at(0), false)},
+			},
+			wantIDs:    []string{"v2", "v1"},
+			wantLatest: "v2",
+		},
+		{
+			name: "most recent marker cuts off older versions",
+			listing: &s3v2.ListObjectVersionsOutput{
+				Versions: []types.ObjectVersion{
+					version("v3", at(3*time.Minute), true),
+					version("v2", at(time.Minute), false),
+					version("v1", at(0), false),
+				},
+				DeleteMarkers: []types.DeleteMarkerEntry{
+					marker("d1", at(30*time.Second), false),
+					marker("d2", at(2*time.Minute), false),
+				},
+			},
+			wantIDs:    []string{"v3"},
+			wantLatest: "v3",
+		},
+		{
+			name: "deleted state",
+			listing: &s3v2.ListObjectVersionsOutput{
+				Versions:      []types.ObjectVersion{version("v1", at(0), false)},
+				DeleteMarkers: []types.DeleteMarkerEntry{marker("d1", at(time.Minute), true)},
+			},
+			wantIDs:    []string{},
+			wantLatest: "d1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			be := newCacheTestBackend(t)
+			be.Ctx = context.Background()
+			fake := &fakeS3{listing: tt.listing, serials: map[string]int{"v1": 1, "v2": 2, "v3": 3}}
+
+			versions, _, latest, err := be.listStateVersions(fake, "terraform.tfstate")
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantIDs, stateVersionIDs(versions))
+			assert.Equal(t, tt.wantLatest, latest)
+		})
+	}
+}
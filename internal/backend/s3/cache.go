@@ -4,6 +4,14 @@
 package s3
 
 import (
+	"encoding/json"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/apex/log"
+	"github.com/hashicorp/go-tfe"
+
 	"github.com/staranto/tfctl/internal/cacheutil"
 	"github.com/staranto/tfctl/internal/config"
 )
@@ -36,7 +44,155 @@ func CacheWriter(be *BackendS3, key string, data []byte) error {
 	return cacheutil.Write(sub, key, data)
 }
 
+// offline reports whether --offline is set, in which case the backend serves
+// only from the cache.
+func (be *BackendS3) offline() bool {
+	return be.opts().Bool("offline")
+}
+
+// noWorkspaceFile reports whether --no-prefixed-workspace-file is set, in
+// which case the environment file is never read and the default workspace is
+// used unless RootDir::<env> names another.
+func (be *BackendS3) noWorkspaceFile() bool {
+	return be.opts().Bool("no-prefixed-workspace-file")
+}
+
 func PurgeCache() error {
 	cleanHours, _ := config.GetInt("cache.clean")
 	return cacheutil.Purge(cleanHours)
 }
+
+// purgeCache purges the cache with PurgeCache, logging a failure. Offline, the
+// cache is all there is, so it is left alone.
+func (be *BackendS3) purgeCache() {
+	if be.offline() {
+		return
+	}
+	if err := PurgeCache(); err != nil {
+		log.WithError(err).Warn("failed to purge cache")
+	}
+}
+
+// DefaultListTTL is how long, in seconds, a cached state version listing is
+// served before S3 is listed again when cache.list_ttl is not set.
+const DefaultListTTL = 300
+
+// cachedListing is the on-disk form of a computed StateVersions listing.
+// Latest is the version ID (or delete marker ID) S3 reported as the latest
+// for the state object when the listing was built.
+type cachedListing struct {
+	Latest   string               `json:"latest"`
+	Versions []cachedStateVersion `json:"versions"`
+}
+
+// cachedStateVersion holds the tfe.StateVersion fields StateVersions fills in
+// and the version's object details.
+type cachedStateVersion struct {
+	ID        string    `json:"id"`
+	CreatedAt time.Time `json:"created-at"`
+	Serial    int64     `json:"serial"`
+	objectInfo
+}
+
+// listCacheTTL returns the configured listing TTL. Zero or less disables the
+// listing cache.
+func listCacheTTL() time.Duration {
+	ttl, _ := config.GetInt("cache.list_ttl", DefaultListTTL)
+	return time.Duration(ttl) * time.Second
+}
+
+// ListingCacheReader returns the cached listing for the state object at
+// objectKey if one exists and was written less than ttl ago.
+func ListingCacheReader(be *BackendS3, objectKey string, ttl time.Duration) (*cachedListing, bool) {
+	if ttl <= 0 {
+		return nil, false
+	}
+
+	sub := []string{be.Backend.Config.Bucket, be.Backend.Config.Prefix, be.Backend.Config.Key}
+	entry, ok := cacheutil.Read(sub, "versions:"+objectKey)
+	if !ok {
+		return nil, false
+	}
+
+	info, err := os.Stat(entry.Path)
+	if err != nil || time.Since(info.ModTime()) > ttl {
+		return nil, false
+	}
+
+	var listing cachedListing
+	if err := json.Unmarshal(entry.Data, &listing); err != nil {
+		return nil, false
+	}
+	return &listing, true
+}
+
+// ListingCacheWriter stores the listing for the state object at objectKey.
+func ListingCacheWriter(be *BackendS3, objectKey string, listing *cachedListing) error {
+	data, err := json.Marshal(listing)
+	if err != nil {
+		return err
+	}
+	sub := []string{be.Backend.Config.Bucket, be.Backend.Config.Prefix, be.Backend.Config.Key}
+	return cacheutil.Write(sub, "versions:"+objectKey, data)
+}
+
+// SerialCacheReader returns the cached serial of the state at versionID.
+// Object versions are immutable, so serial entries never go stale.
+func SerialCacheReader(be *BackendS3, versionID string) (int64, bool) {
+	sub := []string{be.Backend.Config.Bucket, be.Backend.Config.Prefix, be.Backend.Config.Key}
+	entry, ok := cacheutil.Read(sub, "serial:"+versionID)
+	if !ok {
+		return 0, false
+	}
+	serial, err := strconv.ParseInt(string(entry.Data), 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return serial, true
+}
+
+// SerialCacheWriter stores the serial of the state at versionID.
+func SerialCacheWriter(be *BackendS3, versionID string, serial int64) error {
+	sub := []string{be.Backend.Config.Bucket, be.Backend.Config.Prefix, be.Backend.Config.Key}
+	return cacheutil.Write(sub, "serial:"+versionID, []byte(strconv.FormatInt(serial, 10)))
+}
+
+// toStateVersions converts the cached listing back into state versions.
+func (l *cachedListing) toStateVersions() []*tfe.StateVersion {
+	versions := make([]*tfe.StateVersion, 0, len(l.Versions))
+	for _, v := range l.Versions {
+		versions = append(versions, &tfe.StateVersion{
+			ID:        v.ID,
+			CreatedAt: v.CreatedAt,
+			Serial:    v.Serial,
+		})
+	}
+	return versions
+}
+
+// objects returns the object details of the cached versions, keyed by ID.
+// Listings cached before the details were recorded have none.
+func (l *cachedListing) objects() map[string]objectInfo {
+	objects := make(map[string]objectInfo, len(l.Versions))
+	for _, v := range l.Versions {
+		if v.objectInfo != (objectInfo{}) {
+			objects[v.ID] = v.objectInfo
+		}
+	}
+	return objects
+}
+
+// newCachedListing captures versions, their object details and the latest ID
+// for caching.
+func newCachedListing(latest string, versions []*tfe.StateVersion, objects map[string]objectInfo) *cachedListing {
+	listing := &cachedListing{Latest: latest}
+	for _, v := range versions {
+		listing.Versions = append(listing.Versions, cachedStateVersion{
+			ID:         v.ID,
+			CreatedAt:  v.CreatedAt,
+			Serial:     v.Serial,
+			objectInfo: objects[v.ID],
+		})
+	}
+	return listing
+}
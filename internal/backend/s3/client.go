@@ -0,0 +1,39 @@
+// Copyright (c) 2026 Steve Taranto <staranto@gmail.com>.
+// SPDX-License-Identifier: Apache-2.0
+
+package s3
+
+import (
+	"context"
+	"fmt"
+
+	s3v2 "github.com/aws/aws-sdk-go-v2/service/s3"
+
+	awsx "github.com/staranto/tfctl/internal/aws"
+)
+
+// S3API is the part of the S3 client the backend uses. *s3.Client satisfies
+// it, and tests substitute a fake to fabricate listings.
+type S3API interface {
+	GetObject(ctx context.Context, params *s3v2.GetObjectInput, optFns ...func(*s3v2.Options)) (*s3v2.GetObjectOutput, error)
+	ListObjectVersions(ctx context.Context, params *s3v2.ListObjectVersionsInput, optFns ...func(*s3v2.Options)) (*s3v2.ListObjectVersionsOutput, error)
+}
+
+// client returns be.Client, first defaulting it to an S3 client for the
+// backend's region built from the ambient AWS configuration.
+func (be *BackendS3) client() (S3API, error) {
+	if be.Client != nil {
+		return be.Client, nil
+	}
+
+	var cfgOpts []awsx.Option
+	if be.Backend.Config.Region != "" {
+		cfgOpts = append(cfgOpts, awsx.WithRegion(be.Backend.Config.Region))
+	}
+	cfg, err := awsx.LoadAWSConfig(be.Ctx, cfgOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+	be.Client = awsx.NewS3(cfg)
+	return be.Client, nil
+}
@@ -0,0 +1,180 @@
+// Copyright (c) 2026 Steve Taranto <staranto@gmail.com>.
+// SPDX-License-Identifier: Apache-2.0
+
+package backend
+
+import (
+	"encoding/json"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+
+	"github.com/staranto/tfctl/internal/util"
+)
+
+// initState is the part of .terraform/terraform.tfstate that records the
+// backend configuration captured by the last terraform init.
+type initState struct {
+	Backend struct {
+		Type   string                 `json:"type"`
+		Config map[string]interface{} `json:"config"`
+	} `json:"backend"`
+}
+
+// backendBlock is the backend (or cloud) block found in the root module.
+type backendBlock struct {
+	Type   string
+	Config map[string]interface{}
+}
+
+// StaleInit reports whether the backend block in rootDir's .tf files no longer
+// matches the configuration recorded in .terraform/terraform.tfstate, which
+// means terraform init has not been rerun since the block changed. checked is
+// false when either side is missing or the .tf files do not parse.
+//
+// Terraform's own Backend.Hash covers every attribute of the backend's schema,
+// which tfctl does not have, so it cannot be recomputed here. Instead both
+// sides are hashed over the attributes set in the block. Attributes that are
+// not literals (e.g. references to locals) are skipped, and values supplied
+// only through -backend-config are ignored since the block does not set them.
+func StaleInit(rootDir string) (stale bool, checked bool) {
+	raw, err := os.ReadFile(filepath.Join(util.DataDir(rootDir), "terraform.tfstate"))
+	if err != nil {
+		return false, false
+	}
+
+	var state initState
+	if err := json.Unmarshal(raw, &state); err != nil || state.Backend.Type == "" {
+		return false, false
+	}
+
+	block, ok := readBackendBlock(rootDir)
+	if !ok {
+		return false, false
+	}
+
+	want := configHash(block.Type, block.Config)
+	got := configHash(state.Backend.Type, project(state.Backend.Config, block.Config))
+
+	return want != got, true
+}
+
+// readBackendBlock parses the .tf files of the root module in rootDir and
+// returns the first terraform { backend "<type>" {} } or terraform { cloud {} }
+// block found.
+func readBackendBlock(rootDir string) (*backendBlock, bool) {
+	files, err := filepath.Glob(filepath.Join(rootDir, "*.tf"))
+	if err != nil {
+		return nil, false
+	}
+
+	for _, file := range files {
+		src, err := os.ReadFile(file)
+		if err != nil {
+			return nil, false
+		}
+
+		f, diags := hclsyntax.ParseConfig(src, file, hcl.Pos{Line: 1, Column: 1})
+		if diags.HasErrors() {
+			return nil, false
+		}
+
+		for _, tf := range f.Body.(*hclsyntax.Body).Blocks {
+			if tf.Type != "terraform" {
+				continue
+			}
+			for _, b := range tf.Body.Blocks {
+				switch {
+				case b.Type == "backend" && len(b.Labels) == 1:
+					return &backendBlock{Type: b.Labels[0], Config: bodyValues(b.Body)}, true
+				case b.Type == "cloud":
+					return &backendBlock{Type: "cloud", Config: bodyValues(b.Body)}, true
+				}
+			}
+		}
+	}
+
+	return nil, false
+}
+
+// bodyValues converts the literal attributes of body, and recursively its
+// nested blocks, to the values they take in JSON. A single nested block of a
+// type becomes an object and repeated ones a list of objects. Attributes that
+// cannot be evaluated without context are skipped.
+func bodyValues(body *hclsyntax.Body) map[string]interface{} {
+	values := map[string]interface{}{}
+
+	for name, attr := range body.Attributes {
+		val, diags := attr.Expr.Value(nil)
+		if diags.HasErrors() || !val.IsWhollyKnown() {
+			continue
+		}
+		raw, err := ctyjson.Marshal(val, val.Type())
+		if err != nil {
+			continue
+		}
+		var v interface{}
+		if err := json.Unmarshal(raw, &v); err != nil {
+			continue
+		}
+		values[name] = v
+	}
+
+	for _, b := range body.Blocks {
+		nested := bodyValues(b.Body)
+		switch existing := values[b.Type].(type) {
+		case nil:
+			values[b.Type] = nested
+		case []interface{}:
+			values[b.Type] = append(existing, nested)
+		default:
+			values[b.Type] = []interface{}{existing, nested}
+		}
+	}
+
+	return values
+}
+
+// project returns state restricted to the object keys present in shape, so
+// that attributes the block does not set do not affect the comparison.
+func project(state interface{}, shape interface{}) interface{} {
+	switch s := shape.(type) {
+	case map[string]interface{}:
+		m, ok := state.(map[string]interface{})
+		if !ok {
+			// Terraform may record a single nested block as a one-element list.
+			if l, ok := state.([]interface{}); ok && len(l) == 1 {
+				return project(l[0], shape)
+			}
+			return state
+		}
+		out := make(map[string]interface{}, len(s))
+		for k, v := range s {
+			out[k] = project(m[k], v)
+		}
+		return out
+	case []interface{}:
+		l, ok := state.([]interface{})
+		if !ok || len(l) != len(s) {
+			return state
+		}
+		out := make([]interface{}, len(s))
+		for i := range s {
+			out[i] = project(l[i], s[i])
+		}
+		return out
+	default:
+		return state
+	}
+}
+
+// configHash returns a checksum of the backend type and config. JSON encoding
+// sorts map keys, so equal configs always hash the same.
+func configHash(typ string, config interface{}) uint32 {
+	raw, _ := json.Marshal([]interface{}{typ, config})
+	return crc32.ChecksumIEEE(raw)
+}
@@ -0,0 +1,140 @@
+// Copyright (c) 2026 Steve Taranto <staranto@gmail.com>.
+// SPDX-License-Identifier: Apache-2.0
+// no-cloc
+
+package backend
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeRootDir creates a root module with the given main.tf and
+// .terraform/terraform.tfstate contents. Empty contents skip the file.
+func writeRootDir(t *testing.T, mainTF, initState string) string {
+	t.Helper()
+	dir := t.TempDir()
+	if mainTF != "" {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "main.tf"), []byte(mainTF), 0o600))
+	}
+	if initState != "" {
+		require.NoError(t, os.Mkdir(filepath.Join(dir, ".terraform"), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, ".terraform", "terraform.tfstate"), []byte(initState), 0o600))
+	}
+	return dir
+}
+
+const s3InitState = `{"version":3,"backend":{"type":"s3","config":{
+	"bucket":"state-bucket","key":"app/terraform.tfstate","region":"us-east-1",
+	"encrypt":true,"dynamodb_table":null,"assume_role":{"role_arn":"arn:aws:iam::1:role/tf"}},
+	"hash":1234567}}`
+
+func TestStaleInit(t *testing.T) {
+	tests := []struct {
+		name        string
+		mainTF      string
+		initState   string
+		wantStale   bool
+		wantChecked bool
+	}{
+		{
+			name: "matching",
+			mainTF: `terraform {
+  backend "s3" {
+    bucket  = "state-bucket"
+    key     = "app/terraform.tfstate"
+    encrypt = true
+    assume_role {
+      role_arn = "arn:aws:iam::1:role/tf"
+    }
+  }
+}`,
+			initState:   s3InitState,
+			wantChecked: true,
+		},
+		{
+			name: "partial config supplied at init",
+			mainTF: `terraform {
+  backend "s3" {
+    key = "app/terraform.tfstate"
+  }
+}`,
+			initState:   s3InitState,
+			wantChecked: true,
+		},
+		{
+			name: "changed value",
+			mainTF: `terraform {
+  backend "s3" {
+    bucket = "state-bucket"
+    key    = "other/terraform.tfstate"
+  }
+}`,
+			initState:   s3InitState,
+			wantStale:   true,
+			wantChecked: true,
+		},
+		{
+			name: "changed nested block",
+			mainTF: `terraform {
+  backend "s3" {
+    assume_role {
+      role_arn = "arn:aws:iam::2:role/tf"
+    }
+  }
+}`,
+			initState:   s3InitState,
+			wantStale:   true,
+			wantChecked: true,
+		},
+		{
+			name: "changed type",
+			mainTF: `terraform {
+  cloud {
+    organization = "acme"
+  }
+}`,
+			initState:   s3InitState,
+			wantStale:   true,
+			wantChecked: true,
+		},
+		{
+			name: "non-literal attributes are skipped",
+			mainTF: `terraform {
+  backend "s3" {
+    bucket = local.bucket
+    key    = "app/terraform.tfstate"
+  }
+}`,
+			initState:   s3InitState,
+			wantChecked: true,
+		},
+		{
+			name:      "no backend block",
+			mainTF:    `resource "null_resource" "a" {}`,
+			initState: s3InitState,
+		},
+		{
+			name:      "unparseable HCL",
+			mainTF:    `terraform {`,
+			initState: s3InitState,
+		},
+		{
+			name:   "not initialized",
+			mainTF: `terraform { backend "s3" {} }`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeRootDir(t, tt.mainTF, tt.initState)
+			stale, checked := StaleInit(dir)
+			assert.Equal(t, tt.wantChecked, checked)
+			assert.Equal(t, tt.wantStale, stale)
+		})
+	}
+}
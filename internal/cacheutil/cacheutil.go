@@ -7,6 +7,7 @@ import (
 	"bytes"
 	"crypto/sha256"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -15,6 +16,10 @@ import (
 	"github.com/staranto/tfctl/internal/log"
 )
 
+// ErrOfflineMiss reports that --offline is set and the data a command needs is
+// not in the cache, so it would have to come from the network.
+var ErrOfflineMiss = errors.New("not in the cache and --offline is set")
+
 // Entry represents a cached artifact on disk.
 // Key is the clear-text key; EncodedKey is the hashed filename.
 type Entry struct {
@@ -90,13 +95,91 @@ func Purge(hours int) error {
 		return nil
 	}
 
+	return purgeOlderThan(time.Duration(hours) * time.Hour)
+}
+
+// PurgeAll removes every file in the cache, regardless of age. It is a no-op
+// if the cache dir cannot be resolved.
+func PurgeAll() error {
+	return purgeOlderThan(0)
+}
+
+// purgeOlderThan removes cache files whose modification time is more than
+// maxAge ago. A maxAge of zero removes every file.
+func purgeOlderThan(maxAge time.Duration) error {
+	paths, err := PurgeCandidates(maxAge)
+	if err != nil {
+		return err
+	}
+
+	for _, path := range paths {
+		if err := os.Remove(path); err == nil {
+			log.Debugf("removed cache file %s", path)
+		} else {
+			log.WithError(err).Warnf("failed to remove cache file %s", path)
+		}
+	}
+	return nil
+}
+
+// PurgeCandidates returns, in lexical order, the cache files whose
+// modification time is more than maxAge ago, i.e. the files a purge would
+// remove. A maxAge of zero selects every file. Nothing is removed.
+func PurgeCandidates(maxAge time.Duration) ([]string, error) {
 	base, ok := Dir()
 	if !ok {
-		return nil
+		return nil, nil
 	}
 
-	maxAge := time.Duration(hours) * time.Hour
-	if err := filepath.Walk(base, func(path string, info os.FileInfo, walkErr error) error {
+	var paths []string
+	if err := walkFiles(base, func(path string, info os.FileInfo) {
+		if maxAge == 0 || time.Since(info.ModTime()) > maxAge {
+			paths = append(paths, path)
+		}
+	}); err != nil {
+		return nil, fmt.Errorf("failed to purge cache: %w", err)
+	}
+	return paths, nil
+}
+
+// Stats summarizes the files currently in the cache.
+type Stats struct {
+	Files  int
+	Bytes  int64
+	Oldest time.Time
+	Newest time.Time
+}
+
+// Stat walks the cache dir and returns the number of files, their total size,
+// and the modification times of the oldest and newest files. A missing cache
+// dir yields empty Stats.
+func Stat() (Stats, error) {
+	var stats Stats
+
+	base, ok := Dir()
+	if !ok {
+		return stats, nil
+	}
+
+	if err := walkFiles(base, func(_ string, info os.FileInfo) {
+		stats.Files++
+		stats.Bytes += info.Size()
+		if stats.Oldest.IsZero() || info.ModTime().Before(stats.Oldest) {
+			stats.Oldest = info.ModTime()
+		}
+		if info.ModTime().After(stats.Newest) {
+			stats.Newest = info.ModTime()
+		}
+	}); err != nil {
+		return stats, fmt.Errorf("failed to stat cache: %w", err)
+	}
+	return stats, nil
+}
+
+// walkFiles calls fn for every regular file beneath base. Files that vanish
+// mid-walk, and a base that does not exist, are silently skipped.
+func walkFiles(base string, fn func(path string, info os.FileInfo)) error {
+	return filepath.Walk(base, func(path string, info os.FileInfo, walkErr error) error {
 		// Guard against nil info (can occur if the file disappeared). This is an
 		// unlikely edge case and has only happened when multiple Jenkins run were
 		// misconfigured and coincidently colllided on the cache entries.
@@ -107,22 +190,13 @@ func Purge(hours int) error {
 			return walkErr
 		}
 
-		if info == nil {
+		if info == nil || info.IsDir() {
 			return nil
 		}
 
-		if !info.IsDir() && time.Since(info.ModTime()) > maxAge {
-			if err := os.Remove(path); err == nil {
-				log.Debugf("removed cache file %s", path)
-			} else {
-				log.WithError(err).Warnf("failed to remove cache file %s", path)
-			}
-		}
+		fn(path, info)
 		return nil
-	}); err != nil {
-		return fmt.Errorf("failed to purge cache: %w", err)
-	}
-	return nil
+	})
 }
 
 // Read attempts to read a cached entry.
@@ -149,7 +223,9 @@ func Read(subdirs []string, clearKey string) (*Entry, bool) {
 	}, true
 }
 
-// Write stores data for the given key beneath subdirs. Creates directories as needed.
+// Write stores data for the given key beneath subdirs. Creates directories as
+// needed. The data is written to a temp file and renamed into place so that
+// concurrent readers never observe a partially written entry.
 func Write(subdirs []string, clearKey string, data []byte) error {
 	if !Enabled() {
 		return nil // treat as disabled.
@@ -164,7 +240,23 @@ func Write(subdirs []string, clearKey string, data []byte) error {
 		return fmt.Errorf("failed to create cache directory: %w", err)
 	}
 	p := filepath.Join(dir, encoded)
-	if err := os.WriteFile(p, data, os.FileMode(0o600)); err != nil { //nolint:mnd
+
+	// CreateTemp creates the file with 0600 permissions.
+	tmp, err := os.CreateTemp(dir, encoded+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to write to cache: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write to cache: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write to cache: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), p); err != nil {
+		_ = os.Remove(tmp.Name())
 		return fmt.Errorf("failed to write to cache: %w", err)
 	}
 	log.Debugf("cache write: key=%s", clearKey)
@@ -4,8 +4,10 @@
 package cacheutil
 
 import (
+	"bytes"
 	"os"
 	"path/filepath"
+	"sync"
 	"testing"
 	"time"
 
@@ -363,6 +365,37 @@ func TestWrite_FilePermissions(t *testing.T) {
 	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
 }
 
+// TestWrite_Concurrent verifies concurrent writers of the same key never
+// expose a partially written entry to readers and leave no temp files behind.
+func TestWrite_Concurrent(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("TFCTL_CACHE_DIR", tmpDir)
+	t.Setenv("TFCTL_CACHE", "1")
+
+	testKey := "concurrent-key"
+	testData := bytes.Repeat([]byte("x"), 64*1024)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, Write([]string{"sub"}, testKey, testData))
+		}()
+		go func() {
+			defer wg.Done()
+			if entry, ok := Read([]string{"sub"}, testKey); ok {
+				assert.Equal(t, len(testData), len(entry.Data))
+			}
+		}()
+	}
+	wg.Wait()
+
+	files, err := os.ReadDir(filepath.Join(tmpDir, "sub"))
+	assert.NoError(t, err)
+	assert.Len(t, files, 1)
+}
+
 // TestWrite_OverwritesExisting verifies Write overwrites existing cache
 // files.
 func TestWrite_OverwritesExisting(t *testing.T) {
@@ -528,6 +561,110 @@ func TestPurge_NestedDirectories(t *testing.T) {
 	assert.NoFileExists(t, oldPath)
 }
 
+// TestPurgeAll_RemovesEverything verifies PurgeAll removes files of any age,
+// including those in nested directories.
+func TestPurgeAll_RemovesEverything(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("TFCTL_CACHE_DIR", tmpDir)
+
+	nestedDir := filepath.Join(tmpDir, "level1")
+	require.NoError(t, os.MkdirAll(nestedDir, 0o755))
+
+	recentPath := filepath.Join(tmpDir, "recent.txt")
+	require.NoError(t, os.WriteFile(recentPath, []byte("recent"), 0o600))
+
+	futurePath := filepath.Join(nestedDir, "future.txt")
+	require.NoError(t, os.WriteFile(futurePath, []byte("future"), 0o600))
+	futureTime := time.Now().Add(time.Hour)
+	require.NoError(t, os.Chtimes(futurePath, futureTime, futureTime))
+
+	assert.NoError(t, PurgeAll())
+	assert.NoFileExists(t, recentPath)
+	assert.NoFileExists(t, futurePath)
+	assert.DirExists(t, nestedDir)
+}
+
+// TestPurgeCandidates verifies the selection matches what Purge would remove,
+// across nested directories, and that nothing is removed.
+func TestPurgeCandidates(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("TFCTL_CACHE_DIR", tmpDir)
+
+	nestedDir := filepath.Join(tmpDir, "level1")
+	require.NoError(t, os.MkdirAll(nestedDir, 0o755))
+
+	pastTime := time.Now().Add(-3 * time.Hour)
+	oldPath := filepath.Join(tmpDir, "old.txt")
+	nestedOldPath := filepath.Join(nestedDir, "old.txt")
+	for _, p := range []string{oldPath, nestedOldPath} {
+		require.NoError(t, os.WriteFile(p, []byte("old"), 0o600))
+		require.NoError(t, os.Chtimes(p, pastTime, pastTime))
+	}
+
+	recentPath := filepath.Join(tmpDir, "recent.txt")
+	require.NoError(t, os.WriteFile(recentPath, []byte("recent"), 0o600))
+
+	paths, err := PurgeCandidates(time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, []string{nestedOldPath, oldPath}, paths)
+
+	paths, err = PurgeCandidates(0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{nestedOldPath, oldPath, recentPath}, paths)
+
+	for _, p := range []string{oldPath, nestedOldPath, recentPath} {
+		assert.FileExists(t, p)
+	}
+}
+
+// TestPurgeCandidates_MissingDir verifies a cache dir that doesn't exist yet
+// selects nothing.
+func TestPurgeCandidates_MissingDir(t *testing.T) {
+	t.Setenv("TFCTL_CACHE_DIR", filepath.Join(t.TempDir(), "missing"))
+
+	paths, err := PurgeCandidates(0)
+	assert.NoError(t, err)
+	assert.Empty(t, paths)
+}
+
+// TestStat_CountsFiles verifies Stat reports the file count, total size and
+// the oldest and newest modification times across nested directories.
+func TestStat_CountsFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("TFCTL_CACHE_DIR", tmpDir)
+
+	nestedDir := filepath.Join(tmpDir, "level1")
+	require.NoError(t, os.MkdirAll(nestedDir, 0o755))
+
+	oldTime := time.Now().Add(-3 * time.Hour).Truncate(time.Second)
+	newTime := time.Now().Add(-1 * time.Hour).Truncate(time.Second)
+
+	oldPath := filepath.Join(tmpDir, "old.txt")
+	require.NoError(t, os.WriteFile(oldPath, []byte("old"), 0o600))
+	require.NoError(t, os.Chtimes(oldPath, oldTime, oldTime))
+
+	newPath := filepath.Join(nestedDir, "new.txt")
+	require.NoError(t, os.WriteFile(newPath, []byte("newer"), 0o600))
+	require.NoError(t, os.Chtimes(newPath, newTime, newTime))
+
+	stats, err := Stat()
+	require.NoError(t, err)
+	assert.Equal(t, 2, stats.Files)
+	assert.Equal(t, int64(8), stats.Bytes)
+	assert.True(t, oldTime.Equal(stats.Oldest))
+	assert.True(t, newTime.Equal(stats.Newest))
+}
+
+// TestStat_MissingDir verifies Stat returns empty stats when the cache dir has
+// not been created yet.
+func TestStat_MissingDir(t *testing.T) {
+	t.Setenv("TFCTL_CACHE_DIR", filepath.Join(t.TempDir(), "missing"))
+
+	stats, err := Stat()
+	require.NoError(t, err)
+	assert.Equal(t, Stats{}, stats)
+}
+
 // TestEncodeKey_Consistency verifies encodeKey produces consistent output.
 func TestEncodeKey_Consistency(t *testing.T) {
 	testKey := "consistent-key"
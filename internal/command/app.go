@@ -7,7 +7,7 @@ import (
 	"context"
 	"fmt"
 	"os"
-	"sort"
+	"slices"
 	"strings"
 
 	"github.com/urfave/cli/v3"
@@ -17,7 +17,9 @@ import (
 	"github.com/staranto/tfctl/internal/util"
 )
 
-func InitApp(ctx context.Context, args []string) (*cli.Command, error) {
+// InitApp builds the tfctl command tree for args. run is what batch runs each
+// line of its file through; with a nil run, batch reports it isn't available.
+func InitApp(ctx context.Context, args []string, run BatchRunner) (*cli.Command, error) {
 
 	// Save the CWD at startup and then defer restoring it so we're tidy.
 	sd, _ := os.Getwd()
@@ -27,6 +29,17 @@ func InitApp(ctx context.Context, args []string) (*cli.Command, error) {
 		}
 	}()
 
+	// Like terraform -chdir, --chdir switches directory before RootDir is
+	// resolved, so a relative RootDir and the default RootDir are both taken
+	// from the new directory.
+	wd := sd
+	if dir := ChdirArg(args); dir != "" {
+		if err := os.Chdir(dir); err != nil {
+			return nil, fmt.Errorf("failed to change to --chdir directory (%s): %w", dir, err)
+		}
+		wd, _ = os.Getwd()
+	}
+
 	// The arg[1] immediately following the binary (arg[0]) is the tfctl
 	// subcommand and also represents the namespace key to be used when retrieving
 	// config values. arg[1] could be -h/--help, so ignore it if it appears to be
@@ -38,9 +51,18 @@ func InitApp(ctx context.Context, args []string) (*cli.Command, error) {
 
 	// allow short if-style local cfg; no actual outer cfg
 	cfg2, _ := config.Load(ns) //nolint
+
+	// --profile, or $TFCTL_PROFILE, supplies flag defaults from a profiles.<name>
+	// config block, which the flag constructors put ahead of their other sources.
+	profile, err := loadProfile(ProfileArg(args))
+	if err != nil {
+		return nil, err
+	}
+
 	meta := meta.Meta{
 		Args:        args,
 		Config:      cfg2,
+		Profile:     profile,
 		Context:     ctx,
 		StartingDir: sd,
 	}
@@ -49,23 +71,31 @@ func InitApp(ctx context.Context, args []string) (*cli.Command, error) {
 	// This is determined by whether or not it begins with - or --.  If it does,
 	// it's a flag and the CWD directory is the starting directory.  If it's not,
 	// we assume we have a directory spec of some sort and need to parse it more.
-	// Special-case the 'completion' and 'ps' commands which take a plain
-	// positional argument (e.g., 'bash' or 'zsh' for completion, plan file
-	// for ps).
-	if (ns != "completion" && ns != "ps") && len(args) > 2 && !strings.HasPrefix(args[2], "-") {
-		if wd, env, err := util.ParseRootDir(args[2]); err == nil {
+	// Special-case the 'batch', 'cache', 'completion', 'config' and 'ps' commands
+	// which take a plain positional argument (e.g., 'bash' or 'zsh' for
+	// completion, 'validate' for config, 'purge' for cache, plan file for ps,
+	// command file for batch).
+	if (ns != "batch" && ns != "cache" && ns != "completion" && ns != "config" && ns != "ps") && len(args) > 2 && !strings.HasPrefix(args[2], "-") {
+		if wd, env, workspace, err := util.ParseRootDir(args[2]); err == nil {
 			meta.RootDir = wd
 			meta.Env = env
+			meta.Workspace = workspace
 		} else {
 			return nil, fmt.Errorf("failed to parse rootDir (%s): %w", args[2], err)
 		}
 	} else {
-		meta.RootDir = sd
+		meta.RootDir = wd
 	}
 
+	// Shell completion is enabled so the static scripts from "tfctl completion"
+	// can ask for live flag values with --generate-shell-completion. The cli's
+	// own script generator is parked under a hidden name so it doesn't collide
+	// with the completion command.
 	app := &cli.Command{
-		Name:  "tfctl",
-		Usage: "Terraform Control",
+		Name:                       "tfctl",
+		Usage:                      "Terraform Control",
+		EnableShellCompletion:      true,
+		ShellCompletionCommandName: "__complete",
 		Flags: []cli.Flag{
 			&cli.BoolFlag{
 				Name:        "version",
@@ -77,24 +107,80 @@ func InitApp(ctx context.Context, args []string) (*cli.Command, error) {
 	}
 
 	app.Commands = append(app.Commands,
+		apqCommandBuilder(meta),
+		batchCommandBuilder(meta, run),
+		cacheCommandBuilder(meta),
+		configCommandBuilder(meta),
+		cvqCommandBuilder(meta),
 		mqCommandBuilder(meta),
+		ncqCommandBuilder(meta),
+		ocqCommandBuilder(meta),
 		oqCommandBuilder(meta),
 		pqCommandBuilder(meta),
 		psCommandBuilder(meta),
 		rqCommandBuilder(meta),
+		rtqCommandBuilder(meta),
 		siCommandBuilder(meta),
+		soqCommandBuilder(meta),
 		sqCommandBuilder(meta),
 		svqCommandBuilder(meta),
 		wqCommandBuilder(meta),
 		completionCommandBuilder(meta),
 	)
 
-	// Make sure flags are sorted for the --help text.
+	// Make sure flags are sorted for the --help text, either alphabetically or,
+	// with help.order set to grouped, clustered by purpose.
+	order, _ := config.GetString("help.order", "alpha")
 	for _, cmd := range app.Commands {
-		sort.Slice(cmd.Flags, func(i, j int) bool {
-			return cmd.Flags[i].Names()[0] < cmd.Flags[j].Names()[0]
-		})
+		sortFlags(cmd.Flags, order == "grouped")
+		cmd.ShellComplete = flagValueCompleter()
 	}
 
 	return app, nil
 }
+
+// ChdirArg returns the directory given by the last --chdir flag in args, in
+// either "--chdir dir" or "--chdir=dir" form, or "" if there is none.
+func ChdirArg(args []string) string {
+	return flagArg(args, "chdir")
+}
+
+// ColorArgs joins a color mode following a bare --color or -c in args to the
+// flag, so "--color always" means "--color=always" rather than a bare --color
+// followed by an "always" argument. A bare --color still means auto. The
+// joining is unconditional, so a RootDir named auto, always or never right
+// after a bare --color is taken as the mode; "--color ./always" or putting the
+// RootDir first keeps it a directory.
+func ColorArgs(args []string) []string {
+	result := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if (arg == "--color" || arg == "-c") && i+1 < len(args) && slices.Contains(colorModes, args[i+1]) {
+			arg += "=" + args[i+1]
+			i++
+		}
+		result = append(result, arg)
+	}
+	return result
+}
+
+// flagArg returns the value of the last --name flag in args, or -name for a
+// one letter name, in either "--name value" or "--name=value" form, or "" if
+// there is none.
+func flagArg(args []string, name string) string {
+	flag := "--" + name
+	if len(name) == 1 {
+		flag = "-" + name
+	}
+
+	var value string
+	for i, arg := range args {
+		switch {
+		case arg == flag && i+1 < len(args):
+			value = args[i+1]
+		case strings.HasPrefix(arg, flag+"="):
+			value = strings.TrimPrefix(arg, flag+"=")
+		}
+	}
+	return value
+}
@@ -0,0 +1,99 @@
+// Copyright (c) 2026 Steve Taranto <staranto@gmail.com>.
+// SPDX-License-Identifier: Apache-2.0
+// no-cloc
+
+package command
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v3"
+
+	"github.com/staranto/tfctl/internal/backend"
+	"github.com/staranto/tfctl/internal/backend/local"
+	"github.com/staranto/tfctl/internal/util"
+)
+
+func TestChdirArg(t *testing.T) {
+	assert.Equal(t, "", ChdirArg([]string{"tfctl", "sq", "."}))
+	assert.Equal(t, "infra", ChdirArg([]string{"tfctl", "sq", "--chdir", "infra"}))
+	assert.Equal(t, "b", ChdirArg([]string{"tfctl", "sq", "--chdir=a", "--chdir", "b"}))
+	assert.Equal(t, "", ChdirArg([]string{"tfctl", "sq", "--chdir"}))
+}
+
+// findCommand returns the named subcommand of app.
+func findCommand(t *testing.T, app *cli.Command, name string) *cli.Command {
+	t.Helper()
+	for _, cmd := range app.Commands {
+		if cmd.Name == name {
+			return cmd
+		}
+	}
+	t.Fatalf("command %s not found", name)
+	return nil
+}
+
+func TestInitApp_Chdir(t *testing.T) {
+	t.Setenv("TFCTL_CFG_FILE", "")
+
+	// A bare terraform.tfstate makes the directory resolve to a local backend.
+	root := t.TempDir()
+	state := `{"version":4,"terraform_version":"1.5.0","serial":1,"lineage":"x","resources":[]}`
+	require.NoError(t, os.WriteFile(filepath.Join(root, "terraform.tfstate"), []byte(state), 0o600))
+	sub := filepath.Join(root, "sub")
+	require.NoError(t, os.Mkdir(sub, 0o755))
+
+	start, err := os.Getwd()
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{name: "default RootDir", args: []string{"tfctl", "sq", ".", "--chdir", root}, want: root},
+		{name: "relative RootDir", args: []string{"tfctl", "sq", "sub", "--chdir=" + root}, want: sub},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app, err := InitApp(context.Background(), tt.args, nil)
+			require.NoError(t, err)
+
+			cwd, _ := os.Getwd()
+			assert.Equal(t, start, cwd, "InitApp should restore the working directory")
+
+			sq := findCommand(t, app, "sq")
+			assert.Equal(t, tt.want, GetMeta(sq).RootDir)
+
+			if tt.want == root {
+				be, err := backend.NewBackend(context.Background(), *sq)
+				require.NoError(t, err)
+				assert.IsType(t, &local.BackendLocal{}, be)
+			}
+		})
+	}
+}
+
+func TestInitApp_RootDirWorkspace(t *testing.T) {
+	t.Setenv("TFCTL_CFG_FILE", "")
+
+	root := t.TempDir()
+	infra := filepath.Join(root, "infra")
+	require.NoError(t, os.Mkdir(infra, 0o755))
+
+	app, err := InitApp(context.Background(), []string{"tfctl", "sq", infra + "@prod"}, nil)
+	require.NoError(t, err)
+	m := GetMeta(findCommand(t, app, "sq"))
+	assert.Equal(t, infra, m.RootDir)
+	assert.Equal(t, "prod", m.Workspace)
+	assert.Empty(t, m.Env)
+
+	_, err = InitApp(context.Background(), []string{"tfctl", "sq", infra + "@prod::dev"}, nil)
+	require.ErrorIs(t, err, util.ErrAmbiguousRootDir)
+}
@@ -0,0 +1,110 @@
+// Copyright (c) 2026 Steve Taranto <staranto@gmail.com>.
+// SPDX-License-Identifier: Apache-2.0
+
+package command
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/apex/log"
+	"github.com/hashicorp/go-tfe"
+	"github.com/urfave/cli/v3"
+
+	"github.com/staranto/tfctl/internal/filters"
+	"github.com/staranto/tfctl/internal/meta"
+)
+
+// apqDefaultAttrs specifies the default attributes displayed for agent pools
+// in the "apq" command output.
+var apqDefaultAttrs = []string{".id", "name", "agent-count", "organization-scoped"}
+
+// apqCommandAction is the action handler for the "apq" subcommand. It lists
+// agent pools for the selected organization(s), supports --tldr/--schema
+// shortcuts, and emits results per common flags.
+func apqCommandAction(ctx context.Context, cmd *cli.Command) error {
+	be, orgs, client, err := InitRemoteOrgQuery(ctx, cmd)
+	if err != nil {
+		return err
+	}
+
+	fn := RemoteQueryFetcherFactory(
+		be,
+		orgs,
+		apqFetcher(client),
+		apqServerSideFilterAugmenter,
+		"list agent pools",
+	)
+
+	return NewQueryActionRunner(
+		"apq",
+		reflect.TypeOf((*tfe.AgentPool)(nil)).Elem(),
+		apqDefaultAttrs,
+		fn,
+	).Run(ctx, cmd)
+}
+
+// apqFetcher returns a RemoteOrgListFetcher that lists one page of agent
+// pools using the provided client.
+func apqFetcher(
+	client *tfe.Client,
+) RemoteOrgListFetcher[*tfe.AgentPool, tfe.AgentPoolListOptions] {
+	return func(
+		ctx context.Context,
+		org string,
+		opts *tfe.AgentPoolListOptions,
+	) ([]*tfe.AgentPool, *tfe.Pagination, error) {
+		page, err := client.AgentPools.List(ctx, org, opts)
+		if err != nil {
+			return nil, nil, err
+		}
+		return page.Items, page.Pagination, nil
+	}
+}
+
+// apqServerSideFilterAugmenter augments the AgentPoolListOptions with
+// server-side filters extracted from the --filter flag.
+func apqServerSideFilterAugmenter(
+	_ context.Context,
+	cmd *cli.Command,
+	opts *tfe.AgentPoolListOptions,
+) error {
+	spec := cmd.String("filter")
+	filterList := filters.BuildFilters(spec)
+
+	for _, f := range filterList {
+		// We only care about server-side filters.
+		if !f.ServerSide {
+			continue
+		}
+
+		switch f.Key {
+		case "name":
+			opts.Query = f.Value
+		case "workspace":
+			opts.AllowedWorkspacesName = f.Value
+		case "project":
+			opts.AllowedProjectsName = f.Value
+		}
+	}
+
+	log.Debugf("opts after augmentation: %+v", opts)
+	return nil
+}
+
+// apqCommandBuilder constructs the cli.Command for "apq", wiring metadata,
+// flags, and action handlers.
+func apqCommandBuilder(meta meta.Meta) *cli.Command {
+	return (&QueryCommandBuilder{
+		Name:      "apq",
+		Usage:     "agent pool query",
+		UsageText: "tfctl apq [RootDir] [options]",
+		Flags: []cli.Flag{
+			NewHostFlag(meta.Profile, "apq", meta.Config.Source),
+			NewOrgFlag(meta.Profile, "apq", meta.Config.Source),
+			NewPartialFlag(),
+		},
+		Action: apqCommandAction,
+		Meta:   meta,
+	}).Build()
+}
@@ -0,0 +1,64 @@
+// Copyright (c) 2026 Steve Taranto <staranto@gmail.com>.
+// SPDX-License-Identifier: Apache-2.0
+// no-cloc
+
+package command
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/go-tfe"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v3"
+
+	"github.com/staranto/tfctl/internal/backend/remote"
+)
+
+func TestApqFetcher_ListsAgentPoolsPerOrg(t *testing.T) {
+	var queries []string
+	srv := newFakeTFEServer(t, fakeTFERoute{suffix: "/agent-pools", pages: 1,
+		render: func(r *http.Request, _ int) string {
+			org := strings.Split(r.URL.Path, "/")[4]
+			queries = append(queries, r.URL.Query().Get("q"))
+			return fmt.Sprintf(`{"id":"apool-%s","type":"agent-pools","attributes":`+
+				`{"name":"%s-pool","agent-count":2,"organization-scoped":true}}`, org, org)
+		},
+	})
+
+	var pools []*tfe.AgentPool
+	args := []string{"--org", "alpha,beta", "--filter", "_name=pool"}
+	runOrgCommand(t, args, func(ctx context.Context, cmd *cli.Command) error {
+		be, orgs, client, err := InitRemoteOrgQuery(ctx, cmd, remote.WithClient(fakeTFEClient(srv)))
+		if err != nil {
+			return err
+		}
+		pools, err = RemoteQueryFetcherFactory(
+			be, orgs, apqFetcher(client), apqServerSideFilterAugmenter, "list agent pools",
+		)(ctx, cmd)
+		return err
+	})
+
+	require.Len(t, pools, 2)
+	assert.Equal(t, "alpha-pool", pools[0].Name)
+	assert.Equal(t, "beta-pool", pools[1].Name)
+	assert.Equal(t, 2, pools[0].AgentCount)
+	assert.True(t, pools[1].OrganizationScoped)
+	assert.Equal(t, []string{"pool", "pool"}, queries)
+}
+
+func TestApqServerSideFilterAugmenter(t *testing.T) {
+	cmd := &cli.Command{Flags: []cli.Flag{&cli.StringFlag{Name: "filter"}}}
+	require.NoError(t, cmd.Set("filter", "_name=gpu,_workspace=app,_project=core,agent-count=0"))
+
+	opts := &tfe.AgentPoolListOptions{}
+	require.NoError(t, apqServerSideFilterAugmenter(context.Background(), cmd, opts))
+
+	assert.Equal(t, "gpu", opts.Query)
+	assert.Equal(t, "app", opts.AllowedWorkspacesName)
+	assert.Equal(t, "core", opts.AllowedProjectsName)
+}
@@ -0,0 +1,179 @@
+// Copyright (c) 2026 Steve Taranto <staranto@gmail.com>.
+// SPDX-License-Identifier: Apache-2.0
+
+package command
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/staranto/tfctl/internal/meta"
+)
+
+// BatchRunner runs one complete tfctl command line, args[0] being the program
+// name, through the same argument processing as the tfctl binary. batch runs
+// each line of its file through the one main hands to InitApp.
+type BatchRunner func(ctx context.Context, args []string) error
+
+var (
+	// errBatchUnavailable is returned when batch was built without a runner.
+	errBatchUnavailable = errors.New("batch is not available")
+
+	// errBatchFile is returned when batch is run without a file.
+	errBatchFile = errors.New("batch needs a file of tfctl command lines, or - for stdin")
+)
+
+// batchLine is one command line of a batch file.
+type batchLine struct {
+	number int
+	args   []string
+}
+
+// batchCommandAction is the action handler for "batch". It runs every line of
+// the file named by its argument, or stdin for "-", in this process, so TFE
+// clients and their connections are set up once for the whole batch. Each
+// command's output is preceded by a "==> line <==" marker. The batch stops at
+// the first failing line.
+func batchCommandAction(ctx context.Context, cmd *cli.Command, run BatchRunner) error {
+	if run == nil {
+		return errBatchUnavailable
+	}
+
+	path := cmd.Args().First()
+	if path == "" {
+		return errBatchFile
+	}
+
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("failed to open batch file: %w", err)
+		}
+		defer f.Close()
+		r = f
+	}
+
+	lines, err := readBatch(r)
+	if err != nil {
+		return err
+	}
+
+	for i, line := range lines {
+		// The marker goes wherever the line's own output does.
+		format := flagArg(line.args, "output")
+		if o := flagArg(line.args, "o"); o != "" {
+			format = o
+		}
+		if err := writeMarker(flagArg(line.args, "out"), format, strings.Join(line.args, " "), i == 0); err != nil {
+			return fmt.Errorf("batch line %d: %w", line.number, err)
+		}
+
+		if err := run(ctx, append([]string{"tfctl"}, line.args...)); err != nil {
+			return fmt.Errorf("batch line %d: %w", line.number, err)
+		}
+	}
+
+	return nil
+}
+
+// readBatch parses r into command lines. Blank lines and lines starting with
+// # are skipped, and a leading "tfctl" is optional. Arguments are split on
+// whitespace, except within single or double quotes.
+func readBatch(r io.Reader) ([]batchLine, error) {
+	var lines []batchLine
+
+	scanner := bufio.NewScanner(r)
+	for number := 1; scanner.Scan(); number++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		args, err := splitBatchLine(text)
+		if err != nil {
+			return nil, fmt.Errorf("batch line %d: %w", number, err)
+		}
+		if args[0] == "tfctl" {
+			args = args[1:]
+		}
+		if len(args) == 0 {
+			continue
+		}
+		if args[0] == "batch" {
+			return nil, fmt.Errorf("batch line %d: batch can't be nested", number)
+		}
+
+		lines = append(lines, batchLine{number: number, args: args})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read batch file: %w", err)
+	}
+
+	return lines, nil
+}
+
+// splitBatchLine splits line on whitespace, keeping quoted runs, such as
+// --filter 'name=~web app', together and dropping the quotes.
+func splitBatchLine(line string) ([]string, error) {
+	var (
+		args  []string
+		arg   strings.Builder
+		inArg bool
+		quote rune
+	)
+
+	for _, r := range line {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+				continue
+			}
+			arg.WriteRune(r)
+		case r == '\'' || r == '"':
+			quote, inArg = r, true
+		case r == ' ' || r == '\t':
+			if inArg {
+				args = append(args, arg.String())
+				arg.Reset()
+				inArg = false
+			}
+		default:
+			arg.WriteRune(r)
+			inArg = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote", quote)
+	}
+	if inArg {
+		args = append(args, arg.String())
+	}
+
+	return args, nil
+}
+
+// batchCommandBuilder constructs the cli.Command for "batch", which runs each
+// line of its file through run.
+func batchCommandBuilder(meta meta.Meta, run BatchRunner) *cli.Command {
+	return &cli.Command{
+		Name:      "batch",
+		Usage:     "run the tfctl commands in a file",
+		UsageText: "tfctl batch <file|->",
+		Metadata: map[string]any{
+			"meta": meta,
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			return batchCommandAction(ctx, cmd, run)
+		},
+	}
+}
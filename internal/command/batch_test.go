@@ -0,0 +1,175 @@
+// Copyright (c) 2026 Steve Taranto <staranto@gmail.com>.
+// SPDX-License-Identifier: Apache-2.0
+// no-cloc
+
+package command
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadBatch(t *testing.T) {
+	lines, err := readBatch(strings.NewReader(`
+# workspaces first
+wq --org acme
+tfctl sq infra --filter 'type=aws_s3_bucket' --attrs "name,type"
+
+  svq infra::prod
+`))
+	require.NoError(t, err)
+	assert.Equal(t, []batchLine{
+		{number: 3, args: []string{"wq", "--org", "acme"}},
+		{number: 4, args: []string{"sq", "infra", "--filter", "type=aws_s3_bucket", "--attrs", "name,type"}},
+		{number: 6, args: []string{"svq", "infra::prod"}},
+	}, lines)
+
+	_, err = readBatch(strings.NewReader("sq --filter 'name=x\n"))
+	require.EqualError(t, err, "batch line 1: unterminated ' quote")
+
+	_, err = readBatch(strings.NewReader("sq\nbatch more.txt\n"))
+	require.EqualError(t, err, "batch line 2: batch can't be nested")
+}
+
+func TestSplitBatchLine(t *testing.T) {
+	tests := []struct {
+		line string
+		want []string
+	}{
+		{line: "sq", want: []string{"sq"}},
+		{line: "sq  --attrs\tname", want: []string{"sq", "--attrs", "name"}},
+		{line: `sq --filter 'name=~web app'`, want: []string{"sq", "--filter", "name=~web app"}},
+		{line: `sq --filter "name=it's"`, want: []string{"sq", "--filter", "name=it's"}},
+		{line: `sq --filter=''`, want: []string{"sq", "--filter="}},
+		{line: `sq ''`, want: []string{"sq", ""}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			got, err := splitBatchLine(tt.line)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestBatchCommandAction(t *testing.T) {
+	t.Setenv("TFCTL_CFG_FILE", "")
+	cacheDir := t.TempDir()
+	t.Setenv("TFCTL_CACHE_DIR", cacheDir)
+
+	root := t.TempDir()
+	state := `{"version":4,"terraform_version":"1.5.0","serial":1,"lineage":"x","resources":[` +
+		`{"mode":"managed","type":"aws_s3_bucket","name":"logs","provider":"provider[\"registry.terraform.io/hashicorp/aws\"]",` +
+		`"instances":[{"attributes":{"id":"logs-bucket"}}]}]}`
+	require.NoError(t, os.WriteFile(filepath.Join(root, "terraform.tfstate"), []byte(state), 0o600))
+
+	batch := filepath.Join(t.TempDir(), "batch.txt")
+	require.NoError(t, os.WriteFile(batch, []byte("sq "+root+"\ncache path\n"), 0o600))
+
+	var ran [][]string
+	var run BatchRunner
+	run = func(ctx context.Context, args []string) error {
+		ran = append(ran, args)
+		app, err := InitApp(ctx, args, run)
+		if err != nil {
+			return err
+		}
+		return app.Run(ctx, args)
+	}
+
+	stdout := os.Stdout
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	os.Stdout = w
+	t.Cleanup(func() { os.Stdout = stdout })
+
+	args := []string{"tfctl", "batch", batch}
+	app, err := InitApp(context.Background(), args, run)
+	require.NoError(t, err)
+	runErr := app.Run(context.Background(), args)
+
+	w.Close()
+	os.Stdout = stdout
+	out, err := io.ReadAll(r)
+	require.NoError(t, err)
+	require.NoError(t, runErr)
+
+	assert.Equal(t, [][]string{{"tfctl", "sq", root}, {"tfctl", "cache", "path"}}, ran)
+	got := string(out)
+	assert.Contains(t, got, "==> sq "+root+" <==\n")
+	assert.Contains(t, got, "aws_s3_bucket.logs")
+	assert.Contains(t, got, "\n\n==> cache path <==\n"+cacheDir+"\n")
+	assert.Less(t, strings.Index(got, "aws_s3_bucket.logs"), strings.Index(got, "==> cache path"))
+}
+
+// TestBatchCommandAction_FreshFlags verifies a flag set on one batch line
+// doesn't leak into the next, which still takes the value from its
+// environment variable.
+func TestBatchCommandAction_FreshFlags(t *testing.T) {
+	t.Setenv("TFCTL_CFG_FILE", "")
+	t.Setenv("TFCTL_CACHE_DIR", t.TempDir())
+	t.Setenv("TFCTL_WORKSPACE", "fromenv")
+
+	root := t.TempDir()
+	batch := filepath.Join(t.TempDir(), "batch.txt")
+	require.NoError(t, os.WriteFile(batch, []byte(
+		"sq "+root+" --workspace one --print-sources\n"+
+			"sq "+root+" --print-sources\n"), 0o600))
+
+	var run BatchRunner
+	run = func(ctx context.Context, args []string) error {
+		app, err := InitApp(ctx, args, run)
+		if err != nil {
+			return err
+		}
+		return app.Run(ctx, args)
+	}
+
+	stdout := os.Stdout
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	os.Stdout = w
+	t.Cleanup(func() { os.Stdout = stdout })
+
+	args := []string{"tfctl", "batch", batch}
+	app, err := InitApp(context.Background(), args, run)
+	require.NoError(t, err)
+	runErr := app.Run(context.Background(), args)
+
+	w.Close()
+	os.Stdout = stdout
+	out, err := io.ReadAll(r)
+	require.NoError(t, err)
+	require.NoError(t, runErr)
+
+	first, second, ok := strings.Cut(string(out), "==> sq "+root+" --print-sources <==")
+	require.True(t, ok)
+	assert.Contains(t, first, `"workspace": {
+    "value": "one",
+    "source": "command line"`)
+	assert.Contains(t, second, `"workspace": {
+    "value": "fromenv",
+    "source": "environment variable \"TFCTL_WORKSPACE\""`)
+}
+
+// TestBatchCommandAction_NoRunner verifies batch built without a runner reports
+// it rather than running anything.
+func TestBatchCommandAction_NoRunner(t *testing.T) {
+	t.Setenv("TFCTL_CFG_FILE", "")
+
+	batch := filepath.Join(t.TempDir(), "batch.txt")
+	require.NoError(t, os.WriteFile(batch, []byte("cache path\n"), 0o600))
+
+	args := []string{"tfctl", "batch", batch}
+	app, err := InitApp(context.Background(), args, nil)
+	require.NoError(t, err)
+	assert.ErrorIs(t, app.Run(context.Background(), args), errBatchUnavailable)
+}
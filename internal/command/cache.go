@@ -0,0 +1,136 @@
+// Copyright (c) 2026 Steve Taranto <staranto@gmail.com>.
+// SPDX-License-Identifier: Apache-2.0
+
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/staranto/tfctl/internal/cacheutil"
+	"github.com/staranto/tfctl/internal/meta"
+)
+
+// errNoCacheDir is returned when neither TFCTL_CACHE_DIR nor the OS user cache
+// dir can be resolved.
+var errNoCacheDir = errors.New("cache directory could not be resolved")
+
+// cachePathCommandAction is the action handler for "cache path". It prints the
+// base cache directory.
+func cachePathCommandAction(_ context.Context, cmd *cli.Command) error {
+	dir, ok := cacheutil.Dir()
+	if !ok {
+		return errNoCacheDir
+	}
+
+	fmt.Fprintln(cmd.Root().Writer, dir)
+	return nil
+}
+
+// cacheInfoCommandAction is the action handler for "cache info". It reports
+// the cache location, whether caching is enabled, and the number, size and age
+// of the cached files.
+func cacheInfoCommandAction(_ context.Context, cmd *cli.Command) error {
+	dir, ok := cacheutil.Dir()
+	if !ok {
+		return errNoCacheDir
+	}
+
+	stats, err := cacheutil.Stat()
+	if err != nil {
+		return err
+	}
+
+	writeCacheInfo(cmd.Root().Writer, dir, cacheutil.Enabled(), stats, time.Now())
+	return nil
+}
+
+// writeCacheInfo renders stats as one "key: value" line per item. Ages are
+// relative to now and omitted when the cache is empty.
+func writeCacheInfo(w io.Writer, dir string, enabled bool, stats cacheutil.Stats, now time.Time) {
+	fmt.Fprintf(w, "path: %s\n", dir)
+	fmt.Fprintf(w, "enabled: %t\n", enabled)
+	fmt.Fprintf(w, "files: %d\n", stats.Files)
+	fmt.Fprintf(w, "bytes: %d\n", stats.Bytes)
+
+	if stats.Files == 0 {
+		return
+	}
+
+	fmt.Fprintf(w, "oldest: %s\n", now.Sub(stats.Oldest).Round(time.Second))
+	fmt.Fprintf(w, "newest: %s\n", now.Sub(stats.Newest).Round(time.Second))
+}
+
+// cachePurgeCommandAction is the action handler for "cache purge". It removes
+// every cached file or, with --older-than, only files older than the given
+// number of hours. With --dry-run it lists those files instead.
+func cachePurgeCommandAction(_ context.Context, cmd *cli.Command) error {
+	if _, ok := cacheutil.Dir(); !ok {
+		return errNoCacheDir
+	}
+
+	if cmd.Bool("dry-run") {
+		paths, err := cacheutil.PurgeCandidates(time.Duration(cmd.Int("older-than")) * time.Hour)
+		if err != nil {
+			return err
+		}
+		for _, path := range paths {
+			fmt.Fprintln(cmd.Root().Writer, path)
+		}
+		return nil
+	}
+
+	if hours := cmd.Int("older-than"); hours > 0 {
+		return cacheutil.Purge(hours)
+	}
+
+	return cacheutil.PurgeAll()
+}
+
+// cacheCommandBuilder constructs the cli.Command for "cache" and its "info",
+// "path" and "purge" subcommands.
+func cacheCommandBuilder(meta meta.Meta) *cli.Command {
+	return &cli.Command{
+		Name:      "cache",
+		Usage:     "inspect and manage the tfctl cache",
+		UsageText: "tfctl cache info|path|purge",
+		Metadata: map[string]any{
+			"meta": meta,
+		},
+		Commands: []*cli.Command{
+			{
+				Name:      "info",
+				Usage:     "report cache location, size and entry ages",
+				UsageText: "tfctl cache info",
+				Action:    cacheInfoCommandAction,
+			},
+			{
+				Name:      "path",
+				Usage:     "print the cache directory",
+				UsageText: "tfctl cache path",
+				Action:    cachePathCommandAction,
+			},
+			{
+				Name:      "purge",
+				Usage:     "remove cached files",
+				UsageText: "tfctl cache purge [--older-than hours] [--dry-run]",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "dry-run",
+						Usage: "list the files that would be removed without removing them",
+					},
+					&cli.IntFlag{
+						Name:  "older-than",
+						Usage: "only remove files older than this many hours",
+					},
+				},
+				Action: cachePurgeCommandAction,
+			},
+		},
+	}
+}
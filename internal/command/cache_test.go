@@ -0,0 +1,76 @@
+// Copyright (c) 2026 Steve Taranto <staranto@gmail.com>.
+// SPDX-License-Identifier: Apache-2.0
+// no-cloc
+
+package command
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/staranto/tfctl/internal/cacheutil"
+	"github.com/staranto/tfctl/internal/meta"
+)
+
+func TestWriteCacheInfo(t *testing.T) {
+	now := time.Date(2026, 1, 2, 12, 0, 0, 0, time.UTC)
+
+	var buf bytes.Buffer
+	writeCacheInfo(&buf, "/tmp/tfctl", true, cacheutil.Stats{}, now)
+	assert.Equal(t, "path: /tmp/tfctl\nenabled: true\nfiles: 0\nbytes: 0\n", buf.String())
+
+	buf.Reset()
+	stats := cacheutil.Stats{
+		Files:  3,
+		Bytes:  1024,
+		Oldest: now.Add(-26 * time.Hour),
+		Newest: now.Add(-90 * time.Second),
+	}
+	writeCacheInfo(&buf, "/tmp/tfctl", false, stats, now)
+	assert.Equal(t, "path: /tmp/tfctl\nenabled: false\nfiles: 3\nbytes: 1024\n"+
+		"oldest: 26h0m0s\nnewest: 1m30s\n", buf.String())
+}
+
+func TestCacheCommand(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("TFCTL_CACHE_DIR", tmpDir)
+
+	oldPath := filepath.Join(tmpDir, "old")
+	require.NoError(t, os.WriteFile(oldPath, []byte("old"), 0o600))
+	pastTime := time.Now().Add(-3 * time.Hour)
+	require.NoError(t, os.Chtimes(oldPath, pastTime, pastTime))
+
+	recentPath := filepath.Join(tmpDir, "recent")
+	require.NoError(t, os.WriteFile(recentPath, []byte("recent"), 0o600))
+
+	run := func(args ...string) string {
+		var buf bytes.Buffer
+		cmd := cacheCommandBuilder(meta.Meta{})
+		cmd.Writer = &buf
+		require.NoError(t, cmd.Run(context.Background(), append([]string{"cache"}, args...)))
+		return buf.String()
+	}
+
+	assert.Equal(t, tmpDir+"\n", run("path"))
+	assert.Contains(t, run("info"), "files: 2\nbytes: 9\n")
+
+	// A dry run lists what would go and leaves it in place.
+	assert.Equal(t, oldPath+"\n", run("purge", "--older-than", "1", "--dry-run"))
+	assert.Equal(t, oldPath+"\n"+recentPath+"\n", run("purge", "--dry-run"))
+	assert.FileExists(t, oldPath)
+	assert.FileExists(t, recentPath)
+
+	run("purge", "--older-than", "1")
+	assert.NoFileExists(t, oldPath)
+	assert.FileExists(t, recentPath)
+
+	run("purge")
+	assert.NoFileExists(t, recentPath)
+}
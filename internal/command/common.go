@@ -6,17 +6,25 @@ package command
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"reflect"
+	"slices"
+	"strconv"
 
+	"github.com/apex/log"
 	"github.com/hashicorp/go-tfe"
 	"github.com/hashicorp/jsonapi"
 	"github.com/urfave/cli/v3"
+	"golang.org/x/sync/errgroup"
 
 	"github.com/staranto/tfctl/internal/attrs"
+	"github.com/staranto/tfctl/internal/backend"
 	"github.com/staranto/tfctl/internal/backend/remote"
+	"github.com/staranto/tfctl/internal/config"
 	"github.com/staranto/tfctl/internal/meta"
 	"github.com/staranto/tfctl/internal/output"
 )
@@ -28,14 +36,20 @@ var DefaultListOptions = tfe.ListOptions{
 	PageSize:   100,
 }
 
-// BuildAttrs constructs an AttrList with defaults and optional extras from
-// --attrs, then applies the global transform spec.
+// BuildAttrs constructs an AttrList with defaults, the --view preset and
+// optional extras from --attrs, in that order so each can extend or override
+// the one before, then applies the global transform spec.
 func BuildAttrs(cmd *cli.Command, defaults ...string) (al attrs.AttrList) {
 	//nolint:errcheck
 	{
 		for _, d := range defaults {
 			al.Set(d)
 		}
+		// The flag validator has already checked the view exists.
+		if view := cmd.String("view"); view != "" {
+			spec, _ := config.View(cmd.Name, view)
+			al.Set(spec)
+		}
 		if extras := cmd.String("attrs"); extras != "" {
 			al.Set(extras)
 		}
@@ -45,23 +59,175 @@ func BuildAttrs(cmd *cli.Command, defaults ...string) (al attrs.AttrList) {
 }
 
 // DumpSchemaIfRequested writes the JSON schema for the provided type to stdout
-// when --schema is set, and returns true if it handled the request.
+// when --schema is set, and returns true if it handled the request. --deep
+// widens the schema to nested attributes and relationships.
 func DumpSchemaIfRequested(cmd *cli.Command, t reflect.Type) bool {
 	if cmd.Bool("schema") {
-		output.DumpSchema("", t, nil)
+		output.DumpSchema("", t, cmd.Bool("deep"), nil)
 		return true
 	}
 	return false
 }
 
+// redactedFlags are the flags whose values --print-config masks.
+var redactedFlags = []string{"passphrase"}
+
+// configSource is a flag's resolved value and where it came from, as printed
+// by --print-sources.
+type configSource struct {
+	Value  any    `json:"value"`
+	Source string `json:"source"`
+}
+
+// PrintConfigIfRequested writes the resolved value of every flag, after
+// config, env and flag precedence, to w as a json object when --print-config
+// or --print-sources is set, and returns true if it handled the request. With
+// --print-sources each value is paired with its source, see flagSource.
+func PrintConfigIfRequested(cmd *cli.Command, w io.Writer) bool {
+	sources := cmd.Bool("print-sources")
+	if !cmd.Bool("print-config") && !sources {
+		return false
+	}
+
+	values := make(map[string]any)
+	for _, f := range cmd.Flags {
+		name := f.Names()[0]
+		if name == "help" || name == "print-config" || name == "print-sources" {
+			continue
+		}
+		value := cmd.Value(name)
+		if slices.Contains(redactedFlags, name) && value != "" {
+			value = "<redacted>"
+		}
+		if sources {
+			values[name] = configSource{Value: value, Source: flagSource(f)}
+			continue
+		}
+		values[name] = value
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(values); err != nil {
+		log.Errorf("failed to encode config: %v", err)
+	}
+	return true
+}
+
+// flagSource describes where the value of f came from: "default", "command
+// line", or the value source in its Sources chain that supplied it, such as a
+// namespaced or global config key or an environment variable. The chain is
+// only consulted when the flag wasn't given on the command line, so a flag
+// whose value differs from what its chain yields was set there.
+func flagSource(f cli.Flag) string {
+	if !f.IsSet() {
+		return "default"
+	}
+
+	field := reflect.Indirect(reflect.ValueOf(f)).FieldByName("Sources")
+	if !field.IsValid() {
+		return "command line"
+	}
+	if chain, ok := field.Interface().(cli.ValueSourceChain); ok {
+		if raw, source, found := chain.LookupWithSource(); found && sourceYields(f, raw) {
+			return source.String()
+		}
+	}
+	return "command line"
+}
+
+// sourceYields reports whether raw, as looked up in a value source, parses to
+// the current value of f.
+func sourceYields(f cli.Flag, raw string) bool {
+	// Generic flags may normalize what they're given, e.g. --color maps true
+	// to auto, so raw is parsed into a fresh value of the same type.
+	if generic, ok := f.(*cli.GenericFlag); ok && generic.Value != nil && reflect.TypeOf(generic.Value).Kind() == reflect.Pointer {
+		fresh, ok := reflect.New(reflect.TypeOf(generic.Value).Elem()).Interface().(cli.Value)
+		return ok && fresh.Set(raw) == nil && fresh.String() == generic.Value.String()
+	}
+
+	switch value := f.Get().(type) {
+	case bool:
+		parsed, err := strconv.ParseBool(raw)
+		return (raw == "" && !value) || (err == nil && parsed == value)
+	default:
+		return fmt.Sprint(value) == raw
+	}
+}
+
+// writeMarker writes the "==> name <==" marker that precedes one of several
+// outputs, after a blank line unless it is the first, to the --out file out or
+// stdout, wherever the output it precedes goes. A sqlite database has no room
+// for markers, so none is written to one.
+func writeMarker(out, format, name string, first bool) error {
+	if format == "sqlite" {
+		return nil
+	}
+
+	w, err := output.OpenOut(out)
+	if err != nil {
+		return err
+	}
+	if !first {
+		fmt.Fprintln(w)
+	}
+	fmt.Fprintf(w, "==> %s <==\n", name)
+	return w.Close()
+}
+
 // EmitJSONAPISlice marshals a slice as JSONAPI and passes it to the common
-// output routine.
-func EmitJSONAPISlice(results any, al attrs.AttrList, cmd *cli.Command) error {
+// output routine. computed, if given, holds extra attributes for each element
+// of results, in the same order, which are merged into the rows.
+func EmitJSONAPISlice(results any, al attrs.AttrList, cmd *cli.Command, computed ...map[string]any) error {
 	var raw bytes.Buffer
 	if err := jsonapi.MarshalPayload(&raw, results); err != nil {
 		return fmt.Errorf("failed to marshal payload: %w", err)
 	}
-	output.SliceDiceSpit(raw, al, cmd, "data", os.Stdout, nil)
+	if len(computed) > 0 {
+		if err := mergeAttributes(&raw, computed); err != nil {
+			return err
+		}
+	}
+	return output.SliceDiceSpit(raw, al, cmd, "data", os.Stdout, nil)
+}
+
+// mergeAttributes adds computed[i] to the attributes of the i-th row of the
+// JSONAPI payload in raw. Numbers are decoded as json.Number so the other
+// attributes round-trip unchanged.
+func mergeAttributes(raw *bytes.Buffer, computed []map[string]any) error {
+	var payload map[string]any
+	dec := json.NewDecoder(raw)
+	dec.UseNumber()
+	if err := dec.Decode(&payload); err != nil {
+		return fmt.Errorf("failed to decode payload: %w", err)
+	}
+
+	rows, _ := payload["data"].([]any)
+	for i, row := range rows {
+		if i >= len(computed) {
+			break
+		}
+		row, ok := row.(map[string]any)
+		if !ok {
+			continue
+		}
+		attributes, ok := row["attributes"].(map[string]any)
+		if !ok {
+			attributes = map[string]any{}
+			row["attributes"] = attributes
+		}
+		for k, v := range computed[i] {
+			attributes[k] = v
+		}
+	}
+
+	raw.Reset()
+	enc := json.NewEncoder(raw)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(payload); err != nil {
+		return fmt.Errorf("failed to encode payload: %w", err)
+	}
 	return nil
 }
 
@@ -77,12 +243,21 @@ func GetMeta(cmd *cli.Command) meta.Meta {
 	return meta.Meta{}
 }
 
+// DefaultPageParallelism is the number of list pages fetched concurrently
+// when the pagination.parallelism config key is not set.
+const DefaultPageParallelism = 4
+
 // PaginateWithOptions[T, O] is a generic paginator that drives paginated API
 // calls with mutable options. It handles pagination logic and returns all
 // collected results. The augmenter callback (if provided) is called before
 // each API invocation, allowing options customization (e.g., setting filters
 // or tags). The fetcher callback encapsulates the actual API call and must
 // return results, pagination info, and any error.
+//
+// When the first page reports TotalPages, the remaining pages are fetched
+// concurrently (bounded by the pagination.parallelism config key) using
+// copies of the augmented first-page options, and reassembled in page order.
+// When the total is unknown, pages are walked sequentially.
 func PaginateWithOptions[T, O any](
 	ctx context.Context,
 	cmd *cli.Command,
@@ -110,10 +285,27 @@ func PaginateWithOptions[T, O any](
 		results = append(results, items...)
 
 		// Check if there are more pages
-		if pagination.NextPage == 0 {
+		if pagination == nil || pagination.NextPage == 0 {
 			break
 		}
 
+		// If the page count is known, fan out over the rest of the pages.
+		if pagination.TotalPages >= pagination.NextPage {
+			parallelism, _ := config.GetInt("pagination.parallelism", DefaultPageParallelism)
+			rest, err := fetchPagesConcurrently(
+				ctx,
+				options,
+				fetcher,
+				pagination.NextPage,
+				pagination.TotalPages,
+				parallelism,
+			)
+			if err != nil {
+				return nil, err
+			}
+			return append(results, rest...), nil
+		}
+
 		// Increment page number for next iteration
 		setPageNumber(options, pagination.NextPage)
 	}
@@ -121,37 +313,143 @@ func PaginateWithOptions[T, O any](
 	return results, nil
 }
 
+// fetchPagesConcurrently fetches pages first through last (inclusive) with at
+// most parallelism concurrent calls. Each call gets its own shallow copy of
+// options with the page number set, and the items are returned in page order.
+func fetchPagesConcurrently[T, O any](
+	ctx context.Context,
+	options *O,
+	fetcher func(context.Context, *O) ([]T, *tfe.Pagination, error),
+	first int,
+	last int,
+	parallelism int,
+) ([]T, error) {
+	if parallelism < 1 {
+		parallelism = 1
+	}
+
+	pages := make([][]T, last-first+1)
+
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(parallelism)
+
+	for page := first; page <= last; page++ {
+		g.Go(func() error {
+			opts := *options
+			setPageNumber(&opts, page)
+
+			items, _, err := fetcher(gctx, &opts)
+			if err != nil {
+				return err
+			}
+			pages[page-first] = items
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	var results []T
+	for _, items := range pages {
+		results = append(results, items...)
+	}
+	return results, nil
+}
+
 // RemoteQueryFetcherFactory creates a generic fetch function for remote
 // org-based queries. It handles the common pagination and augmentation logic,
 // delegating only the API call itself to the provided fetcher, and handling
-// errors with the provided operation name for context.
+// errors with the provided operation name for context. Each organization in
+// orgs is paginated in turn with fresh options and the results are
+// concatenated. The fetcher is expected to capture a single shared client.
+// With --partial, a failing organization is recorded and skipped rather than
+// aborting the query; the rows from the others are returned together with a
+// *PartialError describing the failures.
 func RemoteQueryFetcherFactory[T, O any](
 	be *remote.BackendRemote,
-	org string,
+	orgs []string,
 	fetcher RemoteOrgListFetcher[T, O],
 	augmenter Augmenter[O],
 	operation string,
 ) func(context.Context, *cli.Command) ([]T, error) {
 	return func(ctx context.Context, cmd *cli.Command) ([]T, error) {
-		options := new(O)
-		// Set DefaultListOptions on the options struct's ListOptions field
-		setListOptionsDefaults(options)
+		var results []T
+		var failures []SourceError
 
-		results, err := PaginateWithOptions(
-			ctx,
-			cmd,
-			options,
-			func(ctx context.Context, opts *O) ([]T, *tfe.Pagination, error) {
-				items, pagination, err := fetcher(ctx, org, opts)
-				if err != nil {
-					ctxErr := OrgQueryErrorContext(be, org, operation)
-					return nil, nil, remote.FriendlyTFE(err, ctxErr)
+		for _, org := range orgs {
+			options := new(O)
+			// Set DefaultListOptions on the options struct's ListOptions field
+			setListOptionsDefaults(options)
+
+			items, err := PaginateWithOptions(
+				ctx,
+				cmd,
+				options,
+				func(ctx context.Context, opts *O) ([]T, *tfe.Pagination, error) {
+					items, pagination, err := fetcher(ctx, org, opts)
+					if err != nil {
+						ctxErr := OrgQueryErrorContext(be, org, operation)
+						return nil, nil, remote.FriendlyTFE(err, ctxErr)
+					}
+					return items, pagination, nil
+				},
+				augmenter,
+			)
+			if err != nil {
+				if !cmd.Bool("partial") {
+					return nil, err
 				}
-				return items, pagination, nil
-			},
-			augmenter,
-		)
-		return results, err
+				failures = append(failures, SourceError{Source: "org=" + org, Err: err})
+				continue
+			}
+			results = append(results, items...)
+		}
+
+		if len(failures) > 0 {
+			return results, &PartialError{Errors: failures, Total: len(orgs)}
+		}
+
+		return results, nil
+	}
+}
+
+// WorkspaceListFetcher[T, O] returns the function listing one page of the T
+// of the workspace workspaceID with client, such as its configuration
+// versions. O is the options type of the list call.
+type WorkspaceListFetcher[T, O any] func(
+	client *tfe.Client,
+	workspaceID string,
+) func(context.Context, *O) ([]T, *tfe.Pagination, error)
+
+// WorkspaceQueryFetcherFactory creates a fetch function for queries listing
+// something of a single workspace, the backend's or --workspace. It requires
+// a remote or cloud backend, resolves the workspace and paginates the fetcher
+// over it starting from the default list options.
+func WorkspaceQueryFetcherFactory[T, O any](
+	be backend.Backend,
+	fetcher WorkspaceListFetcher[T, O],
+) func(context.Context, *cli.Command) ([]T, error) {
+	return func(ctx context.Context, cmd *cli.Command) ([]T, error) {
+		rbe, ok := be.(*remote.BackendRemote)
+		if !ok {
+			return nil, fmt.Errorf("%s requires a remote or cloud backend, not %s", cmd.Name, be)
+		}
+
+		client, err := rbe.Client()
+		if err != nil {
+			return nil, err
+		}
+
+		workspace, err := rbe.Workspace()
+		if err != nil {
+			return nil, err
+		}
+
+		options := new(O)
+		setListOptionsDefaults(options)
+		return PaginateWithOptions(ctx, cmd, options, fetcher(client, workspace.ID), nil)
 	}
 }
 
@@ -0,0 +1,346 @@
+// Copyright (c) 2026 Steve Taranto <staranto@gmail.com>.
+// SPDX-License-Identifier: Apache-2.0
+// no-cloc
+
+package command
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-tfe"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v3"
+
+	"github.com/staranto/tfctl/internal/backend/local"
+	"github.com/staranto/tfctl/internal/backend/remote"
+	"github.com/staranto/tfctl/internal/config"
+	"github.com/staranto/tfctl/internal/meta"
+)
+
+// pagedFetcher simulates a list endpoint with totalPages pages of two items
+// each. When knownTotal is false, TotalPages is left unset. Later pages
+// answer faster so concurrent completion order differs from page order.
+func pagedFetcher(totalPages int, knownTotal bool, seen *sync.Map) func(context.Context, *tfe.ProjectListOptions) ([]*tfe.Project, *tfe.Pagination, error) {
+	return func(_ context.Context, opts *tfe.ProjectListOptions) ([]*tfe.Project, *tfe.Pagination, error) {
+		page := opts.PageNumber
+		seen.Store(page, opts.Query)
+		time.Sleep(time.Duration(totalPages-page) * time.Millisecond)
+
+		p := &tfe.Pagination{CurrentPage: page}
+		if page < totalPages {
+			p.NextPage = page + 1
+		}
+		if knownTotal {
+			p.TotalPages = totalPages
+		}
+		return []*tfe.Project{
+			{ID: "a", Name: string(rune('a' + page))},
+			{ID: "b", Name: string(rune('a' + page))},
+		}, p, nil
+	}
+}
+
+func TestPaginateWithOptions_ConcurrentKeepsOrder(t *testing.T) {
+	var seen sync.Map
+	var augmented int32
+
+	augmenter := func(_ context.Context, _ *cli.Command, opts *tfe.ProjectListOptions) error {
+		atomic.AddInt32(&augmented, 1)
+		opts.Query = "prod"
+		return nil
+	}
+
+	options := tfe.ProjectListOptions{ListOptions: DefaultListOptions}
+	results, err := PaginateWithOptions(context.Background(), &cli.Command{}, &options, pagedFetcher(10, true, &seen), augmenter)
+	require.NoError(t, err)
+	require.Len(t, results, 20)
+
+	for i, p := range results {
+		assert.Equal(t, string(rune('a'+i/2+1)), p.Name)
+	}
+
+	// The augmenter runs once for page one; later pages inherit its options.
+	assert.Equal(t, int32(1), augmented)
+	for page := 1; page <= 10; page++ {
+		q, ok := seen.Load(page)
+		require.True(t, ok, "page %d not fetched", page)
+		assert.Equal(t, "prod", q)
+	}
+}
+
+func TestPaginateWithOptions_SequentialWhenTotalUnknown(t *testing.T) {
+	var seen sync.Map
+	var augmented int32
+
+	augmenter := func(_ context.Context, _ *cli.Command, _ *tfe.ProjectListOptions) error {
+		atomic.AddInt32(&augmented, 1)
+		return nil
+	}
+
+	options := tfe.ProjectListOptions{ListOptions: DefaultListOptions}
+	results, err := PaginateWithOptions(context.Background(), &cli.Command{}, &options, pagedFetcher(3, false, &seen), augmenter)
+	require.NoError(t, err)
+	assert.Len(t, results, 6)
+	assert.Equal(t, int32(3), augmented)
+}
+
+// TestPaginateWithOptions_LimitedFanOut verifies a limit past the first page
+// stops the concurrent fan-out at the pages holding it.
+func TestPaginateWithOptions_LimitedFanOut(t *testing.T) {
+	var seen sync.Map
+	fetch := pagedFetcher(10, true, &seen)
+
+	options := tfe.ProjectListOptions{ListOptions: tfe.ListOptions{PageNumber: 1, PageSize: 2}}
+	results, err := PaginateWithOptions(context.Background(), &cli.Command{}, &options,
+		func(ctx context.Context, opts *tfe.ProjectListOptions) ([]*tfe.Project, *tfe.Pagination, error) {
+			items, p, err := fetch(ctx, opts)
+			return items, limitedPagination(p, opts.PageSize, 5), err
+		}, nil)
+	require.NoError(t, err)
+	assert.Len(t, results, 6)
+
+	for page := 1; page <= 10; page++ {
+		_, ok := seen.Load(page)
+		assert.Equal(t, page <= 3, ok, "page %d", page)
+	}
+}
+
+// runWorkspaceQuery runs fetcher through WorkspaceQueryFetcherFactory for
+// workspace web of org acme, on a fake server that also answers routes, and
+// returns the rows.
+func runWorkspaceQuery[T, O any](
+	t *testing.T,
+	fetcher WorkspaceListFetcher[T, O],
+	routes ...fakeTFERoute,
+) []T {
+	t.Helper()
+	t.Setenv("TF_TOKEN", "test")
+
+	srv := newFakeTFEServer(t, append(routes,
+		fakeTFERoute{suffix: "/api/v2/organizations/acme/workspaces/web", render: func(*http.Request, int) string {
+			return `{"data":{"id":"ws-abc","type":"workspaces","attributes":{"name":"web"}}}`
+		}},
+	)...)
+
+	var rows []T
+	cmd := &cli.Command{
+		Name:  "q",
+		Flags: []cli.Flag{&cli.StringFlag{Name: "workspace"}},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			be := &remote.BackendRemote{Ctx: ctx, Cmd: cmd, Scheme: "http"}
+			be.Backend.Config.Hostname = strings.TrimPrefix(srv.URL, "http://")
+			be.Backend.Config.Organization = "acme"
+			be.Backend.Config.Workspaces.Name = "web"
+
+			var err error
+			rows, err = WorkspaceQueryFetcherFactory(be, fetcher)(ctx, cmd)
+			return err
+		},
+	}
+	require.NoError(t, cmd.Run(context.Background(), []string{"q"}))
+	return rows
+}
+
+func TestWorkspaceQueryFetcherFactory(t *testing.T) {
+	var workspaceIDs []string
+	projects := runWorkspaceQuery(t,
+		func(_ *tfe.Client, workspaceID string) func(context.Context, *tfe.ProjectListOptions) ([]*tfe.Project, *tfe.Pagination, error) {
+			workspaceIDs = append(workspaceIDs, workspaceID)
+			return func(_ context.Context, opts *tfe.ProjectListOptions) ([]*tfe.Project, *tfe.Pagination, error) {
+				assert.Equal(t, DefaultListOptions.PageSize, opts.PageSize)
+				return []*tfe.Project{{ID: "prj-1"}}, &tfe.Pagination{CurrentPage: 1}, nil
+			}
+		},
+	)
+	assert.Equal(t, []string{"ws-abc"}, workspaceIDs)
+	require.Len(t, projects, 1)
+
+	// Anything but a remote or cloud backend is refused by command name.
+	cmd := &cli.Command{Name: "cvq"}
+	_, err := WorkspaceQueryFetcherFactory(&local.BackendLocal{}, cvqFetcher)(context.Background(), cmd)
+	assert.ErrorContains(t, err, "cvq requires a remote or cloud backend")
+}
+
+func TestPrintConfigIfRequested(t *testing.T) {
+	cfg := filepath.Join(t.TempDir(), "tfctl.yaml")
+	require.NoError(t, os.WriteFile(cfg, []byte("theme: mono\norg: global\nmq:\n  org: acme\n  color: always\n"), 0o600))
+	t.Setenv("TFCTL_CFG_FILE", cfg)
+	t.Setenv("TFCTL_HOST", "tfe.example.com")
+	t.Setenv("TFCTL_OFFLINE", "true")
+	_, err := config.Load()
+	require.NoError(t, err)
+	t.Cleanup(func() { config.Config = config.Type{} })
+
+	run := func(args ...string) (map[string]any, bool) {
+		var buf bytes.Buffer
+		var handled bool
+		cmd := &cli.Command{
+			Name: "mq",
+			Flags: append([]cli.Flag{
+				NewHostFlag(meta.Profile{}, "mq", cfg),
+				NewOrgFlag(meta.Profile{}, "mq", cfg),
+				&cli.StringFlag{Name: "passphrase"},
+				NewOfflineFlag(meta.Profile{}),
+				NewPrintConfigFlag(),
+			}, NewGlobalFlags(meta.Profile{}, "mq")...),
+			Action: func(_ context.Context, cmd *cli.Command) error {
+				handled = PrintConfigIfRequested(cmd, &buf)
+				return nil
+			},
+		}
+		require.NoError(t, cmd.Run(context.Background(), append([]string{"mq"}, args...)))
+		if !handled {
+			assert.Empty(t, buf.String())
+			return nil, false
+		}
+		// Values print as typed, not HTML-escaped.
+		assert.NotContains(t, buf.String(), `\u003c`)
+		var got map[string]any
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &got))
+		return got, true
+	}
+
+	_, handled := run()
+	assert.False(t, handled)
+
+	got, handled := run("--print-config", "--output", "json", "--passphrase", "s3cret")
+	require.True(t, handled)
+
+	// Env and namespaced config values win over the defaults, and flags win
+	// over everything.
+	assert.Equal(t, "tfe.example.com", got["host"])
+	assert.Equal(t, "acme", got["org"])
+	assert.Equal(t, "always", got["color"])
+	assert.Equal(t, "mono", got["theme"])
+	assert.Equal(t, true, got["offline"])
+	assert.Equal(t, "json", got["output"])
+	assert.Equal(t, "all", got["fields"])
+	assert.Equal(t, "<redacted>", got["passphrase"])
+	assert.NotContains(t, got, "print-config")
+	assert.NotContains(t, got, "help")
+
+	got, _ = run("--print-config", "--host", "other.example.com", "--org", "flagged")
+	assert.Equal(t, "other.example.com", got["host"])
+	assert.Equal(t, "flagged", got["org"])
+	assert.Equal(t, "", got["passphrase"])
+}
+
+func TestPrintConfigIfRequested_Sources(t *testing.T) {
+	cfg := filepath.Join(t.TempDir(), "tfctl.yaml")
+	require.NoError(t, os.WriteFile(cfg, []byte("theme: mono\norg: global\ncolor: true\nmq:\n  org: acme\n"), 0o600))
+	t.Setenv("TFCTL_CFG_FILE", cfg)
+	t.Setenv("TFCTL_HOST", "tfe.example.com")
+	t.Setenv("TFCTL_OFFLINE", "1")
+	_, err := config.Load()
+	require.NoError(t, err)
+	t.Cleanup(func() { config.Config = config.Type{} })
+
+	run := func(args ...string) map[string]configSource {
+		var buf bytes.Buffer
+		cmd := &cli.Command{
+			Name: "mq",
+			Flags: append([]cli.Flag{
+				NewHostFlag(meta.Profile{}, "mq", cfg),
+				NewOrgFlag(meta.Profile{}, "mq", cfg),
+				&cli.StringFlag{Name: "passphrase"},
+				NewOfflineFlag(meta.Profile{}),
+				NewPrintConfigFlag(),
+				NewPrintSourcesFlag(),
+			}, NewGlobalFlags(meta.Profile{}, "mq")...),
+			Action: func(_ context.Context, cmd *cli.Command) error {
+				require.True(t, PrintConfigIfRequested(cmd, &buf))
+				return nil
+			},
+		}
+		require.NoError(t, cmd.Run(context.Background(), append([]string{"mq", "--print-sources"}, args...)))
+		var got map[string]configSource
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &got))
+		return got
+	}
+
+	got := run("--output", "json", "--passphrase", "s3cret")
+	assert.Equal(t, configSource{Value: "acme", Source: `config key "mq.org"`}, got["org"])
+	assert.Equal(t, configSource{Value: "mono", Source: `config key "theme"`}, got["theme"])
+	assert.Equal(t, configSource{Value: "auto", Source: `config key "color"`}, got["color"])
+	assert.Equal(t, configSource{Value: "tfe.example.com", Source: `environment variable "TFCTL_HOST"`}, got["host"])
+	assert.Equal(t, configSource{Value: true, Source: `environment variable "TFCTL_OFFLINE"`}, got["offline"])
+	assert.Equal(t, configSource{Value: "json", Source: "command line"}, got["output"])
+	assert.Equal(t, configSource{Value: "all", Source: "default"}, got["fields"])
+	assert.Equal(t, configSource{Value: "<redacted>", Source: "command line"}, got["passphrase"])
+	assert.NotContains(t, got, "print-sources")
+
+	// The command line wins over the config, namespaced or not.
+	got = run("--org", "flagged", "--theme", "solarized", "--color=never")
+	assert.Equal(t, configSource{Value: "flagged", Source: "command line"}, got["org"])
+	assert.Equal(t, configSource{Value: "solarized", Source: "command line"}, got["theme"])
+	assert.Equal(t, configSource{Value: "never", Source: "command line"}, got["color"])
+}
+
+func TestMergeAttributes(t *testing.T) {
+	raw := bytes.NewBufferString(`{"data":[` +
+		`{"id":"sv-1","type":"state-versions","attributes":{"serial":9007199254740993,"name":"a&b"}},` +
+		`{"id":"sv-2","type":"state-versions"}]}`)
+
+	require.NoError(t, mergeAttributes(raw, []map[string]any{
+		{"age": int64(60)},
+		{"age": nil},
+	}))
+
+	// Existing attributes round-trip unchanged, including large integers.
+	assert.JSONEq(t, `{"data":[`+
+		`{"id":"sv-1","type":"state-versions","attributes":{"serial":9007199254740993,"name":"a&b","age":60}},`+
+		`{"id":"sv-2","type":"state-versions","attributes":{"age":null}}]}`, raw.String())
+	assert.Contains(t, raw.String(), "9007199254740993")
+	assert.Contains(t, raw.String(), "a&b")
+}
+
+// TestBuildAttrs_View verifies the --view preset is applied over the command
+// defaults and that --attrs extends or overrides it.
+func TestBuildAttrs_View(t *testing.T) {
+	cfg := filepath.Join(t.TempDir(), "tfctl.yaml")
+	require.NoError(t, os.WriteFile(cfg, []byte(
+		"sq:\n  views:\n    security: \"!.mode,type,name,tags.Owner\"\n"), 0o600))
+	t.Setenv("TFCTL_CFG_FILE", cfg)
+	t.Cleanup(func() { config.Config = config.Type{} })
+
+	// included runs sq with args and returns the included output keys.
+	included := func(args ...string) ([]string, error) {
+		var keys []string
+		cmd := &cli.Command{
+			Name:  "sq",
+			Flags: NewGlobalFlags(meta.Profile{}, "sq"),
+			Action: func(_ context.Context, cmd *cli.Command) error {
+				for _, attr := range BuildAttrs(cmd, "id", "name") {
+					if attr.Include {
+						keys = append(keys, attr.OutputKey)
+					}
+				}
+				return nil
+			},
+		}
+		err := cmd.Run(context.Background(), append([]string{"sq"}, args...))
+		return keys, err
+	}
+
+	keys, err := included("--view", "security")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"id", "name", "type", "Owner"}, keys)
+
+	keys, err = included("--view", "security", "--attrs", "!name,.mode")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"id", "type", "Owner", "mode"}, keys)
+
+	_, err = included("--view", "missing")
+	require.ErrorContains(t, err, "no sq.views.missing in config")
+}
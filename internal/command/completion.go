@@ -30,12 +30,12 @@ _tfctl()
     _get_comp_words_by_ref -n : cur prev
 
     if [[ ${COMP_CWORD} -eq 1 ]]; then
-        COMPREPLY=( $(compgen -W "mq oq pq rq si sq svq wq completion --help --version" -- "$cur") )
+        COMPREPLY=( $(compgen -W "apq batch cache config cvq mq ncq ocq oq pq rq rtq si soq sq svq wq completion --help --version" -- "$cur") )
         return 0
     fi
 
     cmd=${COMP_WORDS[1]}
-  local common="--attrs -a --color -c --filter -f --output -o --sort -s --titles -t --tldr"
+  local common="--agg --also-csv --also-json --attrs -a --chdir --color -c --count --fail-on-empty --fields --filter -f --formatter-cmd --group-by --no-pager --out --output -o --print-config --print-sources --profile --sort -s --theme --titles -t --tldr --view"
 
     # Determine if an optional RootDir (first non-flag after subcommand) has
 		# already been provided
@@ -51,32 +51,66 @@ _tfctl()
     done
 
     case "$cmd" in
-    mq)
-      local opts="$common --schema --host -h --org"
+    apq)
+      local opts="$common --schema --deep --partial --host -h --org"
+            ;;
+        cvq)
+      local opts="$common --explain-backend --no-prefixed-workspace-file --env --schema --deep --host -h --org --workspace -w"
+            ;;
+        mq)
+      local opts="$common --schema --deep --partial --host -h --org"
+            ;;
+        ncq)
+      local opts="$common --explain-backend --no-prefixed-workspace-file --env --schema --deep --host -h --org --workspace -w"
+            ;;
+        ocq)
+      local opts="$common --schema --deep --partial --host -h --org"
             ;;
         oq)
-      local opts="$common --schema --host -h"
+      local opts="$common --schema --deep --host -h"
             ;;
         pq)
-      local opts="$common --schema --host -h --org"
+      local opts="$common --schema --deep --partial --host -h --org"
             ;;
         rq)
-      local opts="$common --schema --host -h --org --limit -l --workspace -w"
+      local opts="$common --explain-backend --no-prefixed-workspace-file --env --all-workspaces --schema --deep --partial --host -h --org --limit -l --workspace -w"
+            ;;
+        rtq)
+      local opts="$common --explain-backend --no-prefixed-workspace-file --env --schema --deep --host -h --org --run --workspace -w"
             ;;
         si)
-            local opts="$common --passphrase -p --sv"
+            local opts="$common --explain-backend --no-prefixed-workspace-file --env --offline --browse --decrypt-cmd --passphrase -p --passphrase-file --sv"
+            ;;
+        soq)
+      local opts="$common --offline --raw-path --with-schema --schema --deep --partial --host -h --org"
             ;;
         sq)
-      local opts="$common --chop --concrete -k --diff --diff_filter --host -h --org --passphrase --short --sv --limit --workspace -w"
+      local opts="$common --explain-backend --no-prefixed-workspace-file --env --offline --raw-path --with-schema --all-workspaces --address-sep --at --chop --concrete -k --decrypt-cmd --diff --diff-attrs --diff-format --diff_filter --host -h --org --passphrase --passphrase-file --passphrase-stdin --short --state-file --sv --limit --workspace -w"
             ;;
         svq)
-      local opts="$common --schema --host -h --org --limit -l --workspace -w"
+      local opts="$common --explain-backend --no-prefixed-workspace-file --env --offline --raw-path --with-schema --all-workspaces --compare --deltas --schema --deep --partial --host -h --org --limit -l --workspace -w"
             ;;
         wq)
-      local opts="$common --schema --host -h --org --limit -l"
+      local opts="$common --schema --deep --partial --execution-mode --host -h --org --limit -l --stale"
+            ;;
+        batch)
+            COMPREPLY=( $(compgen -f -- "$cur") )
+            return 0
+            ;;
+        cache)
+            if [[ "$prev" == "purge" ]]; then
+                COMPREPLY=( $(compgen -W "--older-than --dry-run" -- "$cur") )
+            else
+                COMPREPLY=( $(compgen -W "info path purge" -- "$cur") )
+            fi
+            return 0
+            ;;
+        config)
+            COMPREPLY=( $(compgen -W "validate" -- "$cur") )
+            return 0
             ;;
         completion)
-            local opts="bash zsh"
+            local opts="bash fish powershell zsh"
             COMPREPLY=( $(compgen -W "$opts" -- "$cur") )
             return 0
             ;;
@@ -85,8 +119,30 @@ _tfctl()
             ;;
     esac
 
+    # Workspace, org, host, profile and view values come from live data via tfctl itself.
+    if [[ "$prev" == "--workspace" || "$prev" == "-w" || "$prev" == "--org" || "$prev" == "--host" || "$prev" == "--profile" || "$prev" == "--view" ]]; then
+        COMPREPLY=( $(compgen -W "$("${COMP_WORDS[@]:0:COMP_CWORD}" --generate-shell-completion 2>/dev/null)" -- "$cur") )
+        return 0
+    fi
+
     if [[ "$prev" == "--output" || "$prev" == "-o" ]]; then
-        COMPREPLY=( $(compgen -W "text json raw yaml" -- "$cur") )
+        COMPREPLY=( $(compgen -W "text table-wide exec json jsonl prometheus raw sqlite summary yaml" -- "$cur") )
+        return 0
+    fi
+
+    if [[ "$prev" == "--theme" ]]; then
+        COMPREPLY=( $(compgen -W "default highcontrast mono solarized" -- "$cur") )
+        return 0
+    fi
+
+    if [[ "$prev" == "--diff-format" ]]; then
+        COMPREPLY=( $(compgen -W "text unified json" -- "$cur") )
+        return 0
+    fi
+
+    # --color takes its mode inline, e.g. --color=auto.
+    if [[ "$prev" == "=" && "${COMP_WORDS[COMP_CWORD-2]}" == "--color" ]]; then
+        COMPREPLY=( $(compgen -W "auto always never" -- "$cur") )
         return 0
     fi
 
@@ -106,29 +162,61 @@ complete -F _tfctl tfctl
 
 const zshCompletionScript = `#compdef tfctl
 
+# Workspace, org, host, profile and view values come from live data via tfctl itself.
+_tfctl_live() {
+  local -a vals
+  vals=(${(f)"$(${words[1,CURRENT-1]} --generate-shell-completion 2>/dev/null)"})
+  compadd -a vals
+}
+
 _tfctl() {
   local -a cmds
   cmds=(
+    'apq:agent pool query'
+    'cvq:configuration version query'
     'mq:module registry query'
+    'ncq:notification configuration query'
+    'ocq:oauth client query'
     'oq:organization query'
     'pq:project query'
     'rq:run query'
+    'rtq:run task result query'
     'si:interactive state inspector'
+    'soq:state output query across the organization'
     'sq:state query'
     'svq:state version query'
     'wq:workspace query'
+    'batch:run the tfctl commands in a file'
+    'cache:inspect and manage the tfctl cache'
+    'config:inspect the tfctl config file'
     'completion:generate shell completion script'
   )
 
   local -a common
   common=(
+  '--agg[aggregate for grouped rows]:agg'
+  '--also-csv[also write results as CSV]:file:_files'
+  '--also-json[also write results as JSON]:file:_files'
   '(-a --attrs)'{-a,--attrs}'[attributes to include]:attrs'
-  '(-c --color)'{-c,--color}'[enable colored text]'
+  '--chdir[switch to directory before resolving RootDir]:directory:_directories'
+  '(-c --color)'{-c,--color=-}'[colored output]::mode:(auto always never)'
+  '--count[only print the number of matching rows]'
+  '--fail-on-empty[exit with status 3 when no rows match]'
+  '--fields[row fields to extract]:fields:(all none)'
   '(-f --filter)'{-f,--filter}'[filters to apply]:filters'
-  '(-o --output)'{-o,--output}'[output format]:format:(text json raw yaml)'
+  '--group-by[count rows per attribute value]:attr'
+  '--no-pager[do not page long text output]'
+  '--formatter-cmd[program --output exec pipes json results through]:command'
+  '--out[file to write the output to, gzipped if it ends in .gz]:file:_files'
+  '(-o --output)'{-o,--output}'[output format]:format:(text table-wide exec json jsonl prometheus raw sqlite summary yaml)'
+  '--print-config[print resolved flag values as json]'
+  '--print-sources[print resolved flag values and their sources as json]'
+  '--profile[named bundle of flag defaults]:profile:_tfctl_live'
   '(-s --sort)'{-s,--sort}'[sort attributes]:attrs'
+  '--theme[table color theme]:theme:(default highcontrast mono solarized)'
   '(-t --titles)'{-t,--titles}'[show titles]'
   '--tldr[show tldr page]'
+  '--view[named attrs preset]:view:_tfctl_live'
   )
 
   if (( CURRENT == 2 )); then
@@ -138,80 +226,210 @@ _tfctl() {
 
   local curcontext="$curcontext" state line
   case $words[2] in
+    apq)
+      _arguments -C \
+        $common \
+        '--schema[dump schema]' \
+        '--deep[with --schema, include nested attributes and relationships]' \
+        '--partial[emit successful rows when some sources fail]' \
+        '(-h --host)'{-h,--host}'[host]:host:_tfctl_live' \
+        '--org[organization]:org:_tfctl_live' \
+        '::RootDir:_directories'
+      ;;
     mq)
       _arguments -C \
         $common \
         '--schema[dump schema]' \
-        '(-h --host)'{-h,--host}'[host]' \
-        '--org[organization]' \
+        '--deep[with --schema, include nested attributes and relationships]' \
+        '--partial[emit successful rows when some sources fail]' \
+        '(-h --host)'{-h,--host}'[host]:host:_tfctl_live' \
+        '--org[organization]:org:_tfctl_live' \
+        '::RootDir:_directories'
+      ;;
+    cvq)
+      _arguments -C \
+        $common \
+        '--explain-backend[trace backend detection decisions]' \
+        '--no-prefixed-workspace-file[ignore the workspace in the environment file]' \
+        '--env[workspace env to use instead of the environment file]:env' \
+        '--schema[dump schema]' \
+        '--deep[with --schema, include nested attributes and relationships]' \
+        '(-h --host)'{-h,--host}'[host]:host:_tfctl_live' \
+        '--org[organization]:org:_tfctl_live' \
+        '(-w --workspace)'{-w,--workspace}'[workspace]:workspace:_tfctl_live' \
+        '::RootDir:_directories'
+      ;;
+    ncq)
+      _arguments -C \
+        $common \
+        '--explain-backend[trace backend detection decisions]' \
+        '--no-prefixed-workspace-file[ignore the workspace in the environment file]' \
+        '--env[workspace env to use instead of the environment file]:env' \
+        '--schema[dump schema]' \
+        '--deep[with --schema, include nested attributes and relationships]' \
+        '(-h --host)'{-h,--host}'[host]:host:_tfctl_live' \
+        '--org[organization]:org:_tfctl_live' \
+        '(-w --workspace)'{-w,--workspace}'[workspace]:workspace:_tfctl_live' \
+        '::RootDir:_directories'
+      ;;
+    ocq)
+      _arguments -C \
+        $common \
+        '--schema[dump schema]' \
+        '--deep[with --schema, include nested attributes and relationships]' \
+        '--partial[emit successful rows when some sources fail]' \
+        '(-h --host)'{-h,--host}'[host]:host:_tfctl_live' \
+        '--org[organization]:org:_tfctl_live' \
         '::RootDir:_directories'
       ;;
     oq)
       _arguments -C \
         $common \
         '--schema[dump schema]' \
-        '(-h --host)'{-h,--host}'[host]' \
+        '--deep[with --schema, include nested attributes and relationships]' \
+        '(-h --host)'{-h,--host}'[host]:host:_tfctl_live' \
         '::RootDir:_directories'
       ;;
     pq)
       _arguments -C \
         $common \
         '--schema[dump schema]' \
-        '(-h --host)'{-h,--host}'[host]' \
-        '--org[organization]' \
+        '--deep[with --schema, include nested attributes and relationships]' \
+        '--partial[emit successful rows when some sources fail]' \
+        '(-h --host)'{-h,--host}'[host]:host:_tfctl_live' \
+        '--org[organization]:org:_tfctl_live' \
         '::RootDir:_directories'
       ;;
     rq)
       _arguments -C \
         $common \
+        '--explain-backend[trace backend detection decisions]' \
+        '--no-prefixed-workspace-file[ignore the workspace in the environment file]' \
+        '--env[workspace env to use instead of the environment file]:env' \
+        '--all-workspaces[query every workspace the backend selects]' \
         '--schema[dump schema]' \
+        '--deep[with --schema, include nested attributes and relationships]' \
+        '--partial[emit successful rows when some sources fail]' \
         '--limit[-l][limit results]':limit \
-        '(-h --host)'{-h,--host}'[host]' \
-        '--org[organization]' \
+        '(-h --host)'{-h,--host}'[host]:host:_tfctl_live' \
+        '--org[organization]:org:_tfctl_live' \
+        '::RootDir:_directories'
+      ;;
+    rtq)
+      _arguments -C \
+        $common \
+        '--explain-backend[trace backend detection decisions]' \
+        '--no-prefixed-workspace-file[ignore the workspace in the environment file]' \
+        '--env[workspace env to use instead of the environment file]:env' \
+        '--schema[dump schema]' \
+        '--deep[with --schema, include nested attributes and relationships]' \
+        '(-h --host)'{-h,--host}'[host]:host:_tfctl_live' \
+        '--org[organization]:org:_tfctl_live' \
+        '--run[run ID]:run' \
+        '(-w --workspace)'{-w,--workspace}'[workspace]:workspace:_tfctl_live' \
         '::RootDir:_directories'
       ;;
     si)
       _arguments -C \
+        '--explain-backend[trace backend detection decisions]' \
+        '--no-prefixed-workspace-file[ignore the workspace in the environment file]' \
+        '--env[workspace env to use instead of the environment file]:env' \
+        '--offline[serve from the cache only]' \
+        '--browse[browse resources in a filterable list]' \
+        '--decrypt-cmd[program to decrypt raw state]:command' \
         '(-p --passphrase)'{-p,--passphrase}'[state passphrase]' \
+        '--passphrase-file[read the encrypted state passphrase from a file]:file:_files' \
         '--sv[state version]' \
         '::RootDir:_directories'
       ;;
+    soq)
+      _arguments -C \
+        $common \
+        '--offline[serve from the cache only]' \
+        '--raw-path[gjson path --output raw prints]:path' \
+        '--with-schema[precede jsonl output with a schema line]' \
+        '--schema[dump schema]' \
+        '--deep[with --schema, include nested attributes and relationships]' \
+        '--partial[emit successful rows when some sources fail]' \
+        '(-h --host)'{-h,--host}'[host]:host:_tfctl_live' \
+        '--org[organization]:org:_tfctl_live' \
+        '::RootDir:_directories'
+      ;;
     sq)
       _arguments -C \
         $common \
+        '--explain-backend[trace backend detection decisions]' \
+        '--no-prefixed-workspace-file[ignore the workspace in the environment file]' \
+        '--env[workspace env to use instead of the environment file]:env' \
+        '--offline[serve from the cache only]' \
+        '--raw-path[gjson path --output raw prints]:path' \
+        '--with-schema[precede jsonl output with a schema line]' \
+        '--all-workspaces[query every workspace the backend selects]' \
+        '--address-sep[separator joining resource address components]:separator' \
+        '--at[query the state version active at an RFC3339 time]:time' \
         '--chop[chop common resource prefix from names]' \
         '--concrete[only include concrete resources]' \
+        '--decrypt-cmd[program to decrypt raw state]:command' \
         '--diff[find difference between state versions]' \
+        '--diff-attrs[resource attributes to compare]:attrs' \
+        '--diff-format[diff rendering]:format:(text unified json)' \
         '--diff_filter[filter for diff results]' \
-        '--host[host to use for queries]' \
+        '--host[host to use for queries]:host:_tfctl_live' \
         '--limit[limit state versions returned]' \
         '(-p --passphrase)'{-p,--passphrase}'[encrypted state passphrase]' \
+        '--passphrase-file[read the encrypted state passphrase from a file]:file:_files' \
+        '--passphrase-stdin[read the encrypted state passphrase from stdin]' \
         '--short[include full resource name paths]' \
+        '--state-file[local state file to query, - for stdin]:file:_files' \
         '--sv[state version to query]' \
-        '(-w --workspace)'{-w,--workspace}'[workspace]' \
+        '(-w --workspace)'{-w,--workspace}'[workspace]:workspace:_tfctl_live' \
         '::RootDir:_directories'
       ;;
     svq)
       _arguments -C \
         $common \
+        '--explain-backend[trace backend detection decisions]' \
+        '--no-prefixed-workspace-file[ignore the workspace in the environment file]' \
+        '--env[workspace env to use instead of the environment file]:env' \
+        '--offline[serve from the cache only]' \
+        '--raw-path[gjson path --output raw prints]:path' \
+        '--with-schema[precede jsonl output with a schema line]' \
+        '--all-workspaces[query every workspace the backend selects]' \
+        '--compare[summarize the latest N state versions]:count' \
+        '--deltas[show the change in resource count between versions]' \
         '--schema[dump schema]' \
+        '--deep[with --schema, include nested attributes and relationships]' \
+        '--partial[emit successful rows when some sources fail]' \
         '--limit[-l][limit results]':limit \
-        '(-h --host)'{-h,--host}'[host]' \
-        '--org[organization]' \
-        '(-w --workspace)'{-w,--workspace}'[workspace]' \
+        '(-h --host)'{-h,--host}'[host]:host:_tfctl_live' \
+        '--org[organization]:org:_tfctl_live' \
+        '(-w --workspace)'{-w,--workspace}'[workspace]:workspace:_tfctl_live' \
         '::RootDir:_directories'
       ;;
     wq)
       _arguments -C \
         $common \
         '--schema[dump schema]' \
+        '--deep[with --schema, include nested attributes and relationships]' \
+        '--partial[emit successful rows when some sources fail]' \
         '--limit[-l][limit results]':limit \
-        '(-h --host)'{-h,--host}'[host]' \
-        '--org[organization]' \
+        '--stale[only workspaces whose current run is older than this age]:age' \
+        '--execution-mode[only workspaces with this execution mode]:mode:(agent local remote)' \
+        '(-h --host)'{-h,--host}'[host]:host:_tfctl_live' \
+        '--org[organization]:org:_tfctl_live' \
         '::RootDir:_directories'
       ;;
+    batch)
+      _arguments '1:file:_files'
+      ;;
+    cache)
+      _arguments '1: :(info path purge)' '--older-than[only remove files older than N hours]:hours' '--dry-run[list files that would be removed]'
+      ;;
+    config)
+      _arguments '1: :(validate)'
+      ;;
     completion)
-      _arguments '1: :((bash zsh))'
+      _arguments '1: :((bash fish powershell zsh))'
       ;;
     *)
       _arguments -C $common '*:directory:_directories'
@@ -227,6 +445,198 @@ fi
 compdef _tfctl tfctl tfctl
 `
 
+const fishCompletionScript = `# fish completion for tfctl
+set -l tfctl_commands apq batch cache config cvq mq ncq ocq oq pq rq rtq si soq sq svq wq completion
+set -l tfctl_queries apq cvq mq ncq ocq oq pq rq rtq si soq sq svq wq
+
+complete -c tfctl -f
+
+# Workspace, org, host, profile and view values come from live data via tfctl itself.
+function __tfctl_live
+    set -l tokens (commandline -opc)
+    $tokens --generate-shell-completion 2>/dev/null
+end
+complete -c tfctl -n "not __fish_seen_subcommand_from $tfctl_commands" -l help -d 'show help'
+complete -c tfctl -n "not __fish_seen_subcommand_from $tfctl_commands" -s v -l version -d 'print version'
+
+# Subcommands
+complete -c tfctl -n "not __fish_seen_subcommand_from $tfctl_commands" -a apq -d 'agent pool query'
+complete -c tfctl -n "not __fish_seen_subcommand_from $tfctl_commands" -a batch -d 'run the tfctl commands in a file'
+complete -c tfctl -n "not __fish_seen_subcommand_from $tfctl_commands" -a cache -d 'inspect and manage the tfctl cache'
+complete -c tfctl -n "not __fish_seen_subcommand_from $tfctl_commands" -a config -d 'inspect the tfctl config file'
+complete -c tfctl -n "not __fish_seen_subcommand_from $tfctl_commands" -a cvq -d 'configuration version query'
+complete -c tfctl -n "not __fish_seen_subcommand_from $tfctl_commands" -a mq -d 'module registry query'
+complete -c tfctl -n "not __fish_seen_subcommand_from $tfctl_commands" -a ncq -d 'notification configuration query'
+complete -c tfctl -n "not __fish_seen_subcommand_from $tfctl_commands" -a ocq -d 'oauth client query'
+complete -c tfctl -n "not __fish_seen_subcommand_from $tfctl_commands" -a oq -d 'organization query'
+complete -c tfctl -n "not __fish_seen_subcommand_from $tfctl_commands" -a pq -d 'project query'
+complete -c tfctl -n "not __fish_seen_subcommand_from $tfctl_commands" -a rq -d 'run query'
+complete -c tfctl -n "not __fish_seen_subcommand_from $tfctl_commands" -a rtq -d 'run task result query'
+complete -c tfctl -n "not __fish_seen_subcommand_from $tfctl_commands" -a si -d 'interactive state inspector'
+complete -c tfctl -n "not __fish_seen_subcommand_from $tfctl_commands" -a soq -d 'state output query across the organization'
+complete -c tfctl -n "not __fish_seen_subcommand_from $tfctl_commands" -a sq -d 'state query'
+complete -c tfctl -n "not __fish_seen_subcommand_from $tfctl_commands" -a svq -d 'state version query'
+complete -c tfctl -n "not __fish_seen_subcommand_from $tfctl_commands" -a wq -d 'workspace query'
+complete -c tfctl -n "not __fish_seen_subcommand_from $tfctl_commands" -a completion -d 'generate shell completion script'
+
+# Optional RootDir positional for the query commands
+complete -c tfctl -n "__fish_seen_subcommand_from $tfctl_queries" -a '(__fish_complete_directories)'
+
+# Common flags
+complete -c tfctl -n "__fish_seen_subcommand_from $tfctl_queries" -l agg -r -d 'aggregate for grouped rows'
+complete -c tfctl -n "__fish_seen_subcommand_from $tfctl_queries" -l also-csv -r -F -d 'also write results as CSV'
+complete -c tfctl -n "__fish_seen_subcommand_from $tfctl_queries" -l also-json -r -F -d 'also write results as JSON'
+complete -c tfctl -n "__fish_seen_subcommand_from $tfctl_queries" -s a -l attrs -r -d 'attributes to include'
+complete -c tfctl -n "__fish_seen_subcommand_from $tfctl_queries" -l chdir -r -a '(__fish_complete_directories)' -d 'switch to directory before resolving RootDir'
+complete -c tfctl -n "__fish_seen_subcommand_from $tfctl_queries" -s c -l color -a 'auto always never' -d 'colored output'
+complete -c tfctl -n "__fish_seen_subcommand_from $tfctl_queries" -l count -d 'only print the number of matching rows'
+complete -c tfctl -n "__fish_seen_subcommand_from $tfctl_queries" -l fail-on-empty -d 'exit with status 3 when no rows match'
+complete -c tfctl -n "__fish_seen_subcommand_from $tfctl_queries" -l fields -x -a 'all none' -d 'row fields to extract'
+complete -c tfctl -n "__fish_seen_subcommand_from $tfctl_queries" -s f -l filter -r -d 'filters to apply'
+complete -c tfctl -n "__fish_seen_subcommand_from $tfctl_queries" -l group-by -r -d 'count rows per attribute value'
+complete -c tfctl -n "__fish_seen_subcommand_from $tfctl_queries" -l no-pager -d 'do not page long text output'
+complete -c tfctl -n "__fish_seen_subcommand_from $tfctl_queries" -l formatter-cmd -r -d 'program --output exec pipes json results through'
+complete -c tfctl -n "__fish_seen_subcommand_from $tfctl_queries" -l out -r -F -d 'file to write the output to, gzipped if it ends in .gz'
+complete -c tfctl -n "__fish_seen_subcommand_from $tfctl_queries" -s o -l output -x -a 'text table-wide exec json jsonl prometheus raw sqlite summary yaml' -d 'output format'
+complete -c tfctl -n "__fish_seen_subcommand_from $tfctl_queries" -s s -l sort -r -d 'sort attributes'
+complete -c tfctl -n "__fish_seen_subcommand_from $tfctl_queries" -l theme -x -a 'default highcontrast mono solarized' -d 'table color theme'
+complete -c tfctl -n "__fish_seen_subcommand_from $tfctl_queries" -s t -l titles -d 'show titles'
+complete -c tfctl -n "__fish_seen_subcommand_from $tfctl_queries" -l print-config -d 'print resolved flag values as json'
+complete -c tfctl -n "__fish_seen_subcommand_from $tfctl_queries" -l print-sources -d 'print resolved flag values and their sources as json'
+complete -c tfctl -n "__fish_seen_subcommand_from $tfctl_queries" -l profile -x -a '(__tfctl_live)' -d 'named bundle of flag defaults'
+complete -c tfctl -n "__fish_seen_subcommand_from $tfctl_queries" -l tldr -d 'show tldr page'
+complete -c tfctl -n "__fish_seen_subcommand_from $tfctl_queries" -l view -x -a '(__tfctl_live)' -d 'named attrs preset'
+
+# Command-specific flags
+complete -c tfctl -n "__fish_seen_subcommand_from apq cvq mq ncq ocq oq pq rq rtq soq svq wq" -l schema -d 'dump schema'
+complete -c tfctl -n "__fish_seen_subcommand_from apq cvq mq ncq ocq oq pq rq rtq soq svq wq" -l deep -d 'with --schema, include nested attributes and relationships'
+complete -c tfctl -n "__fish_seen_subcommand_from apq mq ocq pq rq soq svq wq" -l partial -d 'emit successful rows when some sources fail'
+complete -c tfctl -n "__fish_seen_subcommand_from apq cvq mq ncq ocq oq pq rq rtq soq sq svq wq" -s h -l host -x -a '(__tfctl_live)' -d 'host'
+complete -c tfctl -n "__fish_seen_subcommand_from apq cvq mq ncq ocq pq rq rtq soq sq svq wq" -l org -x -a '(__tfctl_live)' -d 'organization'
+complete -c tfctl -n "__fish_seen_subcommand_from cvq ncq rq rtq sq svq" -s w -l workspace -x -a '(__tfctl_live)' -d 'workspace'
+complete -c tfctl -n "__fish_seen_subcommand_from rq svq wq" -s l -l limit -r -d 'limit results'
+complete -c tfctl -n "__fish_seen_subcommand_from wq" -l stale -r -d 'only workspaces whose current run is older than this age'
+complete -c tfctl -n "__fish_seen_subcommand_from wq" -l execution-mode -x -a 'agent local remote' -d 'only workspaces with this execution mode'
+complete -c tfctl -n "__fish_seen_subcommand_from sq" -l limit -r -d 'limit results'
+complete -c tfctl -n "__fish_seen_subcommand_from rtq" -l run -r -d 'run ID'
+complete -c tfctl -n "__fish_seen_subcommand_from svq" -l compare -r -d 'summarize the latest N state versions'
+complete -c tfctl -n "__fish_seen_subcommand_from svq" -l deltas -d 'show the change in resource count between versions'
+complete -c tfctl -n "__fish_seen_subcommand_from si" -l browse -d 'browse resources in a filterable list'
+complete -c tfctl -n "__fish_seen_subcommand_from cvq ncq rq rtq si sq svq" -l explain-backend -d 'trace backend detection decisions'
+complete -c tfctl -n "__fish_seen_subcommand_from cvq ncq rq rtq si sq svq" -l no-prefixed-workspace-file -d 'ignore the workspace in the environment file'
+complete -c tfctl -n "__fish_seen_subcommand_from cvq ncq rq rtq si sq svq" -l env -r -d 'workspace env to use instead of the environment file'
+complete -c tfctl -n "__fish_seen_subcommand_from rq sq svq" -l all-workspaces -d 'query every workspace the backend selects'
+complete -c tfctl -n "__fish_seen_subcommand_from si soq sq svq" -l offline -d 'serve from the cache only'
+complete -c tfctl -n "__fish_seen_subcommand_from soq sq svq" -l raw-path -x -d 'gjson path --output raw prints'
+complete -c tfctl -n "__fish_seen_subcommand_from soq sq svq" -l with-schema -d 'precede jsonl output with a schema line'
+complete -c tfctl -n "__fish_seen_subcommand_from si sq" -l decrypt-cmd -r -d 'program to decrypt raw state'
+complete -c tfctl -n "__fish_seen_subcommand_from si" -s p -l passphrase -r -d 'state passphrase'
+complete -c tfctl -n "__fish_seen_subcommand_from sq" -l passphrase -r -d 'state passphrase'
+complete -c tfctl -n "__fish_seen_subcommand_from si sq" -l passphrase-file -r -F -d 'read the state passphrase from a file'
+complete -c tfctl -n "__fish_seen_subcommand_from sq" -l passphrase-stdin -d 'read the state passphrase from stdin'
+complete -c tfctl -n "__fish_seen_subcommand_from sq" -l state-file -r -F -d 'local state file to query, - for stdin'
+complete -c tfctl -n "__fish_seen_subcommand_from si sq" -l sv -r -d 'state version'
+complete -c tfctl -n "__fish_seen_subcommand_from sq" -l chop -d 'chop common resource prefix'
+complete -c tfctl -n "__fish_seen_subcommand_from sq" -s k -l concrete -d 'only managed resources'
+complete -c tfctl -n "__fish_seen_subcommand_from sq" -l diff -d 'diff state versions'
+complete -c tfctl -n "__fish_seen_subcommand_from sq" -l diff-attrs -r -d 'resource attributes to compare'
+complete -c tfctl -n "__fish_seen_subcommand_from sq" -l diff-format -x -a 'text unified json' -d 'diff rendering'
+complete -c tfctl -n "__fish_seen_subcommand_from sq" -l diff_filter -r -d 'diff filter'
+complete -c tfctl -n "__fish_seen_subcommand_from sq" -l short -d 'short resource names'
+complete -c tfctl -n "__fish_seen_subcommand_from sq" -l address-sep -r -d 'separator joining resource address components'
+complete -c tfctl -n "__fish_seen_subcommand_from sq" -l at -r -d 'query the state version active at an RFC3339 time'
+
+# The batch file, and subcommands of cache, config and completion
+complete -c tfctl -n "__fish_seen_subcommand_from batch" -F
+complete -c tfctl -n "__fish_seen_subcommand_from cache; and not __fish_seen_subcommand_from info path purge" -a 'info path purge'
+complete -c tfctl -n "__fish_seen_subcommand_from purge" -l older-than -r -d 'only remove files older than N hours'
+complete -c tfctl -n "__fish_seen_subcommand_from purge" -l dry-run -d 'list files that would be removed'
+complete -c tfctl -n "__fish_seen_subcommand_from config; and not __fish_seen_subcommand_from validate" -a validate
+complete -c tfctl -n "__fish_seen_subcommand_from completion; and not __fish_seen_subcommand_from bash fish powershell zsh" -a 'bash fish powershell zsh'
+`
+
+const powershellCompletionScript = `# powershell completion for tfctl
+Register-ArgumentCompleter -Native -CommandName tfctl -ScriptBlock {
+    param($wordToComplete, $commandAst, $cursorPosition)
+
+    $commands = @('apq', 'batch', 'cache', 'config', 'cvq', 'mq', 'ncq', 'ocq', 'oq', 'pq', 'rq', 'rtq', 'si', 'soq', 'sq', 'svq', 'wq', 'completion')
+    $common = @('--agg', '--also-csv', '--also-json', '--attrs', '-a', '--chdir', '--color', '--color=always', '--color=never', '-c', '--count', '--fail-on-empty', '--fields', '--filter', '-f',
+        '--formatter-cmd', '--group-by', '--no-pager', '--out', '--output', '-o', '--print-config', '--print-sources', '--profile', '--sort', '-s', '--theme', '--titles', '-t', '--tldr', '--view')
+    $opts = @{
+        'apq'        = @('--schema', '--deep', '--partial', '--host', '-h', '--org')
+        'cvq'        = @('--explain-backend', '--no-prefixed-workspace-file', '--env', '--schema', '--deep', '--host', '-h', '--org', '--workspace', '-w')
+        'mq'         = @('--schema', '--deep', '--partial', '--host', '-h', '--org')
+        'ncq'        = @('--explain-backend', '--no-prefixed-workspace-file', '--env', '--schema', '--deep', '--host', '-h', '--org', '--workspace', '-w')
+        'ocq'        = @('--schema', '--deep', '--partial', '--host', '-h', '--org')
+        'oq'         = @('--schema', '--deep', '--host', '-h')
+        'pq'         = @('--schema', '--deep', '--partial', '--host', '-h', '--org')
+        'rq'         = @('--explain-backend', '--no-prefixed-workspace-file', '--env', '--all-workspaces', '--schema', '--deep', '--partial', '--host', '-h', '--org', '--limit', '-l', '--workspace', '-w')
+        'rtq'        = @('--explain-backend', '--no-prefixed-workspace-file', '--env', '--schema', '--deep', '--host', '-h', '--org', '--run', '--workspace', '-w')
+        'si'         = @('--explain-backend', '--no-prefixed-workspace-file', '--env', '--offline', '--browse', '--decrypt-cmd', '--passphrase', '-p', '--passphrase-file', '--sv')
+        'soq'        = @('--offline', '--raw-path', '--with-schema', '--schema', '--deep', '--partial', '--host', '-h', '--org')
+        'sq'         = @('--explain-backend', '--no-prefixed-workspace-file', '--env', '--offline', '--raw-path', '--with-schema', '--all-workspaces', '--address-sep', '--at', '--chop', '--concrete', '-k', '--decrypt-cmd', '--diff', '--diff-attrs', '--diff-format', '--diff_filter', '--host', '-h',
+            '--org', '--passphrase', '--passphrase-file', '--passphrase-stdin', '--short', '--state-file', '--sv', '--limit', '--workspace', '-w')
+        'svq'        = @('--explain-backend', '--no-prefixed-workspace-file', '--env', '--offline', '--raw-path', '--with-schema', '--all-workspaces', '--compare', '--deltas', '--schema', '--deep', '--partial', '--host', '-h', '--org', '--limit', '-l', '--workspace', '-w')
+        'wq'         = @('--schema', '--deep', '--partial', '--execution-mode', '--host', '-h', '--org', '--limit', '-l', '--stale')
+    }
+    $subs = @{
+        'cache'      = @('info', 'path', 'purge')
+        'config'     = @('validate')
+        'completion' = @('bash', 'fish', 'powershell', 'zsh')
+    }
+    $outputs = @('text', 'table-wide', 'exec', 'json', 'jsonl', 'prometheus', 'raw', 'sqlite', 'summary', 'yaml')
+    $themes = @('default', 'highcontrast', 'mono', 'solarized')
+
+    # Words typed so far, excluding the one being completed.
+    $words = @($commandAst.CommandElements | Where-Object { $_.Extent.EndOffset -lt $cursorPosition } |
+        ForEach-Object { $_.ToString() })
+    $prev = if ($words.Count -gt 0) { $words[-1] } else { '' }
+
+    $candidates = @()
+    if ($words.Count -le 1) {
+        $candidates = $commands + @('--help', '--version')
+    } elseif ($prev -eq '--output' -or $prev -eq '-o') {
+        $candidates = $outputs
+    } elseif ($prev -eq '--theme') {
+        $candidates = $themes
+    } elseif ($prev -eq '--diff-format') {
+        $candidates = @('text', 'unified', 'json')
+    } elseif (@('--workspace', '-w', '--org', '--host', '--profile', '--view') -contains $prev) {
+        # Workspace, org, host, profile and view values come from live data via tfctl itself.
+        $candidates = @(& $words[0] @($words | Select-Object -Skip 1) '--generate-shell-completion' 2>$null)
+    } else {
+        $cmd = $words[1]
+        if ($cmd -eq 'batch') {
+            # The batch file.
+            Get-ChildItem -Path "$wordToComplete*" -ErrorAction SilentlyContinue | ForEach-Object {
+                [System.Management.Automation.CompletionResult]::new($_.Name, $_.Name, 'ProviderItem', $_.Name)
+            }
+            return
+        } elseif ($subs.ContainsKey($cmd)) {
+            if ($words.Count -eq 2) {
+                $candidates = $subs[$cmd]
+            } elseif ($cmd -eq 'cache' -and $words[2] -eq 'purge') {
+                $candidates = @('--older-than', '--dry-run')
+            }
+        } elseif ($opts.ContainsKey($cmd)) {
+            if ($wordToComplete -like '-*') {
+                $candidates = $common + $opts[$cmd]
+            } else {
+                # The optional RootDir positional is a directory.
+                Get-ChildItem -Directory -Path "$wordToComplete*" -ErrorAction SilentlyContinue | ForEach-Object {
+                    [System.Management.Automation.CompletionResult]::new($_.Name, $_.Name, 'ProviderContainer', $_.Name)
+                }
+                return
+            }
+        }
+    }
+
+    $candidates | Where-Object { $_ -like "$wordToComplete*" } | ForEach-Object {
+        [System.Management.Automation.CompletionResult]::new($_, $_, 'ParameterValue', $_)
+    }
+}
+`
+
 func completionCommandAction(ctx context.Context, cmd *cli.Command) error {
 	shell := ""
 	if args := cmd.Args().Slice(); len(args) > 0 {
@@ -237,16 +647,25 @@ func completionCommandAction(ctx context.Context, cmd *cli.Command) error {
 		fmt.Fprint(os.Stdout, bashCompletionScript)
 	case "zsh":
 		fmt.Fprint(os.Stdout, zshCompletionScript)
+	case "fish":
+		fmt.Fprint(os.Stdout, fishCompletionScript)
+	case "powershell":
+		fmt.Fprint(os.Stdout, powershellCompletionScript)
 	default:
-		// Try to detect from SHELL or print help
+		// Try to detect from SHELL or print help. PSModulePath is checked first
+		// since pwsh on Unix keeps the login SHELL in the environment.
 		sh := os.Getenv("SHELL")
 		switch {
+		case os.Getenv("PSModulePath") != "":
+			fmt.Fprint(os.Stdout, powershellCompletionScript)
 		case strings.HasSuffix(sh, "zsh"):
 			fmt.Fprint(os.Stdout, zshCompletionScript)
 		case strings.HasSuffix(sh, "bash"):
 			fmt.Fprint(os.Stdout, bashCompletionScript)
+		case strings.HasSuffix(sh, "fish"):
+			fmt.Fprint(os.Stdout, fishCompletionScript)
 		default:
-			fmt.Fprintln(os.Stderr, "usage: tfctl completion [bash|zsh]")
+			fmt.Fprintln(os.Stderr, "usage: tfctl completion [bash|fish|powershell|zsh]")
 			return nil
 		}
 	}
@@ -257,7 +676,7 @@ func completionCommandBuilder(meta meta.Meta) *cli.Command {
 	return &cli.Command{
 		Name:      "completion",
 		Usage:     "generate shell completion script",
-		UsageText: "tfctl completion [bash|zsh]",
+		UsageText: "tfctl completion [bash|fish|powershell|zsh]",
 		Metadata: map[string]any{
 			"meta": meta,
 		},
@@ -0,0 +1,68 @@
+// Copyright (c) 2026 Steve Taranto <staranto@gmail.com>.
+// SPDX-License-Identifier: Apache-2.0
+
+package command
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/staranto/tfctl/internal/config"
+	"github.com/staranto/tfctl/internal/meta"
+)
+
+// configValidateCommandAction is the action handler for "config validate". It
+// loads the config file, reports unknown or mistyped keys and returns an error
+// if any were found.
+func configValidateCommandAction(_ context.Context, cmd *cli.Command) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	var commands []string
+	for _, c := range cmd.Root().Commands {
+		commands = append(commands, c.Name)
+	}
+
+	return reportConfigIssues(cmd.Root().Writer, cfg.Source, config.Validate(cfg.Data, commands))
+}
+
+// reportConfigIssues writes one line per issue to w, or an ok line when there
+// are none, and returns an error summarizing the issue count.
+func reportConfigIssues(w io.Writer, source string, issues []config.Issue) error {
+	if len(issues) == 0 {
+		fmt.Fprintf(w, "%s: ok\n", source)
+		return nil
+	}
+
+	for _, issue := range issues {
+		fmt.Fprintf(w, "%s: %s\n", source, issue)
+	}
+
+	return fmt.Errorf("%d config problem(s) found in %s", len(issues), source)
+}
+
+// configCommandBuilder constructs the cli.Command for "config" and its
+// "validate" subcommand.
+func configCommandBuilder(meta meta.Meta) *cli.Command {
+	return &cli.Command{
+		Name:      "config",
+		Usage:     "inspect the tfctl config file",
+		UsageText: "tfctl config validate",
+		Metadata: map[string]any{
+			"meta": meta,
+		},
+		Commands: []*cli.Command{
+			{
+				Name:      "validate",
+				Usage:     "report unknown or mistyped config keys",
+				UsageText: "tfctl config validate",
+				Action:    configValidateCommandAction,
+			},
+		},
+	}
+}
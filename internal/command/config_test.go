@@ -0,0 +1,31 @@
+// Copyright (c) 2026 Steve Taranto <staranto@gmail.com>.
+// SPDX-License-Identifier: Apache-2.0
+// no-cloc
+
+package command
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/staranto/tfctl/internal/config"
+)
+
+func TestReportConfigIssues(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, reportConfigIssues(&buf, "cfg.yaml", nil))
+	assert.Equal(t, "cfg.yaml: ok\n", buf.String())
+
+	buf.Reset()
+	issues := []config.Issue{
+		{Key: "orgg", Problem: "unknown key"},
+		{Key: "padding", Problem: `expected int, got string "2"`},
+	}
+	err := reportConfigIssues(&buf, "cfg.yaml", issues)
+	require.Error(t, err)
+	assert.Equal(t, "2 config problem(s) found in cfg.yaml", err.Error())
+	assert.Equal(t, "cfg.yaml: orgg: unknown key\ncfg.yaml: padding: expected int, got string \"2\"\n", buf.String())
+}
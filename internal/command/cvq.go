@@ -0,0 +1,78 @@
+// Copyright (c) 2026 Steve Taranto <staranto@gmail.com>.
+// SPDX-License-Identifier: Apache-2.0
+
+package command
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/hashicorp/go-tfe"
+	"github.com/urfave/cli/v3"
+
+	"github.com/staranto/tfctl/internal/meta"
+)
+
+// cvqDefaultAttrs specifies the default attributes displayed for configuration
+// versions in the "cvq" command output. Configuration versions carry no
+// created-at, so the time they were queued stands in for it.
+var cvqDefaultAttrs = []string{".id", "source", "status", "status-timestamps.queued-at:created-at"}
+
+// cvqCommandAction is the action handler for the "cvq" subcommand. It lists
+// the configuration versions of the workspace via the active backend,
+// supports --tldr/--schema shortcuts, and emits results per common flags.
+func cvqCommandAction(ctx context.Context, cmd *cli.Command) error {
+	be, err := InitLocalBackendQuery(ctx, cmd)
+	if err != nil {
+		return err
+	}
+
+	return NewQueryActionRunner(
+		"cvq",
+		reflect.TypeOf((*tfe.ConfigurationVersion)(nil)).Elem(),
+		cvqDefaultAttrs,
+		WorkspaceQueryFetcherFactory(be, cvqFetcher),
+	).Run(ctx, cmd)
+}
+
+// cvqFetcher returns a fetcher that lists one page of the configuration
+// versions of workspaceID, newest first, using the provided client. Upload
+// URLs are dropped since they are short-lived, pre-signed write URLs.
+func cvqFetcher(
+	client *tfe.Client,
+	workspaceID string,
+) func(context.Context, *tfe.ConfigurationVersionListOptions) ([]*tfe.ConfigurationVersion, *tfe.Pagination, error) {
+	return func(
+		ctx context.Context,
+		opts *tfe.ConfigurationVersionListOptions,
+	) ([]*tfe.ConfigurationVersion, *tfe.Pagination, error) {
+		page, err := client.ConfigurationVersions.List(ctx, workspaceID, opts)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, cv := range page.Items {
+			cv.UploadURL = ""
+		}
+		return page.Items, page.Pagination, nil
+	}
+}
+
+// cvqCommandBuilder constructs the cli.Command for "cvq", wiring metadata,
+// flags, and action handlers.
+func cvqCommandBuilder(meta meta.Meta) *cli.Command {
+	return (&QueryCommandBuilder{
+		Name:      "cvq",
+		Usage:     "configuration version query",
+		UsageText: "tfctl cvq [RootDir] [options]",
+		Flags: []cli.Flag{
+			NewExplainBackendFlag(),
+			NewNoWorkspaceFileFlag(),
+			NewEnvFlag(),
+			NewHostFlag(meta.Profile, "cvq"),
+			NewOrgFlag(meta.Profile, "cvq"),
+			NewWorkspaceFlag(meta.Profile),
+		},
+		Action: cvqCommandAction,
+		Meta:   meta,
+	}).Build()
+}
@@ -0,0 +1,57 @@
+// Copyright (c) 2026 Steve Taranto <staranto@gmail.com>.
+// SPDX-License-Identifier: Apache-2.0
+// no-cloc
+
+package command
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/go-tfe"
+	"github.com/hashicorp/jsonapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v3"
+
+	"github.com/staranto/tfctl/internal/attrs"
+	"github.com/staranto/tfctl/internal/output"
+)
+
+func TestCvqCommand_DropsUploadURLs(t *testing.T) {
+	cvs := runWorkspaceQuery(t, cvqFetcher, fakeTFERoute{
+		suffix: "/api/v2/workspaces/ws-abc/configuration-versions", pages: 2,
+		render: func(_ *http.Request, page int) string {
+			return fmt.Sprintf(`{"id":"cv-%d","type":"configuration-versions","attributes":`+
+				`{"source":"github","status":"uploaded","speculative":false,"upload-url":"https://archivist/v1/object/secret",`+
+				`"status-timestamps":{"queued-at":"2026-03-0%dT10:00:00Z"}}}`, page, page)
+		},
+	})
+
+	require.Len(t, cvs, 2)
+	for _, cv := range cvs {
+		assert.Equal(t, tfe.ConfigurationSourceGithub, cv.Source)
+		assert.Empty(t, cv.UploadURL)
+	}
+
+	// The default attributes render the queued time as created-at.
+	var raw bytes.Buffer
+	require.NoError(t, jsonapi.MarshalPayload(&raw, cvs))
+
+	var al attrs.AttrList
+	for _, a := range cvqDefaultAttrs {
+		require.NoError(t, al.Set(a))
+	}
+
+	out := &cli.Command{Flags: []cli.Flag{&cli.StringFlag{Name: "output", Value: "text"}}}
+	buf := new(bytes.Buffer)
+	output.SliceDiceSpit(raw, al, out, "data", buf, nil)
+
+	assert.Equal(t, []string{
+		"cv-1", "github", "uploaded", "2026-03-01T10:00:00Z",
+		"cv-2", "github", "uploaded", "2026-03-02T10:00:00Z",
+	}, strings.Fields(buf.String()))
+}
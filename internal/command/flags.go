@@ -4,97 +4,410 @@
 package command
 
 import (
+	"fmt"
 	"os/exec"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
 
-	altsrc "github.com/urfave/cli-altsrc/v3"
-	yaml "github.com/urfave/cli-altsrc/v3/yaml"
 	"github.com/urfave/cli/v3"
+
+	"github.com/staranto/tfctl/internal/config"
+	"github.com/staranto/tfctl/internal/meta"
+	"github.com/staranto/tfctl/internal/output"
 )
 
-var (
-	schemaFlag *cli.BoolFlag = &cli.BoolFlag{
+// The flags shared by several commands are built by constructors rather than
+// held in package variables, so each InitApp, such as one per batch line, gets
+// fresh flags that don't remember being set by a previous run.
+
+// NewDecryptCmdFlag constructs the cli.StringFlag for the "decrypt-cmd" flag.
+func NewDecryptCmdFlag() *cli.StringFlag {
+	return &cli.StringFlag{
+		Name:  "decrypt-cmd",
+		Usage: "external program to pipe raw state through for decryption",
+		Sources: cli.NewValueSourceChain(
+			cli.EnvVar("TFCTL_DECRYPT_CMD"),
+		),
+	}
+}
+
+// NewPassphraseFileFlag constructs the cli.StringFlag for the
+// "passphrase-file" flag.
+func NewPassphraseFileFlag() *cli.StringFlag {
+	return &cli.StringFlag{
+		Name:      "passphrase-file",
+		Usage:     "read the encrypted state passphrase from this file",
+		TakesFile: true,
+	}
+}
+
+// NewPartialFlag constructs the cli.BoolFlag for the "partial" flag. Only the
+// queries that can read several sources take it: those spanning --org lists,
+// and rq and svq with --all-workspaces.
+func NewPartialFlag() *cli.BoolFlag {
+	return &cli.BoolFlag{
+		Name:        "partial",
+		Usage:       "emit successful rows when some sources of a multi-source query fail",
+		HideDefault: true,
+	}
+}
+
+// NewDeepFlag constructs the cli.BoolFlag for the "deep" flag.
+func NewDeepFlag() *cli.BoolFlag {
+	return &cli.BoolFlag{
+		Name:        "deep",
+		Usage:       "with --schema, include nested attributes and relationships",
+		HideDefault: true,
+	}
+}
+
+// NewExplainBackendFlag constructs the cli.BoolFlag for the "explain-backend" flag.
+func NewExplainBackendFlag() *cli.BoolFlag {
+	return &cli.BoolFlag{
+		Name:        "explain-backend",
+		Usage:       "trace backend detection decisions to stderr",
+		HideDefault: true,
+	}
+}
+
+// NewAllWorkspacesFlag constructs the cli.BoolFlag for the "all-workspaces" flag.
+func NewAllWorkspacesFlag() *cli.BoolFlag {
+	return &cli.BoolFlag{
+		Name:        "all-workspaces",
+		Usage:       "query every workspace the backend selects, or the whole organization, as one result set",
+		HideDefault: true,
+	}
+}
+
+// NewEnvFlag constructs the cli.StringFlag for the "env" flag.
+func NewEnvFlag() *cli.StringFlag {
+	return &cli.StringFlag{
+		Name:  "env",
+		Usage: "workspace env to use instead of the environment file, like RootDir::env",
+	}
+}
+
+// NewNoWorkspaceFileFlag constructs the cli.BoolFlag for the "no-prefixed-workspace-file" flag.
+func NewNoWorkspaceFileFlag() *cli.BoolFlag {
+	return &cli.BoolFlag{
+		Name:  "no-prefixed-workspace-file",
+		Usage: "ignore the workspace selected in the environment file; use --workspace, --env or RootDir::env",
+		Sources: cli.NewValueSourceChain(
+			cli.EnvVar("TFCTL_NO_WORKSPACE_FILE"),
+		),
+		HideDefault: true,
+	}
+}
+
+// NewOfflineFlag constructs the cli.BoolFlag for the "offline" flag, looked up
+// in profile first.
+func NewOfflineFlag(profile meta.Profile) *cli.BoolFlag {
+	return &cli.BoolFlag{
+		Name:  "offline",
+		Usage: "serve state from the cache only and fail on a cache miss",
+		Sources: profileSources(profile, "offline",
+			cli.EnvVar("TFCTL_OFFLINE"),
+		),
+		Value: false,
+	}
+}
+
+// NewPrintConfigFlag constructs the cli.BoolFlag for the "print-config" flag.
+func NewPrintConfigFlag() *cli.BoolFlag {
+	return &cli.BoolFlag{
+		Name:        "print-config",
+		Usage:       "print the resolved flag values as json and exit",
+		HideDefault: true,
+	}
+}
+
+// NewPrintSourcesFlag constructs the cli.BoolFlag for the "print-sources" flag.
+func NewPrintSourcesFlag() *cli.BoolFlag {
+	return &cli.BoolFlag{
+		Name:        "print-sources",
+		Usage:       "like --print-config, also showing where each value came from",
+		HideDefault: true,
+	}
+}
+
+// NewRawPathFlag constructs the cli.StringFlag for the "raw-path" flag, looked
+// up in profile first.
+func NewRawPathFlag(profile meta.Profile) *cli.StringFlag {
+	return &cli.StringFlag{
+		Name:    "raw-path",
+		Usage:   "gjson path of the part of the document --output raw prints",
+		Sources: profileSources(profile, "raw-path"),
+	}
+}
+
+// NewSchemaFlag constructs the cli.BoolFlag for the "schema" flag.
+func NewSchemaFlag() *cli.BoolFlag {
+	return &cli.BoolFlag{
 		Name:        "schema",
 		Usage:       "dump the schema",
 		HideDefault: true,
 	}
+}
 
-	tldrFlag *cli.BoolFlag = &cli.BoolFlag{
+// NewTldrFlag constructs the cli.BoolFlag for the "tldr" flag.
+func NewTldrFlag() *cli.BoolFlag {
+	return &cli.BoolFlag{
 		Name:        "tldr",
 		Usage:       "show tldr page",
 		Hidden:      !pathHas("tldr"),
 		HideDefault: true,
 	}
+}
+
+// NewWithSchemaFlag constructs the cli.BoolFlag for the "with-schema" flag,
+// looked up in profile first.
+func NewWithSchemaFlag(profile meta.Profile) *cli.BoolFlag {
+	return &cli.BoolFlag{
+		Name:    "with-schema",
+		Usage:   "precede jsonl output with a schema line listing the attributes",
+		Sources: profileSources(profile, "with-schema"),
+		Value:   false,
+	}
+}
 
-	workspaceFlag *cli.StringFlag = &cli.StringFlag{
+// NewWorkspaceFlag constructs the cli.StringFlag for the "workspace" flag,
+// looked up in profile first.
+func NewWorkspaceFlag(profile meta.Profile) *cli.StringFlag {
+	return &cli.StringFlag{
 		Name:    "workspace",
 		Aliases: []string{"w"},
 		Usage:   "workspace to use for query. Overrides the backend",
-		Sources: cli.NewValueSourceChain(
+		Sources: profileSources(profile, "workspace",
 			cli.EnvVar("TFCTL_WORKSPACE"),
 		),
 		Value: "",
 	}
-)
+}
+
+// NewGlobalFlags returns the flags shared by the query commands, each looked
+// up in profile first, see profileSources. params[0], if given, is the command
+// name, which namespaces the config defaults of flags such as --color.
+func NewGlobalFlags(profile meta.Profile, params ...string) (flags []cli.Flag) {
+	// --color defaults to <cmd>.color, then color, from the config file, so
+	// color can be turned on for some commands only.
+	colorSources := profileSources(profile, "color")
+	if len(params) > 0 && params[0] != "" {
+		colorSources.Chain = append(colorSources.Chain, &configValueSource{key: params[0] + ".color"})
+	}
+	colorSources.Chain = append(colorSources.Chain, &configValueSource{key: "color"})
+
+	// --view names a preset under <cmd>.views, so it needs the command name.
+	var command string
+	if len(params) > 0 {
+		command = params[0]
+	}
 
-func NewGlobalFlags(params ...string) (flags []cli.Flag) {
 	flags = []cli.Flag{
+		&cli.StringFlag{
+			Name:    "agg",
+			Usage:   "aggregate to add to --group-by rows, e.g. sum:<attr>",
+			Sources: profileSources(profile, "agg"),
+			Validator: func(value string) error {
+				return FlagValidators(value, AggValidator)
+			},
+		},
+		&cli.StringFlag{
+			Name:      "also-csv",
+			Usage:     "also write the results as CSV to this file",
+			Sources:   profileSources(profile, "also-csv"),
+			TakesFile: true,
+		},
+		&cli.StringFlag{
+			Name:      "also-json",
+			Usage:     "also write the results as JSON to this file",
+			Sources:   profileSources(profile, "also-json"),
+			TakesFile: true,
+		},
 		&cli.StringFlag{
 			Name:    "attrs",
 			Aliases: []string{"a"},
 			Usage:   "comma-separated list of attributes to include in results",
+			Sources: profileSources(profile, "attrs"),
 		},
-		&cli.BoolFlag{
+		// chdir has no profile source: InitApp switches directory before the
+		// profile is read.
+		&cli.StringFlag{
+			Name:  "chdir",
+			Usage: "switch to this directory before resolving RootDir",
+		},
+		&cli.GenericFlag{
 			Name:    "color",
 			Aliases: []string{"c"},
-			Usage:   "enable colored text output",
+			Usage:   "colored table, json and yaml output (auto, always, never). A bare --color means auto",
+			Sources: colorSources,
+			Value:   &colorValue{mode: "never"},
+		},
+		&cli.BoolFlag{
+			Name:    "count",
+			Usage:   "only print the number of matching rows",
+			Sources: profileSources(profile, "count"),
 			Value:   false,
 		},
+		&cli.BoolFlag{
+			Name:    "fail-on-empty",
+			Usage:   "exit with status 3 when no rows match",
+			Sources: profileSources(profile, "fail-on-empty"),
+			Value:   false,
+		},
+		&cli.StringFlag{
+			Name:    "fields",
+			Usage:   "row fields to extract (all, none)",
+			Sources: profileSources(profile, "fields"),
+			Value:   "all",
+			Validator: func(value string) error {
+				return FlagValidators(value, FieldsValidator)
+			},
+		},
 		&cli.StringFlag{
 			Name:    "filter",
 			Aliases: []string{"f"},
 			Usage:   "comma-separated list of filters to apply to results",
+			Sources: profileSources(profile, "filter"),
+		},
+		&cli.StringFlag{
+			Name:  "formatter-cmd",
+			Usage: "program --output exec pipes the json results through",
+			Sources: profileSources(profile, "formatter-cmd",
+				cli.EnvVar("TFCTL_FORMATTER_CMD"),
+			),
+		},
+		&cli.StringFlag{
+			Name:    "group-by",
+			Usage:   "emit a count of rows for each distinct value of an attribute",
+			Sources: profileSources(profile, "group-by"),
 		},
 		&cli.BoolFlag{
 			Name:    "local",
 			Aliases: []string{"l"},
 			Usage:   "show local timestamps",
+			Sources: profileSources(profile, "local"),
+			Value:   false,
+		},
+		&cli.BoolFlag{
+			Name:    "no-pager",
+			Usage:   "don't page long text output through $TFCTL_PAGER or $PAGER",
+			Sources: profileSources(profile, "no-pager"),
 			Value:   false,
 		},
+		&cli.StringFlag{
+			Name:      "out",
+			Usage:     "file to write the output to, gzipped if it ends in .gz",
+			Sources:   profileSources(profile, "out"),
+			TakesFile: true,
+		},
 		&cli.StringFlag{
 			Name:    "output",
 			Aliases: []string{"o"},
 			Usage:   "output format",
+			Sources: profileSources(profile, "output"),
 			Value:   "text",
 			Validator: func(value string) error {
 				return FlagValidators(value, OutputValidator)
 			},
 		},
+		&cli.StringFlag{
+			Name:  "profile",
+			Usage: "named bundle of flag defaults from the profiles config key",
+			Sources: cli.NewValueSourceChain(
+				cli.EnvVar("TFCTL_PROFILE"),
+			),
+		},
 		&cli.StringFlag{
 			Name:    "sort",
 			Aliases: []string{"s"},
 			Usage:   "comma-separated list of attributes to sort the results by",
+			Sources: profileSources(profile, "sort"),
+		},
+		&cli.StringFlag{
+			Name:  "theme",
+			Usage: fmt.Sprintf("table color theme (%s)", strings.Join(output.ThemeNames(), ", ")),
+			Sources: profileSources(profile, "theme",
+				&configValueSource{key: "theme"},
+			),
+			Validator: func(value string) error {
+				_, err := output.LookupTheme(value)
+				return err
+			},
 		},
 		&cli.BoolFlag{
 			Name:    "titles",
 			Aliases: []string{"t"},
 			Usage:   "show titles with text output",
+			Sources: profileSources(profile, "titles"),
 			Value:   false,
 		},
+		&cli.StringFlag{
+			Name:    "view",
+			Usage:   "named --attrs preset from the <command>.views config key",
+			Sources: profileSources(profile, "view"),
+			Validator: func(value string) error {
+				_, err := config.View(command, value)
+				return err
+			},
+		},
 	}
 
 	return
 }
 
-// NewHostFlag constructs a cli.StringFlag for the "host" flag, optionally
-// namespaced to a command and config file.  params[1] is the config file.  Note
-// that currently the sq command does not include params[1], thereby forcing the
-// host to be derived from the backend or explicit flag.
-func NewHostFlag(params ...string) (flag *cli.StringFlag) {
+// colorModes are the values accepted by --color.
+var colorModes = []string{"auto", "always", "never"}
+
+// colorValue is the cli.Value behind --color. It reports itself as a bool
+// flag so a bare --color (or -c) still parses, meaning auto, and maps the old
+// --color=true and --color=false onto auto and never.
+type colorValue struct {
+	mode string
+}
+
+// Set implements cli.Value.
+func (v *colorValue) Set(s string) error {
+	switch s {
+	case "true":
+		s = "auto"
+	case "false":
+		s = "never"
+	}
+	if !slices.Contains(colorModes, s) {
+		return fmt.Errorf("invalid color mode %q, must be one of %v", s, colorModes)
+	}
+	v.mode = s
+	return nil
+}
+
+// String implements cli.Value.
+func (v *colorValue) String() string {
+	return v.mode
+}
+
+// Get implements cli.Getter.
+func (v *colorValue) Get() any {
+	return v.mode
+}
+
+// IsBoolFlag lets --color be given without a value.
+func (v *colorValue) IsBoolFlag() bool {
+	return true
+}
+
+// NewHostFlag constructs a cli.StringFlag for the "host" flag, looked up in
+// profile first and optionally namespaced to a command and config file.
+// params[1] is the config file.  Note that currently the sq command does not
+// include params[1], thereby forcing the host to be derived from the backend or
+// explicit flag.
+func NewHostFlag(profile meta.Profile, params ...string) (flag *cli.StringFlag) {
 	flag = &cli.StringFlag{
 		Name:    "host",
 		Aliases: []string{"h"},
 		Usage:   "host to use for all commands. Overrides the backend",
-		Sources: cli.NewValueSourceChain(
+		Sources: profileSources(profile, "host",
 			cli.EnvVar("TFCTL_HOST"),
 			cli.EnvVar("TF_CLOUD_HOSTNAME"),
 		),
@@ -108,15 +421,16 @@ func NewHostFlag(params ...string) (flag *cli.StringFlag) {
 	return
 }
 
-// NewOrgFlag constructs a cli.StringFlag for the "org" flag, optionally
-// namespaced to a command and config file. params[1] is the config file.  Note
-// that currently the sq command does not include params[1], thereby forcing the
-// org to be derived from the backend or explicit flag.
-func NewOrgFlag(params ...string) (flag *cli.StringFlag) {
+// NewOrgFlag constructs a cli.StringFlag for the "org" flag, looked up in
+// profile first and optionally namespaced to a command and config file.
+// params[1] is the config file.  Note that currently the sq command does not
+// include params[1], thereby forcing the org to be derived from the backend or
+// explicit flag.
+func NewOrgFlag(profile meta.Profile, params ...string) (flag *cli.StringFlag) {
 	flag = &cli.StringFlag{
 		Name:  "org",
 		Usage: "organization to use for all commands. Overrides the backend",
-		Sources: cli.NewValueSourceChain(
+		Sources: profileSources(profile, "org",
 			cli.EnvVar("TFCTL_ORG"),
 			cli.EnvVar("TF_CLOUD_ORGANIZATION"),
 		),
@@ -134,19 +448,103 @@ func NewOrgFlag(params ...string) (flag *cli.StringFlag) {
 }
 
 // NameSpacedValueChainFlagFromConfigFile adds namespaced and global config file
-// sources to the given flag's Sources chain.
+// sources to the given flag's Sources chain. Values are read from the merged
+// config loaded from path, so keys from included files are honored too.
 func NameSpacedValueChainFlagFromConfigFile(ns string, path string, flag *cli.StringFlag) *cli.StringFlag {
-	src := yaml.YAML(ns+"."+flag.Name, altsrc.StringSourcer(path))
-	flag.Sources.Chain = append(flag.Sources.Chain, src)
+	if path == "" {
+		return flag
+	}
 
-	src = yaml.YAML(flag.Name, altsrc.StringSourcer(path))
-	flag.Sources.Chain = append(flag.Sources.Chain, src)
+	flag.Sources.Chain = append(flag.Sources.Chain,
+		&configValueSource{key: ns + "." + flag.Name},
+		&configValueSource{key: flag.Name},
+	)
 
 	return flag
 }
 
+// configValueSource is a cli.ValueSource that looks a key up in the merged
+// tfctl config.
+type configValueSource struct {
+	key string
+}
+
+// Lookup implements cli.ValueSource. YAML booleans, e.g. "color: true", are
+// passed on as "true" or "false".
+func (s *configValueSource) Lookup() (string, bool) {
+	if value, err := config.GetString(s.key); err == nil {
+		return value, true
+	}
+	if value, err := config.GetBool(s.key); err == nil {
+		return strconv.FormatBool(value), true
+	}
+	return "", false
+}
+
+// String implements fmt.Stringer.
+func (s *configValueSource) String() string {
+	return fmt.Sprintf("config key %q", s.key)
+}
+
+// GoString implements fmt.GoStringer.
+func (s *configValueSource) GoString() string {
+	return fmt.Sprintf("&configValueSource{key:%q}", s.key)
+}
+
 // pathHas checks if the given key exists in cfg.Source.
 func pathHas(target string) bool {
 	_, err := exec.LookPath(target)
 	return err == nil
 }
+
+// flagGroupOrder lists the flag groups in the order they are shown in --help
+// when help.order is "grouped". Flags not found in any group, such as --schema
+// and --print-config, are shown last.
+var flagGroupOrder = [][]string{
+	// Connection: where the data comes from.
+	{
+		"chdir", "profile", "host", "org", "workspace", "run", "sv", "at",
+		"state-file", "passphrase", "passphrase-file", "passphrase-stdin",
+		"decrypt-cmd", "offline", "no-prefixed-workspace-file", "env",
+		"all-workspaces", "partial", "explain-backend",
+	},
+	// Filter: which rows are returned.
+	{
+		"filter", "sort", "limit", "concrete", "diff", "diff-attrs",
+		"diff-format", "diff_filter", "count", "fail-on-empty", "group-by",
+		"agg", "fields", "stale", "execution-mode",
+	},
+	// Output: how the rows are rendered.
+	{
+		"output", "out", "raw-path", "no-pager", "formatter-cmd", "also-csv",
+		"also-json", "with-schema", "view", "attrs", "titles", "color",
+		"theme", "local", "chop", "short", "address-sep", "compare", "deltas",
+		"browse",
+	},
+}
+
+// flagGroup returns the index of the group containing the named flag, or
+// len(flagGroupOrder) if the flag is ungrouped.
+func flagGroup(name string) int {
+	for i, group := range flagGroupOrder {
+		if slices.Contains(group, name) {
+			return i
+		}
+	}
+	return len(flagGroupOrder)
+}
+
+// sortFlags orders flags for --help. By default flags are sorted by their first
+// name. When grouped is true, related flags are clustered per flagGroupOrder
+// and sorted by name within each group. The sort is stable.
+func sortFlags(flags []cli.Flag, grouped bool) {
+	sort.SliceStable(flags, func(i, j int) bool {
+		one, two := flags[i].Names()[0], flags[j].Names()[0]
+		if grouped {
+			if g1, g2 := flagGroup(one), flagGroup(two); g1 != g2 {
+				return g1 < g2
+			}
+		}
+		return one < two
+	})
+}
@@ -0,0 +1,267 @@
+// Copyright (c) 2026 Steve Taranto <staranto@gmail.com>.
+// SPDX-License-Identifier: Apache-2.0
+// no-cloc
+
+package command
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v3"
+
+	"github.com/staranto/tfctl/internal/config"
+	"github.com/staranto/tfctl/internal/meta"
+)
+
+func flagNames(flags []cli.Flag) []string {
+	names := make([]string, 0, len(flags))
+	for _, f := range flags {
+		names = append(names, f.Names()[0])
+	}
+	return names
+}
+
+func TestSortFlags_Alpha(t *testing.T) {
+	cmd := wqCommandBuilder(meta.Meta{})
+	sortFlags(cmd.Flags, false)
+
+	names := flagNames(cmd.Flags)
+	assert.IsIncreasing(t, names)
+}
+
+func TestSortFlags_Grouped(t *testing.T) {
+	cmd := wqCommandBuilder(meta.Meta{})
+	sortFlags(cmd.Flags, true)
+
+	assert.Equal(t, []string{
+		// Connection
+		"chdir", "host", "org", "partial", "profile",
+		// Filter
+		"agg", "count", "execution-mode", "fail-on-empty", "fields", "filter", "group-by", "limit", "sort", "stale",
+		// Output
+		"also-csv", "also-json", "attrs", "color", "formatter-cmd", "local", "no-pager", "out", "output", "theme", "titles", "view",
+		// Other
+		"deep", "print-config", "print-sources", "schema", "tldr",
+	}, flagNames(cmd.Flags))
+}
+
+// TestFlagGroupOrder_Complete verifies every flag of every command is in a
+// flagGroupOrder group, so a new flag isn't left at the bottom of grouped
+// --help by accident.
+func TestFlagGroupOrder_Complete(t *testing.T) {
+	// These don't shape a query and are meant to be shown last.
+	ungrouped := []string{"deep", "print-config", "print-sources", "schema", "tldr"}
+
+	app, err := InitApp(context.Background(), []string{"tfctl"}, nil)
+	require.NoError(t, err)
+
+	for _, cmd := range app.Commands {
+		for _, name := range flagNames(cmd.Flags) {
+			if slices.Contains(ungrouped, name) {
+				continue
+			}
+			assert.Less(t, flagGroup(name), len(flagGroupOrder), "%s --%s is in no flag group", cmd.Name, name)
+		}
+	}
+}
+
+// TestStateOnlyFlags verifies the flags only state queries honor are
+// registered on those commands alone.
+func TestStateOnlyFlags(t *testing.T) {
+	stateOnly := []string{"offline", "raw-path", "with-schema"}
+	for name, build := range map[string]func(meta.Meta) *cli.Command{
+		"soq": soqCommandBuilder,
+		"sq":  sqCommandBuilder,
+		"svq": svqCommandBuilder,
+	} {
+		assert.Subset(t, flagNames(build(meta.Meta{}).Flags), stateOnly, name)
+	}
+	for name, build := range map[string]func(meta.Meta) *cli.Command{
+		"mq": mqCommandBuilder,
+		"oq": oqCommandBuilder,
+		"pq": pqCommandBuilder,
+		"wq": wqCommandBuilder,
+	} {
+		names := flagNames(build(meta.Meta{}).Flags)
+		for _, flag := range stateOnly {
+			assert.NotContains(t, names, flag, name)
+		}
+	}
+}
+
+// TestPartialFlag verifies --partial is registered only on the queries that
+// can read several sources.
+func TestPartialFlag(t *testing.T) {
+	for name, build := range map[string]func(meta.Meta) *cli.Command{
+		"apq": apqCommandBuilder,
+		"mq":  mqCommandBuilder,
+		"ocq": ocqCommandBuilder,
+		"pq":  pqCommandBuilder,
+		"rq":  rqCommandBuilder,
+		"soq": soqCommandBuilder,
+		"svq": svqCommandBuilder,
+		"wq":  wqCommandBuilder,
+	} {
+		assert.Contains(t, flagNames(build(meta.Meta{}).Flags), "partial", name)
+	}
+	for name, build := range map[string]func(meta.Meta) *cli.Command{
+		"cvq": cvqCommandBuilder,
+		"ncq": ncqCommandBuilder,
+		"oq":  oqCommandBuilder,
+		"rtq": rtqCommandBuilder,
+	} {
+		assert.NotContains(t, flagNames(build(meta.Meta{}).Flags), "partial", name)
+	}
+}
+
+// TestGlobalFlagsValidator_Partial verifies --partial on a command with
+// --all-workspaces is only accepted along with it.
+func TestGlobalFlagsValidator_Partial(t *testing.T) {
+	tests := []struct {
+		args    []string
+		wantErr string
+	}{
+		{args: []string{"--all-workspaces", "--partial"}},
+		{args: []string{"--all-workspaces"}},
+		{args: []string{"--partial"}, wantErr: "--partial requires --all-workspaces"},
+	}
+
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.args, " "), func(t *testing.T) {
+			var err error
+			cmd := &cli.Command{
+				Name:  "rq",
+				Flags: []cli.Flag{NewAllWorkspacesFlag(), NewPartialFlag()},
+				Action: func(ctx context.Context, c *cli.Command) error {
+					err = GlobalFlagsValidator(ctx, c)
+					return nil
+				},
+			}
+			require.NoError(t, cmd.Run(context.Background(), append([]string{"rq"}, tt.args...)))
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
+
+// TestGlobalFlagsValidator_Deep verifies --deep is only accepted along with
+// --schema.
+func TestGlobalFlagsValidator_Deep(t *testing.T) {
+	tests := []struct {
+		args    []string
+		wantErr string
+	}{
+		{args: []string{"--schema", "--deep"}},
+		{args: []string{"--schema"}},
+		{args: []string{"--deep"}, wantErr: "--deep requires --schema"},
+	}
+
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.args, " "), func(t *testing.T) {
+			var err error
+			cmd := &cli.Command{
+				Name:  "svq",
+				Flags: []cli.Flag{NewSchemaFlag(), NewDeepFlag()},
+				Action: func(ctx context.Context, c *cli.Command) error {
+					err = GlobalFlagsValidator(ctx, c)
+					return nil
+				},
+			}
+			require.NoError(t, cmd.Run(context.Background(), append([]string{"svq"}, tt.args...)))
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestFlagGroup(t *testing.T) {
+	assert.Equal(t, 0, flagGroup("host"))
+	assert.Equal(t, 1, flagGroup("filter"))
+	assert.Equal(t, 2, flagGroup("output"))
+	assert.Equal(t, len(flagGroupOrder), flagGroup("tldr"))
+}
+
+func TestColorFlag(t *testing.T) {
+	tests := []struct {
+		args     []string
+		want     string
+		wantArgs []string
+	}{
+		{nil, "never", nil},
+		{[]string{"--color"}, "auto", nil},
+		{[]string{"-c"}, "auto", nil},
+		{[]string{"--color=always"}, "always", nil},
+		{[]string{"--color=never"}, "never", nil},
+		{[]string{"--color=true"}, "auto", nil},
+		{[]string{"--color=false"}, "never", nil},
+		// A mode given as the next argument belongs to the flag.
+		{[]string{"--color", "always"}, "always", nil},
+		{[]string{"-c", "never", "dir"}, "never", []string{"dir"}},
+		// Anything else after a bare --color stays an argument.
+		{[]string{"--color", "dir"}, "auto", []string{"dir"}},
+	}
+
+	for _, tt := range tests {
+		var got any
+		var gotArgs []string
+		cmd := &cli.Command{
+			Name:  "test",
+			Flags: NewGlobalFlags(meta.Profile{}),
+			Action: func(_ context.Context, cmd *cli.Command) error {
+				got = cmd.Value("color")
+				gotArgs = cmd.Args().Slice()
+				return nil
+			},
+		}
+		require.NoError(t, cmd.Run(context.Background(), ColorArgs(append([]string{"test"}, tt.args...))))
+		assert.Equal(t, tt.want, got, "args %v", tt.args)
+		assert.ElementsMatch(t, tt.wantArgs, gotArgs, "args %v", tt.args)
+	}
+
+	cmd := &cli.Command{Name: "test", Flags: NewGlobalFlags(meta.Profile{})}
+	assert.Error(t, cmd.Run(context.Background(), []string{"test", "--color=sometimes"}))
+}
+
+func TestColorFlag_Config(t *testing.T) {
+	cfg := filepath.Join(t.TempDir(), "tfctl.yaml")
+	require.NoError(t, os.WriteFile(cfg, []byte("color: false\nsq:\n  color: true\nsi:\n  color: always\n"), 0o600))
+	t.Setenv("TFCTL_CFG_FILE", cfg)
+	_, err := config.Load()
+	require.NoError(t, err)
+	t.Cleanup(func() { config.Config = config.Type{} })
+
+	color := func(name string, args ...string) any {
+		var got any
+		cmd := &cli.Command{
+			Name:  name,
+			Flags: NewGlobalFlags(meta.Profile{}, name),
+			Action: func(_ context.Context, cmd *cli.Command) error {
+				got = cmd.Value("color")
+				return nil
+			},
+		}
+		require.NoError(t, cmd.Run(context.Background(), append([]string{name}, args...)))
+		return got
+	}
+
+	// The namespaced key wins over the global one, which wins over the
+	// built-in default, and the flag wins over all of them.
+	assert.Equal(t, "auto", color("sq"))
+	assert.Equal(t, "always", color("si"))
+	assert.Equal(t, "never", color("wq"))
+	assert.Equal(t, "never", color("sq", "--color=never"))
+	assert.Equal(t, "always", color("wq", "--color=always"))
+}
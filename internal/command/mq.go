@@ -24,7 +24,7 @@ var mqDefaultAttrs = []string{".id", "name"}
 // registry modules for the selected organization, supports --tldr/--schema
 // shortcuts, and emits results per common flags.
 func mqCommandAction(ctx context.Context, cmd *cli.Command) error {
-	be, org, client, err := InitRemoteOrgQuery(ctx, cmd)
+	be, orgs, client, err := InitRemoteOrgQuery(ctx, cmd)
 	if err != nil {
 		return err
 	}
@@ -45,7 +45,7 @@ func mqCommandAction(ctx context.Context, cmd *cli.Command) error {
 	// Use RemoteQueryFetcherFactory to handle pagination and augmentation
 	fn := RemoteQueryFetcherFactory(
 		be,
-		org,
+		orgs,
 		fetcher,
 		mqServerSideFilterAugmenter,
 		"list registry modules",
@@ -105,8 +105,9 @@ func mqCommandBuilder(meta meta.Meta) *cli.Command {
 		Usage:     "module registry query",
 		UsageText: "tfctl mq [RootDir] [options]",
 		Flags: []cli.Flag{
-			NewHostFlag("mq", meta.Config.Source),
-			NewOrgFlag("mq", meta.Config.Source),
+			NewHostFlag(meta.Profile, "mq", meta.Config.Source),
+			NewOrgFlag(meta.Profile, "mq", meta.Config.Source),
+			NewPartialFlag(),
 		},
 		Action: mqCommandAction,
 		Meta:   meta,
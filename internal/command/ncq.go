@@ -0,0 +1,98 @@
+// Copyright (c) 2026 Steve Taranto <staranto@gmail.com>.
+// SPDX-License-Identifier: Apache-2.0
+
+package command
+
+import (
+	"context"
+	"net/url"
+	"reflect"
+
+	"github.com/hashicorp/go-tfe"
+	"github.com/urfave/cli/v3"
+
+	"github.com/staranto/tfctl/internal/meta"
+)
+
+// ncqDefaultAttrs specifies the default attributes displayed for notification
+// configurations in the "ncq" command output.
+var ncqDefaultAttrs = []string{".id", "name", "destination-type", "enabled", "triggers"}
+
+// ncqCommandAction is the action handler for the "ncq" subcommand. It lists
+// the notification configurations of the backend's workspace (or --workspace),
+// supports --tldr/--schema shortcuts, and emits results per common flags.
+func ncqCommandAction(ctx context.Context, cmd *cli.Command) error {
+	be, err := InitLocalBackendQuery(ctx, cmd)
+	if err != nil {
+		return err
+	}
+
+	return NewQueryActionRunner(
+		"ncq",
+		reflect.TypeOf((*tfe.NotificationConfiguration)(nil)).Elem(),
+		ncqDefaultAttrs,
+		WorkspaceQueryFetcherFactory(be, ncqFetcher),
+	).Run(ctx, cmd)
+}
+
+// ncqFetcher returns a fetcher that lists one page of the notification
+// configurations of workspaceID using the provided client. Destination URLs
+// are reduced to their scheme and host and HMAC tokens are masked, since
+// webhook paths commonly embed credentials.
+func ncqFetcher(
+	client *tfe.Client,
+	workspaceID string,
+) func(context.Context, *tfe.NotificationConfigurationListOptions) ([]*tfe.NotificationConfiguration, *tfe.Pagination, error) {
+	return func(
+		ctx context.Context,
+		opts *tfe.NotificationConfigurationListOptions,
+	) ([]*tfe.NotificationConfiguration, *tfe.Pagination, error) {
+		page, err := client.NotificationConfigurations.List(ctx, workspaceID, opts)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, nc := range page.Items {
+			nc.URL = redactURL(nc.URL)
+			if nc.Token != "" {
+				nc.Token = ocqSecretMask
+			}
+			for _, dr := range nc.DeliveryResponses {
+				dr.URL = redactURL(dr.URL)
+			}
+		}
+		return page.Items, page.Pagination, nil
+	}
+}
+
+// redactURL reduces a URL to its scheme and host. Anything that does not parse
+// as an absolute URL is masked entirely.
+func redactURL(raw string) string {
+	if raw == "" {
+		return ""
+	}
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return ocqSecretMask
+	}
+	return u.Scheme + "://" + u.Host
+}
+
+// ncqCommandBuilder constructs the cli.Command for "ncq", wiring metadata,
+// flags, and action handlers.
+func ncqCommandBuilder(meta meta.Meta) *cli.Command {
+	return (&QueryCommandBuilder{
+		Name:      "ncq",
+		Usage:     "notification configuration query",
+		UsageText: "tfctl ncq [RootDir] [options]",
+		Flags: []cli.Flag{
+			NewExplainBackendFlag(),
+			NewNoWorkspaceFileFlag(),
+			NewEnvFlag(),
+			NewHostFlag(meta.Profile, "ncq"),
+			NewOrgFlag(meta.Profile, "ncq"),
+			NewWorkspaceFlag(meta.Profile),
+		},
+		Action: ncqCommandAction,
+		Meta:   meta,
+	}).Build()
+}
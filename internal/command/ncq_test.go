@@ -0,0 +1,40 @@
+// Copyright (c) 2026 Steve Taranto <staranto@gmail.com>.
+// SPDX-License-Identifier: Apache-2.0
+// no-cloc
+
+package command
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/hashicorp/go-tfe"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNcqCommand_Redacts(t *testing.T) {
+	ncs := runWorkspaceQuery(t, ncqFetcher, fakeTFERoute{
+		suffix: "/api/v2/workspaces/ws-abc/notification-configurations", pages: 2,
+		render: func(_ *http.Request, page int) string {
+			return fmt.Sprintf(`{"id":"nc-%d","type":"notification-configurations","attributes":`+
+				`{"name":"notify-%d","destination-type":"slack","enabled":true,`+
+				`"triggers":["run:errored"],"token":"hmac","url":"https://hooks.slack.com/services/T0/B0/secret"}}`,
+				page, page)
+		},
+	})
+
+	require.Len(t, ncs, 2)
+	for _, nc := range ncs {
+		assert.Equal(t, tfe.NotificationDestinationTypeSlack, nc.DestinationType)
+		assert.Equal(t, "https://hooks.slack.com", nc.URL)
+		assert.Equal(t, ocqSecretMask, nc.Token)
+	}
+}
+
+func TestRedactURL(t *testing.T) {
+	assert.Equal(t, "", redactURL(""))
+	assert.Equal(t, "https://example.com:8443", redactURL("https://user:pw@example.com:8443/hook?key=x"))
+	assert.Equal(t, ocqSecretMask, redactURL("not a url"))
+}
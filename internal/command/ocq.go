@@ -0,0 +1,95 @@
+// Copyright (c) 2026 Steve Taranto <staranto@gmail.com>.
+// SPDX-License-Identifier: Apache-2.0
+
+package command
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/hashicorp/go-tfe"
+	"github.com/urfave/cli/v3"
+
+	"github.com/staranto/tfctl/internal/meta"
+)
+
+// ocqDefaultAttrs specifies the default attributes displayed for OAuth clients
+// in the "ocq" command output.
+var ocqDefaultAttrs = []string{".id", "name", "service-provider", "created-at"}
+
+// ocqSecretMask replaces OAuth client credentials in the "ocq" command output.
+const ocqSecretMask = "********"
+
+// ocqCommandAction is the action handler for the "ocq" subcommand. It lists
+// OAuth clients (VCS connections) for the selected organization(s), supports
+// --tldr/--schema shortcuts, and emits results per common flags.
+func ocqCommandAction(ctx context.Context, cmd *cli.Command) error {
+	be, orgs, client, err := InitRemoteOrgQuery(ctx, cmd)
+	if err != nil {
+		return err
+	}
+
+	fn := RemoteQueryFetcherFactory(
+		be,
+		orgs,
+		ocqFetcher(client),
+		nil,
+		"list oauth clients",
+	)
+
+	return NewQueryActionRunner(
+		"ocq",
+		reflect.TypeOf((*tfe.OAuthClient)(nil)).Elem(),
+		ocqDefaultAttrs,
+		fn,
+	).Run(ctx, cmd)
+}
+
+// ocqFetcher returns a RemoteOrgListFetcher that lists one page of OAuth
+// clients using the provided client. Client credentials are masked before
+// they reach any output.
+func ocqFetcher(
+	client *tfe.Client,
+) RemoteOrgListFetcher[*tfe.OAuthClient, tfe.OAuthClientListOptions] {
+	return func(
+		ctx context.Context,
+		org string,
+		opts *tfe.OAuthClientListOptions,
+	) ([]*tfe.OAuthClient, *tfe.Pagination, error) {
+		page, err := client.OAuthClients.List(ctx, org, opts)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, oc := range page.Items {
+			ocqMask(oc)
+		}
+		return page.Items, page.Pagination, nil
+	}
+}
+
+// ocqMask masks every credential an OAuth client carries: the OAuth key and
+// secret, and the RSA key pair's public half used with Bitbucket Data Center.
+func ocqMask(oc *tfe.OAuthClient) {
+	for _, field := range []*string{&oc.Key, &oc.Secret, &oc.RSAPublicKey} {
+		if *field != "" {
+			*field = ocqSecretMask
+		}
+	}
+}
+
+// ocqCommandBuilder constructs the cli.Command for "ocq", wiring metadata,
+// flags, and action handlers.
+func ocqCommandBuilder(meta meta.Meta) *cli.Command {
+	return (&QueryCommandBuilder{
+		Name:      "ocq",
+		Usage:     "oauth client query",
+		UsageText: "tfctl ocq [RootDir] [options]",
+		Flags: []cli.Flag{
+			NewHostFlag(meta.Profile, "ocq", meta.Config.Source),
+			NewOrgFlag(meta.Profile, "ocq", meta.Config.Source),
+			NewPartialFlag(),
+		},
+		Action: ocqCommandAction,
+		Meta:   meta,
+	}).Build()
+}
@@ -0,0 +1,52 @@
+// Copyright (c) 2026 Steve Taranto <staranto@gmail.com>.
+// SPDX-License-Identifier: Apache-2.0
+// no-cloc
+
+package command
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"testing"
+
+	"github.com/hashicorp/go-tfe"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v3"
+
+	"github.com/staranto/tfctl/internal/backend/remote"
+)
+
+func TestOcqFetcher_PaginatesAndMasksSecrets(t *testing.T) {
+	var requests atomic.Int32
+	srv := newFakeTFEServer(t, fakeTFERoute{suffix: "/oauth-clients", pages: 2,
+		render: func(_ *http.Request, page int) string {
+			requests.Add(1)
+			return fmt.Sprintf(`{"id":"oc-%d","type":"oauth-clients","attributes":`+
+				`{"name":"vcs-%d","service-provider":"github","key":"k3y","secret":"s3cr3t","rsa-public-key":"ssh-rsa AAAA"}}`, page, page)
+		},
+	})
+
+	var clients []*tfe.OAuthClient
+	runOrgCommand(t, []string{"--org", "acme"}, func(ctx context.Context, cmd *cli.Command) error {
+		be, orgs, client, err := InitRemoteOrgQuery(ctx, cmd, remote.WithClient(fakeTFEClient(srv)))
+		if err != nil {
+			return err
+		}
+		clients, err = RemoteQueryFetcherFactory(be, orgs, ocqFetcher(client), nil, "list oauth clients")(ctx, cmd)
+		return err
+	})
+
+	assert.Equal(t, int32(2), requests.Load())
+	require.Len(t, clients, 2)
+	assert.Equal(t, "vcs-1", *clients[0].Name)
+	assert.Equal(t, "vcs-2", *clients[1].Name)
+	for _, oc := range clients {
+		assert.Equal(t, tfe.ServiceProviderGithub, oc.ServiceProvider)
+		assert.Equal(t, ocqSecretMask, oc.Key)
+		assert.Equal(t, ocqSecretMask, oc.Secret)
+		assert.Equal(t, ocqSecretMask, oc.RSAPublicKey)
+	}
+}
@@ -92,7 +92,7 @@ func oqCommandBuilder(meta meta.Meta) *cli.Command {
 		Usage:     "organization query",
 		UsageText: "tfctl oq [RootDir] [options]",
 		Flags: []cli.Flag{
-			NewHostFlag("oq", meta.Config.Source),
+			NewHostFlag(meta.Profile, "oq", meta.Config.Source),
 		},
 		Action: oqCommandAction,
 		Meta:   meta,
@@ -6,6 +6,7 @@ package command
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/apex/log"
 	"github.com/hashicorp/go-tfe"
@@ -13,6 +14,7 @@ import (
 
 	"github.com/staranto/tfctl/internal/backend"
 	"github.com/staranto/tfctl/internal/backend/remote"
+	"github.com/staranto/tfctl/internal/cacheutil"
 )
 
 // RemoteOrgListFetcher[T, O] is the signature for a function that performs
@@ -43,46 +45,71 @@ func InitLocalBackendQuery(ctx context.Context, cmd *cli.Command) (
 
 // InitRemoteOrgQuery initializes a remote backend connection for queries that
 // operate exclusively on organizations. It returns the backend, organization
-// name, and TFE client, or an error if initialization fails.
+// names, and TFE client, or an error if initialization fails. The --org value
+// may be a comma-separated list of organizations; a single client is built
+// and shared across all of them so the rate limiter and 429 retry handling in
+// go-tfe apply to the query as a whole rather than per organization. opts
+// follow the default backend options, such as remote.WithClient.
 func InitRemoteOrgQuery(
 	ctx context.Context,
 	cmd *cli.Command,
-) (*remote.BackendRemote, string, *tfe.Client, error) {
-	be, err := remote.NewBackendRemote(ctx, cmd, remote.BuckNaked())
+	opts ...remote.BackendRemoteOption,
+) (*remote.BackendRemote, []string, *tfe.Client, error) {
+	// Organization queries read the API directly and are never cached.
+	if cmd.Bool("offline") {
+		return nil, nil, nil, fmt.Errorf("%s queries the API directly: %w", cmd.Name, cacheutil.ErrOfflineMiss)
+	}
+
+	be, err := remote.NewBackendRemote(ctx, cmd, append([]remote.BackendRemoteOption{remote.BuckNaked()}, opts...)...)
 	if err != nil {
-		return nil, "", nil, err
+		return nil, nil, nil, err
 	}
 	log.Debugf("be: %v", be)
 
 	client, err := be.Client()
 	if err != nil {
-		return nil, "", nil, err
+		return nil, nil, nil, err
 	}
 	log.Debugf("client: %v", client.BaseURL())
 
 	org, err := be.Organization()
 	if err != nil {
-		return nil, "", nil, fmt.Errorf(
+		return nil, nil, nil, fmt.Errorf(
 			"failed to resolve organization: %w",
 			err,
 		)
 	}
 
-	return be, org, client, nil
+	return be, splitOrgs(org), client, nil
 }
 
 // OrgQueryErrorContext is a helper to construct remote.ErrorContext for
-// organization-related queries (mq, pq). It requires the backend and
-// organization.
+// organization-related queries (mq, pq). A nil backend, as passed by
+// backend-agnostic queries such as rq, leaves the host empty.
 func OrgQueryErrorContext(
 	be *remote.BackendRemote,
 	org string,
 	operation string,
 ) remote.ErrorContext {
-	return remote.ErrorContext{
-		Host:      be.Backend.Config.Hostname,
+	ctxErr := remote.ErrorContext{
 		Org:       org,
 		Operation: operation,
 		Resource:  "organization",
 	}
+	if be != nil {
+		ctxErr.Host = be.Backend.Config.Hostname
+	}
+	return ctxErr
+}
+
+// splitOrgs splits a comma-separated organization spec into its trimmed,
+// non-empty members.
+func splitOrgs(spec string) []string {
+	var orgs []string
+	for _, org := range strings.Split(spec, ",") {
+		if org = strings.TrimSpace(org); org != "" {
+			orgs = append(orgs, org)
+		}
+	}
+	return orgs
 }
@@ -0,0 +1,164 @@
+// Copyright (c) 2026 Steve Taranto <staranto@gmail.com>.
+// SPDX-License-Identifier: Apache-2.0
+// no-cloc
+
+package command
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/go-tfe"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v3"
+
+	"github.com/staranto/tfctl/internal/backend/remote"
+	"github.com/staranto/tfctl/internal/cacheutil"
+	"github.com/staranto/tfctl/internal/meta"
+)
+
+// fakeTFERoute answers the requests to a fake TFE server whose path ends in
+// suffix. A list route, one with pages, answers page n of pages with the data
+// items render(r, n) returns and that page's pagination meta. Any other route
+// answers render(r, 0) as the whole document. An empty render answers 404.
+type fakeTFERoute struct {
+	suffix string
+	pages  int
+	render func(r *http.Request, page int) string
+}
+
+// orgWorkspacesRoute lists a single workspace named after the organization
+// for any organization.
+var orgWorkspacesRoute = fakeTFERoute{suffix: "/workspaces", pages: 1,
+	render: func(r *http.Request, _ int) string {
+		org := strings.Split(r.URL.Path, "/")[4]
+		return fmt.Sprintf(`{"id":"ws-%s","type":"workspaces","attributes":{"name":"%s-ws"}}`, org, org)
+	},
+}
+
+// newFakeTFEServer returns a server that answers the ping endpoint and the
+// first of routes matching each request, or 404 when none does.
+func newFakeTFEServer(t *testing.T, routes ...fakeTFERoute) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		if r.URL.Path == "/api/v2/ping" {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		for _, route := range routes {
+			if !strings.HasSuffix(r.URL.Path, route.suffix) {
+				continue
+			}
+			if route.pages == 0 {
+				if doc := route.render(r, 0); doc != "" {
+					fmt.Fprint(w, doc)
+					return
+				}
+				break
+			}
+			page, _ := strconv.Atoi(r.URL.Query().Get("page[number]"))
+			page = max(page, 1)
+			next := "null"
+			if page < route.pages {
+				next = strconv.Itoa(page + 1)
+			}
+			fmt.Fprintf(w, `{"data":[%s],"meta":{"pagination":`+
+				`{"current-page":%d,"next-page":%s,"total-pages":%d,"total-count":%d}}}`,
+				route.render(r, page), page, next, route.pages, route.pages)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// fakeTFEClient builds clients bound to srv, for remote.WithClient.
+func fakeTFEClient(srv *httptest.Server) func() (*tfe.Client, error) {
+	return func() (*tfe.Client, error) {
+		return tfe.NewClient(&tfe.Config{Address: srv.URL, Token: "test"})
+	}
+}
+
+// runOrgCommand runs a minimal command carrying the host/org/filter flags so
+// that action can exercise InitRemoteOrgQuery with parsed flag values.
+func runOrgCommand(t *testing.T, args []string, action cli.ActionFunc) {
+	t.Helper()
+	cmd := &cli.Command{
+		Name: "wq",
+		Flags: []cli.Flag{
+			NewHostFlag(meta.Profile{}, "wq"),
+			NewOrgFlag(meta.Profile{}, "wq"),
+			&cli.StringFlag{Name: "filter"},
+			&cli.BoolFlag{Name: "offline"},
+		},
+		Action: action,
+	}
+	require.NoError(t, cmd.Run(context.Background(), append([]string{"wq"}, args...)))
+}
+
+func TestInitRemoteOrgQuery_SharesClientAcrossOrgs(t *testing.T) {
+	srv := newFakeTFEServer(t, orgWorkspacesRoute)
+
+	constructed := 0
+	newClient := func() (*tfe.Client, error) {
+		constructed++
+		return fakeTFEClient(srv)()
+	}
+
+	var names []string
+	runOrgCommand(t, []string{"--org", "alpha, beta,gamma"}, func(ctx context.Context, cmd *cli.Command) error {
+		be, orgs, client, err := InitRemoteOrgQuery(ctx, cmd, remote.WithClient(newClient))
+		if err != nil {
+			return err
+		}
+		assert.Equal(t, []string{"alpha", "beta", "gamma"}, orgs)
+
+		fetcher := func(ctx context.Context, org string, opts *tfe.WorkspaceListOptions) ([]*tfe.Workspace, *tfe.Pagination, error) {
+			page, err := client.Workspaces.List(ctx, org, opts)
+			if err != nil {
+				return nil, nil, err
+			}
+			return page.Items, page.Pagination, nil
+		}
+
+		results, err := RemoteQueryFetcherFactory(be, orgs, fetcher, nil, "list workspaces")(ctx, cmd)
+		if err != nil {
+			return err
+		}
+		for _, ws := range results {
+			names = append(names, ws.Name)
+		}
+		return nil
+	})
+
+	assert.Equal(t, 1, constructed)
+	assert.Equal(t, []string{"alpha-ws", "beta-ws", "gamma-ws"}, names)
+}
+
+func TestInitRemoteOrgQuery_Offline(t *testing.T) {
+	newClient := func() (*tfe.Client, error) {
+		t.Fatal("offline must not build a client")
+		return nil, nil
+	}
+
+	runOrgCommand(t, []string{"--org", "acme", "--offline"}, func(ctx context.Context, cmd *cli.Command) error {
+		_, _, _, err := InitRemoteOrgQuery(ctx, cmd, remote.WithClient(newClient))
+		assert.ErrorIs(t, err, cacheutil.ErrOfflineMiss)
+		assert.ErrorContains(t, err, "wq queries the API directly")
+		return nil
+	})
+}
+
+func TestSplitOrgs(t *testing.T) {
+	assert.Equal(t, []string{"acme"}, splitOrgs("acme"))
+	assert.Equal(t, []string{"a", "b"}, splitOrgs(" a ,, b,"))
+	assert.Nil(t, splitOrgs(""))
+}
@@ -0,0 +1,56 @@
+// Copyright (c) 2026 Steve Taranto <staranto@gmail.com>.
+// SPDX-License-Identifier: Apache-2.0
+
+package command
+
+import (
+	"fmt"
+	"io"
+)
+
+// SourceError records the failure of a single source (e.g. an organization)
+// within an aggregated query.
+type SourceError struct {
+	Source string
+	Err    error
+}
+
+// Error implements the error interface.
+func (e SourceError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Source, e.Err)
+}
+
+// Unwrap returns the underlying error so callers can use errors.Is/As.
+func (e SourceError) Unwrap() error {
+	return e.Err
+}
+
+// PartialError is returned by aggregated fetches run with --partial when one
+// or more sources failed. The rows from the successful sources are returned
+// alongside it and are still emitted; the failures are rendered afterwards as
+// a separate error section.
+type PartialError struct {
+	Errors []SourceError
+	Total  int
+}
+
+// Error implements the error interface.
+func (e *PartialError) Error() string {
+	return fmt.Sprintf("%d of %d sources failed", len(e.Errors), e.Total)
+}
+
+// Unwrap returns the per-source errors so callers can use errors.Is/As.
+func (e *PartialError) Unwrap() []error {
+	errs := make([]error, len(e.Errors))
+	for i := range e.Errors {
+		errs[i] = e.Errors[i]
+	}
+	return errs
+}
+
+// Render writes one line per failed source to w.
+func (e *PartialError) Render(w io.Writer) {
+	for _, se := range e.Errors {
+		fmt.Fprintf(w, "error: %v\n", se)
+	}
+}
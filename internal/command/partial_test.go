@@ -0,0 +1,78 @@
+// Copyright (c) 2026 Steve Taranto <staranto@gmail.com>.
+// SPDX-License-Identifier: Apache-2.0
+// no-cloc
+
+package command
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/hashicorp/go-tfe"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v3"
+)
+
+var errBeta = errors.New("beta is down")
+
+// mixedFetcher returns one project per organization, failing for "beta".
+func mixedFetcher(_ context.Context, org string, _ *tfe.ProjectListOptions) ([]*tfe.Project, *tfe.Pagination, error) {
+	if org == "beta" {
+		return nil, nil, errBeta
+	}
+	return []*tfe.Project{{Name: org + "-proj"}}, &tfe.Pagination{}, nil
+}
+
+// runMixedFetch runs the fetch for orgs alpha, beta and gamma with the given
+// args and returns what the fetch produced.
+func runMixedFetch(t *testing.T, args ...string) ([]*tfe.Project, error) {
+	t.Helper()
+	var results []*tfe.Project
+	var fetchErr error
+	cmd := &cli.Command{
+		Name:  "pq",
+		Flags: []cli.Flag{NewPartialFlag()},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			fn := RemoteQueryFetcherFactory(nil, []string{"alpha", "beta", "gamma"}, mixedFetcher, nil, "list projects")
+			results, fetchErr = fn(ctx, cmd)
+			return nil
+		},
+	}
+	require.NoError(t, cmd.Run(context.Background(), append([]string{"pq"}, args...)))
+	return results, fetchErr
+}
+
+func TestRemoteQueryFetcherFactory_FailsBatchWithoutPartial(t *testing.T) {
+	results, err := runMixedFetch(t)
+	assert.ErrorIs(t, err, errBeta)
+	assert.Nil(t, results)
+
+	var partial *PartialError
+	assert.False(t, errors.As(err, &partial))
+}
+
+func TestRemoteQueryFetcherFactory_PartialKeepsSuccessfulRows(t *testing.T) {
+	results, err := runMixedFetch(t, "--partial")
+
+	var partial *PartialError
+	require.True(t, errors.As(err, &partial))
+	assert.ErrorIs(t, err, errBeta)
+	assert.Equal(t, 3, partial.Total)
+	require.Len(t, partial.Errors, 1)
+	assert.Equal(t, "org=beta", partial.Errors[0].Source)
+	assert.Equal(t, "1 of 3 sources failed", partial.Error())
+
+	var names []string
+	for _, p := range results {
+		names = append(names, p.Name)
+	}
+	assert.Equal(t, []string{"alpha-proj", "gamma-proj"}, names)
+
+	var buf bytes.Buffer
+	partial.Render(&buf)
+	assert.Contains(t, buf.String(), "error: org=beta: ")
+	assert.Contains(t, buf.String(), "beta is down")
+}
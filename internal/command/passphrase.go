@@ -0,0 +1,80 @@
+// Copyright (c) 2026 Steve Taranto <staranto@gmail.com>.
+// SPDX-License-Identifier: Apache-2.0
+
+package command
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/staranto/tfctl/internal/state"
+)
+
+// passphraseEnvVars are the environment variables, in order, that hold the
+// passphrase for OpenTofu encrypted state. TF_VAR_passphrase is what si has
+// always read.
+var passphraseEnvVars = []string{"TFCTL_PASSPHRASE", "TF_VAR_passphrase"}
+
+// decryptOptions returns the state.DecryptOptions given by cmd's flags.
+func decryptOptions(cmd *cli.Command) state.DecryptOptions {
+	return state.DecryptOptions{Command: cmd.String("decrypt-cmd")}
+}
+
+// resolvePassphrase returns the passphrase for OpenTofu encrypted state: the
+// one given on the command line, else the first of passphraseEnvVars set,
+// else one prompted for.
+func resolvePassphrase(cmd *cli.Command) (string, error) {
+	// First, look to the flag, file or stdin for passphrase value.
+	passphrase, err := flagPassphrase(cmd)
+	if err != nil {
+		return "", err
+	}
+
+	// Issue 14 - Next look in env and use it if found.
+	for _, name := range passphraseEnvVars {
+		if passphrase != "" {
+			break
+		}
+		passphrase = os.Getenv(name)
+	}
+
+	// Finally, prompt for passphrase
+	if passphrase == "" {
+		passphrase, _ = state.GetPassphrase()
+	}
+
+	return passphrase, nil
+}
+
+// flagPassphrase returns the passphrase given by --passphrase,
+// --passphrase-file or --passphrase-stdin, read from the root command's
+// Reader, or "" when none was given. A command without one of the flags, such
+// as si without --passphrase-stdin, simply never has it set.
+func flagPassphrase(cmd *cli.Command) (string, error) {
+	switch {
+	case cmd.String("passphrase") != "":
+		return cmd.String("passphrase"), nil
+	case cmd.String("passphrase-file") != "":
+		return state.ReadPassphraseFile(cmd.String("passphrase-file"))
+	case cmd.Bool("passphrase-stdin"):
+		return state.ReadPassphrase(cmd.Root().Reader)
+	}
+	return "", nil
+}
+
+// passphraseSourcesValidator rejects more than one passphrase source given on
+// the command line.
+func passphraseSourcesValidator(cmd *cli.Command) error {
+	sources := 0
+	for _, name := range []string{"passphrase", "passphrase-file", "passphrase-stdin"} {
+		if cmd.IsSet(name) {
+			sources++
+		}
+	}
+	if sources > 1 {
+		return fmt.Errorf("--passphrase, --passphrase-file and --passphrase-stdin are mutually exclusive")
+	}
+	return nil
+}
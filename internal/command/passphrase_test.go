@@ -0,0 +1,108 @@
+// Copyright (c) 2026 Steve Taranto <staranto@gmail.com>.
+// SPDX-License-Identifier: Apache-2.0
+// no-cloc
+
+package command
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v3"
+
+	"github.com/staranto/tfctl/internal/meta"
+)
+
+func TestFlagPassphrase(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "passphrase")
+	require.NoError(t, os.WriteFile(path, []byte("from-file\n"), 0o600))
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{name: "none", args: nil, want: ""},
+		{name: "flag", args: []string{"--passphrase", "from-flag"}, want: "from-flag"},
+		{name: "file", args: []string{"--passphrase-file", path}, want: "from-file"},
+		{name: "stdin", args: []string{"--passphrase-stdin"}, want: "from-stdin"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			cmd := &cli.Command{
+				Name:   "sq",
+				Reader: strings.NewReader("from-stdin\n"),
+				Flags: []cli.Flag{
+					&cli.StringFlag{Name: "passphrase"},
+					&cli.StringFlag{Name: "passphrase-file"},
+					&cli.BoolFlag{Name: "passphrase-stdin"},
+				},
+				Action: func(_ context.Context, cmd *cli.Command) error {
+					var err error
+					got, err = flagPassphrase(cmd)
+					return err
+				},
+			}
+			require.NoError(t, cmd.Run(context.Background(), append([]string{"sq"}, tt.args...)))
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+// TestResolvePassphrase_Env verifies sq and si read the same environment
+// variables, TFCTL_PASSPHRASE first, when no flag gives the passphrase.
+func TestResolvePassphrase_Env(t *testing.T) {
+	tests := []struct {
+		name  string
+		tfctl string
+		tfVar string
+		args  []string
+		want  string
+	}{
+		{name: "TFCTL_PASSPHRASE", tfctl: "tfctl", tfVar: "tf-var", want: "tfctl"},
+		{name: "TF_VAR_passphrase", tfVar: "tf-var", want: "tf-var"},
+		{name: "flag wins", tfctl: "tfctl", args: []string{"--passphrase", "flag"}, want: "flag"},
+	}
+
+	for _, tt := range tests {
+		for name, build := range map[string]func(meta.Meta) *cli.Command{
+			"si": siCommandBuilder,
+			"sq": sqCommandBuilder,
+		} {
+			t.Run(name+" "+tt.name, func(t *testing.T) {
+				t.Setenv("TFCTL_PASSPHRASE", tt.tfctl)
+				t.Setenv("TF_VAR_passphrase", tt.tfVar)
+
+				var got string
+				cmd := build(meta.Meta{})
+				cmd.Before = nil
+				cmd.Action = func(_ context.Context, cmd *cli.Command) error {
+					var err error
+					got, err = resolvePassphrase(cmd)
+					return err
+				}
+				require.NoError(t, cmd.Run(context.Background(), append([]string{name}, tt.args...)))
+				assert.Equal(t, tt.want, got)
+			})
+		}
+	}
+}
+
+// TestSi_PassphraseSourcesExclusive verifies si, like sq, takes one
+// passphrase source.
+func TestSi_PassphraseSourcesExclusive(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "passphrase")
+	require.NoError(t, os.WriteFile(path, []byte("from-file\n"), 0o600))
+
+	cmd := siCommandBuilder(meta.Meta{})
+	cmd.Action = func(context.Context, *cli.Command) error { return nil }
+	err := cmd.Run(context.Background(), []string{"si", "--passphrase", "x", "--passphrase-file", path})
+	assert.EqualError(t, err, "--passphrase, --passphrase-file and --passphrase-stdin are mutually exclusive")
+}
@@ -12,7 +12,6 @@ import (
 	"github.com/hashicorp/go-tfe"
 	"github.com/urfave/cli/v3"
 
-	"github.com/staranto/tfctl/internal/backend/remote"
 	"github.com/staranto/tfctl/internal/filters"
 	"github.com/staranto/tfctl/internal/meta"
 )
@@ -23,42 +22,33 @@ var pqDefaultAttrs = []string{".id", "name"}
 // projects for the selected organization, supports --tldr/--schema
 // short-circuit behavior, and emits output per common flags.
 func pqCommandAction(ctx context.Context, cmd *cli.Command) error {
-	be, org, client, err := InitRemoteOrgQuery(ctx, cmd)
+	be, orgs, client, err := InitRemoteOrgQuery(ctx, cmd)
 	if err != nil {
 		return err
 	}
 
-	fn := func(ctx context.Context, cmd *cli.Command) ([]*tfe.Project, error) {
-		options := tfe.ProjectListOptions{
-			ListOptions: DefaultListOptions,
+	// Create a fetcher that captures the client in a closure
+	fetcher := func(
+		ctx context.Context,
+		org string,
+		opts *tfe.ProjectListOptions,
+	) ([]*tfe.Project, *tfe.Pagination, error) {
+		page, err := client.Projects.List(ctx, org, opts)
+		if err != nil {
+			return nil, nil, err
 		}
-		return PaginateWithOptions(
-			ctx,
-			cmd,
-			&options,
-			func(ctx context.Context, opts *tfe.ProjectListOptions) (
-				[]*tfe.Project,
-				*tfe.Pagination,
-				error,
-			) {
-				page, err := client.Projects.List(ctx, org, opts)
-				if err != nil {
-					ctxErr := OrgQueryErrorContext(
-						be,
-						org,
-						"list projects",
-					)
-					return nil, nil, remote.FriendlyTFE(
-						err,
-						ctxErr,
-					)
-				}
-				return page.Items, page.Pagination, nil
-			},
-			pqServerSideFilterAugmenter,
-		)
+		return page.Items, page.Pagination, nil
 	}
 
+	// Use RemoteQueryFetcherFactory to handle pagination and augmentation
+	fn := RemoteQueryFetcherFactory(
+		be,
+		orgs,
+		fetcher,
+		pqServerSideFilterAugmenter,
+		"list projects",
+	)
+
 	return NewQueryActionRunner(
 		"pq",
 		reflect.TypeOf((*tfe.Project)(nil)).Elem(),
@@ -114,8 +104,9 @@ func pqCommandBuilder(meta meta.Meta) *cli.Command {
 		Name:  "pq",
 		Usage: "project query",
 		Flags: []cli.Flag{
-			NewHostFlag("pq", meta.Config.Source),
-			NewOrgFlag("pq", meta.Config.Source),
+			NewHostFlag(meta.Profile, "pq", meta.Config.Source),
+			NewOrgFlag(meta.Profile, "pq", meta.Config.Source),
+			NewPartialFlag(),
 		},
 		Action: pqCommandAction,
 		Meta:   meta,
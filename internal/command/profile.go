@@ -0,0 +1,76 @@
+// Copyright (c) 2026 Steve Taranto <staranto@gmail.com>.
+// SPDX-License-Identifier: Apache-2.0
+
+package command
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/urfave/cli/v3"
+
+	"github.com/staranto/tfctl/internal/config"
+	"github.com/staranto/tfctl/internal/meta"
+)
+
+// ProfileArg returns the profile named by the last --profile flag in args, in
+// either "--profile name" or "--profile=name" form, else $TFCTL_PROFILE, or ""
+// if there is neither. A --profile whose value is being shell completed names
+// no profile.
+func ProfileArg(args []string) string {
+	name := os.Getenv("TFCTL_PROFILE")
+	for i, arg := range args {
+		switch {
+		case arg == "--profile" && i+1 < len(args) && args[i+1] != completionFlag:
+			name = args[i+1]
+		case strings.HasPrefix(arg, "--profile="):
+			name = strings.TrimPrefix(arg, "--profile=")
+		}
+	}
+	return name
+}
+
+// loadProfile returns the named profile, or the zero profile when name is "".
+func loadProfile(name string) (meta.Profile, error) {
+	if name == "" {
+		return meta.Profile{}, nil
+	}
+
+	values, err := config.Profile(name)
+	if err != nil {
+		return meta.Profile{}, fmt.Errorf("failed to load profile (%s): %w", name, err)
+	}
+	log.Debugf("profile: name=%s values=%v", name, values)
+	return meta.Profile{Name: name, Values: values}, nil
+}
+
+// profileSources returns a Sources chain for flag that looks it up in profile
+// ahead of sources, so a profile value overrides env vars and config keys
+// while a flag given on the command line still wins.
+func profileSources(profile meta.Profile, flag string, sources ...cli.ValueSource) cli.ValueSourceChain {
+	return cli.NewValueSourceChain(append([]cli.ValueSource{&profileValueSource{profile: profile, flag: flag}}, sources...)...)
+}
+
+// profileValueSource is a cli.ValueSource that looks a flag up in a profile.
+type profileValueSource struct {
+	profile meta.Profile
+	flag    string
+}
+
+// Lookup implements cli.ValueSource.
+func (s *profileValueSource) Lookup() (string, bool) {
+	value, ok := s.profile.Values[s.flag]
+	return value, ok
+}
+
+// String implements fmt.Stringer.
+func (s *profileValueSource) String() string {
+	return fmt.Sprintf("profile %q", s.profile.Name)
+}
+
+// GoString implements fmt.GoStringer.
+func (s *profileValueSource) GoString() string {
+	return fmt.Sprintf("&profileValueSource{profile:%q, flag:%q}", s.profile.Name, s.flag)
+}
@@ -0,0 +1,78 @@
+// Copyright (c) 2026 Steve Taranto <staranto@gmail.com>.
+// SPDX-License-Identifier: Apache-2.0
+// no-cloc
+
+package command
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v3"
+
+	"github.com/staranto/tfctl/internal/config"
+)
+
+func TestProfileArg(t *testing.T) {
+	t.Setenv("TFCTL_PROFILE", "")
+	assert.Empty(t, ProfileArg([]string{"tfctl", "wq"}))
+	assert.Equal(t, "prod", ProfileArg([]string{"tfctl", "wq", "--profile", "prod"}))
+	assert.Equal(t, "stage", ProfileArg([]string{"tfctl", "wq", "--profile=prod", "--profile", "stage"}))
+	assert.Empty(t, ProfileArg([]string{"tfctl", "wq", "--profile", completionFlag}))
+
+	t.Setenv("TFCTL_PROFILE", "env")
+	assert.Equal(t, "env", ProfileArg([]string{"tfctl", "wq"}))
+	assert.Equal(t, "prod", ProfileArg([]string{"tfctl", "wq", "--profile=prod"}))
+}
+
+func TestInitApp_Profile(t *testing.T) {
+	cfg := filepath.Join(t.TempDir(), "tfctl.yaml")
+	require.NoError(t, os.WriteFile(cfg, []byte(
+		"org: global\nprofiles:\n  prod:\n    host: tfe.example.com\n    org: acme\n    output: json\n    titles: true\n"), 0o600))
+	t.Setenv("TFCTL_CFG_FILE", cfg)
+	t.Setenv("TFCTL_PROFILE", "")
+	t.Setenv("TFCTL_HOST", "env.example.com")
+	t.Cleanup(func() { config.Config = config.Type{} })
+
+	// run initializes the app for args and returns the flag values mq sees.
+	run := func(args ...string) (map[string]any, error) {
+		args = append([]string{"tfctl", "mq"}, args...)
+		app, err := InitApp(context.Background(), args, nil)
+		if err != nil {
+			return nil, err
+		}
+		got := map[string]any{}
+		findCommand(t, app, "mq").Action = func(_ context.Context, cmd *cli.Command) error {
+			for _, name := range []string{"host", "org", "output", "titles"} {
+				got[name] = cmd.Value(name)
+			}
+			return nil
+		}
+		return got, app.Run(context.Background(), args)
+	}
+
+	// The profile beats env vars and config keys, explicit flags beat the
+	// profile.
+	got, err := run("--profile", "prod", "--org", "explicit")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"host": "tfe.example.com", "org": "explicit", "output": "json", "titles": true}, got)
+
+	// Without a profile the chain falls through to env vars and config keys.
+	got, err = run()
+	require.NoError(t, err)
+	assert.Equal(t, "env.example.com", got["host"])
+	assert.Equal(t, "global", got["org"])
+	assert.Equal(t, false, got["titles"])
+
+	t.Setenv("TFCTL_PROFILE", "prod")
+	got, err = run()
+	require.NoError(t, err)
+	assert.Equal(t, "acme", got["org"])
+
+	_, err = run("--profile", "missing")
+	require.EqualError(t, err, "failed to load profile (missing): no profiles.missing in config")
+}
@@ -43,6 +43,10 @@ func psCommandAction(ctx context.Context, cmd *cli.Command) error {
 	meta := cmd.Metadata["meta"].(meta.Meta)
 	log.Debugf("Executing action for %v", meta.Args[1:])
 
+	if PrintConfigIfRequested(cmd, os.Stdout) {
+		return nil
+	}
+
 	header := "\nPlan action summary"
 	if cmd.String("filter") != "" {
 		header += " (filtered)"
@@ -106,9 +110,7 @@ func psCommandAction(ctx context.Context, cmd *cli.Command) error {
 	var raw bytes.Buffer
 	raw.Write(jsonData)
 
-	output.SliceDiceSpit(raw, attrList, cmd, "", os.Stdout, nil)
-
-	return nil
+	return output.SliceDiceSpit(raw, attrList, cmd, "", os.Stdout, nil)
 }
 
 // parsePlanOutput reads the plan input and extracts resource action lines.
@@ -168,10 +170,10 @@ func parsePlanOutput(input io.Reader) ([]PlanResource, error) {
 
 // psCommandBuilder constructs the "ps" subcommand.
 func psCommandBuilder(meta meta.Meta) *cli.Command {
-	flags := NewGlobalFlags("ps")
+	flags := NewGlobalFlags(meta.Profile, "ps")
 
 	// Remove the --attrs flag since ps doesn't use it.
-	var noAttrsFlags []cli.Flag
+	noAttrsFlags := []cli.Flag{NewPrintConfigFlag(), NewPrintSourcesFlag()}
 	for _, flag := range flags {
 		if flag.Names()[0] != "attrs" {
 			noAttrsFlags = append(noAttrsFlags, flag)
@@ -5,10 +5,14 @@ package command
 
 import (
 	"context"
+	"errors"
+	"os"
 	"reflect"
 
 	"github.com/apex/log"
 	"github.com/urfave/cli/v3"
+
+	"github.com/staranto/tfctl/internal/output"
 )
 
 // QueryActionRunner[T] encapsulates the common query action pattern for all
@@ -20,6 +24,10 @@ type QueryActionRunner[T any] struct {
 	SchemaType   reflect.Type
 	DefaultAttrs []string
 	FetchFn      func(context.Context, *cli.Command) ([]T, error)
+
+	// Computed, if set, returns attributes derived from a result, e.g. from
+	// its timestamps, which are added to its row before filtering and output.
+	Computed func(T) map[string]any
 }
 
 // Run executes the query action with the provided context and command.
@@ -43,17 +51,31 @@ func (qar *QueryActionRunner[T]) Run(
 	attrs := BuildAttrs(cmd, qar.DefaultAttrs...)
 	log.Debugf("attrs: %v", attrs)
 
-	// Step 4: Fetch data.
+	// Step 4: Fetch data. A *PartialError still carries rows to emit.
 	results, err := qar.FetchFn(ctx, cmd)
-	if err != nil {
+	var partial *PartialError
+	if err != nil && !errors.As(err, &partial) {
 		return err
 	}
 
 	// Step 5: Emit + return.
-	if err := EmitJSONAPISlice(results, attrs, cmd); err != nil {
-		return err
+	var computed []map[string]any
+	if qar.Computed != nil {
+		for _, result := range results {
+			computed = append(computed, qar.Computed(result))
+		}
+	}
+	// An empty result under --fail-on-empty still lets a partial failure be
+	// reported, which takes precedence.
+	emitErr := EmitJSONAPISlice(results, attrs, cmd, computed...)
+	if emitErr != nil && !errors.Is(emitErr, output.ErrEmpty) {
+		return emitErr
+	}
+	if partial != nil {
+		partial.Render(os.Stderr)
+		return partial
 	}
-	return nil
+	return emitErr
 }
 
 // NewQueryActionRunner creates a QueryActionRunner with the provided
@@ -5,6 +5,7 @@ package command
 
 import (
 	"context"
+	"os"
 
 	"github.com/urfave/cli/v3"
 
@@ -15,7 +16,7 @@ import (
 // subcommands (mq, pq, oq, svq, rq, wq) using a consistent pattern.
 // It accepts the command name, usage text, optional UsageText, custom flags,
 // the action handler, and meta. The builder automatically wires metadata,
-// adds tldr/schema flags, applies global flags, and sets up validators.
+// adds tldr/schema/print-config/print-sources flags, applies global flags, and sets up validators.
 type QueryCommandBuilder struct {
 	Name      string
 	Usage     string
@@ -35,12 +36,22 @@ func (qcb *QueryCommandBuilder) Build() *cli.Command {
 			"meta": qcb.Meta,
 		},
 		Flags: append(qcb.Flags, append([]cli.Flag{
-			tldrFlag,
-			schemaFlag,
-		}, NewGlobalFlags(qcb.Name)...)...),
+			NewPrintConfigFlag(),
+			NewPrintSourcesFlag(),
+			NewTldrFlag(),
+			NewSchemaFlag(),
+			NewDeepFlag(),
+		}, NewGlobalFlags(qcb.Meta.Profile, qcb.Name)...)...),
 		Before: func(ctx context.Context, c *cli.Command) (context.Context, error) {
 			return ctx, GlobalFlagsValidator(ctx, c)
 		},
-		Action: qcb.Action,
+		// --print-config is handled before the action so it works without
+		// credentials or a reachable host.
+		Action: func(ctx context.Context, c *cli.Command) error {
+			if PrintConfigIfRequested(c, os.Stdout) {
+				return nil
+			}
+			return qcb.Action(ctx, c)
+		},
 	}
 }
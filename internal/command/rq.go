@@ -5,12 +5,20 @@ package command
 
 import (
 	"context"
+	"fmt"
 	"reflect"
+	"slices"
+	"time"
 
+	"github.com/apex/log"
 	"github.com/hashicorp/go-tfe"
 	"github.com/urfave/cli/v3"
 
+	"github.com/staranto/tfctl/internal/backend"
+	"github.com/staranto/tfctl/internal/backend/remote"
+	"github.com/staranto/tfctl/internal/filters"
 	"github.com/staranto/tfctl/internal/meta"
+	"github.com/staranto/tfctl/internal/util"
 )
 
 // rqDefaultAttrs specifies the default attributes displayed for runs in
@@ -26,45 +34,163 @@ func rqCommandAction(ctx context.Context, cmd *cli.Command) error {
 		return err
 	}
 
-	// Create a fetcher that delegates to the backend
-	fetcher := func(
-		ctx context.Context,
-		org string,
-		opts *tfe.RunListOptions,
-	) ([]*tfe.Run, *tfe.Pagination, error) {
-		runs, err := be.Runs()
-		if err != nil {
-			return nil, nil, err
-		}
-		// Local backend doesn't support pagination, return all results
-		return runs, &tfe.Pagination{NextPage: 0}, nil
+	if cmd.Bool("all-workspaces") {
+		return rqAllWorkspaces(ctx, cmd, be)
 	}
 
-	// Use RemoteQueryFetcherFactory to handle augmentation
-	// (though local backend doesn't support it)
-	fn := RemoteQueryFetcherFactory(
-		nil, // no backend for error context (local backend)
-		"",  // no org needed
-		fetcher,
-		rqServerSideFilterAugmenter,
-		"list runs",
-	)
+	fn := func(ctx context.Context, cmd *cli.Command) ([]*tfe.Run, error) {
+		return be.Runs(RqServerSideFilterAugmenter)
+	}
 
-	return NewQueryActionRunner(
+	runner := NewQueryActionRunner(
 		"rq",
 		reflect.TypeOf((*tfe.Run)(nil)).Elem(),
 		rqDefaultAttrs,
 		fn,
-	).Run(ctx, cmd)
+	)
+	runner.Computed = rqDurations
+	return runner.Run(ctx, cmd)
+}
+
+// rqAllWorkspaces lists the runs of every workspace --all-workspaces selects
+// as one result set, each row carrying the workspace it came from.
+func rqAllWorkspaces(ctx context.Context, cmd *cli.Command, be backend.Backend) error {
+	fn, workspaceOf := AllWorkspacesFetcherFactory(be, rqWorkspaceRuns)
+
+	runner := NewQueryActionRunner(
+		"rq",
+		reflect.TypeOf((*tfe.Run)(nil)).Elem(),
+		append(slices.Clone(rqDefaultAttrs), "workspace"),
+		fn,
+	)
+	runner.Computed = func(run *tfe.Run) map[string]any {
+		computed := rqDurations(run)
+		computed["workspace"] = workspaceOf(run)
+		return computed
+	}
+	return runner.Run(ctx, cmd)
+}
+
+// rqWorkspaceRuns lists the runs of the workspace name, narrowed by the server-side run
+// filters and stopping once --limit runs are fetched.
+func rqWorkspaceRuns(
+	ctx context.Context,
+	cmd *cli.Command,
+	_ *remote.BackendRemote,
+	client *tfe.Client,
+	org string,
+	name string,
+) ([]*tfe.Run, error) {
+	limit := cmd.Int("limit")
+	options := &tfe.RunListForOrganizationOptions{
+		WorkspaceNames: name,
+		ListOptions:    tfe.ListOptions{PageNumber: 1, PageSize: util.PageSize(limit)},
+	}
+
+	// The augmenter appends repeated filters, so it's applied once here rather
+	// than before every page.
+	if err := RqServerSideFilterAugmenter(ctx, cmd, options); err != nil {
+		return nil, fmt.Errorf("failed to augment run options: %w", err)
+	}
+
+	return PaginateWithOptions(ctx, cmd, options,
+		func(ctx context.Context, opts *tfe.RunListForOrganizationOptions) ([]*tfe.Run, *tfe.Pagination, error) {
+			page, err := client.Runs.ListForOrganization(ctx, org, opts)
+			if err != nil {
+				return nil, nil, err
+			}
+			// The organization run list has no total count, so the pages are
+			// walked in order.
+			pagination := &tfe.Pagination{CurrentPage: page.CurrentPage, NextPage: page.NextPage}
+			return page.Items, limitedPagination(pagination, opts.PageSize, limit), nil
+		}, nil)
+}
+
+// rqDurations returns the computed duration attributes of a run, in whole
+// seconds: plan-duration from planning to the end of the plan, apply-duration
+// from applying to the end of the apply, and duration from creation to the
+// run's final status. A phase that hasn't started and finished is nil.
+func rqDurations(run *tfe.Run) map[string]any {
+	ts := run.StatusTimestamps
+	if ts == nil {
+		ts = &tfe.RunStatusTimestamps{}
+	}
+
+	planEnd := rqFirst(ts.PlannedAt, ts.PlannedAndFinishedAt, ts.PlannedAndSavedAt, ts.ErroredAt, ts.CanceledAt)
+	applyEnd := rqFirst(ts.AppliedAt, ts.ErroredAt, ts.CanceledAt, ts.ForceCanceledAt)
+	end := rqFirst(ts.AppliedAt, ts.PlannedAndFinishedAt, ts.PlannedAndSavedAt, ts.ErroredAt,
+		ts.CanceledAt, ts.ForceCanceledAt, ts.DiscardedAt)
+
+	return map[string]any{
+		"plan-duration":  rqSeconds(ts.PlanningAt, planEnd),
+		"apply-duration": rqSeconds(ts.ApplyingAt, applyEnd),
+		"duration":       rqSeconds(run.CreatedAt, end),
+	}
+}
+
+// rqFirst returns the first of times that is set.
+func rqFirst(times ...time.Time) time.Time {
+	for _, t := range times {
+		if !t.IsZero() {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// rqSeconds returns the whole seconds from start to end, or nil if either is
+// unset or end precedes start.
+func rqSeconds(start, end time.Time) any {
+	if start.IsZero() || end.IsZero() || end.Before(start) {
+		return nil
+	}
+	return int64(end.Sub(start) / time.Second)
 }
 
-// rqServerSideFilterAugmenter returns immediately without augmenting options.
-// Local backend queries do not support server-side filtering.
-func rqServerSideFilterAugmenter(
+// RqServerSideFilterAugmenter augments the RunListForOrganizationOptions with
+// server-side filters extracted from the --filter flag. Filters with
+// ServerSide=true and a key of status, source, operation, user or commit
+// populate the matching field in opts. Repeated status, source and operation
+// filters are joined into the comma-separated list the API expects. Filters
+// on any other key are ignored.
+// NOTE Like SvqServerSideFilterAugmenter, this func is public because it is
+// passed through the backend.Backend interface rather than a factory.
+func RqServerSideFilterAugmenter(
 	_ context.Context,
-	_ *cli.Command,
-	_ *tfe.RunListOptions,
+	cmd *cli.Command,
+	opts *tfe.RunListForOrganizationOptions,
 ) error {
+	spec := cmd.String("filter")
+	filterList := filters.BuildFilters(spec)
+
+	join := func(list, value string) string {
+		if list == "" {
+			return value
+		}
+		return list + "," + value
+	}
+
+	for _, f := range filterList {
+		// We only care about server-side filters.
+		if !f.ServerSide {
+			continue
+		}
+
+		switch f.Key {
+		case "status":
+			opts.Status = join(opts.Status, f.Value)
+		case "source":
+			opts.Source = join(opts.Source, f.Value)
+		case "operation":
+			opts.Operation = join(opts.Operation, f.Value)
+		case "user":
+			opts.User = f.Value
+		case "commit":
+			opts.Commit = f.Value
+		}
+	}
+
+	log.Debugf("opts after augmentation: %+v", opts)
 	return nil
 }
 
@@ -79,12 +205,17 @@ func rqCommandBuilder(meta meta.Meta) *cli.Command {
 			&cli.IntFlag{
 				Name:    "limit",
 				Aliases: []string{"l"},
-				Usage:   "limit runs returned",
-				Value:   99999,
+				Usage:   "limit runs returned, 0 for no limit",
+				Value:   0,
 			},
-			NewHostFlag("rq"),
-			NewOrgFlag("rq"),
-			workspaceFlag,
+			NewExplainBackendFlag(),
+			NewNoWorkspaceFileFlag(),
+			NewEnvFlag(),
+			NewAllWorkspacesFlag(),
+			NewHostFlag(meta.Profile, "rq"),
+			NewOrgFlag(meta.Profile, "rq"),
+			NewPartialFlag(),
+			NewWorkspaceFlag(meta.Profile),
 		},
 		Action: rqCommandAction,
 		Meta:   meta,
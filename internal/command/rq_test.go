@@ -0,0 +1,135 @@
+// Copyright (c) 2026 Steve Taranto <staranto@gmail.com>.
+// SPDX-License-Identifier: Apache-2.0
+// no-cloc
+
+package command
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-tfe"
+	"github.com/hashicorp/jsonapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v3"
+
+	"github.com/staranto/tfctl/internal/attrs"
+	"github.com/staranto/tfctl/internal/output"
+)
+
+func TestRqServerSideFilterAugmenter(t *testing.T) {
+	var opts tfe.RunListForOrganizationOptions
+
+	cmd := &cli.Command{
+		Name:  "rq",
+		Flags: []cli.Flag{&cli.StringFlag{Name: "filter"}},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			return RqServerSideFilterAugmenter(ctx, cmd, &opts)
+		},
+	}
+	args := []string{"rq", "--filter",
+		"_status=applied,_status=errored,_operation=plan_only,_user=steve,status=planned,_bogus=x"}
+	require.NoError(t, cmd.Run(context.Background(), args))
+
+	assert.Equal(t, "applied,errored", opts.Status)
+	assert.Equal(t, "plan_only", opts.Operation)
+	assert.Equal(t, "steve", opts.User)
+	assert.Empty(t, opts.Source)
+	assert.Empty(t, opts.Commit)
+}
+
+func TestRqDurations(t *testing.T) {
+	created := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) time.Time { return created.Add(d) }
+
+	tests := []struct {
+		name string
+		ts   *tfe.RunStatusTimestamps
+		want map[string]any
+	}{
+		{
+			name: "applied",
+			ts: &tfe.RunStatusTimestamps{
+				PlanningAt: at(10 * time.Second),
+				PlannedAt:  at(70 * time.Second),
+				ApplyingAt: at(2 * time.Minute),
+				AppliedAt:  at(14 * time.Minute),
+			},
+			want: map[string]any{"plan-duration": int64(60), "apply-duration": int64(720), "duration": int64(840)},
+		},
+		{
+			name: "plan only",
+			ts: &tfe.RunStatusTimestamps{
+				PlanningAt:           at(5 * time.Second),
+				PlannedAndFinishedAt: at(65 * time.Second),
+			},
+			want: map[string]any{"plan-duration": int64(60), "apply-duration": nil, "duration": int64(65)},
+		},
+		{
+			name: "plan errored",
+			ts: &tfe.RunStatusTimestamps{
+				PlanningAt: at(5 * time.Second),
+				ErroredAt:  at(35 * time.Second),
+			},
+			want: map[string]any{"plan-duration": int64(30), "apply-duration": nil, "duration": int64(35)},
+		},
+		{
+			name: "running",
+			ts: &tfe.RunStatusTimestamps{
+				PlanningAt: at(5 * time.Second),
+			},
+			want: map[string]any{"plan-duration": nil, "apply-duration": nil, "duration": nil},
+		},
+		{
+			name: "no timestamps",
+			want: map[string]any{"plan-duration": nil, "apply-duration": nil, "duration": nil},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			run := &tfe.Run{ID: "run-1", CreatedAt: created, StatusTimestamps: tt.ts}
+			assert.Equal(t, tt.want, rqDurations(run))
+		})
+	}
+}
+
+func TestRq_DurationFilter(t *testing.T) {
+	created := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	runs := []*tfe.Run{
+		{ID: "run-fast", CreatedAt: created, Status: tfe.RunApplied,
+			StatusTimestamps: &tfe.RunStatusTimestamps{AppliedAt: created.Add(3 * time.Minute)}},
+		{ID: "run-slow", CreatedAt: created, Status: tfe.RunApplied,
+			StatusTimestamps: &tfe.RunStatusTimestamps{AppliedAt: created.Add(25 * time.Minute)}},
+		{ID: "run-pending", CreatedAt: created, Status: tfe.RunPending},
+	}
+
+	var raw bytes.Buffer
+	require.NoError(t, jsonapi.MarshalPayload(&raw, runs))
+	computed := make([]map[string]any, 0, len(runs))
+	for _, run := range runs {
+		computed = append(computed, rqDurations(run))
+	}
+	require.NoError(t, mergeAttributes(&raw, computed))
+
+	var al attrs.AttrList
+	require.NoError(t, al.Set(".id,status,duration"))
+
+	cmd := &cli.Command{Flags: []cli.Flag{
+		&cli.StringFlag{Name: "output", Value: "json"},
+		&cli.StringFlag{Name: "filter", Value: "duration>10m"},
+	}}
+	buf := new(bytes.Buffer)
+	output.SliceDiceSpit(raw, al, cmd, "data", buf, nil)
+
+	got := strings.Join(strings.Fields(buf.String()), "")
+	assert.Contains(t, got, `"id":"run-slow"`)
+	assert.Contains(t, got, `"duration":1500`)
+	assert.Contains(t, got, `"status":"applied"`)
+	assert.NotContains(t, got, "run-fast")
+	assert.NotContains(t, got, "run-pending")
+}
@@ -0,0 +1,171 @@
+// Copyright (c) 2026 Steve Taranto <staranto@gmail.com>.
+// SPDX-License-Identifier: Apache-2.0
+
+package command
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+
+	"github.com/hashicorp/go-tfe"
+	"github.com/urfave/cli/v3"
+
+	"github.com/staranto/tfctl/internal/backend/remote"
+	"github.com/staranto/tfctl/internal/meta"
+)
+
+// rtqDefaultAttrs specifies the default attributes displayed for run task
+// results in the "rtq" command output.
+var rtqDefaultAttrs = []string{"task-name", "stage", "status"}
+
+// rtqStageOrder is the order in which the stages of a run execute.
+var rtqStageOrder = []tfe.Stage{tfe.PrePlan, tfe.PostPlan, tfe.PreApply, tfe.PostApply}
+
+// rtqResult is one row of the "rtq" listing: the result of a run task in one
+// stage of a run.
+type rtqResult struct {
+	ID               string `jsonapi:"primary,task-results"`
+	Run              string `jsonapi:"attr,run"`
+	Stage            string `jsonapi:"attr,stage"`
+	StageStatus      string `jsonapi:"attr,stage-status"`
+	TaskName         string `jsonapi:"attr,task-name"`
+	Status           string `jsonapi:"attr,status"`
+	EnforcementLevel string `jsonapi:"attr,enforcement-level"`
+	Message          string `jsonapi:"attr,message"`
+	URL              string `jsonapi:"attr,url"`
+}
+
+// rtqCommandAction is the action handler for the "rtq" subcommand. It reads
+// the task stages of --run, or of the workspace's current run, and emits one
+// row per task result.
+func rtqCommandAction(ctx context.Context, cmd *cli.Command) error {
+	be, err := InitLocalBackendQuery(ctx, cmd)
+	if err != nil {
+		return err
+	}
+
+	fn := func(ctx context.Context, cmd *cli.Command) ([]*rtqResult, error) {
+		rbe, ok := be.(*remote.BackendRemote)
+		if !ok {
+			return nil, fmt.Errorf("rtq requires a remote or cloud backend, not %s", be)
+		}
+
+		client, err := rbe.Client()
+		if err != nil {
+			return nil, err
+		}
+
+		runID := cmd.String("run")
+		if runID == "" {
+			workspace, err := rbe.Workspace()
+			if err != nil {
+				return nil, err
+			}
+			if workspace.CurrentRun == nil {
+				return nil, fmt.Errorf("workspace %s has no runs", workspace.Name)
+			}
+			runID = workspace.CurrentRun.ID
+		}
+
+		run, err := client.Runs.ReadWithOptions(ctx, runID, &tfe.RunReadOptions{
+			Include: []tfe.RunIncludeOpt{tfe.RunTaskStages},
+		})
+		if err != nil {
+			return nil, remote.FriendlyTFE(err, remote.ErrorContext{
+				Host:      rbe.Backend.Config.Hostname,
+				Operation: "read run " + runID,
+				Resource:  "run",
+			})
+		}
+
+		return rtqReduce(ctx, run, client.TaskResults.Read)
+	}
+
+	return NewQueryActionRunner(
+		"rtq",
+		reflect.TypeOf(rtqResult{}),
+		rtqDefaultAttrs,
+		fn,
+	).Run(ctx, cmd)
+}
+
+// rtqReduce flattens the task results of the run's stages into one row per
+// result, ordered by stage and task name. Results the stage payload carries
+// only by ID are read with read.
+func rtqReduce(
+	ctx context.Context,
+	run *tfe.Run,
+	read func(context.Context, string) (*tfe.TaskResult, error),
+) ([]*rtqResult, error) {
+	var rows []*rtqResult
+	for _, stage := range run.TaskStages {
+		for _, tr := range stage.TaskResults {
+			// A relation that was not included carries only its ID.
+			if tr.TaskName == "" && tr.Status == "" {
+				full, err := read(ctx, tr.ID)
+				if err != nil {
+					return nil, fmt.Errorf("failed to read task result %s: %w", tr.ID, err)
+				}
+				tr = full
+			}
+
+			rows = append(rows, &rtqResult{
+				ID:               tr.ID,
+				Run:              run.ID,
+				Stage:            string(stage.Stage),
+				StageStatus:      string(stage.Status),
+				TaskName:         tr.TaskName,
+				Status:           string(tr.Status),
+				EnforcementLevel: string(tr.WorkspaceTaskEnforcementLevel),
+				Message:          tr.Message,
+				URL:              tr.URL,
+			})
+		}
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		a, b := rows[i], rows[j]
+		if a.Stage != b.Stage {
+			return rtqStageIndex(a.Stage) < rtqStageIndex(b.Stage)
+		}
+		return a.TaskName < b.TaskName
+	})
+
+	return rows, nil
+}
+
+// rtqStageIndex returns the position of stage in rtqStageOrder. Unknown stages
+// sort last.
+func rtqStageIndex(stage string) int {
+	if i := slices.Index(rtqStageOrder, tfe.Stage(stage)); i >= 0 {
+		return i
+	}
+	return len(rtqStageOrder)
+}
+
+// rtqCommandBuilder constructs the cli.Command for "rtq", wiring metadata,
+// flags, and action handlers.
+func rtqCommandBuilder(meta meta.Meta) *cli.Command {
+	return (&QueryCommandBuilder{
+		Name:      "rtq",
+		Usage:     "run task result query",
+		UsageText: "tfctl rtq [RootDir] [options]",
+		Flags: []cli.Flag{
+			NewExplainBackendFlag(),
+			NewNoWorkspaceFileFlag(),
+			NewEnvFlag(),
+			NewHostFlag(meta.Profile, "rtq"),
+			NewOrgFlag(meta.Profile, "rtq"),
+			&cli.StringFlag{
+				Name:  "run",
+				Usage: "run ID to query (default the workspace's current run)",
+			},
+			NewWorkspaceFlag(meta.Profile),
+		},
+		Action: rtqCommandAction,
+		Meta:   meta,
+	}).Build()
+}
@@ -0,0 +1,119 @@
+// Copyright (c) 2026 Steve Taranto <staranto@gmail.com>.
+// SPDX-License-Identifier: Apache-2.0
+// no-cloc
+
+package command
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/hashicorp/go-tfe"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRtqReduce(t *testing.T) {
+	run := &tfe.Run{
+		ID: "run-1",
+		TaskStages: []*tfe.TaskStage{
+			{
+				Stage:  tfe.PostPlan,
+				Status: tfe.TaskStageFailed,
+				TaskResults: []*tfe.TaskResult{
+					{ID: "taskrs-3", TaskName: "snyk", Status: tfe.TaskFailed, Message: "2 high",
+						WorkspaceTaskEnforcementLevel: tfe.Mandatory},
+					{ID: "taskrs-2"},
+				},
+			},
+			{
+				Stage:       tfe.PrePlan,
+				Status:      tfe.TaskStagePassed,
+				TaskResults: []*tfe.TaskResult{{ID: "taskrs-1", TaskName: "lint", Status: tfe.TaskPassed}},
+			},
+		},
+	}
+
+	var reads []string
+	read := func(_ context.Context, id string) (*tfe.TaskResult, error) {
+		reads = append(reads, id)
+		return &tfe.TaskResult{ID: id, TaskName: "infracost", Status: tfe.TaskPassed,
+			WorkspaceTaskEnforcementLevel: tfe.Advisory}, nil
+	}
+
+	rows, err := rtqReduce(context.Background(), run, read)
+	require.NoError(t, err)
+
+	// Only the result carried by ID is read.
+	assert.Equal(t, []string{"taskrs-2"}, reads)
+
+	var keys []string
+	for _, r := range rows {
+		keys = append(keys, fmt.Sprintf("%s/%s/%s=%s", r.Run, r.Stage, r.TaskName, r.Status))
+	}
+	assert.Equal(t, []string{
+		"run-1/pre_plan/lint=passed",
+		"run-1/post_plan/infracost=passed",
+		"run-1/post_plan/snyk=failed",
+	}, keys)
+
+	assert.Equal(t, "failed", rows[2].StageStatus)
+	assert.Equal(t, "mandatory", rows[2].EnforcementLevel)
+	assert.Equal(t, "2 high", rows[2].Message)
+	assert.Equal(t, "advisory", rows[1].EnforcementLevel)
+}
+
+func TestRtqReduce_Edges(t *testing.T) {
+	rows, err := rtqReduce(context.Background(), &tfe.Run{ID: "run-1"}, nil)
+	require.NoError(t, err)
+	assert.Empty(t, rows)
+
+	run := &tfe.Run{TaskStages: []*tfe.TaskStage{{Stage: tfe.PreApply, TaskResults: []*tfe.TaskResult{{ID: "taskrs-1"}}}}}
+	_, err = rtqReduce(context.Background(), run, func(context.Context, string) (*tfe.TaskResult, error) {
+		return nil, assert.AnError
+	})
+	assert.ErrorIs(t, err, assert.AnError)
+	assert.ErrorContains(t, err, "taskrs-1")
+
+	assert.Equal(t, len(rtqStageOrder), rtqStageIndex("unknown"))
+}
+
+func TestRtqReduce_FromAPI(t *testing.T) {
+	srv := newFakeTFEServer(t,
+		fakeTFERoute{suffix: "/api/v2/runs/run-abc", render: func(r *http.Request, _ int) string {
+			assert.Equal(t, "task_stages", r.URL.Query().Get("include"))
+			return `{"data":{"id":"run-abc","type":"runs","attributes":{"status":"planned"},` +
+				`"relationships":{"task-stages":{"data":[{"id":"ts-1","type":"task-stages"}]}}},` +
+				`"included":[{"id":"ts-1","type":"task-stages","attributes":{"stage":"post_plan","status":"passed"},` +
+				`"relationships":{"task-results":{"data":[{"id":"taskrs-1","type":"task-results"}]}}}]}`
+		}},
+		fakeTFERoute{suffix: "/api/v2/task-results/taskrs-1", render: func(*http.Request, int) string {
+			return `{"data":{"id":"taskrs-1","type":"task-results","attributes":` +
+				`{"task-name":"snyk","status":"passed","message":"ok","workspace-task-enforcement-level":"advisory"}}}`
+		}},
+	)
+
+	client, err := tfe.NewClient(&tfe.Config{Address: srv.URL, Token: "test"})
+	require.NoError(t, err)
+
+	run, err := client.Runs.ReadWithOptions(context.Background(), "run-abc", &tfe.RunReadOptions{
+		Include: []tfe.RunIncludeOpt{tfe.RunTaskStages},
+	})
+	require.NoError(t, err)
+
+	rows, err := rtqReduce(context.Background(), run, client.TaskResults.Read)
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.Equal(t, rtqResult{
+		ID:               "taskrs-1",
+		Run:              "run-abc",
+		Stage:            "post_plan",
+		StageStatus:      "passed",
+		TaskName:         "snyk",
+		Status:           "passed",
+		EnforcementLevel: "advisory",
+		Message:          "ok",
+	}, *rows[0])
+}
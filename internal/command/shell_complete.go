@@ -0,0 +1,181 @@
+// Copyright (c) 2026 Steve Taranto <staranto@gmail.com>.
+// SPDX-License-Identifier: Apache-2.0
+
+package command
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/apex/log"
+	"github.com/hashicorp/go-tfe"
+	"github.com/urfave/cli/v3"
+
+	"github.com/staranto/tfctl/internal/backend/remote"
+	"github.com/staranto/tfctl/internal/config"
+)
+
+// completionFlag is appended by the shell to a partial command line to ask for
+// completion candidates instead of running the command. The static scripts in
+// completion.go use it to complete flag values from live data.
+const completionFlag = "--generate-shell-completion"
+
+// flagValueCompleter returns the ShellComplete callback shared by the tfctl
+// commands. When the word being completed is the value of --workspace, --org
+// or --host, the candidates come from the server, reached through a backend
+// built with opts, and the credentials file, and for --profile and --view
+// from the config file.
+// Otherwise it falls back to the cli default of offering flags and
+// subcommands. Errors are logged at debug level and yield no candidates so a
+// failed lookup never spills into the shell.
+func flagValueCompleter(opts ...remote.BackendRemoteOption) cli.ShellCompleteFunc {
+	return func(ctx context.Context, cmd *cli.Command) {
+		var (
+			values []string
+			err    error
+		)
+
+		switch completingFlag(GetMeta(cmd).Args) {
+		case "--workspace", "-w":
+			values, err = completeWorkspaces(ctx, cmd, opts)
+		case "--org":
+			values, err = completeOrgs(ctx, cmd, opts)
+		case "--host", "-h":
+			values, err = completeHosts()
+		case "--profile":
+			values = config.ProfileNames()
+		case "--view":
+			values = config.ViewNames(cmd.Name)
+		default:
+			cli.DefaultCompleteWithFlags(ctx, cmd)
+			return
+		}
+
+		if err != nil {
+			log.Debugf("flagValueCompleter: %v", err)
+			return
+		}
+
+		for _, v := range values {
+			fmt.Fprintln(cmd.Root().Writer, v)
+		}
+	}
+}
+
+// completingFlag returns the arg ahead of the trailing completion flag, which
+// is the flag whose value is being completed, or "" if args is not a
+// completion request.
+func completingFlag(args []string) string {
+	n := len(args)
+	if n < 2 || args[n-1] != completionFlag {
+		return ""
+	}
+	return args[n-2]
+}
+
+// completionBackend builds the remote backend used to look up completion
+// candidates. The RootDir backend supplies host and organization when it is a
+// remote backend, and --host and --org override it as they do for queries.
+// opts follow FromRootDir.
+func completionBackend(ctx context.Context, cmd *cli.Command, opts []remote.BackendRemoteOption) (*remote.BackendRemote, *tfe.Client, error) {
+	be, err := remote.NewBackendRemote(ctx, cmd, append([]remote.BackendRemoteOption{remote.FromRootDir(GetMeta(cmd).RootDir, false)}, opts...)...)
+	if err != nil {
+		return nil, nil, err
+	}
+	be.Backend.Config.Hostname = be.Host()
+
+	client, err := be.Client()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return be, client, nil
+}
+
+// completeWorkspaces returns the names of the workspaces in the resolved
+// organization(s).
+func completeWorkspaces(ctx context.Context, cmd *cli.Command, opts []remote.BackendRemoteOption) ([]string, error) {
+	be, client, err := completionBackend(ctx, cmd, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	org, err := be.Organization()
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, org := range splitOrgs(org) {
+		options := tfe.WorkspaceListOptions{ListOptions: DefaultListOptions}
+		workspaces, err := PaginateWithOptions(ctx, cmd, &options, func(
+			ctx context.Context,
+			opts *tfe.WorkspaceListOptions,
+		) ([]*tfe.Workspace, *tfe.Pagination, error) {
+			page, err := client.Workspaces.List(ctx, org, opts)
+			if err != nil {
+				return nil, nil, err
+			}
+			return page.Items, page.Pagination, nil
+		}, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, ws := range workspaces {
+			names = append(names, ws.Name)
+		}
+	}
+
+	slices.Sort(names)
+	return slices.Compact(names), nil
+}
+
+// completeOrgs returns the names of the organizations the token can see.
+func completeOrgs(ctx context.Context, cmd *cli.Command, opts []remote.BackendRemoteOption) ([]string, error) {
+	_, client, err := completionBackend(ctx, cmd, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	options := tfe.OrganizationListOptions{ListOptions: DefaultListOptions}
+	orgs, err := PaginateWithOptions(ctx, cmd, &options, func(
+		ctx context.Context,
+		opts *tfe.OrganizationListOptions,
+	) ([]*tfe.Organization, *tfe.Pagination, error) {
+		page, err := client.Organizations.List(ctx, opts)
+		if err != nil {
+			return nil, nil, err
+		}
+		return page.Items, page.Pagination, nil
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(orgs))
+	for _, org := range orgs {
+		names = append(names, org.Name)
+	}
+
+	slices.Sort(names)
+	return names, nil
+}
+
+// completeHosts returns the hosts with credentials in the Terraform
+// credentials file, plus the configured host and app.terraform.io.
+func completeHosts() ([]string, error) {
+	hosts, err := remote.CredentialHosts()
+	if err != nil {
+		return nil, err
+	}
+
+	if host, _ := config.GetString("host"); host != "" {
+		hosts = append(hosts, host)
+	}
+	hosts = append(hosts, "app.terraform.io")
+
+	slices.Sort(hosts)
+	return slices.Compact(hosts), nil
+}
@@ -0,0 +1,96 @@
+// Copyright (c) 2026 Steve Taranto <staranto@gmail.com>.
+// SPDX-License-Identifier: Apache-2.0
+// no-cloc
+
+package command
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/go-tfe"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v3"
+
+	"github.com/staranto/tfctl/internal/backend/remote"
+	"github.com/staranto/tfctl/internal/meta"
+)
+
+// runCompletion runs args as a completion request against a root command with
+// a single "wq" subcommand wired like InitApp wires the real ones, completing
+// with complete, and returns what was written.
+func runCompletion(t *testing.T, complete cli.ShellCompleteFunc, args ...string) string {
+	t.Helper()
+	args = append(append([]string{"tfctl", "wq"}, args...), completionFlag)
+
+	buf := new(bytes.Buffer)
+	app := &cli.Command{
+		Name:                       "tfctl",
+		EnableShellCompletion:      true,
+		ShellCompletionCommandName: "__complete",
+		Writer:                     buf,
+		Commands: []*cli.Command{{
+			Name: "wq",
+			Metadata: map[string]any{
+				"meta": meta.Meta{Args: args, RootDirSpec: meta.RootDirSpec{RootDir: t.TempDir()}},
+			},
+			Flags: []cli.Flag{
+				NewHostFlag(meta.Profile{}, "wq"),
+				NewOrgFlag(meta.Profile{}, "wq"),
+				NewWorkspaceFlag(meta.Profile{}),
+			},
+			ShellComplete: complete,
+			Action: func(context.Context, *cli.Command) error {
+				t.Fatal("action must not run in completion mode")
+				return nil
+			},
+		}},
+	}
+	require.NoError(t, app.Run(context.Background(), args))
+	return buf.String()
+}
+
+func TestCompletingFlag(t *testing.T) {
+	assert.Equal(t, "--org", completingFlag([]string{"tfctl", "wq", "--org", completionFlag}))
+	assert.Equal(t, "", completingFlag([]string{"tfctl", "wq", "--org"}))
+	assert.Equal(t, "", completingFlag([]string{completionFlag}))
+	assert.Equal(t, "", completingFlag(nil))
+}
+
+func TestCompleteFlagValues_Workspaces(t *testing.T) {
+	complete := flagValueCompleter(remote.WithClient(fakeTFEClient(newFakeTFEServer(t, orgWorkspacesRoute))))
+
+	assert.Equal(t, "alpha-ws\nbeta-ws\n", runCompletion(t, complete, "--org", "beta,alpha", "--workspace"))
+	assert.Equal(t, "alpha-ws\n", runCompletion(t, complete, "--org", "alpha", "-w"))
+}
+
+func TestCompleteFlagValues_Hosts(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	require.NoError(t, os.MkdirAll(filepath.Join(home, ".terraform.d"), 0o755))
+	require.NoError(t, os.WriteFile(
+		filepath.Join(home, ".terraform.d", "credentials.tfrc.json"),
+		[]byte(`{"credentials":{"tfe.example.com":{"token":"x"},"app.terraform.io":{"token":"y"}}}`),
+		0o600,
+	))
+
+	assert.Equal(t, "app.terraform.io\ntfe.example.com\n", runCompletion(t, flagValueCompleter(), "--host"))
+}
+
+func TestCompleteFlagValues_NoCredentials(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	assert.Equal(t, "app.terraform.io\n", runCompletion(t, flagValueCompleter(), "--host"))
+}
+
+func TestCompleteFlagValues_LookupErrorIsSilent(t *testing.T) {
+	complete := flagValueCompleter(remote.WithClient(func() (*tfe.Client, error) {
+		return nil, assert.AnError
+	}))
+
+	assert.Empty(t, runCompletion(t, complete, "--org", "acme", "--workspace"))
+}
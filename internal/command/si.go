@@ -32,14 +32,24 @@ func siCommandAction(ctx context.Context, cmd *cli.Command) error {
 	meta := cmd.Metadata["meta"].(meta.Meta)
 	log.Debugf("Executing action for %v", meta.Args[1:])
 
+	if PrintConfigIfRequested(cmd, os.Stdout) {
+		return nil
+	}
+
 	config.Config.Namespace = "si"
 
 	// Use the same backend detection and state loading as sq
-	stateData, err := state.LoadStateData(ctx, cmd, meta.RootDir)
+	stateData, err := state.LoadStateData(ctx, cmd, meta.RootDir, func() (string, error) {
+		return resolvePassphrase(cmd)
+	})
 	if err != nil {
 		return err
 	}
 
+	if cmd.Bool("browse") {
+		return runSiBrowser(stateData)
+	}
+
 	// Run interactive console
 	return runSiInteractiveConsole(stateData)
 }
@@ -328,19 +338,35 @@ func siCommandBuilder(meta meta.Meta) *cli.Command {
 			"meta": meta,
 		},
 		Flags: append([]cli.Flag{
+			&cli.BoolFlag{
+				Name:  "browse",
+				Usage: "browse resources in a filterable list instead of the query console",
+			},
+			NewDecryptCmdFlag(),
+			NewExplainBackendFlag(),
+			NewNoWorkspaceFileFlag(),
+			NewEnvFlag(),
+			NewOfflineFlag(meta.Profile),
 			&cli.StringFlag{
 				Name:    "passphrase",
 				Aliases: []string{"p"},
 				Usage:   "passphrase for encrypted state files",
 				Value:   "",
 			},
+			// The console reads stdin, so there's no --passphrase-stdin.
+			NewPassphraseFileFlag(),
+			NewPrintConfigFlag(),
+			NewPrintSourcesFlag(),
 			&cli.StringFlag{
 				Name:        "sv",
 				Usage:       "state version to query",
 				Value:       "0",
 				HideDefault: true,
 			},
-		}, NewGlobalFlags("si")...),
+		}, NewGlobalFlags(meta.Profile, "si")...),
+		Before: func(ctx context.Context, cmd *cli.Command) (context.Context, error) {
+			return ctx, passphraseSourcesValidator(cmd)
+		},
 		Action: siCommandAction,
 	}
 }
@@ -0,0 +1,335 @@
+// Copyright (c) 2026 Steve Taranto <staranto@gmail.com>.
+// SPDX-License-Identifier: Apache-2.0
+
+package command
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/atotto/clipboard"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/tidwall/gjson"
+
+	"github.com/staranto/tfctl/internal/attrs"
+	"github.com/staranto/tfctl/internal/filters"
+	"github.com/staranto/tfctl/internal/output"
+)
+
+// siBrowseDefaultAttrs mirrors the sq defaults so that --filter specs written
+// for sq select the same resources in the browser.
+var siBrowseDefaultAttrs = []string{"!.mode", "!.type", ".resource", "id", "name"}
+
+// siBrowseChrome is the number of lines the list view uses for its header and
+// footer.
+const siBrowseChrome = 4
+
+// siBrowseModel is the Bubble Tea model for "si --browse". It lists the state's
+// resource instances, as flattened for sq, and shows the attributes of the one
+// selected.
+type siBrowseModel struct {
+	rows    []gjson.Result // Every resource instance.
+	visible []int          // Indexes into rows that pass the filter.
+	cursor  int            // Index into visible.
+	offset  int            // First visible row on screen.
+	height  int
+
+	filter    textinput.Model
+	filtering bool
+	spec      string
+
+	detail       bool
+	detailLines  []string
+	detailOffset int
+
+	status    string                  // Result of the last copy, shown until the next key.
+	clipboard func(text string) error // Copies text to the system clipboard.
+}
+
+// newSiBrowseModel flattens the state into one row per resource instance.
+func newSiBrowseModel(stateData map[string]interface{}) (siBrowseModel, error) {
+	doc, err := json.Marshal(stateData)
+	if err != nil {
+		return siBrowseModel{}, fmt.Errorf("failed to marshal state: %w", err)
+	}
+
+	ti := textinput.New()
+	ti.Prompt = "/"
+	ti.CharLimit = 2048
+
+	m := siBrowseModel{
+		rows:      gjson.ParseBytes(output.FlattenState(doc)).Array(),
+		height:    24, //nolint:mnd
+		filter:    ti,
+		clipboard: clipboard.WriteAll,
+	}
+	m.applyFilter("")
+	return m, nil
+}
+
+// applyFilter keeps the rows matching spec, which uses the --filter syntax.
+// Filter keys other than the sq defaults are looked up under the instance
+// attributes, as if they had been added with --attrs.
+func (m *siBrowseModel) applyFilter(spec string) {
+	m.spec = spec
+	m.visible = m.visible[:0]
+	m.cursor, m.offset = 0, 0
+
+	if spec == "" {
+		for i := range m.rows {
+			m.visible = append(m.visible, i)
+		}
+		return
+	}
+
+	var al attrs.AttrList
+	_ = al.Set(strings.Join(siBrowseDefaultAttrs, ","))
+	for _, f := range filters.BuildFilters(spec) {
+		_ = al.Set(f.Key)
+	}
+
+	// Flattened addresses are unique, so the matching rows are found again by
+	// their resource address.
+	raw := make([]string, len(m.rows))
+	for i, row := range m.rows {
+		raw[i] = row.Raw
+	}
+	matched := make(map[interface{}]bool)
+	candidates := gjson.Parse("[" + strings.Join(raw, ",") + "]")
+	for _, row := range filters.FilterDataset(candidates, al, spec) {
+		matched[row["resource"]] = true
+	}
+
+	for i, row := range m.rows {
+		if matched[row.Get("resource").String()] {
+			m.visible = append(m.visible, i)
+		}
+	}
+}
+
+func (m siBrowseModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m siBrowseModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.height = msg.Height
+		m.scroll()
+		return m, nil
+
+	case tea.KeyMsg:
+		if msg.String() == "ctrl+c" {
+			return m, tea.Quit
+		}
+
+		m.status = ""
+		if !m.filtering && len(m.visible) > 0 {
+			switch msg.String() {
+			case "y":
+				m.copy(m.rows[m.visible[m.cursor]].Get("resource").String(), "address")
+				return m, nil
+			case "Y":
+				m.copy(strings.Join(siBrowseDetail(m.rows[m.visible[m.cursor]]), "\n"), "attributes")
+				return m, nil
+			}
+		}
+
+		switch {
+		case m.filtering:
+			return m.updateFilter(msg)
+		case m.detail:
+			return m.updateDetail(msg)
+		default:
+			return m.updateList(msg)
+		}
+	}
+
+	return m, nil
+}
+
+// updateList handles keys while the resource list is shown.
+func (m siBrowseModel) updateList(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "q":
+		return m, tea.Quit
+	case "up", "k":
+		m.cursor--
+	case "down", "j":
+		m.cursor++
+	case "pgup":
+		m.cursor -= m.pageSize()
+	case "pgdown":
+		m.cursor += m.pageSize()
+	case "home", "g":
+		m.cursor = 0
+	case "end", "G":
+		m.cursor = len(m.visible) - 1
+	case "/":
+		m.filtering = true
+		m.filter.SetValue(m.spec)
+		m.filter.CursorEnd()
+		return m, m.filter.Focus()
+	case "esc":
+		m.applyFilter("")
+	case "enter", "right", "l":
+		if len(m.visible) > 0 {
+			m.detail = true
+			m.detailOffset = 0
+			m.detailLines = siBrowseDetail(m.rows[m.visible[m.cursor]])
+		}
+	}
+
+	m.scroll()
+	return m, nil
+}
+
+// updateFilter handles keys while the filter is being edited. Enter applies
+// it and esc leaves the current filter in place.
+func (m siBrowseModel) updateFilter(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "enter":
+		m.filtering = false
+		m.filter.Blur()
+		m.applyFilter(strings.TrimSpace(m.filter.Value()))
+		return m, nil
+	case "esc":
+		m.filtering = false
+		m.filter.Blur()
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.filter, cmd = m.filter.Update(msg)
+	return m, cmd
+}
+
+// updateDetail handles keys while a resource's attributes are shown.
+func (m siBrowseModel) updateDetail(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	maxOffset := max(len(m.detailLines)-m.pageSize(), 0)
+
+	switch msg.String() {
+	case "q":
+		return m, tea.Quit
+	case "esc", "left", "h", "backspace":
+		m.detail = false
+	case "up", "k":
+		m.detailOffset--
+	case "down", "j":
+		m.detailOffset++
+	case "pgup":
+		m.detailOffset -= m.pageSize()
+	case "pgdown":
+		m.detailOffset += m.pageSize()
+	}
+
+	m.detailOffset = min(max(m.detailOffset, 0), maxOffset)
+	return m, nil
+}
+
+// copy puts text on the clipboard and reports the outcome in the footer.
+// Without a clipboard, e.g. over SSH, the footer says so instead of failing.
+func (m *siBrowseModel) copy(text, what string) {
+	if err := m.clipboard(text); err != nil {
+		m.status = "clipboard unavailable: " + err.Error()
+		return
+	}
+	m.status = "copied " + what
+}
+
+// pageSize is the number of rows that fit between the header and footer.
+func (m siBrowseModel) pageSize() int {
+	return max(m.height-siBrowseChrome, 1)
+}
+
+// scroll clamps the cursor to the visible rows and moves the window so the
+// cursor stays on screen.
+func (m *siBrowseModel) scroll() {
+	m.cursor = min(max(m.cursor, 0), max(len(m.visible)-1, 0))
+
+	page := m.pageSize()
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+	if m.cursor >= m.offset+page {
+		m.offset = m.cursor - page + 1
+	}
+}
+
+func (m siBrowseModel) View() string {
+	titleStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#623CE4")).Bold(true)
+	helpStyle := lipgloss.NewStyle().Faint(true)
+
+	var lines []string
+
+	if m.detail {
+		row := m.rows[m.visible[m.cursor]]
+		lines = append(lines, titleStyle.Render(row.Get("resource").String()), "")
+		end := min(m.detailOffset+m.pageSize(), len(m.detailLines))
+		lines = append(lines, m.detailLines[m.detailOffset:end]...)
+		lines = append(lines, "", m.footer(helpStyle, "↑/↓ scroll • y copy address • Y copy attributes • esc back • q quit"))
+		return strings.Join(lines, "\n")
+	}
+
+	header := fmt.Sprintf("%d of %d resources", len(m.visible), len(m.rows))
+	if m.spec != "" {
+		header += "  filter: " + m.spec
+	}
+	lines = append(lines, titleStyle.Render(header), "")
+
+	end := min(m.offset+m.pageSize(), len(m.visible))
+	for i := m.offset; i < end; i++ {
+		cursor := "  "
+		if i == m.cursor {
+			cursor = "> "
+		}
+		lines = append(lines, cursor+m.rows[m.visible[i]].Get("resource").String())
+	}
+
+	lines = append(lines, "")
+	if m.filtering {
+		lines = append(lines, m.filter.View())
+	} else {
+		lines = append(lines, m.footer(helpStyle, "↑/↓ move • enter inspect • / filter • y copy address • Y copy attributes • esc clear filter • q quit"))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// footer returns the status of the last copy, if any, or the help text.
+func (m siBrowseModel) footer(style lipgloss.Style, help string) string {
+	if m.status != "" {
+		return m.status
+	}
+	return style.Render(help)
+}
+
+// siBrowseDetail renders the attributes of a resource instance as indented
+// JSON, one line per element.
+func siBrowseDetail(row gjson.Result) []string {
+	attributes := row.Get("attributes")
+	if !attributes.Exists() {
+		return []string{"(no attributes)"}
+	}
+
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, []byte(attributes.Raw), "", "  "); err != nil {
+		return []string{attributes.Raw}
+	}
+	return strings.Split(buf.String(), "\n")
+}
+
+// runSiBrowser launches the resource browser for stateData.
+func runSiBrowser(stateData map[string]interface{}) error {
+	m, err := newSiBrowseModel(stateData)
+	if err != nil {
+		return err
+	}
+
+	_, err = tea.NewProgram(m, tea.WithAltScreen()).Run()
+	return err
+}
@@ -0,0 +1,197 @@
+// Copyright (c) 2026 Steve Taranto <staranto@gmail.com>.
+// SPDX-License-Identifier: Apache-2.0
+// no-cloc
+
+package command
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// siBrowseState is a state with four resource instances, two of them from
+// one counted resource.
+const siBrowseState = `{"version":4,"resources":[
+	{"mode":"managed","type":"aws_instance","name":"web","instances":[
+		{"index_key":0,"attributes":{"id":"i-1","instance_type":"t3.micro"}},
+		{"index_key":1,"attributes":{"id":"i-2","instance_type":"t3.large"}}]},
+	{"mode":"managed","type":"aws_s3_bucket","name":"logs","instances":[
+		{"attributes":{"id":"logs-bucket","tags":{"env":"prod"}}}]},
+	{"mode":"data","type":"aws_ami","name":"ubuntu","instances":[
+		{"attributes":{"id":"ami-1"}}]}
+]}`
+
+func newTestSiBrowseModel(t *testing.T) siBrowseModel {
+	t.Helper()
+	var stateData map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(siBrowseState), &stateData))
+	m, err := newSiBrowseModel(stateData)
+	require.NoError(t, err)
+	return m
+}
+
+// press sends each key to the model in turn.
+func press(m siBrowseModel, keys ...string) siBrowseModel {
+	for _, k := range keys {
+		var msg tea.KeyMsg
+		switch k {
+		case "enter":
+			msg = tea.KeyMsg{Type: tea.KeyEnter}
+		case "esc":
+			msg = tea.KeyMsg{Type: tea.KeyEsc}
+		case "up":
+			msg = tea.KeyMsg{Type: tea.KeyUp}
+		case "down":
+			msg = tea.KeyMsg{Type: tea.KeyDown}
+		default:
+			msg = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+		}
+		next, _ := m.Update(msg)
+		m = next.(siBrowseModel)
+	}
+	return m
+}
+
+// visibleAddresses returns the addresses of the rows passing the filter.
+func visibleAddresses(m siBrowseModel) []string {
+	var addrs []string
+	for _, i := range m.visible {
+		addrs = append(addrs, m.rows[i].Get("resource").String())
+	}
+	return addrs
+}
+
+func TestSiBrowse_Rows(t *testing.T) {
+	m := newTestSiBrowseModel(t)
+	assert.Equal(t, []string{
+		"aws_instance.web[0]",
+		"aws_instance.web[1]",
+		"aws_s3_bucket.logs",
+		"data.aws_ami.ubuntu",
+	}, visibleAddresses(m))
+	assert.Contains(t, m.View(), "4 of 4 resources")
+	assert.Contains(t, m.View(), "> aws_instance.web[0]")
+}
+
+func TestSiBrowse_Filter(t *testing.T) {
+	tests := []struct {
+		spec string
+		want []string
+	}{
+		{spec: "resource@web", want: []string{"aws_instance.web[0]", "aws_instance.web[1]"}},
+		{spec: "type=aws_s3_bucket", want: []string{"aws_s3_bucket.logs"}},
+		{spec: "mode=data", want: []string{"data.aws_ami.ubuntu"}},
+		// Keys beyond the sq defaults are instance attributes.
+		{spec: "instance_type=t3.large", want: []string{"aws_instance.web[1]"}},
+		{spec: "id^i-,instance_type@micro", want: []string{"aws_instance.web[0]"}},
+		{spec: "id=nope", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			m := newTestSiBrowseModel(t)
+			m.applyFilter(tt.spec)
+			assert.Equal(t, tt.want, visibleAddresses(m))
+		})
+	}
+}
+
+func TestSiBrowse_Keys(t *testing.T) {
+	m := newTestSiBrowseModel(t)
+
+	// The cursor stops at both ends of the list.
+	m = press(m, "up")
+	assert.Equal(t, 0, m.cursor)
+	m = press(m, "down", "down", "down", "down", "down")
+	assert.Equal(t, 3, m.cursor)
+
+	// / edits the filter, enter applies it and the cursor returns to the top.
+	m = press(m, "/", "t", "y", "p", "e", "=", "a", "w", "s", "_", "i", "n", "s", "t", "a", "n", "c", "e")
+	assert.True(t, m.filtering)
+	assert.Len(t, m.visible, 4, "the filter applies on enter")
+	m = press(m, "enter")
+	assert.False(t, m.filtering)
+	assert.Equal(t, "type=aws_instance", m.spec)
+	assert.Equal(t, []string{"aws_instance.web[0]", "aws_instance.web[1]"}, visibleAddresses(m))
+	assert.Equal(t, 0, m.cursor)
+	assert.Contains(t, m.View(), "2 of 4 resources  filter: type=aws_instance")
+
+	// Enter drills into the selected resource's attributes, esc goes back.
+	m = press(m, "down", "enter")
+	require.True(t, m.detail)
+	view := m.View()
+	assert.Contains(t, view, "aws_instance.web[1]")
+	assert.Contains(t, view, `"instance_type": "t3.large"`)
+	m = press(m, "esc")
+	assert.False(t, m.detail)
+	assert.Equal(t, 1, m.cursor)
+
+	// Esc while editing keeps the applied filter, esc in the list clears it.
+	m = press(m, "/", "x", "esc")
+	assert.Equal(t, "type=aws_instance", m.spec)
+	m = press(m, "esc")
+	assert.Equal(t, "", m.spec)
+	assert.Len(t, m.visible, 4)
+
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")})
+	require.NotNil(t, cmd)
+	assert.Equal(t, tea.Quit(), cmd())
+}
+
+func TestSiBrowse_Scroll(t *testing.T) {
+	m := newTestSiBrowseModel(t)
+	next, _ := m.Update(tea.WindowSizeMsg{Height: siBrowseChrome + 2})
+	m = next.(siBrowseModel)
+
+	m = press(m, "down", "down", "down")
+	assert.Equal(t, 2, m.offset)
+	lines := strings.Split(m.View(), "\n")
+	assert.Equal(t, []string{"  aws_s3_bucket.logs", "> data.aws_ami.ubuntu"}, lines[2:4])
+
+	m = press(m, "g")
+	assert.Equal(t, 0, m.offset)
+}
+
+func TestSiBrowse_Copy(t *testing.T) {
+	var copied []string
+	m := newTestSiBrowseModel(t)
+	m.clipboard = func(text string) error {
+		copied = append(copied, text)
+		return nil
+	}
+	m = press(m, "down", "down", "y")
+	assert.Equal(t, []string{"aws_s3_bucket.logs"}, copied)
+	assert.Contains(t, m.View(), "copied address")
+
+	// The status lasts until the next key.
+	m = press(m, "up")
+	assert.NotContains(t, m.View(), "copied address")
+
+	m = press(m, "down", "enter", "Y")
+	require.True(t, m.detail)
+	assert.Equal(t, strings.Join(siBrowseDetail(m.rows[2]), "\n"), copied[1])
+	assert.Contains(t, m.View(), "copied attributes")
+
+	// y is typed into the filter while it is being edited.
+	m = press(m, "esc", "/", "y")
+	assert.Len(t, copied, 2)
+	assert.Equal(t, "y", m.filter.Value())
+
+	// Without a clipboard the browser keeps going and says why.
+	m.clipboard = func(string) error { return errors.New("no clipboard utilities available") }
+	m = press(m, "esc", "y")
+	assert.Contains(t, m.View(), "clipboard unavailable: no clipboard utilities available")
+}
+
+func TestSiBrowseDetail(t *testing.T) {
+	m := newTestSiBrowseModel(t)
+	assert.Equal(t, []string{"{", `  "id": "logs-bucket",`, `  "tags": {`, `    "env": "prod"`, "  }", "}"},
+		siBrowseDetail(m.rows[2]))
+	assert.Equal(t, []string{"(no attributes)"}, siBrowseDetail(m.rows[0].Get("nope")))
+}
@@ -0,0 +1,225 @@
+// Copyright (c) 2026 Steve Taranto <staranto@gmail.com>.
+// SPDX-License-Identifier: Apache-2.0
+
+package command
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+
+	"github.com/hashicorp/go-tfe"
+	"github.com/urfave/cli/v3"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/staranto/tfctl/internal/backend/remote"
+	"github.com/staranto/tfctl/internal/config"
+	"github.com/staranto/tfctl/internal/meta"
+	"github.com/staranto/tfctl/internal/svutil"
+)
+
+// soqDefaultAttrs specifies the default attributes displayed for state outputs
+// in the "soq" command output.
+var soqDefaultAttrs = []string{"workspace", "name", "value"}
+
+// soqOutput is one row of the "soq" listing: a current state output of a
+// workspace. Rows are keyed by organization, workspace and output name.
+type soqOutput struct {
+	ID           string `jsonapi:"primary,state-version-outputs"`
+	Organization string `jsonapi:"attr,organization"`
+	Workspace    string `jsonapi:"attr,workspace"`
+	Name         string `jsonapi:"attr,name"`
+	Type         string `jsonapi:"attr,type"`
+	Sensitive    bool   `jsonapi:"attr,sensitive"`
+	Value        any    `jsonapi:"attr,value"`
+}
+
+// soqCommandAction is the action handler for the "soq" subcommand. It lists
+// the workspaces of the organization(s), reads the current state outputs of
+// each concurrently, and emits one row per output.
+func soqCommandAction(ctx context.Context, cmd *cli.Command) error {
+	fn := func(ctx context.Context, cmd *cli.Command) ([]*soqOutput, error) {
+		be, orgs, client, err := InitRemoteOrgQuery(ctx, cmd)
+		if err != nil {
+			return nil, err
+		}
+
+		workspaces, err := RemoteQueryFetcherFactory(
+			be,
+			orgs,
+			func(ctx context.Context, org string, opts *tfe.WorkspaceListOptions) ([]*tfe.Workspace, *tfe.Pagination, error) {
+				page, err := client.Workspaces.List(ctx, org, opts)
+				if err != nil {
+					return nil, nil, err
+				}
+				return page.Items, page.Pagination, nil
+			},
+			nil,
+			"list workspaces",
+		)(ctx, cmd)
+
+		var partial *PartialError
+		if err != nil && !errors.As(err, &partial) {
+			return nil, err
+		}
+
+		parallelism, _ := config.GetInt("parallelism", svutil.DefaultParallelism)
+		outputs, failures := soqFetchOutputs(ctx, workspaces, parallelism, soqOutputReader(be, client))
+		if len(failures) > 0 {
+			if !cmd.Bool("partial") {
+				return nil, failures[0].Err
+			}
+			if partial == nil {
+				partial = &PartialError{Total: len(orgs)}
+			}
+			partial.Errors = append(partial.Errors, failures...)
+			partial.Total += len(workspaces)
+		}
+
+		rows := soqReduce(workspaces, outputs)
+		if partial != nil {
+			return rows, partial
+		}
+		return rows, nil
+	}
+
+	return NewQueryActionRunner(
+		"soq",
+		reflect.TypeOf(soqOutput{}),
+		soqDefaultAttrs,
+		fn,
+	).Run(ctx, cmd)
+}
+
+// soqOutputReader returns a reader for the current state outputs of a
+// workspace. A workspace without state has no outputs rather than an error.
+func soqOutputReader(
+	be *remote.BackendRemote,
+	client *tfe.Client,
+) func(context.Context, *tfe.Workspace) ([]*tfe.StateVersionOutput, error) {
+	return func(ctx context.Context, ws *tfe.Workspace) ([]*tfe.StateVersionOutput, error) {
+		list, err := client.StateVersionOutputs.ReadCurrent(ctx, ws.ID)
+		if errors.Is(err, tfe.ErrResourceNotFound) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, remote.FriendlyTFE(err, remote.ErrorContext{
+				Host:      be.Backend.Config.Hostname,
+				Org:       soqOrganization(ws),
+				Workspace: ws.Name,
+				Operation: "read current state version outputs",
+				Resource:  "workspace",
+			})
+		}
+		return list.Items, nil
+	}
+}
+
+// soqFetchOutputs reads the outputs of each workspace using at most
+// parallelism concurrent calls. The outputs are returned in workspace order.
+// Failed reads leave that workspace without outputs and are reported as one
+// SourceError each, so the caller decides whether they are fatal.
+func soqFetchOutputs(
+	ctx context.Context,
+	workspaces []*tfe.Workspace,
+	parallelism int,
+	read func(context.Context, *tfe.Workspace) ([]*tfe.StateVersionOutput, error),
+) ([][]*tfe.StateVersionOutput, []SourceError) {
+	if parallelism < 1 {
+		parallelism = 1
+	}
+
+	outputs := make([][]*tfe.StateVersionOutput, len(workspaces))
+	errs := make([]error, len(workspaces))
+
+	var g errgroup.Group
+	g.SetLimit(parallelism)
+
+	for i, ws := range workspaces {
+		g.Go(func() error {
+			// Each worker owns a distinct index, so no locking is needed.
+			outputs[i], errs[i] = read(ctx, ws)
+			return nil
+		})
+	}
+	_ = g.Wait()
+
+	var failures []SourceError
+	for i, err := range errs {
+		if err != nil {
+			failures = append(failures, SourceError{Source: "workspace=" + workspaces[i].Name, Err: err})
+		}
+	}
+
+	return outputs, failures
+}
+
+// soqReduce flattens the per-workspace outputs into one row per workspace and
+// output name, ordered by organization, workspace and name. Sensitive values
+// are masked whether or not the API returned them.
+func soqReduce(workspaces []*tfe.Workspace, outputs [][]*tfe.StateVersionOutput) []*soqOutput {
+	var rows []*soqOutput
+	for i, ws := range workspaces {
+		if i >= len(outputs) {
+			break
+		}
+		for _, o := range outputs[i] {
+			row := &soqOutput{
+				ID:           o.ID,
+				Organization: soqOrganization(ws),
+				Workspace:    ws.Name,
+				Name:         o.Name,
+				Type:         o.Type,
+				Sensitive:    o.Sensitive,
+				Value:        o.Value,
+			}
+			if o.Sensitive {
+				row.Value = ocqSecretMask
+			}
+			rows = append(rows, row)
+		}
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		a, b := rows[i], rows[j]
+		if a.Organization != b.Organization {
+			return a.Organization < b.Organization
+		}
+		if a.Workspace != b.Workspace {
+			return a.Workspace < b.Workspace
+		}
+		return a.Name < b.Name
+	})
+
+	return rows
+}
+
+// soqOrganization returns the name of the workspace's organization, or "" when
+// the relationship was not included in the payload.
+func soqOrganization(ws *tfe.Workspace) string {
+	if ws.Organization == nil {
+		return ""
+	}
+	return ws.Organization.Name
+}
+
+// soqCommandBuilder constructs the cli.Command for "soq", wiring metadata,
+// flags, and action handlers.
+func soqCommandBuilder(meta meta.Meta) *cli.Command {
+	return (&QueryCommandBuilder{
+		Name:      "soq",
+		Usage:     "state output query across the organization",
+		UsageText: "tfctl soq [RootDir] [options]",
+		Flags: []cli.Flag{
+			NewHostFlag(meta.Profile, "soq"),
+			NewOfflineFlag(meta.Profile),
+			NewOrgFlag(meta.Profile, "soq"),
+			NewPartialFlag(),
+			NewRawPathFlag(meta.Profile),
+			NewWithSchemaFlag(meta.Profile),
+		},
+		Action: soqCommandAction,
+		Meta:   meta,
+	}).Build()
+}
@@ -0,0 +1,148 @@
+// Copyright (c) 2026 Steve Taranto <staranto@gmail.com>.
+// SPDX-License-Identifier: Apache-2.0
+// no-cloc
+
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"testing"
+
+	"github.com/hashicorp/go-tfe"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/staranto/tfctl/internal/backend/remote"
+)
+
+func soqWorkspace(org, name string) *tfe.Workspace {
+	ws := &tfe.Workspace{ID: "ws-" + name, Name: name}
+	if org != "" {
+		ws.Organization = &tfe.Organization{Name: org}
+	}
+	return ws
+}
+
+func TestSoqReduce(t *testing.T) {
+	workspaces := []*tfe.Workspace{
+		soqWorkspace("beta", "net"),
+		soqWorkspace("alpha", "web"),
+		soqWorkspace("alpha", "app"),
+		soqWorkspace("alpha", "empty"),
+	}
+	outputs := [][]*tfe.StateVersionOutput{
+		{{ID: "wsout-1", Name: "vpc_id", Type: "string", Value: "vpc-123"}},
+		{
+			{ID: "wsout-2", Name: "url", Type: "string", Value: "https://web"},
+			{ID: "wsout-3", Name: "db_password", Type: "string", Sensitive: true, Value: "hunter2"},
+		},
+		{
+			{ID: "wsout-4", Name: "vpc_id", Type: "string", Value: "vpc-123"},
+			{ID: "wsout-5", Name: "api_key", Type: "string", Sensitive: true},
+		},
+		nil,
+	}
+
+	rows := soqReduce(workspaces, outputs)
+
+	var keys []string
+	for _, r := range rows {
+		keys = append(keys, fmt.Sprintf("%s/%s/%s=%v", r.Organization, r.Workspace, r.Name, r.Value))
+	}
+	assert.Equal(t, []string{
+		"alpha/app/api_key=********",
+		"alpha/app/vpc_id=vpc-123",
+		"alpha/web/db_password=********",
+		"alpha/web/url=https://web",
+		"beta/net/vpc_id=vpc-123",
+	}, keys)
+
+	assert.Equal(t, "wsout-3", rows[2].ID)
+	assert.True(t, rows[2].Sensitive)
+}
+
+func TestSoqReduce_Edges(t *testing.T) {
+	assert.Empty(t, soqReduce(nil, nil))
+
+	// A workspace without the organization relationship still yields rows.
+	rows := soqReduce(
+		[]*tfe.Workspace{soqWorkspace("", "solo")},
+		[][]*tfe.StateVersionOutput{{{ID: "wsout-1", Name: "n", Value: float64(3)}}},
+	)
+	require.Len(t, rows, 1)
+	assert.Equal(t, "", rows[0].Organization)
+	assert.Equal(t, float64(3), rows[0].Value)
+
+	// Missing output slots are tolerated.
+	assert.Empty(t, soqReduce([]*tfe.Workspace{soqWorkspace("a", "b")}, nil))
+}
+
+func TestSoqFetchOutputs_BoundedAndOrdered(t *testing.T) {
+	var workspaces []*tfe.Workspace
+	for i := range 10 {
+		workspaces = append(workspaces, soqWorkspace("acme", fmt.Sprintf("ws%d", i)))
+	}
+
+	var inFlight, peak atomic.Int32
+	boom := errors.New("boom")
+	read := func(_ context.Context, ws *tfe.Workspace) ([]*tfe.StateVersionOutput, error) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		if ws.Name == "ws3" {
+			return nil, boom
+		}
+		return []*tfe.StateVersionOutput{{Name: ws.Name}}, nil
+	}
+
+	outputs, failures := soqFetchOutputs(context.Background(), workspaces, 3, read)
+
+	assert.LessOrEqual(t, peak.Load(), int32(3))
+	require.Len(t, outputs, 10)
+	for i, o := range outputs {
+		if i == 3 {
+			assert.Nil(t, o)
+			continue
+		}
+		require.Len(t, o, 1)
+		assert.Equal(t, workspaces[i].Name, o[0].Name)
+	}
+
+	require.Len(t, failures, 1)
+	assert.Equal(t, "workspace=ws3", failures[0].Source)
+	assert.ErrorIs(t, failures[0], boom)
+}
+
+func TestSoqOutputReader(t *testing.T) {
+	srv := newFakeTFEServer(t, fakeTFERoute{suffix: "/api/v2/workspaces/ws-web/current-state-version-outputs",
+		render: func(*http.Request, int) string {
+			return `{"data":[{"id":"wsout-1","type":"state-version-outputs",` +
+				`"attributes":{"name":"vpc_id","sensitive":false,"type":"string","value":"vpc-123"}}]}`
+		},
+	})
+
+	client, err := tfe.NewClient(&tfe.Config{Address: srv.URL, Token: "test"})
+	require.NoError(t, err)
+
+	read := soqOutputReader(&remote.BackendRemote{}, client)
+
+	outputs, err := read(context.Background(), soqWorkspace("acme", "web"))
+	require.NoError(t, err)
+	require.Len(t, outputs, 1)
+	assert.Equal(t, "vpc_id", outputs[0].Name)
+	assert.Equal(t, "vpc-123", outputs[0].Value)
+
+	// A workspace with no state version has no outputs.
+	outputs, err = read(context.Background(), soqWorkspace("acme", "fresh"))
+	require.NoError(t, err)
+	assert.Empty(t, outputs)
+}
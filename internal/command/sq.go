@@ -7,14 +7,21 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"slices"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/apex/log"
+	"github.com/hashicorp/go-tfe"
 	"github.com/urfave/cli/v3"
 
 	"github.com/staranto/tfctl/internal/backend"
+	"github.com/staranto/tfctl/internal/backend/remote"
 	"github.com/staranto/tfctl/internal/config"
 	"github.com/staranto/tfctl/internal/differ"
 	"github.com/staranto/tfctl/internal/meta"
@@ -33,78 +40,175 @@ func sqCommandAction(ctx context.Context, cmd *cli.Command) error {
 	if ShortCircuitTLDR(ctx, cmd, "sq") {
 		return nil
 	}
+	if PrintConfigIfRequested(cmd, os.Stdout) {
+		return nil
+	}
 
 	config.Config.Namespace = "sq"
 
-	// Figure out what type of Backend we're in.
-	be, err := backend.NewBackend(ctx, *cmd)
-	if err != nil {
-		return err
+	if cmd.Bool("all-workspaces") {
+		return sqAllWorkspaces(ctx, cmd)
 	}
-	log.Debugf("typBe: %v", be)
 
-	// Short circuit --diff mode.
-	if cmd.Bool("diff") {
-		if _, ok := be.(backend.SelfDiffer); ok {
-			states, diffErr := be.(backend.SelfDiffer).DiffStates(ctx, cmd)
-			if diffErr != nil {
-				log.Errorf("diff error: %v", diffErr)
-				return diffErr
+	var doc []byte
+	var err error
+	if stateFile := cmd.String("state-file"); stateFile != "" {
+		// A state file is read as is, without looking for a backend.
+		doc, err = readStateFile(stateFile, cmd.Root().Reader)
+		if err != nil {
+			return err
+		}
+	} else {
+		// Figure out what type of Backend we're in.
+		be, err := backend.NewBackend(ctx, *cmd)
+		if err != nil {
+			return err
+		}
+		log.Debugf("typBe: %v", be)
+
+		// Short circuit --diff mode.
+		if cmd.Bool("diff") {
+			if _, ok := be.(backend.SelfDiffer); ok {
+				states, diffErr := be.(backend.SelfDiffer).DiffStates(ctx, cmd)
+				if diffErr != nil {
+					log.Errorf("diff error: %v", diffErr)
+					return diffErr
+				}
+
+				return differ.Diff(ctx, cmd, states)
+			} else {
+				log.Debug("Backend does not implement SelfDiffer")
 			}
+		}
 
-			return differ.Diff(ctx, cmd, states)
-		} else {
-			log.Debug("Backend does not implement SelfDiffer")
+		doc, err = be.State()
+		if err != nil {
+			return err
 		}
 	}
 
-	attrs := BuildAttrs(cmd, "!.mode", "!.type", ".resource", "id", "name")
+	attrs := BuildAttrs(cmd, sqDefaultAttrs...)
 	log.Debugf("attrs: %v", attrs)
 
-	var doc []byte
-	doc, err = be.State()
+	doc, err = state.Decrypt(ctx, doc, decryptOptions(cmd), func() (string, error) {
+		return resolvePassphrase(cmd)
+	})
 	if err != nil {
 		return err
 	}
 
-	// If the state is encrypted, there's a little more work to do.
-	var jsonData map[string]interface{}
-	if err := json.Unmarshal(doc, &jsonData); err == nil {
-		if _, exists := jsonData["encrypted_data"]; exists {
-			// First, look to the flag for passphrase value.
-			passphrase := cmd.String("passphrase")
+	var raw bytes.Buffer
+	raw.Write(doc)
 
-			// Issue 14 - Next look in env and use it if found.
-			if passphrase == "" {
-				passphrase = os.Getenv("TFCTL_PASSPHRASE")
-			}
+	return output.SliceDiceSpit(raw, attrs, cmd, "", os.Stdout, sqPostProcess(cmd))
+}
 
-			// Finally, prompt for passphrase
-			if passphrase == "" {
-				passphrase, _ = state.GetPassphrase()
-			}
+// sqDefaultAttrs are the attributes sq lists when --attrs doesn't replace
+// them.
+var sqDefaultAttrs = []string{"!.mode", "!.type", ".resource", "id", "name"}
+
+// sqPostProcess returns the SliceDiceSpit callback applying --chop.
+func sqPostProcess(cmd *cli.Command) func([]map[string]interface{}) error {
+	return func(dataset []map[string]interface{}) error {
+		if cmd.Bool("chop") {
+			chopPrefix(dataset)
+		}
+
+		return nil
+	}
+}
 
-			doc, err = state.DecryptOpenTofuState(doc, passphrase)
+// sqAllWorkspaces lists the resources of every workspace --all-workspaces
+// selects as one result set, each row carrying the workspace it came from. A
+// workspace without state has no resources rather than failing the query.
+func sqAllWorkspaces(ctx context.Context, cmd *cli.Command) error {
+	if cmd.Bool("diff") {
+		return errors.New("--all-workspaces and --diff can't be used together")
+	}
+
+	be, err := backend.NewBackend(ctx, *cmd)
+	if err != nil {
+		return err
+	}
+
+	// The workspaces are read concurrently, but a passphrase is asked for at
+	// most once.
+	passphrase := sync.OnceValues(func() (string, error) {
+		return resolvePassphrase(cmd)
+	})
+
+	fetched, err := fetchAllWorkspaces(ctx, cmd, be,
+		func(ctx context.Context, cmd *cli.Command, rbe *remote.BackendRemote, _ *tfe.Client, _ string, name string) ([]map[string]json.RawMessage, error) {
+			doc, err := rbe.ForWorkspace(name).State()
+			if errors.Is(err, remote.ErrNoCurrentStateVersion) {
+				return nil, nil
+			}
 			if err != nil {
-				return fmt.Errorf("failed to decrypt: %w", err)
+				return nil, err
 			}
-		}
+			doc, err = state.Decrypt(ctx, doc, decryptOptions(cmd), passphrase)
+			if err != nil {
+				return nil, err
+			}
+
+			var st struct {
+				Resources []map[string]json.RawMessage `json:"resources"`
+			}
+			if err := json.Unmarshal(doc, &st); err != nil {
+				return nil, fmt.Errorf("failed to parse state: %w", err)
+			}
+			return st.Resources, nil
+		})
+	if err != nil {
+		return err
 	}
 
-	var raw bytes.Buffer
-	raw.Write(doc)
+	raw, err := sqMergeResources(fetched)
+	if err != nil {
+		return err
+	}
 
-	postProcess := func(dataset []map[string]interface{}) error {
-		if cmd.Bool("chop") {
-			chopPrefix(dataset)
-		}
+	attrs := BuildAttrs(cmd, append(slices.Clone(sqDefaultAttrs), ".workspace")...)
+	return output.SliceDiceSpit(*bytes.NewBuffer(raw), attrs, cmd, "", os.Stdout, sqPostProcess(cmd))
+}
 
-		return nil
+// sqMergeResources returns a state document holding the resources of every
+// workspace, each carrying the name of its workspace under "workspace", which
+// flattening copies into every instance's row.
+func sqMergeResources(fetched []workspaceRows[map[string]json.RawMessage]) ([]byte, error) {
+	resources := []map[string]json.RawMessage{}
+	for _, ws := range fetched {
+		name, err := json.Marshal(ws.name)
+		if err != nil {
+			return nil, err
+		}
+		for _, resource := range ws.rows {
+			resource["workspace"] = name
+			resources = append(resources, resource)
+		}
 	}
 
-	output.SliceDiceSpit(raw, attrs, cmd, "", os.Stdout, postProcess)
+	raw, err := json.Marshal(map[string]any{"resources": resources})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal resources: %w", err)
+	}
+	return raw, nil
+}
 
-	return nil
+// readStateFile returns the state document at path, or the one read from
+// stdin if path is "-".
+func readStateFile(path string, stdin io.Reader) ([]byte, error) {
+	var doc []byte
+	var err error
+	if path == "-" {
+		doc, err = io.ReadAll(stdin)
+	} else {
+		doc, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state file %s: %w", path, err)
+	}
+	return doc, nil
 }
 
 // sqCommandBuilder constructs the cli.Command for "sq", wiring metadata,
@@ -118,6 +222,21 @@ func sqCommandBuilder(meta meta.Meta) *cli.Command {
 			"meta": meta,
 		},
 		Flags: append([]cli.Flag{
+			&cli.StringFlag{
+				Name:  "address-sep",
+				Usage: "separator joining resource address components",
+				Value: ".",
+			},
+			&cli.StringFlag{
+				Name:  "at",
+				Usage: "query the state version active at an RFC3339 time",
+				Validator: func(value string) error {
+					if _, err := time.Parse(time.RFC3339, value); err != nil {
+						return fmt.Errorf("invalid --at time %q: must be RFC3339, e.g. 2024-01-01T00:00:00Z", value)
+					}
+					return nil
+				},
+			},
 			&cli.BoolFlag{
 				Name:  "chop",
 				Usage: "chop common resource prefix from names",
@@ -134,6 +253,23 @@ func sqCommandBuilder(meta meta.Meta) *cli.Command {
 				Usage: "find difference between state versions",
 				Value: false,
 			},
+			&cli.StringFlag{
+				Name:  "diff-attrs",
+				Usage: "comma-separated resource attributes to compare with --diff",
+			},
+			&cli.StringFlag{
+				Name:  "diff-format",
+				Usage: "diff rendering: text, unified or json",
+				Value: "text",
+				Validator: func(value string) error {
+					if !slices.Contains(differ.DiffFormats, value) {
+						return fmt.Errorf("invalid diff format %q: must be one of %s",
+							value, strings.Join(differ.DiffFormats, ", "))
+					}
+					return nil
+				},
+			},
+			NewDecryptCmdFlag(),
 			&cli.StringFlag{
 				Name:   "diff_filter",
 				Hidden: true,
@@ -142,8 +278,8 @@ func sqCommandBuilder(meta meta.Meta) *cli.Command {
 			&cli.IntFlag{
 				Name:   "limit",
 				Hidden: true,
-				Usage:  "limit state versions returned",
-				Value:  99999,
+				Usage:  "limit state versions returned, 0 for no limit",
+				Value:  0,
 			},
 			&cli.BoolFlag{
 				Name:  "short",
@@ -154,26 +290,79 @@ func sqCommandBuilder(meta meta.Meta) *cli.Command {
 				Name:  "passphrase",
 				Usage: "encrypted state passphrase",
 			},
+			NewPassphraseFileFlag(),
+			&cli.BoolFlag{
+				Name:  "passphrase-stdin",
+				Usage: "read the encrypted state passphrase from stdin",
+			},
+			&cli.StringFlag{
+				Name:      "state-file",
+				Usage:     "query a local state file, or stdin if -, instead of the backend",
+				TakesFile: true,
+			},
 			&cli.StringFlag{
 				Name:        "sv",
 				Usage:       "state version to query",
 				Value:       "0",
 				HideDefault: true,
 			},
+			NewExplainBackendFlag(),
+			NewNoWorkspaceFileFlag(),
+			NewEnvFlag(),
+			NewAllWorkspacesFlag(),
 			// We don't want sq to get default host and org values from the config.
 			// Instead, we'll depend on the backend or, in exceptional cases, explicit
 			// --host and --org flags.
-			NewHostFlag("sq"),
-			NewOrgFlag("sq"),
-			tldrFlag,
-			workspaceFlag,
-		}, NewGlobalFlags("sq")...),
+			NewHostFlag(meta.Profile, "sq"),
+			NewOfflineFlag(meta.Profile),
+			NewOrgFlag(meta.Profile, "sq"),
+			NewPrintConfigFlag(),
+			NewPrintSourcesFlag(),
+			NewRawPathFlag(meta.Profile),
+			NewTldrFlag(),
+			NewWithSchemaFlag(meta.Profile),
+			NewWorkspaceFlag(meta.Profile),
+		}, NewGlobalFlags(meta.Profile, "sq")...),
 		Before: func(ctx context.Context, cmd *cli.Command) (context.Context, error) {
 			// If --chop is set, --short must not be set.
 			if cmd.Bool("chop") {
 				_ = cmd.Set("short", "false")
 			}
 
+			// --at is shorthand for an @time --sv spec.
+			if at := cmd.String("at"); at != "" {
+				if cmd.IsSet("sv") {
+					return ctx, fmt.Errorf("--at and --sv are mutually exclusive")
+				}
+				_ = cmd.Set("sv", "@"+at)
+			}
+
+			if err := passphraseSourcesValidator(cmd); err != nil {
+				return ctx, err
+			}
+
+			// A state file is a single document, so there's no backend to pick a
+			// version, workspace or diff from.
+			if stateFile := cmd.String("state-file"); stateFile != "" {
+				var conflict string
+				switch {
+				case cmd.String("at") != "":
+					conflict = "at"
+				case cmd.IsSet("sv"):
+					conflict = "sv"
+				case cmd.Bool("diff"):
+					conflict = "diff"
+				case cmd.Bool("all-workspaces"):
+					conflict = "all-workspaces"
+				}
+				if conflict != "" {
+					return ctx, fmt.Errorf("--state-file and --%s are mutually exclusive", conflict)
+				}
+				if stateFile == "-" && cmd.Bool("passphrase-stdin") {
+					return ctx, fmt.Errorf("--state-file - and --passphrase-stdin both read stdin")
+				}
+			}
+
 			return ctx, GlobalFlagsValidator(ctx, cmd)
 		},
 		Action: sqCommandAction,
@@ -5,9 +5,22 @@
 package command
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v3"
+
+	"github.com/staranto/tfctl/internal/attrs"
+	"github.com/staranto/tfctl/internal/meta"
+	"github.com/staranto/tfctl/internal/output"
 )
 
 func TestChopPrefix_EmptyDataset(t *testing.T) {
@@ -145,3 +158,113 @@ func TestChopPrefix_DifferentLengths_PartialMatch(t *testing.T) {
 	assert.Equal(t, "..prod.server1", data[1]["resource"])
 	assert.Equal(t, "..dev.server2", data[2]["resource"])
 }
+
+func TestSq_PassphraseSourcesExclusive(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "passphrase")
+	require.NoError(t, os.WriteFile(path, []byte("from-file\n"), 0o600))
+
+	for _, args := range [][]string{
+		{"--passphrase", "x", "--passphrase-file", path},
+		{"--passphrase", "x", "--passphrase-stdin"},
+		{"--passphrase-file", path, "--passphrase-stdin"},
+	} {
+		cmd := sqCommandBuilder(meta.Meta{})
+		err := cmd.Run(context.Background(), append([]string{"sq"}, args...))
+		assert.EqualError(t, err, "--passphrase, --passphrase-file and --passphrase-stdin are mutually exclusive", "args %v", args)
+	}
+}
+
+func TestSq_StateFile(t *testing.T) {
+	t.Setenv("TFCTL_CFG_FILE", "")
+	t.Setenv("TFCTL_CACHE_DIR", t.TempDir())
+	t.Chdir(t.TempDir())
+
+	state := `{"version":4,"terraform_version":"1.5.0","serial":1,"lineage":"x","resources":[` +
+		`{"mode":"managed","type":"aws_s3_bucket","name":"logs","provider":"provider[\"registry.terraform.io/hashicorp/aws\"]",` +
+		`"instances":[{"attributes":{"id":"logs-bucket"}}]}]}`
+	path := filepath.Join(t.TempDir(), "ci.tfstate")
+	require.NoError(t, os.WriteFile(path, []byte(state), 0o600))
+
+	var stdin io.Reader
+	run := func(args ...string) (string, error) {
+		stdout := os.Stdout
+		r, w, err := os.Pipe()
+		require.NoError(t, err)
+		os.Stdout = w
+		t.Cleanup(func() { os.Stdout = stdout })
+
+		args = append([]string{"tfctl", "sq"}, args...)
+		app, err := InitApp(context.Background(), args, nil)
+		require.NoError(t, err)
+		app.Reader = stdin
+		runErr := app.Run(context.Background(), args)
+
+		w.Close()
+		os.Stdout = stdout
+		out, err := io.ReadAll(r)
+		require.NoError(t, err)
+		return string(out), runErr
+	}
+
+	out, err := run("--state-file", path, "--output", "json")
+	require.NoError(t, err)
+	assert.Contains(t, out, `"resource":"aws_s3_bucket.logs"`)
+
+	stdin = strings.NewReader(state)
+	out, err = run("--state-file", "-", "--output", "json")
+	require.NoError(t, err)
+	assert.Contains(t, out, `"resource":"aws_s3_bucket.logs"`)
+
+	_, err = run("--state-file", filepath.Join(t.TempDir(), "missing.tfstate"))
+	assert.ErrorContains(t, err, "failed to read state file")
+
+	_, err = run("--state-file", "-", "--passphrase-stdin")
+	assert.EqualError(t, err, "--state-file - and --passphrase-stdin both read stdin")
+
+	for _, args := range [][]string{
+		{"--state-file", path, "--sv", "3"},
+		{"--state-file", path, "--at", "2024-01-01T00:00:00Z"},
+		{"--state-file", path, "--diff"},
+		{"--state-file", path, "--all-workspaces"},
+	} {
+		_, err = run(args...)
+		assert.ErrorContains(t, err, "--state-file and "+args[2]+" are mutually exclusive", "args %v", args)
+	}
+
+}
+
+// TestSqMergeResources verifies the resources of several workspaces render as
+// one result set, each row carrying its workspace.
+func TestSqMergeResources(t *testing.T) {
+	resource := func(name string) map[string]json.RawMessage {
+		var r map[string]json.RawMessage
+		require.NoError(t, json.Unmarshal([]byte(`{"mode":"managed","type":"aws_s3_bucket","name":"`+name+`",`+
+			`"instances":[{"attributes":{"id":"`+name+`-1"}},{"attributes":{"id":"`+name+`-2"}}]}`), &r))
+		return r
+	}
+
+	raw, err := sqMergeResources([]workspaceRows[map[string]json.RawMessage]{
+		{name: "web", rows: []map[string]json.RawMessage{resource("logs")}},
+		{name: "db"},
+		{name: "api", rows: []map[string]json.RawMessage{resource("data")}},
+	})
+	require.NoError(t, err)
+
+	render := func(flags ...cli.Flag) string {
+		var al attrs.AttrList
+		require.NoError(t, al.Set(".resource,id,.workspace"))
+		cmd := &cli.Command{Flags: append([]cli.Flag{&cli.StringFlag{Name: "output", Value: "jsonl"}}, flags...)}
+		var buf bytes.Buffer
+		require.NoError(t, output.SliceDiceSpit(*bytes.NewBuffer(raw), al, cmd, "", &buf, nil))
+		return buf.String()
+	}
+
+	assert.Equal(t,
+		`{"resource":"aws_s3_bucket.logs","id":"logs-1","workspace":"web"}`+"\n"+
+			`{"resource":"aws_s3_bucket.logs","id":"logs-2","workspace":"web"}`+"\n"+
+			`{"resource":"aws_s3_bucket.data","id":"data-1","workspace":"api"}`+"\n"+
+			`{"resource":"aws_s3_bucket.data","id":"data-2","workspace":"api"}`+"\n",
+		render())
+	assert.Equal(t, `{"workspace":"api","count":2}`+"\n"+`{"workspace":"web","count":2}`+"\n",
+		render(&cli.StringFlag{Name: "group-by", Value: "workspace"}, &cli.StringFlag{Name: "sort", Value: "workspace"}))
+}
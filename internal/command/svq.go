@@ -4,20 +4,36 @@
 package command
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
 	"reflect"
+	"slices"
+	"time"
 
 	"github.com/apex/log"
 	"github.com/hashicorp/go-tfe"
+	"github.com/tidwall/gjson"
 	"github.com/urfave/cli/v3"
 
+	"github.com/staranto/tfctl/internal/backend"
+	"github.com/staranto/tfctl/internal/backend/remote"
 	"github.com/staranto/tfctl/internal/meta"
+	"github.com/staranto/tfctl/internal/output"
+	"github.com/staranto/tfctl/internal/util"
 )
 
 // svqDefaultAttrs specifies the default attributes displayed for state
 // versions in the "svq" command output.
 var svqDefaultAttrs = []string{".id", "serial", "created-at"}
 
+// svqCompareDefaultAttrs specifies the default attributes displayed for each
+// state version in "svq --compare" output.
+var svqCompareDefaultAttrs = []string{".id", "serial", "created-at", "resources", "outputs"}
+
 // svqCommandAction is the action handler for the "svq" subcommand. It lists
 // state versions via the active backend, supports --tldr/--schema shortcuts,
 // and emits results per common flags.
@@ -27,16 +43,172 @@ func svqCommandAction(ctx context.Context, cmd *cli.Command) error {
 		return err
 	}
 
+	if cmd.Bool("all-workspaces") {
+		if cmd.Int("compare") > 0 || cmd.Bool("deltas") {
+			return errors.New("--all-workspaces can't be combined with --compare or --deltas")
+		}
+		return svqAllWorkspaces(ctx, cmd, be)
+	}
+
+	if n := cmd.Int("compare"); n > 0 || cmd.Bool("deltas") {
+		return svqCompare(cmd, be, n)
+	}
+
 	fn := func(ctx context.Context, cmd *cli.Command) ([]*tfe.StateVersion, error) {
 		return be.StateVersions(SvqServerSideFilterAugmenter)
 	}
 
-	return NewQueryActionRunner(
+	runner := NewQueryActionRunner(
 		"svq",
 		reflect.TypeOf((*tfe.StateVersion)(nil)).Elem(),
 		svqDefaultAttrs,
 		fn,
-	).Run(ctx, cmd)
+	)
+	// Backends such as S3 know more about a version than tfe.StateVersion
+	// holds, e.g. its size and storage class.
+	if attributer, ok := be.(backend.StateVersionAttributer); ok {
+		runner.Computed = func(sv *tfe.StateVersion) map[string]any {
+			return attributer.StateVersionAttributes(sv.ID)
+		}
+	}
+	return runner.Run(ctx, cmd)
+}
+
+// svqAllWorkspaces lists the state versions of every workspace
+// --all-workspaces selects as one result set, each row carrying the workspace
+// it came from.
+func svqAllWorkspaces(ctx context.Context, cmd *cli.Command, be backend.Backend) error {
+	fn, workspaceOf := AllWorkspacesFetcherFactory(be, svqWorkspaceStateVersions)
+
+	runner := NewQueryActionRunner(
+		"svq",
+		reflect.TypeOf((*tfe.StateVersion)(nil)).Elem(),
+		append(slices.Clone(svqDefaultAttrs), "workspace"),
+		fn,
+	)
+	runner.Computed = func(sv *tfe.StateVersion) map[string]any {
+		return map[string]any{"workspace": workspaceOf(sv)}
+	}
+	return runner.Run(ctx, cmd)
+}
+
+// svqWorkspaceStateVersions lists the state versions of the workspace name, stopping once
+// --limit versions are fetched.
+func svqWorkspaceStateVersions(
+	ctx context.Context,
+	cmd *cli.Command,
+	_ *remote.BackendRemote,
+	client *tfe.Client,
+	org string,
+	name string,
+) ([]*tfe.StateVersion, error) {
+	limit := cmd.Int("limit")
+	options := &tfe.StateVersionListOptions{
+		Organization: org,
+		Workspace:    name,
+		ListOptions:  tfe.ListOptions{PageNumber: 1, PageSize: util.PageSize(limit)},
+	}
+
+	return PaginateWithOptions(ctx, cmd, options,
+		func(ctx context.Context, opts *tfe.StateVersionListOptions) ([]*tfe.StateVersion, *tfe.Pagination, error) {
+			page, err := client.StateVersions.List(ctx, opts)
+			if err != nil {
+				return nil, nil, err
+			}
+			return page.Items, limitedPagination(page.Pagination, opts.PageSize, limit), nil
+		}, SvqServerSideFilterAugmenter)
+}
+
+// svqCompare fetches the state documents of the latest n state versions, or
+// of every listed version when n is 0, and emits one summary row per version,
+// newest first. Downloads go through the backend's States, so previously
+// fetched versions are served from cache. With --deltas each row also carries
+// its change in resource count.
+func svqCompare(cmd *cli.Command, be backend.Backend, n int) error {
+	versions, err := be.StateVersions(SvqServerSideFilterAugmenter)
+	if err != nil {
+		return err
+	}
+	if n > 0 && len(versions) > n {
+		versions = versions[:n]
+	}
+
+	ids := make([]string, 0, len(versions))
+	for _, v := range versions {
+		ids = append(ids, v.ID)
+	}
+
+	docs, err := be.States(ids...)
+	if err != nil {
+		return fmt.Errorf("failed to get states: %w", err)
+	}
+
+	rows, err := svqCompareRows(versions, docs)
+	if err != nil {
+		return err
+	}
+
+	defaults := svqCompareDefaultAttrs
+	if cmd.Bool("deltas") {
+		svqDeltas(rows)
+		defaults = append(slices.Clone(defaults), "delta")
+	}
+
+	raw, err := json.Marshal(map[string]any{"data": rows})
+	if err != nil {
+		return fmt.Errorf("failed to marshal comparison: %w", err)
+	}
+
+	attrs := BuildAttrs(cmd, defaults...)
+	return output.SliceDiceSpit(*bytes.NewBuffer(raw), attrs, cmd, "data", os.Stdout, nil)
+}
+
+// svqCompareRows assembles one row per state version from the version
+// metadata and its state document. Rows use the same id/attributes shape as
+// the API payloads so the common attribute handling applies.
+func svqCompareRows(versions []*tfe.StateVersion, docs [][]byte) ([]map[string]any, error) {
+	if len(versions) != len(docs) {
+		return nil, fmt.Errorf("got %d state documents for %d versions", len(docs), len(versions))
+	}
+
+	rows := make([]map[string]any, 0, len(versions))
+	for i, v := range versions {
+		doc := gjson.ParseBytes(docs[i])
+
+		// Count resource instances, which is what sq lists one row per.
+		instances := 0
+		for _, r := range doc.Get("resources").Array() {
+			instances += len(r.Get("instances").Array())
+		}
+
+		attributes := map[string]any{
+			"serial":            v.Serial,
+			"created-at":        v.CreatedAt.Format(time.RFC3339),
+			"terraform-version": doc.Get("terraform_version").String(),
+			"lineage":           doc.Get("lineage").String(),
+			"resources":         instances,
+			"outputs":           len(doc.Get("outputs").Map()),
+		}
+
+		rows = append(rows, map[string]any{"id": v.ID, "attributes": attributes})
+	}
+
+	return rows, nil
+}
+
+// svqDeltas sets the delta attribute of each newest-first row to the change in
+// resource count from the next older row. The oldest row has nothing to
+// compare against, so its delta is left nil.
+func svqDeltas(rows []map[string]any) {
+	for i, row := range rows {
+		attributes := row["attributes"].(map[string]any)
+		if i == len(rows)-1 {
+			attributes["delta"] = nil
+			continue
+		}
+		older := rows[i+1]["attributes"].(map[string]any)
+		attributes["delta"] = attributes["resources"].(int) - older["resources"].(int)
+	}
 }
 
 // SvqServerSideFilterAugmenter augments the StateVersionListOptions with
@@ -65,15 +237,31 @@ func svqCommandBuilder(meta meta.Meta) *cli.Command {
 		Usage:     "state version query",
 		UsageText: "tfctl svq [RootDir] [options]",
 		Flags: []cli.Flag{
+			&cli.IntFlag{
+				Name:  "compare",
+				Usage: "summarize the latest N state versions side by side",
+			},
+			&cli.BoolFlag{
+				Name:  "deltas",
+				Usage: "show each state version's change in resource count from the previous one",
+			},
 			&cli.IntFlag{
 				Name:    "limit",
 				Aliases: []string{"l"},
-				Usage:   "limit state versions returned",
-				Value:   99999,
+				Usage:   "limit state versions returned, 0 for no limit",
+				Value:   0,
 			},
-			NewHostFlag("svq"),
-			NewOrgFlag("svq"),
-			workspaceFlag,
+			NewExplainBackendFlag(),
+			NewNoWorkspaceFileFlag(),
+			NewEnvFlag(),
+			NewAllWorkspacesFlag(),
+			NewHostFlag(meta.Profile, "svq"),
+			NewOfflineFlag(meta.Profile),
+			NewOrgFlag(meta.Profile, "svq"),
+			NewPartialFlag(),
+			NewRawPathFlag(meta.Profile),
+			NewWithSchemaFlag(meta.Profile),
+			NewWorkspaceFlag(meta.Profile),
 		},
 		Action: svqCommandAction,
 		Meta:   meta,
@@ -0,0 +1,133 @@
+// Copyright (c) 2026 Steve Taranto <staranto@gmail.com>.
+// SPDX-License-Identifier: Apache-2.0
+// no-cloc
+
+package command
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-tfe"
+	"github.com/hashicorp/jsonapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v3"
+
+	"github.com/staranto/tfctl/internal/attrs"
+	"github.com/staranto/tfctl/internal/output"
+)
+
+func TestSvqCompareRows(t *testing.T) {
+	created := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	versions := []*tfe.StateVersion{
+		{ID: "sv-new", Serial: 12, CreatedAt: created.Add(time.Hour)},
+		{ID: "sv-old", Serial: 11, CreatedAt: created},
+	}
+	docs := [][]byte{
+		[]byte(`{"terraform_version":"1.9.0","lineage":"abc","serial":12,
+			"resources":[{"instances":[{},{}]},{"instances":[{}]}],
+			"outputs":{"vpc_id":{},"arn":{}}}`),
+		[]byte(`{"terraform_version":"1.8.5","lineage":"abc","serial":11,
+			"resources":[{"instances":[{}]}],"outputs":{}}`),
+	}
+
+	rows, err := svqCompareRows(versions, docs)
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+
+	assert.Equal(t, "sv-new", rows[0]["id"])
+	newest := rows[0]["attributes"].(map[string]any)
+	assert.Equal(t, int64(12), newest["serial"])
+	assert.Equal(t, "2026-03-01T13:00:00Z", newest["created-at"])
+	assert.Equal(t, "1.9.0", newest["terraform-version"])
+	assert.Equal(t, 3, newest["resources"])
+	assert.Equal(t, 2, newest["outputs"])
+
+	oldest := rows[1]["attributes"].(map[string]any)
+	assert.Equal(t, 1, oldest["resources"])
+	assert.Equal(t, 0, oldest["outputs"])
+
+	_, err = svqCompareRows(versions, docs[:1])
+	assert.Error(t, err)
+}
+
+func TestSvqCompareRows_Render(t *testing.T) {
+	versions := []*tfe.StateVersion{{ID: "sv-1", Serial: 9007199254740993}}
+	docs := [][]byte{[]byte(`{"resources":[{"instances":[{}]}]}`)}
+
+	rows, err := svqCompareRows(versions, docs)
+	require.NoError(t, err)
+	raw, err := json.Marshal(map[string]any{"data": rows})
+	require.NoError(t, err)
+
+	var al attrs.AttrList
+	for _, a := range svqCompareDefaultAttrs {
+		require.NoError(t, al.Set(a))
+	}
+
+	cmd := &cli.Command{Flags: []cli.Flag{&cli.StringFlag{Name: "output", Value: "text"}}}
+	buf := new(bytes.Buffer)
+	output.SliceDiceSpit(*bytes.NewBuffer(raw), al, cmd, "data", buf, nil)
+
+	fields := strings.Fields(buf.String())
+	assert.Equal(t, []string{"sv-1", "9007199254740993", "0001-01-01T00:00:00Z", "1", "-"}, fields)
+}
+
+// TestSvqStateVersionAttributes verifies attributes a backend computes for a
+// state version, such as an S3 object's size and storage class, can be
+// selected with --attrs.
+func TestSvqStateVersionAttributes(t *testing.T) {
+	versions := []*tfe.StateVersion{{ID: "v2", Serial: 2}, {ID: "v1", Serial: 1}}
+	computed := []map[string]any{
+		{"size": int64(82854982), "storage-class": "STANDARD"},
+		nil,
+	}
+
+	var raw bytes.Buffer
+	require.NoError(t, jsonapi.MarshalPayload(&raw, versions))
+	require.NoError(t, mergeAttributes(&raw, computed))
+
+	var al attrs.AttrList
+	require.NoError(t, al.Set(".id,serial,size::b,storage-class"))
+
+	cmd := &cli.Command{Flags: []cli.Flag{&cli.StringFlag{Name: "output", Value: "json"}}}
+	buf := new(bytes.Buffer)
+	output.SliceDiceSpit(raw, al, cmd, "data", buf, nil)
+
+	assert.JSONEq(t, `[
+		{"id":"v2","serial":2,"size":"79 MiB","storage-class":"STANDARD"},
+		{"id":"v1","serial":1,"size":null,"storage-class":null}
+	]`, buf.String())
+}
+
+func TestSvqDeltas(t *testing.T) {
+	// Newest first, as listed: 3, 6, 6 and 2 resource instances.
+	versions := []*tfe.StateVersion{{ID: "sv-4"}, {ID: "sv-3"}, {ID: "sv-2"}, {ID: "sv-1"}}
+	docs := [][]byte{
+		[]byte(`{"resources":[{"instances":[{},{}]},{"instances":[{}]}]}`),
+		[]byte(`{"resources":[{"instances":[{},{},{},{},{},{}]}]}`),
+		[]byte(`{"resources":[{"instances":[{},{},{}]},{"instances":[{},{},{}]}]}`),
+		[]byte(`{"resources":[{"instances":[{},{}]}]}`),
+	}
+
+	rows, err := svqCompareRows(versions, docs)
+	require.NoError(t, err)
+	svqDeltas(rows)
+
+	var deltas []any
+	for _, row := range rows {
+		deltas = append(deltas, row["attributes"].(map[string]any)["delta"])
+	}
+	assert.Equal(t, []any{-3, 0, 4, nil}, deltas)
+
+	// A single version has no predecessor and an empty listing has no rows.
+	rows, err = svqCompareRows(versions[:1], docs[:1])
+	require.NoError(t, err)
+	svqDeltas(rows)
+	assert.Nil(t, rows[0]["attributes"].(map[string]any)["delta"])
+	svqDeltas(nil)
+}
@@ -6,8 +6,12 @@ package command
 import (
 	"context"
 	"fmt"
+	"slices"
+	"strings"
 
 	"github.com/urfave/cli/v3"
+
+	"github.com/staranto/tfctl/internal/output"
 )
 
 type FlagValidatorType func(any) error
@@ -22,11 +26,36 @@ func FlagValidators(value any, validators ...FlagValidatorType) error {
 }
 
 func GlobalFlagsValidator(ctx context.Context, c *cli.Command) error {
+	if c.String("output") == "sqlite" && c.String("out") == "" {
+		return fmt.Errorf("--output sqlite requires --out <file>")
+	}
+	if c.String("output") == "sqlite" && strings.HasSuffix(c.String("out"), ".gz") {
+		return fmt.Errorf("--output sqlite can't write a gzipped --out file")
+	}
+	if c.String("output") == "exec" && c.String("formatter-cmd") == "" {
+		return fmt.Errorf("--output exec requires --formatter-cmd <program>")
+	}
+	// The backends read deep too, so outside --schema it would quietly make a
+	// request per row.
+	if c.Bool("deep") && !c.Bool("schema") {
+		return fmt.Errorf("--deep requires --schema")
+	}
+	// rq and svq read a single workspace unless --all-workspaces is given.
+	if c.Bool("partial") && hasFlag(c, "all-workspaces") && !c.Bool("all-workspaces") {
+		return fmt.Errorf("--partial requires --all-workspaces")
+	}
 	return nil
 }
 
+// hasFlag reports whether c defines the flag name.
+func hasFlag(c *cli.Command, name string) bool {
+	return slices.ContainsFunc(c.Flags, func(f cli.Flag) bool {
+		return slices.Contains(f.Names(), name)
+	})
+}
+
 func OutputValidator(value any) error {
-	var validOutputFlagValues = []string{"text", "json", "raw", "yaml"}
+	var validOutputFlagValues = []string{"text", "table-wide", "exec", "json", "jsonl", "prometheus", "raw", "sqlite", "summary", "yaml"}
 	valid := false
 	for _, v := range validOutputFlagValues {
 		if v == value {
@@ -39,3 +68,19 @@ func OutputValidator(value any) error {
 	}
 	return nil
 }
+
+func FieldsValidator(value any) error {
+	var validFieldsFlagValues = []string{"all", "none"}
+	for _, v := range validFieldsFlagValues {
+		if v == value {
+			return nil
+		}
+	}
+	return fmt.Errorf("must be one of %v", validFieldsFlagValues)
+}
+
+func AggValidator(value any) error {
+	spec, _ := value.(string)
+	_, _, err := output.ParseAggSpec(spec)
+	return err
+}
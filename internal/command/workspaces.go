@@ -0,0 +1,221 @@
+// Copyright (c) 2026 Steve Taranto <staranto@gmail.com>.
+// SPDX-License-Identifier: Apache-2.0
+
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/hashicorp/go-tfe"
+	"github.com/urfave/cli/v3"
+
+	"github.com/staranto/tfctl/internal/backend"
+	"github.com/staranto/tfctl/internal/backend/remote"
+	"github.com/staranto/tfctl/internal/cacheutil"
+	"github.com/staranto/tfctl/internal/config"
+	"github.com/staranto/tfctl/internal/filters"
+	"github.com/staranto/tfctl/internal/svutil"
+	"github.com/staranto/tfctl/internal/util"
+)
+
+// errAllWorkspaces is returned when --all-workspaces is used with a backend
+// that has no organization to list the workspaces of.
+var errAllWorkspaces = errors.New("--all-workspaces needs a remote or cloud backend, or --host and --org")
+
+// WorkspaceFetcher lists the T of the workspace name of org. be is the
+// command's backend, for fetches that read through a copy of it, and client
+// one shared by every workspace.
+type WorkspaceFetcher[T any] func(
+	ctx context.Context,
+	cmd *cli.Command,
+	be *remote.BackendRemote,
+	client *tfe.Client,
+	org string,
+	name string,
+) ([]T, error)
+
+// workspaceRows is what a WorkspaceFetcher returned for one workspace.
+type workspaceRows[T any] struct {
+	name string
+	rows []T
+	err  error
+}
+
+// fetchAllWorkspaces runs fetch against every workspace --all-workspaces
+// selects, see selectWorkspaces, with at most the parallelism config key
+// running at once, and returns each workspace's rows in workspace order. With
+// --partial, a workspace that fails is reported in a *PartialError and the
+// others are still returned.
+func fetchAllWorkspaces[T any](
+	ctx context.Context,
+	cmd *cli.Command,
+	be backend.Backend,
+	fetch WorkspaceFetcher[T],
+) ([]workspaceRows[T], error) {
+	if cmd.String("workspace") != "" {
+		return nil, errors.New("--all-workspaces and --workspace can't be used together")
+	}
+	if GetMeta(cmd).Workspace != "" {
+		return nil, errors.New("--all-workspaces and RootDir@workspace can't be used together")
+	}
+	if cmd.Bool("offline") {
+		return nil, fmt.Errorf("--all-workspaces queries the API directly: %w", cacheutil.ErrOfflineMiss)
+	}
+
+	rbe, ok := be.(*remote.BackendRemote)
+	if !ok {
+		return nil, errAllWorkspaces
+	}
+	org, err := rbe.Organization()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get organization: %w", err)
+	}
+	client, err := rbe.Client()
+	if err != nil {
+		return nil, err
+	}
+
+	names, err := selectWorkspaces(ctx, cmd, rbe, client, org)
+	if err != nil {
+		return nil, err
+	}
+
+	// Failures are kept with their workspace rather than stopping the others,
+	// so --partial can report each one.
+	parallelism, _ := config.GetInt("parallelism", svutil.DefaultParallelism)
+	fetched, _ := svutil.FetchAll(names, parallelism, func(name string) (workspaceRows[T], error) {
+		rows, err := fetch(ctx, cmd, rbe, client, org, name)
+		return workspaceRows[T]{name: name, rows: rows, err: err}, nil
+	})
+
+	var results []workspaceRows[T]
+	var failures []SourceError
+	for _, ws := range fetched {
+		if ws.err != nil {
+			err := remote.FriendlyTFE(ws.err, remote.ErrorContext{
+				Host:      rbe.Host(),
+				Org:       org,
+				Workspace: ws.name,
+				Operation: "query workspace",
+				Resource:  "workspace",
+			})
+			if !cmd.Bool("partial") {
+				return nil, fmt.Errorf("workspace %s: %w", ws.name, err)
+			}
+			failures = append(failures, SourceError{Source: "workspace=" + ws.name, Err: err})
+			continue
+		}
+		results = append(results, ws)
+	}
+
+	if len(failures) > 0 {
+		return results, &PartialError{Errors: failures, Total: len(names)}
+	}
+	return results, nil
+}
+
+// selectWorkspaces returns the names of the workspaces --all-workspaces
+// queries: those the backend selects by name, prefix or tags or, when it
+// selects none, as a backend built from --host and --org doesn't, every
+// workspace of the organization. Either way the _name, _project and _tag
+// filters wq takes narrow them further.
+func selectWorkspaces(
+	ctx context.Context,
+	cmd *cli.Command,
+	rbe *remote.BackendRemote,
+	client *tfe.Client,
+	org string,
+) ([]string, error) {
+	selected, err := rbe.WorkspaceNames()
+	if err != nil && !errors.Is(err, remote.ErrWorkspaceNotSet) {
+		return nil, err
+	}
+	if selected != nil && !slices.ContainsFunc(filters.BuildFilters(cmd.String("filter")),
+		func(f filters.Filter) bool { return f.ServerSide }) {
+		return selected, nil
+	}
+
+	workspaces, err := RemoteQueryFetcherFactory(
+		rbe,
+		[]string{org},
+		func(ctx context.Context, org string, opts *tfe.WorkspaceListOptions) ([]*tfe.Workspace, *tfe.Pagination, error) {
+			page, err := client.Workspaces.List(ctx, org, opts)
+			if err != nil {
+				return nil, nil, err
+			}
+			return page.Items, page.Pagination, nil
+		},
+		wqServerSideFilterAugmenter,
+		"list workspaces",
+	)(ctx, cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, ws := range workspaces {
+		if selected == nil || slices.Contains(selected, ws.Name) {
+			names = append(names, ws.Name)
+		}
+	}
+	return names, nil
+}
+
+// AllWorkspacesFetcherFactory returns a fetch function, for use with
+// QueryActionRunner, that runs fetch against every workspace --all-workspaces
+// selects rather than the backend's own workspace, see fetchAllWorkspaces.
+// --limit caps the rows of each workspace, not the total.
+//
+// The returned workspaceOf gives the name of the workspace a fetched row came
+// from, for the workspace attribute.
+func AllWorkspacesFetcherFactory[T comparable](
+	be backend.Backend,
+	fetch WorkspaceFetcher[T],
+) (fn func(context.Context, *cli.Command) ([]T, error), workspaceOf func(T) string) {
+	names := map[T]string{}
+
+	fn = func(ctx context.Context, cmd *cli.Command) ([]T, error) {
+		fetched, err := fetchAllWorkspaces(ctx, cmd, be, fetch)
+		var partial *PartialError
+		if err != nil && !errors.As(err, &partial) {
+			return nil, err
+		}
+
+		var results []T
+		for _, ws := range fetched {
+			for _, row := range util.Limit(ws.rows, cmd.Int("limit")) {
+				names[row] = ws.name
+				results = append(results, row)
+			}
+		}
+		return results, err
+	}
+
+	workspaceOf = func(row T) string {
+		return names[row]
+	}
+
+	return fn, workspaceOf
+}
+
+// limitedPagination returns p, or nil once the pages up to p's current one
+// hold limit rows of pageSize each, so that PaginateWithOptions stops there.
+// Otherwise its TotalPages is cut to the pages holding limit rows, so that the
+// concurrent fan-out over the remaining pages stops there too.
+func limitedPagination(p *tfe.Pagination, pageSize, limit int) *tfe.Pagination {
+	if p == nil || limit <= 0 || pageSize <= 0 {
+		return p
+	}
+	if util.Limited(p.CurrentPage*pageSize, limit) {
+		return nil
+	}
+	if pages := (limit + pageSize - 1) / pageSize; p.TotalPages > pages {
+		capped := *p
+		capped.TotalPages = pages
+		return &capped
+	}
+	return p
+}
@@ -0,0 +1,239 @@
+// Copyright (c) 2026 Steve Taranto <staranto@gmail.com>.
+// SPDX-License-Identifier: Apache-2.0
+// no-cloc
+
+package command
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/go-tfe"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v3"
+
+	"github.com/staranto/tfctl/internal/backend/remote"
+	"github.com/staranto/tfctl/internal/backend/s3"
+	"github.com/staranto/tfctl/internal/meta"
+)
+
+// newWorkspacesServer serves the workspaces web and db of org acme, each with
+// two runs and two state versions listed one per page when asked for pages of
+// one, and records the query of every request.
+func newWorkspacesServer(t *testing.T, queries *[]string) *httptest.Server {
+	t.Helper()
+	var mu sync.Mutex
+
+	page := func(w http.ResponseWriter, r *http.Request, typ, prefix string) {
+		items := []string{prefix + "-1", prefix + "-2"}
+		next := "null"
+		if r.URL.Query().Get("page[size]") == "1" {
+			if r.URL.Query().Get("page[number]") == "2" {
+				items = items[1:]
+			} else {
+				items, next = items[:1], "2"
+			}
+		}
+		var data []string
+		for _, id := range items {
+			data = append(data, fmt.Sprintf(`{"id":%q,"type":%q,"attributes":{"status":"applied","serial":1}}`, id, typ))
+		}
+		fmt.Fprintf(w, `{"data":[%s],"meta":{"pagination":{"current-page":1,"next-page":%s}}}`,
+			strings.Join(data, ","), next)
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		*queries = append(*queries, r.URL.Path+"?"+r.URL.RawQuery)
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		q := r.URL.Query()
+		switch r.URL.Path {
+		case "/api/v2/ping":
+			w.WriteHeader(http.StatusNoContent)
+		case "/api/v2/organizations/acme/workspaces":
+			fmt.Fprint(w, `{"data":[`+
+				`{"id":"ws-1","type":"workspaces","attributes":{"name":"web"}},`+
+				`{"id":"ws-2","type":"workspaces","attributes":{"name":"db"}}],`+
+				`"meta":{"pagination":{"current-page":1,"next-page":null,"total-pages":1,"total-count":2}}}`)
+		case "/api/v2/organizations/acme/runs":
+			if q.Get("filter[workspace_names]") == "db" && q.Get("filter[status]") == "errored" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			page(w, r, "runs", "run-"+q.Get("filter[workspace_names]"))
+		case "/api/v2/state-versions":
+			page(w, r, "state-versions", "sv-"+q.Get("filter[workspace][name]"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+// runWorkspacesCommand runs action under a minimal command carrying the flags
+// AllWorkspacesFetcherFactory reads.
+func runWorkspacesCommand(t *testing.T, args []string, action cli.ActionFunc) error {
+	t.Helper()
+	cmd := &cli.Command{
+		Name: "rq",
+		Flags: []cli.Flag{
+			NewHostFlag(meta.Profile{}, "rq"),
+			NewOrgFlag(meta.Profile{}, "rq"),
+			&cli.StringFlag{Name: "filter"},
+			&cli.IntFlag{Name: "limit"},
+			&cli.BoolFlag{Name: "offline"},
+			&cli.BoolFlag{Name: "partial"},
+			&cli.StringFlag{Name: "workspace"},
+		},
+		Action: action,
+	}
+	return cmd.Run(context.Background(), append([]string{"rq", "--org", "acme"}, args...))
+}
+
+func TestAllWorkspacesFetcherFactory_Runs(t *testing.T) {
+	var queries []string
+	srv := newWorkspacesServer(t, &queries)
+
+	var ids, workspaces []string
+	err := runWorkspacesCommand(t, []string{"--filter", "_tag.env=prod,_status=applied"},
+		func(ctx context.Context, cmd *cli.Command) error {
+			be, err := remote.NewBackendRemote(ctx, cmd, remote.BuckNaked(), remote.WithClient(fakeTFEClient(srv)))
+			require.NoError(t, err)
+
+			fn, workspaceOf := AllWorkspacesFetcherFactory(be, rqWorkspaceRuns)
+			runs, err := fn(ctx, cmd)
+			for _, run := range runs {
+				ids = append(ids, run.ID)
+				workspaces = append(workspaces, workspaceOf(run))
+			}
+			return err
+		})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"run-web-1", "run-web-2", "run-db-1", "run-db-2"}, ids)
+	assert.Equal(t, []string{"web", "web", "db", "db"}, workspaces)
+
+	// The workspaces are narrowed by wq's tag filter, the runs by rq's status
+	// filter, applied once.
+	for _, q := range queries {
+		path, raw, _ := strings.Cut(q, "?")
+		values, err := url.ParseQuery(raw)
+		require.NoError(t, err)
+		switch path {
+		case "/api/v2/organizations/acme/workspaces":
+			assert.Equal(t, "env", values.Get("filter[tagged][0][key]"))
+		case "/api/v2/organizations/acme/runs":
+			assert.Equal(t, "applied", values.Get("filter[status]"))
+		}
+	}
+}
+
+func TestAllWorkspacesFetcherFactory_LimitPerWorkspace(t *testing.T) {
+	var queries []string
+	srv := newWorkspacesServer(t, &queries)
+
+	var ids, workspaces []string
+	err := runWorkspacesCommand(t, []string{"--limit", "1"}, func(ctx context.Context, cmd *cli.Command) error {
+		be, err := remote.NewBackendRemote(ctx, cmd, remote.BuckNaked(), remote.WithClient(fakeTFEClient(srv)))
+		require.NoError(t, err)
+
+		fn, workspaceOf := AllWorkspacesFetcherFactory(be, svqWorkspaceStateVersions)
+		versions, err := fn(ctx, cmd)
+		for _, sv := range versions {
+			ids = append(ids, sv.ID)
+			workspaces = append(workspaces, workspaceOf(sv))
+		}
+		return err
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"sv-web-1", "sv-db-1"}, ids)
+	assert.Equal(t, []string{"web", "db"}, workspaces)
+	for _, q := range queries {
+		assert.NotContains(t, q, "page%5Bnumber%5D=2", "paging should stop at the limit")
+	}
+}
+
+func TestAllWorkspacesFetcherFactory_Errors(t *testing.T) {
+	var queries []string
+	srv := newWorkspacesServer(t, &queries)
+
+	run := func(args ...string) ([]*tfe.Run, error) {
+		var runs []*tfe.Run
+		err := runWorkspacesCommand(t, args, func(ctx context.Context, cmd *cli.Command) error {
+			be, err := remote.NewBackendRemote(ctx, cmd, remote.BuckNaked(), remote.WithClient(fakeTFEClient(srv)))
+			require.NoError(t, err)
+			fn, _ := AllWorkspacesFetcherFactory(be, rqWorkspaceRuns)
+			runs, err = fn(ctx, cmd)
+			return err
+		})
+		return runs, err
+	}
+
+	_, err := run("--filter", "_status=errored")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "workspace db: ")
+
+	runs, err := run("--filter", "_status=errored", "--partial")
+	var partial *PartialError
+	require.ErrorAs(t, err, &partial)
+	assert.Equal(t, 2, partial.Total)
+	require.Len(t, partial.Errors, 1)
+	assert.Equal(t, "workspace=db", partial.Errors[0].Source)
+	assert.Len(t, runs, 2)
+
+	_, err = run("--workspace", "web")
+	require.EqualError(t, err, "--all-workspaces and --workspace can't be used together")
+
+	err = runWorkspacesCommand(t, nil, func(ctx context.Context, cmd *cli.Command) error {
+		fn, _ := AllWorkspacesFetcherFactory(&s3.BackendS3{}, rqWorkspaceRuns)
+		_, err := fn(ctx, cmd)
+		return err
+	})
+	require.ErrorIs(t, err, errAllWorkspaces)
+}
+
+// TestSelectWorkspaces verifies --all-workspaces takes the workspaces the
+// backend selects, narrowed by the server-side workspace filters, or the whole
+// organization when the backend selects none.
+func TestSelectWorkspaces(t *testing.T) {
+	var queries []string
+	srv := newWorkspacesServer(t, &queries)
+
+	selectWith := func(name string, args ...string) []string {
+		var names []string
+		err := runWorkspacesCommand(t, args, func(ctx context.Context, cmd *cli.Command) error {
+			be, err := remote.NewBackendRemote(ctx, cmd, remote.BuckNaked(), remote.WithClient(fakeTFEClient(srv)))
+			require.NoError(t, err)
+			be.Backend.Config.Workspaces.Name = name
+			client, err := be.Client()
+			require.NoError(t, err)
+			names, err = selectWorkspaces(ctx, cmd, be, client, "acme")
+			return err
+		})
+		require.NoError(t, err)
+		return names
+	}
+
+	assert.Equal(t, []string{"web", "db"}, selectWith(""))
+
+	queries = nil
+	assert.Equal(t, []string{"db"}, selectWith("db"))
+	for _, q := range queries {
+		assert.NotContains(t, q, "/workspaces", "a backend's own selection needs no listing")
+	}
+
+	assert.Equal(t, []string{"db"}, selectWith("db", "--filter", "_tag.env=prod"))
+	assert.Empty(t, selectWith("other", "--filter", "_tag.env=prod"))
+}
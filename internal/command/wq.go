@@ -5,8 +5,13 @@ package command
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"reflect"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/apex/log"
 	"github.com/hashicorp/go-tfe"
@@ -14,6 +19,7 @@ import (
 
 	"github.com/staranto/tfctl/internal/filters"
 	"github.com/staranto/tfctl/internal/meta"
+	"github.com/staranto/tfctl/internal/util"
 )
 
 // wqDefaultAttrs specifies the default attributes displayed for workspaces
@@ -26,11 +32,19 @@ func wqCommandAction(ctx context.Context, cmd *cli.Command) error {
 	// We need to build the builder inside the action so we can access the
 	// client. The builder will handle backend/org init, but we need a way to
 	// pass the client-bound fetcher. Let's use a custom approach.
-	be, org, client, err := InitRemoteOrgQuery(ctx, cmd)
+	be, orgs, client, err := InitRemoteOrgQuery(ctx, cmd)
 	if err != nil {
 		return err
 	}
 
+	// --limit caps the rows left after --stale and --execution-mode, so paging
+	// can only stop at the limit when neither drops fetched rows.
+	limit := cmd.Int("limit")
+	pageLimit := limit
+	if cmd.String("stale") != "" || cmd.String("execution-mode") != "" {
+		pageLimit = 0
+	}
+
 	// Create a fetcher that captures the client in a closure
 	fetcher := func(
 		ctx context.Context,
@@ -41,19 +55,39 @@ func wqCommandAction(ctx context.Context, cmd *cli.Command) error {
 		if err != nil {
 			return nil, nil, err
 		}
-		return page.Items, page.Pagination, nil
+		return page.Items, limitedPagination(page.Pagination, opts.PageSize, pageLimit), nil
 	}
 
 	// Manually call RemoteQueryFetcherFactory and QueryActionRunner since we
 	// already have be, org, client initialized
 	fn := RemoteQueryFetcherFactory(
 		be,
-		org,
+		orgs,
 		fetcher,
 		wqServerSideFilterAugmenter,
 		"list workspaces",
 	)
 
+	if stale := cmd.String("stale"); stale != "" {
+		age, _ := parseAge(stale)
+		fn = wqKeep(fn, func(results []*tfe.Workspace) []*tfe.Workspace {
+			return wqStale(results, time.Now().Add(-age))
+		})
+	}
+
+	if modes := cmd.String("execution-mode"); modes != "" {
+		fn = wqKeep(fn, func(results []*tfe.Workspace) []*tfe.Workspace {
+			return wqExecutionMode(results, strings.Split(modes, ","))
+		})
+	}
+
+	// --limit caps the rows left after the filters above; 0 means no cap.
+	if limit > 0 {
+		fn = wqKeep(fn, func(results []*tfe.Workspace) []*tfe.Workspace {
+			return util.Limit(results, limit)
+		})
+	}
+
 	return NewQueryActionRunner(
 		"wq",
 		reflect.TypeOf((*tfe.Workspace)(nil)).Elem(),
@@ -97,11 +131,96 @@ func wqServerSideFilterAugmenter(
 		}
 	}
 
+	// --stale needs the current run's timestamp, which the listing only carries
+	// when included. The augmenter runs for every page, so include it once.
+	if cmd.String("stale") != "" && !slices.Contains(opts.Include, tfe.WSCurrentRun) {
+		opts.Include = append(opts.Include, tfe.WSCurrentRun)
+	}
+
 	log.Debugf("opts after augmentation: %+v", opts)
 
 	return nil
 }
 
+// wqKeep wraps list so its results are narrowed by keep. The rows of a
+// partially failed multi-org query are narrowed too and the error is kept.
+func wqKeep(
+	list func(context.Context, *cli.Command) ([]*tfe.Workspace, error),
+	keep func([]*tfe.Workspace) []*tfe.Workspace,
+) func(context.Context, *cli.Command) ([]*tfe.Workspace, error) {
+	return func(ctx context.Context, cmd *cli.Command) ([]*tfe.Workspace, error) {
+		results, err := list(ctx, cmd)
+		var partial *PartialError
+		if err != nil && !errors.As(err, &partial) {
+			return nil, err
+		}
+		return keep(results), err
+	}
+}
+
+// wqExecutionModes are the execution modes a workspace can be set to.
+var wqExecutionModes = []string{"agent", "local", "remote"}
+
+// validateExecutionModes checks a comma-separated list of execution modes.
+func validateExecutionModes(value string) error {
+	for _, mode := range strings.Split(value, ",") {
+		if !slices.Contains(wqExecutionModes, mode) {
+			return fmt.Errorf("invalid execution mode %q: expected one of %s",
+				mode, strings.Join(wqExecutionModes, ", "))
+		}
+	}
+	return nil
+}
+
+// wqExecutionMode returns the workspaces whose execution mode is one of modes.
+// The list API has no execution mode filter, so this is applied client-side.
+func wqExecutionMode(workspaces []*tfe.Workspace, modes []string) []*tfe.Workspace {
+	var matched []*tfe.Workspace
+	for _, ws := range workspaces {
+		if slices.Contains(modes, ws.ExecutionMode) {
+			matched = append(matched, ws)
+		}
+	}
+	return matched
+}
+
+// wqStale returns the workspaces whose last activity was before cutoff. The
+// last activity is the creation of the current run or, for a workspace that
+// has never run, the creation of the workspace.
+func wqStale(workspaces []*tfe.Workspace, cutoff time.Time) []*tfe.Workspace {
+	var stale []*tfe.Workspace
+	for _, ws := range workspaces {
+		last := ws.CreatedAt
+		if ws.CurrentRun != nil && !ws.CurrentRun.CreatedAt.IsZero() {
+			last = ws.CurrentRun.CreatedAt
+		}
+		if last.Before(cutoff) {
+			stale = append(stale, ws)
+		}
+	}
+	return stale
+}
+
+// parseAge parses an age such as 30d, 2w or 36h. Days and weeks are added to
+// the units time.ParseDuration accepts.
+func parseAge(s string) (time.Duration, error) {
+	units := map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour}
+	for suffix, unit := range units {
+		if n, ok := strings.CutSuffix(s, suffix); ok {
+			if count, err := strconv.Atoi(n); err == nil && count >= 0 {
+				return time.Duration(count) * unit, nil
+			}
+			return 0, fmt.Errorf("invalid age %q: expected a number of %s, e.g. 30%s", s, suffix, suffix)
+		}
+	}
+
+	age, err := time.ParseDuration(s)
+	if err != nil || age < 0 {
+		return 0, fmt.Errorf("invalid age %q: expected e.g. 30d, 2w or 36h", s)
+	}
+	return age, nil
+}
+
 // wqCommandBuilder constructs the cli.Command for "wq", wiring metadata,
 // flags, and action handlers.
 func wqCommandBuilder(meta meta.Meta) *cli.Command {
@@ -110,14 +229,28 @@ func wqCommandBuilder(meta meta.Meta) *cli.Command {
 		Usage:     "workspace query",
 		UsageText: "tfctl wq [RootDir] [options]",
 		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:      "execution-mode",
+				Usage:     "only workspaces with this execution mode (agent, local, remote), comma-separated",
+				Validator: validateExecutionModes,
+			},
 			&cli.IntFlag{
 				Name:    "limit",
 				Aliases: []string{"l"},
-				Usage:   "limit workspaces returned",
-				Value:   99999,
+				Usage:   "limit workspaces returned, 0 for no limit",
+				Value:   0,
+			},
+			NewHostFlag(meta.Profile, "wq", meta.Config.Source),
+			NewOrgFlag(meta.Profile, "wq", meta.Config.Source),
+			NewPartialFlag(),
+			&cli.StringFlag{
+				Name:  "stale",
+				Usage: "only workspaces whose current run is older than this age, e.g. 30d",
+				Validator: func(value string) error {
+					_, err := parseAge(value)
+					return err
+				},
 			},
-			NewHostFlag("wq", meta.Config.Source),
-			NewOrgFlag("wq", meta.Config.Source),
 		},
 		Action: wqCommandAction,
 		Meta:   meta,
@@ -0,0 +1,189 @@
+// Copyright (c) 2026 Steve Taranto <staranto@gmail.com>.
+// SPDX-License-Identifier: Apache-2.0
+// no-cloc
+
+package command
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-tfe"
+	"github.com/hashicorp/jsonapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v3"
+
+	"github.com/staranto/tfctl/internal/attrs"
+	"github.com/staranto/tfctl/internal/output"
+)
+
+func TestWqStale(t *testing.T) {
+	now := time.Date(2026, 3, 31, 12, 0, 0, 0, time.UTC)
+	daysAgo := func(d int) time.Time { return now.AddDate(0, 0, -d) }
+
+	workspaces := []*tfe.Workspace{
+		{Name: "recent-run", CreatedAt: daysAgo(400), CurrentRun: &tfe.Run{CreatedAt: daysAgo(2)}},
+		{Name: "old-run", CreatedAt: daysAgo(400), CurrentRun: &tfe.Run{CreatedAt: daysAgo(45)}},
+		{Name: "never-run-old", CreatedAt: daysAgo(90)},
+		{Name: "never-run-new", CreatedAt: daysAgo(1)},
+		{Name: "run-not-included", CreatedAt: daysAgo(60), CurrentRun: &tfe.Run{ID: "run-1"}},
+		{Name: "boundary", CreatedAt: daysAgo(400), CurrentRun: &tfe.Run{CreatedAt: daysAgo(30)}},
+	}
+
+	var names []string
+	for _, ws := range wqStale(workspaces, daysAgo(30)) {
+		names = append(names, ws.Name)
+	}
+	assert.Equal(t, []string{"old-run", "never-run-old", "run-not-included"}, names)
+
+	assert.Empty(t, wqStale(workspaces, daysAgo(500)))
+	assert.Empty(t, wqStale(nil, now))
+}
+
+func TestParseAge(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    time.Duration
+		wantErr bool
+	}{
+		{in: "30d", want: 30 * 24 * time.Hour},
+		{in: "2w", want: 14 * 24 * time.Hour},
+		{in: "36h", want: 36 * time.Hour},
+		{in: "1h30m", want: 90 * time.Minute},
+		{in: "0d", want: 0},
+		{in: "d", wantErr: true},
+		{in: "-3d", wantErr: true},
+		{in: "1.5d", wantErr: true},
+		{in: "-1h", wantErr: true},
+		{in: "soon", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := parseAge(tt.in)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestWqServerSideFilterAugmenter_Stale(t *testing.T) {
+	cmd := &cli.Command{Flags: []cli.Flag{
+		&cli.StringFlag{Name: "filter"},
+		&cli.StringFlag{Name: "stale", Value: "30d"},
+	}}
+
+	// The augmenter runs once per page against the same options.
+	opts := &tfe.WorkspaceListOptions{}
+	require.NoError(t, wqServerSideFilterAugmenter(context.Background(), cmd, opts))
+	require.NoError(t, wqServerSideFilterAugmenter(context.Background(), cmd, opts))
+	assert.Equal(t, []tfe.WSIncludeOpt{tfe.WSCurrentRun}, opts.Include)
+
+	cmd = &cli.Command{Flags: []cli.Flag{&cli.StringFlag{Name: "filter"}, &cli.StringFlag{Name: "stale"}}}
+	opts = &tfe.WorkspaceListOptions{}
+	require.NoError(t, wqServerSideFilterAugmenter(context.Background(), cmd, opts))
+	assert.Empty(t, opts.Include)
+}
+
+func TestWqExecutionMode(t *testing.T) {
+	workspaces := []*tfe.Workspace{
+		{Name: "app", ExecutionMode: "remote"},
+		{Name: "laptop", ExecutionMode: "local"},
+		{Name: "private", ExecutionMode: "agent"},
+		{Name: "legacy"},
+	}
+
+	names := func(ws []*tfe.Workspace) []string {
+		var out []string
+		for _, w := range ws {
+			out = append(out, w.Name)
+		}
+		return out
+	}
+
+	assert.Equal(t, []string{"private"}, names(wqExecutionMode(workspaces, []string{"agent"})))
+	assert.Equal(t, []string{"app", "private"}, names(wqExecutionMode(workspaces, []string{"remote", "agent"})))
+	assert.Empty(t, wqExecutionMode(nil, []string{"local"}))
+}
+
+func TestValidateExecutionModes(t *testing.T) {
+	require.NoError(t, validateExecutionModes("agent"))
+	require.NoError(t, validateExecutionModes("local,remote"))
+	assert.ErrorContains(t, validateExecutionModes("cloud"), `invalid execution mode "cloud"`)
+	assert.ErrorContains(t, validateExecutionModes("agent,"), `invalid execution mode ""`)
+}
+
+func TestWqKeep(t *testing.T) {
+	workspaces := []*tfe.Workspace{
+		{Name: "app", ExecutionMode: "remote"},
+		{Name: "private", ExecutionMode: "agent"},
+	}
+	keep := func(ws []*tfe.Workspace) []*tfe.Workspace {
+		return wqExecutionMode(ws, []string{"agent"})
+	}
+
+	// A partial failure keeps the narrowed rows and the error.
+	partial := &PartialError{}
+	fn := wqKeep(func(context.Context, *cli.Command) ([]*tfe.Workspace, error) {
+		return workspaces, partial
+	}, keep)
+	got, err := fn(context.Background(), &cli.Command{})
+	assert.ErrorIs(t, err, partial)
+	require.Len(t, got, 1)
+	assert.Equal(t, "private", got[0].Name)
+
+	// Any other failure drops the rows.
+	fn = wqKeep(func(context.Context, *cli.Command) ([]*tfe.Workspace, error) {
+		return workspaces, errors.New("boom")
+	}, keep)
+	got, err = fn(context.Background(), &cli.Command{})
+	assert.EqualError(t, err, "boom")
+	assert.Nil(t, got)
+}
+
+// TestWq_ExecutionAttrs verifies the execution mode and agent pool of a
+// listed workspace resolve as attributes.
+func TestWq_ExecutionAttrs(t *testing.T) {
+	srv := newFakeTFEServer(t, fakeTFERoute{suffix: "/api/v2/organizations/acme/workspaces", pages: 1,
+		render: func(*http.Request, int) string {
+			return `{"id":"ws-1","type":"workspaces","attributes":{"name":"app","execution-mode":"remote"},` +
+				`"relationships":{"agent-pool":{"data":null}}},` +
+				`{"id":"ws-2","type":"workspaces","attributes":{"name":"private","execution-mode":"agent"},` +
+				`"relationships":{"agent-pool":{"data":{"id":"apool-1","type":"agent-pools"}}}}`
+		},
+	})
+
+	client, err := tfe.NewClient(&tfe.Config{Address: srv.URL, Token: "test"})
+	require.NoError(t, err)
+
+	page, err := client.Workspaces.List(context.Background(), "acme", nil)
+	require.NoError(t, err)
+	require.Len(t, page.Items, 2)
+
+	var raw bytes.Buffer
+	require.NoError(t, jsonapi.MarshalPayload(&raw, page.Items))
+
+	var al attrs.AttrList
+	for _, a := range []string{"name", "execution-mode", ".relationships.agent-pool.data.id:agent-pool"} {
+		require.NoError(t, al.Set(a))
+	}
+
+	out := &cli.Command{Flags: []cli.Flag{&cli.StringFlag{Name: "output", Value: "json"}}}
+	buf := new(bytes.Buffer)
+	output.SliceDiceSpit(raw, al, out, "data", buf, nil)
+
+	got := strings.Join(strings.Fields(buf.String()), "")
+	assert.Contains(t, got, `"execution-mode":"remote"`)
+	assert.Contains(t, got, `"agent-pool":"apool-1"`)
+	assert.Contains(t, got, `"execution-mode":"agent"`)
+}
@@ -17,7 +17,9 @@ import (
 // Type is the in-memory representation of the loaded configuration.
 //
 // Fields:
-//   - Source: absolute path of the YAML file loaded.
+//   - Source: absolute path of the YAML file loaded. When several files are
+//     merged, this is the last (highest precedence) top-level file.
+//   - Sources: every file merged into Data, includes first, in merge order.
 //   - Namespace: optional dot-prefixed keyspace used to prefer namespaced
 //     lookups (e.g. "backend.s3.region").
 //   - Data: raw key/value tree unmarshaled from YAML.
@@ -26,6 +28,7 @@ import (
 // Callers should use typed getters (GetString, GetInt) for convenience.
 type Type struct {
 	Source    string
+	Sources   []string
 	Namespace string
 	Data      map[string]interface{}
 }
@@ -134,35 +137,146 @@ func GetStringSlice(key string, defaultValue ...[]string) ([]string, error) {
 	}
 }
 
-// Load reads the YAML configuration file from the standard user config
-// directory and populates the global Config. If cfgFilePath is provided in the
-// future, it can be used to override the path selection (currently ignored).
-//
-// Returns the loaded Type or an error if the file could not be located or
-// parsed.
-func Load(cfgFilePath ...string) (Type, error) {
-	path, err := getConfigFile()
+// GetBool returns the boolean value for the given dotted key path. If the key
+// is not found and a single defaultValue is provided, the default is returned.
+// Besides YAML booleans, the strings true/false, yes/no, on/off and 1/0 are
+// accepted in any case. Returns an error for any other value.
+func GetBool(key string, defaultValue ...bool) (bool, error) {
+	if len(Config.Data) == 0 {
+		_, _ = Load()
+	}
+
+	val, err := Config.get(key)
+	if err != nil && Config.Namespace != "" {
+		val, err = Config.get(Config.Namespace + "." + key)
+	}
 	if err != nil {
-		return Type{}, err
+		if len(defaultValue) == 1 {
+			return defaultValue[0], nil
+		}
+		return false, err
 	}
 
-	bytes, err := os.ReadFile(path)
+	switch v := val.(type) {
+	case bool:
+		return v, nil
+	case string:
+		switch strings.ToLower(strings.TrimSpace(v)) {
+		case "true", "yes", "on", "1":
+			return true, nil
+		case "false", "no", "off", "0":
+			return false, nil
+		}
+	}
+
+	return false, errors.New("value is not a bool")
+}
+
+// Load reads the YAML configuration file(s) and populates the global Config.
+// If cfgFilePath is provided in the future, it can be used to override the path
+// selection (currently ignored).
+//
+// TFCTL_CFG_FILE may list several files separated by the OS path list
+// separator (":" on Unix). Each file may also name other files in a top-level
+// "includes" list, resolved relative to the including file. All files are
+// deep-merged into a single tree: includes are merged before the file that
+// names them, and files are merged in the order listed, so later files
+// override earlier ones at the leaf level. Maps merge key by key; any other
+// value, including lists, replaces the earlier value outright.
+//
+// Returns the loaded Type or an error if a file could not be located or
+// parsed, or if includes form a cycle.
+func Load(cfgFilePath ...string) (Type, error) {
+	paths, err := getConfigFiles()
 	if err != nil {
 		return Type{}, err
 	}
 
-	var data map[string]interface{}
-	if err := yaml.Unmarshal(bytes, &data); err != nil {
-		return Type{}, err
+	data := map[string]interface{}{}
+	var sources []string
+	for _, path := range paths {
+		if err := loadFile(path, nil, data, &sources); err != nil {
+			return Type{}, err
+		}
 	}
 
 	Config = Type{
-		Source: path,
-		Data:   data}
+		Source:  paths[len(paths)-1],
+		Sources: sources,
+		Data:    data}
 
 	return Config, nil
 }
 
+// loadFile reads path, merges its includes and then its own keys into data,
+// and records each file read in sources. stack holds the chain of files
+// currently being included and is used to detect cycles.
+func loadFile(path string, stack []string, data map[string]interface{}, sources *[]string) error {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+
+	for _, seen := range stack {
+		if seen == abs {
+			return fmt.Errorf("config include cycle: %s", strings.Join(append(stack, abs), " -> "))
+		}
+	}
+	stack = append(stack, abs)
+
+	bytes, err := os.ReadFile(abs)
+	if err != nil {
+		return err
+	}
+
+	var fileData map[string]interface{}
+	if err := yaml.Unmarshal(bytes, &fileData); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", abs, err)
+	}
+
+	if includes, ok := fileData["includes"]; ok {
+		delete(fileData, "includes")
+
+		list, ok := includes.([]interface{})
+		if !ok {
+			return fmt.Errorf("%s: includes must be a list of paths", abs)
+		}
+
+		for _, item := range list {
+			include, ok := item.(string)
+			if !ok {
+				return fmt.Errorf("%s: includes must be a list of paths", abs)
+			}
+			if !filepath.IsAbs(include) {
+				include = filepath.Join(filepath.Dir(abs), include)
+			}
+			if err := loadFile(include, stack, data, sources); err != nil {
+				return err
+			}
+		}
+	}
+
+	log.Debugf("merging config file: %s", abs)
+	merge(data, fileData)
+	*sources = append(*sources, abs)
+
+	return nil
+}
+
+// merge deep-merges src into dst. Nested maps are merged key by key and any
+// other value in src replaces the value in dst.
+func merge(dst, src map[string]interface{}) {
+	for key, value := range src {
+		srcMap, srcIsMap := value.(map[string]interface{})
+		dstMap, dstIsMap := dst[key].(map[string]interface{})
+		if srcIsMap && dstIsMap {
+			merge(dstMap, srcMap)
+			continue
+		}
+		dst[key] = value
+	}
+}
+
 // get traverses the configuration tree using a dotted key path (e.g.
 // "backend.s3.bucket"). If Namespace is set, a namespaced candidate key is
 // attempted first (Namespace + "." + kspec), then the unnamespaced key.
@@ -203,37 +317,48 @@ func (cfg *Type) get(kspec string) (any, error) {
 	return nil, fmt.Errorf("no valid path found among: %v", candidateKeys)
 }
 
-// getConfigFile returns the absolute path to the YAML config file. If the
-// TFCTL_CFG_FILE environment variable is set, it is treated as the full path to
-// the config file. Otherwise, the OS-specific user configuration directory
-// returned by os.UserConfigDir is used with the filename "tfctl.yaml". The file
-// must exist and not be a directory.
-func getConfigFile() (string, error) {
+// getConfigFiles returns the absolute paths of the top-level YAML config
+// files. If the TFCTL_CFG_FILE environment variable is set, it is treated as a
+// list of full paths separated by the OS path list separator. Otherwise, the
+// OS-specific user configuration directory returned by os.UserConfigDir is
+// used with the filename "tfctl.yaml". Every file must exist and not be a
+// directory.
+func getConfigFiles() ([]string, error) {
 	// Check for TFCTL_CFG_FILE environment variable first
-	if cfgPath := os.Getenv("TFCTL_CFG_FILE"); cfgPath != "" {
-		if fileInfo, err := os.Stat(cfgPath); err == nil {
-			if !fileInfo.IsDir() {
-				log.Debugf("using config file from TFCTL_CFG_FILE: %s", cfgPath)
-				return cfgPath, nil
+	if cfgPaths := os.Getenv("TFCTL_CFG_FILE"); cfgPaths != "" {
+		var paths []string
+		for _, cfgPath := range filepath.SplitList(cfgPaths) {
+			if cfgPath == "" {
+				continue
+			}
+			fileInfo, err := os.Stat(cfgPath)
+			if err != nil {
+				return nil, fmt.Errorf("config file not found at TFCTL_CFG_FILE path: %s", cfgPath)
+			}
+			if fileInfo.IsDir() {
+				return nil, fmt.Errorf("TFCTL_CFG_FILE points to a directory: %s", cfgPath)
 			}
-			return "", fmt.Errorf("TFCTL_CFG_FILE points to a directory: %s", cfgPath)
+			log.Debugf("using config file from TFCTL_CFG_FILE: %s", cfgPath)
+			paths = append(paths, cfgPath)
+		}
+		if len(paths) > 0 {
+			return paths, nil
 		}
-		return "", fmt.Errorf("config file not found at TFCTL_CFG_FILE path: %s", cfgPath)
 	}
 
 	// Fall back to user config directory
 	dir, err := os.UserConfigDir()
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	file := filepath.Join(dir, "tfctl.yaml")
 	if fileInfo, err := os.Stat(file); err == nil {
 		if !fileInfo.IsDir() {
 			log.Debugf("using config file: %s", file)
-			return file, nil
+			return []string{file}, nil
 		}
 	}
 
-	return "", fmt.Errorf("no config file found in standard locations")
+	return nil, fmt.Errorf("no config file found in standard locations")
 }
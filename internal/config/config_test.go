@@ -9,6 +9,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // setupTestConfig sets TFCTL_CFG_FILE to point to a test config file.
@@ -574,3 +575,185 @@ func TestGetStringSlice_ErrorCases(t *testing.T) {
 		assert.Error(t, err)
 	})
 }
+
+func TestValidate(t *testing.T) {
+	withConfig(t, "validate.yaml", func(t *testing.T) {
+		issues := Validate(Config.Data, []string{"sq", "wq"})
+
+		var got []string
+		for _, issue := range issues {
+			got = append(got, issue.String())
+		}
+
+		assert.Equal(t, []string{
+			"cache: expected map, got int 24",
+			"colors.odd: expected string, got int 12",
+			"orgg: unknown key",
+			"padding: expected int, got string \"2\"",
+			"pagination.paralelism: unknown key",
+			"profiles.prod.attrs: expected string, bool or number, got list",
+			"profiles.prod.chdir: not allowed in a profile, use --chdir",
+			"profiles.staging: expected map, got string \"bogus\"",
+			"sq.broken: expected list of strings, got string \"--sort name\"",
+			"sq.views.bad: expected string, got list",
+			"wq: expected map, got string \"bogus\"",
+		}, got)
+	})
+}
+
+func TestValidate_Clean(t *testing.T) {
+	data := map[string]interface{}{
+		"org":     "acme",
+		"padding": 2,
+		"help":    map[string]interface{}{"order": "grouped"},
+		"wq":      map[string]interface{}{"defaults": []interface{}{"--sort name"}},
+		"color":   false,
+		"sq":      map[string]interface{}{"color": "always"},
+		"profiles": map[string]interface{}{
+			"prod": map[string]interface{}{"host": "tfe.example.com", "limit": 10, "color": true},
+		},
+	}
+	assert.Empty(t, Validate(data, []string{"sq", "wq"}))
+
+	issues := Validate(map[string]interface{}{"sq": map[string]interface{}{"color": 1}}, []string{"sq"})
+	require.Len(t, issues, 1)
+	assert.Equal(t, "sq.color: expected bool or string, got int 1", issues[0].String())
+}
+
+func TestLoad_Includes(t *testing.T) {
+	withConfig(t, "include-user.yaml", func(t *testing.T) {
+		// Leaf values in the including file override the include.
+		padding, err := GetInt("padding")
+		assert.NoError(t, err)
+		assert.Equal(t, 3, padding)
+
+		// Maps merge key by key.
+		title, _ := GetString("colors.title")
+		even, _ := GetString("colors.even")
+		assert.Equal(t, "#333333", title)
+		assert.Equal(t, "#222222", even)
+
+		// Keys only in the include are kept and includes itself is dropped.
+		org, _ := GetString("org")
+		assert.Equal(t, "base-org", org)
+		_, err = Config.get("includes")
+		assert.Error(t, err)
+
+		require.Len(t, Config.Sources, 2)
+		assert.Equal(t, "include-base.yaml", filepath.Base(Config.Sources[0]))
+		assert.Equal(t, "include-user.yaml", filepath.Base(Config.Sources[1]))
+	})
+}
+
+func TestLoad_PathList(t *testing.T) {
+	user, err := filepath.Abs(filepath.Join("testdata", "include-user.yaml"))
+	require.NoError(t, err)
+	override, err := filepath.Abs(filepath.Join("testdata", "include-override.yaml"))
+	require.NoError(t, err)
+
+	t.Setenv("TFCTL_CFG_FILE", user+string(filepath.ListSeparator)+override)
+	Config = Type{}
+	defer func() { Config = Type{} }()
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, override, cfg.Source)
+
+	// The later file wins, lists are replaced rather than appended.
+	org, _ := GetString("org")
+	assert.Equal(t, "override-org", org)
+	defaults, err := GetStringSlice("wq.defaults")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"--sort -updated-at"}, defaults)
+
+	padding, _ := GetInt("padding")
+	assert.Equal(t, 3, padding)
+}
+
+func TestLoad_IncludeCycle(t *testing.T) {
+	cleanup := setupTestConfig(t, "include-cycle-a.yaml")
+	defer cleanup()
+
+	_, err := Load()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "config include cycle")
+	assert.Contains(t, err.Error(), "include-cycle-b.yaml")
+}
+
+func TestGetBool(t *testing.T) {
+	tests := []struct {
+		name         string
+		key          string
+		namespace    string
+		defaultValue []bool
+		want         bool
+		wantErr      bool
+	}{
+		{name: "yaml true", key: "enabled", want: true},
+		{name: "yaml false", key: "disabled", want: false},
+		{name: "truthy string", key: "quoted_yes", want: true},
+		{name: "falsy string mixed case", key: "quoted_off", want: false},
+		{name: "numeric string", key: "quoted_one", want: true},
+		{name: "nested value", key: "nested.feature.flag", want: true},
+		{name: "namespace fallback", key: "verbose", namespace: "sq", want: true},
+		{name: "missing key with default", key: "missing", defaultValue: []bool{true}, want: true},
+		{name: "missing key without default", key: "missing", wantErr: true},
+		{name: "non-bool string", key: "not_bool", wantErr: true},
+		{name: "non-bool number", key: "number", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withConfig(t, "bool.yaml", func(t *testing.T) {
+				Config.Namespace = tt.namespace
+
+				got, err := GetBool(tt.key, tt.defaultValue...)
+
+				if tt.wantErr {
+					assert.Error(t, err)
+					return
+				}
+
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			})
+		})
+	}
+}
+
+func TestGetStringSlice(t *testing.T) {
+	tests := []struct {
+		name         string
+		key          string
+		namespace    string
+		defaultValue [][]string
+		want         []string
+		wantErr      bool
+	}{
+		{name: "top-level list", key: "list_top", want: []string{"a", "b"}},
+		{name: "nested list", key: "nested.inner.list", want: []string{"one", "two three"}},
+		{name: "namespace fallback", key: "test", namespace: "sq", want: []string{"--output json", "--sort resource,id"}},
+		{name: "missing key with default", key: "missing", defaultValue: [][]string{{"x"}}, want: []string{"x"}},
+		{name: "missing key without default", key: "missing", wantErr: true},
+		{name: "non-string element", key: "nonstring_list", wantErr: true},
+		{name: "scalar value", key: "not_a_list", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withConfig(t, "string-slice.yaml", func(t *testing.T) {
+				Config.Namespace = tt.namespace
+
+				got, err := GetStringSlice(tt.key, tt.defaultValue...)
+
+				if tt.wantErr {
+					assert.Error(t, err)
+					return
+				}
+
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			})
+		})
+	}
+}
@@ -9,5 +9,7 @@
 //   - Windows: %APPDATA%/tfctl/tfctl.yaml
 //
 // Actual resolution relies on os.UserConfigDir which follows platform
-// conventions.
+// conventions. TFCTL_CFG_FILE may name one or more files instead, and any file
+// may pull in others through a top-level "includes" list. Files are deep-merged
+// in load order, with later files winning.
 package config
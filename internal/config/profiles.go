@@ -0,0 +1,71 @@
+// Copyright (c) 2026 Steve Taranto <staranto@gmail.com>.
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// Profile returns the flag defaults of the profiles.<name> block, keyed by
+// flag name. YAML booleans and numbers are rendered as they would be given on
+// the command line. A missing profile, or one that isn't a map of scalars, is
+// an error.
+func Profile(name string) (map[string]string, error) {
+	if len(Config.Data) == 0 {
+		_, _ = Load()
+	}
+
+	val, err := Config.get("profiles." + name)
+	if err != nil {
+		return nil, fmt.Errorf("no profiles.%s in config", name)
+	}
+	m, ok := val.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("profiles.%s: expected map, got %s", name, describe(val))
+	}
+
+	values := make(map[string]string, len(m))
+	for flag, v := range m {
+		switch v := v.(type) {
+		case string:
+			values[flag] = v
+		case bool:
+			values[flag] = strconv.FormatBool(v)
+		case int:
+			values[flag] = strconv.Itoa(v)
+		case int64:
+			values[flag] = strconv.FormatInt(v, 10)
+		case float64:
+			values[flag] = strconv.FormatFloat(v, 'f', -1, 64)
+		default:
+			return nil, fmt.Errorf("profiles.%s.%s: expected %s, got %s", name, flag, KindScalar, describe(v))
+		}
+	}
+	return values, nil
+}
+
+// ProfileNames returns the names of the profiles in the config, sorted.
+func ProfileNames() []string {
+	if len(Config.Data) == 0 {
+		_, _ = Load()
+	}
+
+	val, err := Config.get("profiles")
+	if err != nil {
+		return nil
+	}
+	m, ok := val.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,42 @@
+// Copyright (c) 2026 Steve Taranto <staranto@gmail.com>.
+// SPDX-License-Identifier: Apache-2.0
+// no-cloc
+
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProfile(t *testing.T) {
+	withConfig(t, "profiles.yaml", func(t *testing.T) {
+		values, err := Profile("prod")
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{
+			"host":  "tfe.example.com",
+			"org":   "acme",
+			"limit": "10",
+			"color": "true",
+		}, values)
+
+		_, err = Profile("missing")
+		require.EqualError(t, err, "no profiles.missing in config")
+
+		_, err = Profile("bogus")
+		require.EqualError(t, err, `profiles.bogus: expected map, got string "nope"`)
+
+		_, err = Profile("broken")
+		require.EqualError(t, err, "profiles.broken.attrs: expected string, bool or number, got list")
+
+		assert.Equal(t, []string{"bogus", "broken", "prod", "staging"}, ProfileNames())
+	})
+}
+
+func TestProfileNames_None(t *testing.T) {
+	withConfig(t, "simple.yaml", func(t *testing.T) {
+		assert.Empty(t, ProfileNames())
+	})
+}
@@ -0,0 +1,56 @@
+// Copyright (c) 2026 Steve Taranto <staranto@gmail.com>.
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ExpandSets replaces every "@<set>" argument in args with the arguments of
+// the set of that name under the command's namespace, e.g. wq.prod for
+// "wq @prod". Each entry of a set is split on whitespace. An entry may itself
+// name another set, which is expanded depth-first in its place, so a set can
+// build on @defaults or any other set. A set that includes itself, directly
+// or through others, is an error.
+//
+// An "@<name>" argument that names no set is left as is, so that RootDir forms
+// such as "@prod" still reach the command.
+func ExpandSets(command string, args []string) ([]string, error) {
+	return expandSets(command, args, nil)
+}
+
+// expandSets expands args, stack being the sets being expanded above them.
+func expandSets(command string, args []string, stack []string) ([]string, error) {
+	var out []string
+	for _, arg := range args {
+		name, ok := strings.CutPrefix(arg, "@")
+		if !ok || name == "" {
+			out = append(out, arg)
+			continue
+		}
+		entries, err := GetStringSlice(command + "." + name)
+		if err != nil {
+			out = append(out, arg)
+			continue
+		}
+
+		if slices.Contains(stack, name) {
+			cycle := append(slices.Clone(stack), name)
+			return nil, fmt.Errorf("@set cycle in %s: @%s", command, strings.Join(cycle, " -> @"))
+		}
+
+		var setArgs []string
+		for _, entry := range entries {
+			setArgs = append(setArgs, strings.Fields(entry)...)
+		}
+		expanded, err := expandSets(command, setArgs, append(stack, name))
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, expanded...)
+	}
+	return out, nil
+}
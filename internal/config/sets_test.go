@@ -0,0 +1,70 @@
+// Copyright (c) 2026 Steve Taranto <staranto@gmail.com>.
+// SPDX-License-Identifier: Apache-2.0
+// no-cloc
+
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpandSets(t *testing.T) {
+	withConfig(t, "sets.yaml", func(t *testing.T) {
+		tests := []struct {
+			name string
+			args []string
+			want []string
+		}{
+			{
+				name: "no sets",
+				args: []string{"--sort", "id"},
+				want: []string{"--sort", "id"},
+			},
+			{
+				name: "in place",
+				args: []string{"--limit", "3", "@defaults", "--sort", "id"},
+				want: []string{"--limit", "3", "--sort", "name", "--attrs", "id", "--sort", "id"},
+			},
+			{
+				name: "nested depth-first",
+				args: []string{"@prod-web"},
+				want: []string{"--sort", "name", "--attrs", "id", "--filter", "_tag.env=prod", "--filter", "name@web"},
+			},
+			{
+				name: "several sets",
+				args: []string{"@defaults", "@prod"},
+				want: []string{"--sort", "name", "--attrs", "id", "--sort", "name", "--attrs", "id", "--filter", "_tag.env=prod"},
+			},
+			{
+				name: "unknown set and non-list key pass through",
+				args: []string{"@nope", "@host", "@"},
+				want: []string{"@nope", "@host", "@"},
+			},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				got, err := ExpandSets("wq", tt.args)
+				require.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			})
+		}
+
+		// Sets are looked up under the command's own namespace only.
+		got, err := ExpandSets("sq", []string{"@defaults"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"@defaults"}, got)
+	})
+}
+
+func TestExpandSets_Cycle(t *testing.T) {
+	withConfig(t, "sets.yaml", func(t *testing.T) {
+		_, err := ExpandSets("wq", []string{"@loop-a"})
+		require.EqualError(t, err, "@set cycle in wq: @loop-a -> @loop-b -> @loop-a")
+
+		_, err = ExpandSets("wq", []string{"@self"})
+		require.EqualError(t, err, "@set cycle in wq: @self -> @self")
+	})
+}
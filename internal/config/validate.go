@@ -0,0 +1,282 @@
+// Copyright (c) 2026 Steve Taranto <staranto@gmail.com>.
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Kind is the expected shape of a configuration value.
+type Kind int
+
+const (
+	// KindString is a YAML string.
+	KindString Kind = iota
+	// KindInt is a YAML integer.
+	KindInt
+	// KindStringSlice is a YAML sequence of strings.
+	KindStringSlice
+	// KindMap is a YAML mapping whose contents are not checked.
+	KindMap
+	// KindBoolOrString is a YAML boolean or string.
+	KindBoolOrString
+	// KindScalar is a YAML string, boolean or number.
+	KindScalar
+)
+
+// String returns the human-readable name of the kind used in reports.
+func (k Kind) String() string {
+	switch k {
+	case KindString:
+		return "string"
+	case KindInt:
+		return "int"
+	case KindStringSlice:
+		return "list of strings"
+	case KindMap:
+		return "map"
+	case KindBoolOrString:
+		return "bool or string"
+	case KindScalar:
+		return "string, bool or number"
+	default:
+		return "unknown"
+	}
+}
+
+// Schema maps the dotted keys tfctl reads from the config file to the kind of
+// value each is expected to hold. Intermediate keys (e.g. "cache") are implied
+// by their children.
+var Schema = map[string]Kind{
+	"backend":                KindMap,
+	"cache.clean":            KindInt,
+	"cache.dir":              KindString,
+	"cache.list_ttl":         KindInt,
+	"color":                  KindBoolOrString,
+	"colors.even":            KindString,
+	"colors.odd":             KindString,
+	"colors.title":           KindString,
+	"help.order":             KindString,
+	"host":                   KindString,
+	"org":                    KindString,
+	"padding":                KindInt,
+	"pagination.parallelism": KindInt,
+	"parallelism":            KindInt,
+	"retries":                KindInt,
+	"retry_delay":            KindInt,
+	"theme":                  KindString,
+}
+
+// commandKeys are the keys a command namespace may hold besides views and
+// @sets, which are lists of argument strings expanded in place of "@<set>" on
+// the command line.
+var commandKeys = map[string]Kind{
+	"color": KindBoolOrString,
+	"host":  KindString,
+	"org":   KindString,
+}
+
+// Issue describes a single problem found in the configuration.
+type Issue struct {
+	Key     string
+	Problem string
+}
+
+// String renders the issue as "<key>: <problem>".
+func (i Issue) String() string {
+	return i.Key + ": " + i.Problem
+}
+
+// Validate checks data against Schema and returns the unknown or mistyped
+// keys, sorted by key. Top-level keys matching one of commands are treated as
+// command namespaces, holding host/org/color overrides and @sets, and profiles
+// holds the --profile flag defaults.
+func Validate(data map[string]interface{}, commands []string) []Issue {
+	var issues []Issue
+
+	for key, value := range data {
+		if slices.Contains(commands, key) {
+			issues = append(issues, validateCommand(key, value)...)
+			continue
+		}
+		if key == "profiles" {
+			issues = append(issues, validateProfiles(value)...)
+			continue
+		}
+		issues = append(issues, validateKey(key, value)...)
+	}
+
+	sort.Slice(issues, func(i, j int) bool {
+		return issues[i].Key < issues[j].Key
+	})
+
+	return issues
+}
+
+// validateKey checks a single dotted key against Schema, descending into maps
+// that are a prefix of known keys.
+func validateKey(key string, value interface{}) []Issue {
+	if kind, ok := Schema[key]; ok {
+		return checkKind(key, value, kind)
+	}
+
+	if !isPrefix(key) {
+		return []Issue{{Key: key, Problem: "unknown key"}}
+	}
+
+	m, ok := value.(map[string]interface{})
+	if !ok {
+		return []Issue{{Key: key, Problem: fmt.Sprintf("expected map, got %s", describe(value))}}
+	}
+
+	var issues []Issue
+	for child, v := range m {
+		issues = append(issues, validateKey(key+"."+child, v)...)
+	}
+	return issues
+}
+
+// validateCommand checks a command namespace. Known keys must have their
+// expected kind, views must map names to --attrs specs and every other key is
+// an @set, which must be a list of strings.
+func validateCommand(ns string, value interface{}) []Issue {
+	m, ok := value.(map[string]interface{})
+	if !ok {
+		return []Issue{{Key: ns, Problem: fmt.Sprintf("expected map, got %s", describe(value))}}
+	}
+
+	var issues []Issue
+	for key, v := range m {
+		if key == "views" {
+			issues = append(issues, validateViews(ns+".views", v)...)
+			continue
+		}
+		kind, ok := commandKeys[key]
+		if !ok {
+			kind = KindStringSlice
+		}
+		issues = append(issues, checkKind(ns+"."+key, v, kind)...)
+	}
+	return issues
+}
+
+// validateViews checks a command's views map, each view being an --attrs
+// spec.
+func validateViews(key string, value interface{}) []Issue {
+	views, ok := value.(map[string]interface{})
+	if !ok {
+		return []Issue{{Key: key, Problem: fmt.Sprintf("expected map, got %s", describe(value))}}
+	}
+
+	var issues []Issue
+	for name, v := range views {
+		issues = append(issues, checkKind(key+"."+name, v, KindString)...)
+	}
+	return issues
+}
+
+// validateProfiles checks the profiles map, each profile being a map of flag
+// names to scalar flag values. chdir is rejected, since the directory is
+// switched before a profile is read.
+func validateProfiles(value interface{}) []Issue {
+	profiles, ok := value.(map[string]interface{})
+	if !ok {
+		return []Issue{{Key: "profiles", Problem: fmt.Sprintf("expected map, got %s", describe(value))}}
+	}
+
+	var issues []Issue
+	for name, profile := range profiles {
+		key := "profiles." + name
+		m, ok := profile.(map[string]interface{})
+		if !ok {
+			issues = append(issues, Issue{Key: key, Problem: fmt.Sprintf("expected map, got %s", describe(profile))})
+			continue
+		}
+		for flag, v := range m {
+			if flag == "chdir" {
+				issues = append(issues, Issue{Key: key + "." + flag, Problem: "not allowed in a profile, use --chdir"})
+				continue
+			}
+			issues = append(issues, checkKind(key+"."+flag, v, KindScalar)...)
+		}
+	}
+	return issues
+}
+
+// checkKind returns an issue if value does not have the expected kind.
+func checkKind(key string, value interface{}, kind Kind) []Issue {
+	valid := false
+	switch kind {
+	case KindString:
+		_, valid = value.(string)
+	case KindInt:
+		switch value.(type) {
+		case int, int64:
+			valid = true
+		}
+	case KindStringSlice:
+		if items, ok := value.([]interface{}); ok {
+			valid = true
+			for _, item := range items {
+				if _, ok := item.(string); !ok {
+					valid = false
+					break
+				}
+			}
+		}
+	case KindMap:
+		_, valid = value.(map[string]interface{})
+	case KindBoolOrString:
+		switch value.(type) {
+		case bool, string:
+			valid = true
+		}
+	case KindScalar:
+		switch value.(type) {
+		case bool, string, int, int64, float64:
+			valid = true
+		}
+	}
+
+	if valid {
+		return nil
+	}
+
+	return []Issue{{Key: key, Problem: fmt.Sprintf("expected %s, got %s", kind, describe(value))}}
+}
+
+// isPrefix reports whether key is an intermediate key of a Schema entry.
+func isPrefix(key string) bool {
+	for k := range Schema {
+		if strings.HasPrefix(k, key+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// describe names the YAML shape of a decoded value for reports.
+func describe(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return fmt.Sprintf("string %q", v)
+	case int, int64:
+		return fmt.Sprintf("int %v", v)
+	case float64:
+		return fmt.Sprintf("float %v", v)
+	case bool:
+		return fmt.Sprintf("bool %v", v)
+	case []interface{}:
+		return "list"
+	case map[string]interface{}:
+		return "map"
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
@@ -0,0 +1,51 @@
+// Copyright (c) 2026 Steve Taranto <staranto@gmail.com>.
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"fmt"
+	"sort"
+)
+
+// View returns the --attrs spec saved as the <command>.views.<name> preset. A
+// missing view, or one that isn't a string, is an error.
+func View(command, name string) (string, error) {
+	if len(Config.Data) == 0 {
+		_, _ = Load()
+	}
+
+	key := command + ".views." + name
+	val, err := Config.get(key)
+	if err != nil {
+		return "", fmt.Errorf("no %s in config", key)
+	}
+	spec, ok := val.(string)
+	if !ok {
+		return "", fmt.Errorf("%s: expected string, got %s", key, describe(val))
+	}
+	return spec, nil
+}
+
+// ViewNames returns the names of the views configured for command, sorted.
+func ViewNames(command string) []string {
+	if len(Config.Data) == 0 {
+		_, _ = Load()
+	}
+
+	val, err := Config.get(command + ".views")
+	if err != nil {
+		return nil
+	}
+	m, ok := val.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
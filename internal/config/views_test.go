@@ -0,0 +1,30 @@
+// Copyright (c) 2026 Steve Taranto <staranto@gmail.com>.
+// SPDX-License-Identifier: Apache-2.0
+// no-cloc
+
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestView(t *testing.T) {
+	withConfig(t, "views.yaml", func(t *testing.T) {
+		spec, err := View("sq", "security")
+		require.NoError(t, err)
+		assert.Equal(t, "!.mode,type,name,tags.Owner", spec)
+
+		_, err = View("sq", "missing")
+		require.EqualError(t, err, "no sq.views.missing in config")
+
+		_, err = View("sq", "broken")
+		require.EqualError(t, err, "sq.views.broken: expected string, got list")
+
+		assert.Equal(t, []string{"broken", "security", "wide"}, ViewNames("sq"))
+		assert.Empty(t, ViewNames("wq"))
+		assert.Empty(t, ViewNames("rq"))
+	})
+}
@@ -4,22 +4,35 @@
 package differ
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"reflect"
+	"regexp"
 	"strconv"
 	"strings"
 
 	"github.com/apex/log"
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/tidwall/gjson"
 	"github.com/urfave/cli/v3"
 	"github.com/yudai/gojsondiff"
 	"github.com/yudai/gojsondiff/formatter"
 
+	"github.com/staranto/tfctl/internal/attrs"
+	"github.com/staranto/tfctl/internal/driller"
 	"github.com/staranto/tfctl/internal/meta"
+	"github.com/staranto/tfctl/internal/output"
 )
 
-// Diff compares two states.
+// DiffFormats are the renderings of sq --diff selectable with --diff-format.
+var DiffFormats = []string{"text", "unified", "json"}
+
+// Diff compares two states and writes the differences in the format selected
+// by --diff-format. states[0] is the older state and states[1] the newer.
 func Diff(ctx context.Context, cmd *cli.Command, states [][]byte) error {
 	log.Debugf(">> differ()")
 
@@ -29,6 +42,118 @@ func Diff(ctx context.Context, cmd *cli.Command, states [][]byte) error {
 
 	log.Debugf("len(states): %d %d", len(states[0]), len(states[1]))
 
+	w := cmd.Root().Writer
+	if w == nil {
+		w = os.Stdout
+	}
+
+	if spec := cmd.String("diff-attrs"); spec != "" {
+		var al attrs.AttrList
+		if err := al.Set(spec); err != nil {
+			return fmt.Errorf("invalid --diff-attrs: %w", err)
+		}
+		projected := make([][]byte, len(states))
+		for i, state := range states {
+			var err error
+			if projected[i], err = projectState(state, al); err != nil {
+				return err
+			}
+		}
+		states = projected
+	}
+
+	var filter []string
+	for key := range strings.SplitSeq(cmd.String("diff_filter"), ",") {
+		if key != "" {
+			filter = append(filter, key)
+		}
+	}
+
+	switch format := cmd.String("diff-format"); format {
+	case "", "text":
+		return diffText(w, states, filter)
+	case "unified":
+		return diffUnified(w, states, filter)
+	case "json":
+		return diffJSON(w, states, filter)
+	default:
+		return fmt.Errorf("invalid diff format %q: must be one of %s",
+			format, strings.Join(DiffFormats, ", "))
+	}
+}
+
+// addressKeys are the resource keys that, with an instance's index_key, make
+// up a resource address. projectState keeps them so projected instances still
+// line up.
+var addressKeys = []string{"module", "mode", "type", "name"}
+
+// projectState returns state with each resource instance reduced to the
+// attributes in al, so only they are compared. Keys of al follow --attrs, so
+// "tags" is attributes.tags and ".index_key" is a key of the instance itself.
+// Values keep their raw JSON text and the top-level keys are unchanged.
+func projectState(state []byte, al attrs.AttrList) ([]byte, error) {
+	if !gjson.ValidBytes(state) {
+		return nil, fmt.Errorf("failed to unmarshal state: invalid JSON")
+	}
+
+	project := func(src gjson.Result, keys []string) map[string]any {
+		dst := map[string]any{}
+		for _, key := range keys {
+			if v := src.Get(key); v.Exists() {
+				dst[key] = json.RawMessage(v.Raw)
+			}
+		}
+		return dst
+	}
+
+	resources := []any{}
+	for _, resource := range gjson.GetBytes(state, "resources").Array() {
+		r := project(resource, addressKeys)
+
+		instances := []any{}
+		for _, instance := range resource.Get("instances").Array() {
+			inst := project(instance, []string{"index_key"})
+			for _, attr := range al {
+				if !attr.Include || attr.Key == "*" {
+					continue
+				}
+				v := driller.Driller(instance.Raw, attr.Key)
+				if !v.Exists() {
+					continue
+				}
+				setPath(inst, strings.Split(attr.Key, "."), json.RawMessage(v.Raw))
+			}
+			instances = append(instances, inst)
+		}
+		r["instances"] = instances
+		resources = append(resources, r)
+	}
+
+	doc := map[string]any{}
+	gjson.ParseBytes(state).ForEach(func(key, value gjson.Result) bool {
+		doc[key.Str] = json.RawMessage(value.Raw)
+		return true
+	})
+	doc["resources"] = resources
+
+	return json.Marshal(doc)
+}
+
+// setPath sets value at the nested keys of m, creating intermediate maps.
+func setPath(m map[string]any, keys []string, value any) {
+	for _, key := range keys[:len(keys)-1] {
+		next, ok := m[key].(map[string]any)
+		if !ok {
+			next = map[string]any{}
+			m[key] = next
+		}
+		m = next
+	}
+	m[keys[len(keys)-1]] = value
+}
+
+// diffText writes the colored ASCII delta of the two state documents.
+func diffText(w io.Writer, states [][]byte, filter []string) error {
 	differ := gojsondiff.New()
 
 	delta, err := differ.Compare(states[0], states[1])
@@ -42,12 +167,8 @@ func Diff(ctx context.Context, cmd *cli.Command, states [][]byte) error {
 			return fmt.Errorf("failed to unmarshal state: %w", err)
 		}
 
-		filter := cmd.String("diff_filter")
-
-		for key := range strings.SplitSeq(filter, ",") {
-			if key != "" {
-				delete(jdoc, key)
-			}
+		for _, key := range filter {
+			delete(jdoc, key)
 		}
 
 		config := formatter.AsciiFormatterConfig{
@@ -61,15 +182,126 @@ func Diff(ctx context.Context, cmd *cli.Command, states [][]byte) error {
 			return err
 		}
 
-		fmt.Fprintln(os.Stdout, diffString)
+		fmt.Fprintln(w, diffString)
 		return nil
 	}
 
-	fmt.Fprintln(os.Stdout, "The states are identical.")
+	fmt.Fprintln(w, "The states are identical.")
 	return nil
 }
 
-func ParseDiffArgs(ctx context.Context, cmd *cli.Command) (args []string) {
+// diffUnified writes a unified diff of the two states, each rendered as an
+// indented JSON object of its flattened resource instances keyed by address.
+// Identical states produce no output, as with diff(1).
+func diffUnified(w io.Writer, states [][]byte, filter []string) error {
+	var docs [2]string
+	for i, state := range states[:2] {
+		rows, err := stateRows(state, filter)
+		if err != nil {
+			return err
+		}
+		doc, err := json.MarshalIndent(rows, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal state: %w", err)
+		}
+		docs[i] = string(doc) + "\n"
+	}
+
+	return difflib.WriteUnifiedDiff(w, difflib.UnifiedDiff{
+		A:        difflib.SplitLines(docs[0]),
+		B:        difflib.SplitLines(docs[1]),
+		FromFile: stateLabel(states[0]),
+		ToFile:   stateLabel(states[1]),
+		Context:  3,
+	})
+}
+
+// stateChange is a resource instance present in both states with different
+// contents.
+type stateChange struct {
+	Before map[string]any `json:"before"`
+	After  map[string]any `json:"after"`
+}
+
+// stateDelta is the --diff-format json document. Each set is keyed by
+// resource address.
+type stateDelta struct {
+	Added   map[string]map[string]any `json:"added"`
+	Changed map[string]stateChange    `json:"changed"`
+	Removed map[string]map[string]any `json:"removed"`
+}
+
+// diffJSON writes the resource instances added, changed and removed between
+// the two states as a stateDelta.
+func diffJSON(w io.Writer, states [][]byte, filter []string) error {
+	before, err := stateRows(states[0], filter)
+	if err != nil {
+		return err
+	}
+	after, err := stateRows(states[1], filter)
+	if err != nil {
+		return err
+	}
+
+	delta := stateDelta{
+		Added:   map[string]map[string]any{},
+		Changed: map[string]stateChange{},
+		Removed: map[string]map[string]any{},
+	}
+	for addr, row := range before {
+		switch other, ok := after[addr]; {
+		case !ok:
+			delta.Removed[addr] = row
+		case !reflect.DeepEqual(row, other):
+			delta.Changed[addr] = stateChange{Before: row, After: other}
+		}
+	}
+	for addr, row := range after {
+		if _, ok := before[addr]; !ok {
+			delta.Added[addr] = row
+		}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(delta)
+}
+
+// stateRows returns the flattened resource instances of a state document
+// keyed by address. The keys in filter are dropped from each instance.
+func stateRows(state []byte, filter []string) (map[string]map[string]any, error) {
+	dec := json.NewDecoder(bytes.NewReader(output.FlattenState(state)))
+	dec.UseNumber()
+
+	var flat []map[string]any
+	if err := dec.Decode(&flat); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal state: %w", err)
+	}
+
+	rows := make(map[string]map[string]any, len(flat))
+	for _, row := range flat {
+		addr, _ := row["resource"].(string)
+		delete(row, "resource")
+		for _, key := range filter {
+			delete(row, key)
+		}
+		rows[addr] = row
+	}
+	return rows, nil
+}
+
+// stateLabel names a state in the unified diff header by its serial.
+func stateLabel(state []byte) string {
+	return "serial " + gjson.GetBytes(state, "serial").String()
+}
+
+// serialRangeRegex matches a serial range diff arg, e.g. 5..8.
+var serialRangeRegex = regexp.MustCompile(`^(\d+)\.\.(\d+)$`)
+
+// ParseDiffArgs returns the state version specs following --diff on the
+// command line, at most two. A serial range such as 5..8 is expanded to the
+// serials 5 and 8, which the backends then resolve like any other serial spec.
+func ParseDiffArgs(ctx context.Context, cmd *cli.Command) (args []string, err error) {
 	meta := cmd.Metadata["meta"].(meta.Meta)
 
 	diffFound := false
@@ -86,6 +318,14 @@ func ParseDiffArgs(ctx context.Context, cmd *cli.Command) (args []string) {
 		}
 
 		if diffFound {
+			// A serial range is both diff args.
+			if strings.Contains(a, "..") {
+				if len(args) != 0 {
+					return nil, fmt.Errorf("serial range %q cannot follow another diff arg", a)
+				}
+				return parseSerialRange(a)
+			}
+
 			// If the next arg up is a flag, bail out.  The definition of what is a
 			// flag is a little indeterminate.
 
@@ -103,3 +343,20 @@ func ParseDiffArgs(ctx context.Context, cmd *cli.Command) (args []string) {
 
 	return
 }
+
+// parseSerialRange splits a serial range such as 5..8 into its two serials.
+// Serials must be positive, since 0 and negative numbers are relative specs.
+func parseSerialRange(arg string) ([]string, error) {
+	m := serialRangeRegex.FindStringSubmatch(arg)
+	if m == nil {
+		return nil, fmt.Errorf("invalid serial range %q: expected <from>..<to>, e.g. 5..8", arg)
+	}
+
+	for _, serial := range m[1:] {
+		if n, err := strconv.ParseInt(serial, 10, 64); err != nil || n < 1 {
+			return nil, fmt.Errorf("invalid serial range %q: serials must be positive", arg)
+		}
+	}
+
+	return m[1:], nil
+}
@@ -0,0 +1,190 @@
+// Copyright (c) 2026 Steve Taranto <staranto@gmail.com>.
+// SPDX-License-Identifier: Apache-2.0
+// no-cloc
+
+package differ
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v3"
+
+	"github.com/staranto/tfctl/internal/attrs"
+	"github.com/staranto/tfctl/internal/meta"
+)
+
+const (
+	diffBefore = `{"serial":1,"resources":[
+		{"mode":"managed","type":"aws_s3_bucket","name":"logs","instances":[{"attributes":{"id":"logs","acl":"private"}}]},
+		{"mode":"managed","type":"aws_s3_bucket","name":"old","instances":[{"attributes":{"id":"old"}}]},
+		{"module":"module.net","mode":"managed","type":"aws_subnet","name":"a","instances":[{"index_key":0,"attributes":{"id":"subnet-1"}}]}
+	]}`
+	diffAfter = `{"serial":2,"resources":[
+		{"mode":"managed","type":"aws_s3_bucket","name":"logs","instances":[{"attributes":{"id":"logs","acl":"public-read"}}]},
+		{"mode":"managed","type":"aws_s3_bucket","name":"new","instances":[{"attributes":{"id":"new"}}]},
+		{"module":"module.net","mode":"managed","type":"aws_subnet","name":"a","instances":[{"index_key":0,"attributes":{"id":"subnet-1"}}]}
+	]}`
+)
+
+// runDiff runs Diff through a command carrying the sq diff flags and returns
+// what it wrote.
+func runDiff(t *testing.T, states [][]byte, args ...string) string {
+	t.Helper()
+
+	buf := new(bytes.Buffer)
+	cmd := &cli.Command{
+		Name:   "sq",
+		Writer: buf,
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "diff-attrs"},
+			&cli.StringFlag{Name: "diff-format", Value: "text"},
+			&cli.StringFlag{Name: "diff_filter", Value: "check_results"},
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			return Diff(ctx, cmd, states)
+		},
+	}
+	require.NoError(t, cmd.Run(context.Background(), append([]string{"sq"}, args...)))
+	return buf.String()
+}
+
+func TestDiff_Unified(t *testing.T) {
+	out := runDiff(t, [][]byte{[]byte(diffBefore), []byte(diffAfter)}, "--diff-format", "unified")
+
+	assert.Contains(t, out, "--- serial 1\n+++ serial 2\n")
+	assert.Contains(t, out, "-      \"acl\": \"private\",\n+      \"acl\": \"public-read\",\n")
+	assert.Contains(t, out, "-  \"aws_s3_bucket.old\": {\n")
+	assert.Contains(t, out, "+  \"aws_s3_bucket.new\": {\n")
+	assert.NotContains(t, out, "module.net.aws_subnet.a[0]\": {\n-")
+
+	// Identical states produce no diff.
+	assert.Empty(t, runDiff(t, [][]byte{[]byte(diffBefore), []byte(diffBefore)}, "--diff-format", "unified"))
+}
+
+func TestDiff_JSON(t *testing.T) {
+	out := runDiff(t, [][]byte{[]byte(diffBefore), []byte(diffAfter)}, "--diff-format", "json")
+
+	var delta struct {
+		Added   map[string]map[string]any
+		Changed map[string]struct{ Before, After map[string]any }
+		Removed map[string]map[string]any
+	}
+	require.NoError(t, json.Unmarshal([]byte(out), &delta))
+
+	assert.Equal(t, []string{"aws_s3_bucket.new"}, keys(delta.Added))
+	assert.Equal(t, []string{"aws_s3_bucket.old"}, keys(delta.Removed))
+	require.Contains(t, delta.Changed, "aws_s3_bucket.logs")
+	assert.Len(t, delta.Changed, 1)
+
+	change := delta.Changed["aws_s3_bucket.logs"]
+	assert.Equal(t, "private", change.Before["attributes"].(map[string]any)["acl"])
+	assert.Equal(t, "public-read", change.After["attributes"].(map[string]any)["acl"])
+
+	// Filtered keys are not compared.
+	out = runDiff(t, [][]byte{[]byte(diffBefore), []byte(diffAfter)},
+		"--diff-format", "json", "--diff_filter", "attributes")
+	delta.Changed = nil
+	require.NoError(t, json.Unmarshal([]byte(out), &delta))
+	assert.Empty(t, delta.Changed)
+}
+
+func TestDiff_Attrs(t *testing.T) {
+	before := `{"serial":1,"resources":[{"mode":"managed","type":"aws_instance","name":"web","instances":[
+		{"index_key":0,"attributes":{"id":"i-1","instance_type":"t3.micro","tags":{"env":"dev"},"timeouts":null}}]}]}`
+	after := `{"serial":2,"resources":[{"mode":"managed","type":"aws_instance","name":"web","instances":[
+		{"index_key":0,"attributes":{"id":"i-2","instance_type":"t3.micro","tags":{"env":"prod"},"timeouts":{"create":"5m"}}}]}]}`
+	states := [][]byte{[]byte(before), []byte(after)}
+
+	var delta struct {
+		Changed map[string]struct{ Before, After map[string]any }
+	}
+
+	// Only the listed attributes are compared.
+	out := runDiff(t, states, "--diff-format", "json", "--diff-attrs", "instance_type")
+	require.NoError(t, json.Unmarshal([]byte(out), &delta))
+	assert.Empty(t, delta.Changed)
+
+	out = runDiff(t, states, "--diff-format", "json", "--diff-attrs", "tags,instance_type")
+	delta.Changed = nil
+	require.NoError(t, json.Unmarshal([]byte(out), &delta))
+	require.Contains(t, delta.Changed, "aws_instance.web[0]")
+	assert.Equal(t, map[string]any{
+		"instance_type": "t3.micro",
+		"tags":          map[string]any{"env": "prod"},
+	}, delta.Changed["aws_instance.web[0]"].After["attributes"])
+
+	// The other formats see the same projection.
+	out = runDiff(t, states, "--diff-format", "unified", "--diff-attrs", "tags")
+	assert.Contains(t, out, "-        \"env\": \"dev\"\n+        \"env\": \"prod\"\n")
+	assert.NotContains(t, out, "i-2")
+	assert.NotContains(t, out, "timeouts")
+}
+
+func TestProjectState(t *testing.T) {
+	var al attrs.AttrList
+	require.NoError(t, al.Set("tags.env,.index_key,missing"))
+
+	got, err := projectState([]byte(`{"version":4,"serial":18446744073709551615,"resources":[
+		{"module":"module.net","mode":"managed","type":"aws_subnet","name":"a","provider":"p",
+		 "instances":[{"index_key":"x","schema_version":1,"attributes":{"id":"s","tags":{"env":"dev","team":"t"}}}]}]}`), al)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"version":4,"serial":18446744073709551615,"resources":[
+		{"module":"module.net","mode":"managed","type":"aws_subnet","name":"a",
+		 "instances":[{"index_key":"x","attributes":{"tags":{"env":"dev"}}}]}]}`, string(got))
+	assert.Contains(t, string(got), "18446744073709551615")
+
+	_, err = projectState([]byte("not json"), al)
+	assert.Error(t, err)
+}
+
+func TestDiff_Text(t *testing.T) {
+	assert.Equal(t, "The states are identical.\n",
+		runDiff(t, [][]byte{[]byte(diffBefore), []byte(diffBefore)}))
+	assert.NotEmpty(t, runDiff(t, [][]byte{[]byte(diffBefore), []byte(diffAfter)}))
+}
+
+func TestParseDiffArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    []string
+		wantErr string
+	}{
+		{name: "none", args: []string{"tfctl", "sq", "--diff"}},
+		{name: "two specs", args: []string{"tfctl", "sq", "--diff", "CSV~2", "7"}, want: []string{"CSV~2", "7"}},
+		{name: "stops at flag", args: []string{"tfctl", "sq", "--diff", "5", "--short"}, want: []string{"5"}},
+		{name: "serial range", args: []string{"tfctl", "sq", "--diff", "5..8", "--short"}, want: []string{"5", "8"}},
+		{name: "descending range", args: []string{"tfctl", "sq", "--diff", "8..5"}, want: []string{"8", "5"}},
+		{name: "open range", args: []string{"tfctl", "sq", "--diff", "5.."}, wantErr: "expected <from>..<to>"},
+		{name: "non-numeric range", args: []string{"tfctl", "sq", "--diff", "a..b"}, wantErr: "expected <from>..<to>"},
+		{name: "zero serial", args: []string{"tfctl", "sq", "--diff", "0..3"}, wantErr: "serials must be positive"},
+		{name: "range after spec", args: []string{"tfctl", "sq", "--diff", "4", "5..8"}, wantErr: "cannot follow"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := &cli.Command{Metadata: map[string]any{"meta": meta.Meta{Args: tt.args}}}
+
+			got, err := ParseDiffArgs(context.Background(), cmd)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func keys(m map[string]map[string]any) []string {
+	var out []string
+	for k := range m {
+		out = append(out, k)
+	}
+	return out
+}
@@ -11,15 +11,42 @@ import (
 	"github.com/tidwall/gjson"
 )
 
-// Driller navigates JSON using a flexible dot path supporting arrays
+// segmentRegex matches one segment of a Driller path, e.g. name, name[] or
+// name[0]. It is compiled once since Driller runs for every row and attribute.
+var segmentRegex = regexp.MustCompile(`^([a-zA-Z0-9_-]+)(\[(\d|\*)?\])?$`)
+
+// Driller navigates JSON using a flexible dot path supporting arrays. A #
+// segment, as in instances.#.attributes.id, drills the rest of the path into
+// every element of the array and returns an array of the matched values. A
+// gjson query segment, as in resources.#(type=="aws_instance").name, selects
+// the first matching element, or with #(...)# every matching element.
 func Driller(jsonData string, path string) gjson.Result {
-	parts := strings.Split(path, ".")
+	parts := splitPath(path)
 	current := gjson.Parse(jsonData)
 
-	re := regexp.MustCompile(`^([a-zA-Z0-9_-]+)(\[(\d|\*)?\])?$`)
+	for i, p := range parts {
+		if p == "#" {
+			return drillEach(current, parts[i+1:])
+		}
+
+		// Queries are gjson's to evaluate. A single element array has already
+		// been drilled through, so it is wrapped again for the query to see.
+		if strings.HasPrefix(p, "#(") {
+			if !current.Exists() {
+				return gjson.Result{}
+			}
+			if !current.IsArray() {
+				current = gjson.Parse("[" + current.Raw + "]")
+			}
+			val := current.Get(p)
+			if strings.HasSuffix(p, ")#") {
+				return drillEach(val, parts[i+1:])
+			}
+			current = val
+			continue
+		}
 
-	for _, p := range parts {
-		matches := re.FindStringSubmatch(p)
+		matches := segmentRegex.FindStringSubmatch(p)
 		if len(matches) == 0 {
 			return gjson.Result{} // Invalid path segment
 		}
@@ -60,3 +87,86 @@ func Driller(jsonData string, path string) gjson.Result {
 
 	return current
 }
+
+// splitPath splits a Driller path on the dots that separate its segments.
+// Dots inside a query's parentheses or quotes, as in #(name=="a.b"), are part
+// of the segment.
+func splitPath(path string) []string {
+	var parts []string
+	depth, start := 0, 0
+	quoted, escaped := false, false
+
+	for i := 0; i < len(path); i++ {
+		c := path[i]
+		switch {
+		case escaped:
+			escaped = false
+		case quoted:
+			switch c {
+			case '\\':
+				escaped = true
+			case '"':
+				quoted = false
+			}
+		case c == '"':
+			quoted = true
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case c == '.' && depth == 0:
+			parts = append(parts, path[start:i])
+			start = i + 1
+		}
+	}
+
+	return append(parts, path[start:])
+}
+
+// drillEach drills the remaining path parts into each element of current and
+// collects the values that exist into a JSON array. A single element array has
+// already been drilled through by the previous segment, so a non-array current
+// is treated as that one element.
+func drillEach(current gjson.Result, parts []string) gjson.Result {
+	if !current.Exists() {
+		return gjson.Result{}
+	}
+
+	elems := []gjson.Result{current}
+	if current.IsArray() {
+		elems = current.Array()
+	}
+
+	var raw strings.Builder
+	raw.WriteByte('[')
+	n := 0
+	for _, elem := range elems {
+		if len(parts) > 0 {
+			elem = Driller(elem.Raw, strings.Join(parts, "."))
+		}
+		if !elem.Exists() {
+			continue
+		}
+		if n > 0 {
+			raw.WriteByte(',')
+		}
+		raw.WriteString(elem.Raw)
+		n++
+	}
+	raw.WriteByte(']')
+
+	return gjson.Parse(raw.String())
+}
+
+// Value returns the Go value of a drilled result. It matches
+// gjson.Result.Value except that integral JSON numbers which fit in an int64
+// are returned as int64 rather than float64, so large serials and IDs beyond
+// 2^53 keep their precision.
+func Value(r gjson.Result) interface{} {
+	if r.Type == gjson.Number {
+		if n, err := strconv.ParseInt(r.Raw, 10, 64); err == nil {
+			return n
+		}
+	}
+	return r.Value()
+}
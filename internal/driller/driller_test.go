@@ -64,6 +64,9 @@ func TestDriller(t *testing.T) {
 				if !result.IsArray() {
 					t.Errorf("Expected array but got: %v (type: %T)", result.Value(), result.Value())
 				}
+				if tt.ExpectedStr != "" && result.Raw != tt.ExpectedStr {
+					t.Errorf("Expected %s but got %s", tt.ExpectedStr, result.Raw)
+				}
 				return
 			}
 
@@ -74,3 +77,22 @@ func TestDriller(t *testing.T) {
 		})
 	}
 }
+
+func TestValue(t *testing.T) {
+	doc := `{"serial":9007199254740993,"neg":-42,"ratio":1.5,"huge":1e300,"name":"x"}`
+
+	require.Equal(t, int64(9007199254740993), Value(Driller(doc, "serial")))
+	require.Equal(t, int64(-42), Value(Driller(doc, "neg")))
+	require.Equal(t, 1.5, Value(Driller(doc, "ratio")))
+	require.Equal(t, 1e300, Value(Driller(doc, "huge")))
+	require.Equal(t, "x", Value(Driller(doc, "name")))
+	require.Nil(t, Value(Driller(doc, "missing")))
+}
+
+func TestSplitPath(t *testing.T) {
+	require.Equal(t, []string{"a", "b[0]", "c"}, splitPath("a.b[0].c"))
+	require.Equal(t, []string{"resources", `#(name=="a.b")#`, "id"}, splitPath(`resources.#(name=="a.b")#.id`))
+	require.Equal(t, []string{"a", `#(x=="q\".(")`, "y"}, splitPath(`a.#(x=="q\".(").y`))
+	require.Equal(t, []string{"f", `#(nets.#(=="fb"))`, "first"}, splitPath(`f.#(nets.#(=="fb")).first`))
+	require.Equal(t, []string{""}, splitPath(""))
+}
@@ -7,8 +7,10 @@ import (
 	"fmt"
 	"os"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/apex/log"
 	"github.com/tidwall/gjson"
@@ -113,6 +115,21 @@ func BuildFilters(spec string) []Filter {
 // public entry point used by SliceDiceSpit.  To be clear, this is the result
 // filtering. Any server-side filtering was returned by the API.
 func FilterDataset(candidates gjson.Result, attrs attrs.AttrList, spec string) []map[string]interface{} {
+	return filterDataset(candidates, attrs, spec, true)
+}
+
+// FilterDatasetSkeleton returns one empty row for each candidate that matches
+// the provided spec. Attribute values are never extracted, which makes it a
+// cheaper alternative to FilterDataset when only the matching rows, and not
+// their contents, are of interest.
+func FilterDatasetSkeleton(candidates gjson.Result, attrs attrs.AttrList, spec string) []map[string]interface{} {
+	return filterDataset(candidates, attrs, spec, false)
+}
+
+// filterDataset implements FilterDataset and FilterDatasetSkeleton. When
+// extract is false the matching rows are left empty.
+func filterDataset(candidates gjson.Result, attrs attrs.AttrList, spec string,
+	extract bool) []map[string]interface{} {
 	//nolint:prealloc // Don't prealloc because we don't know what len will be.
 	var filteredResults []map[string]interface{}
 
@@ -129,13 +146,17 @@ func FilterDataset(candidates gjson.Result, attrs attrs.AttrList, spec string) [
 		// If the filter check was successful, add each attribute from the candidate
 		// to the filtered result set.
 		result := make(map[string]interface{})
+		if !extract {
+			filteredResults = append(filteredResults, result)
+			continue
+		}
 		for i := range attrs {
 			attr := attrs[i]
 			// Intentionally defer Transform to SliceDiceSpit output phase.
 			// This function is responsible for filtering only. Transformations
 			// are applied downstream during output formatting.
 			value := driller.Driller(candidate.Raw, attr.Key)
-			result[attr.OutputKey] = value.Value()
+			result[attr.OutputKey] = driller.Value(value)
 		}
 		filteredResults = append(filteredResults, result)
 	}
@@ -191,7 +212,7 @@ func applyFilters(candidate gjson.Result, attrs attrs.AttrList,
 		}
 
 		// Get the value from the candidate for the key. If it's nil, fail early.
-		value := driller.Driller(candidate.Raw, key).Value()
+		value := driller.Value(driller.Driller(candidate.Raw, key))
 		if value == nil {
 			return false
 		}
@@ -203,6 +224,8 @@ func applyFilters(candidate gjson.Result, attrs attrs.AttrList,
 			result = checkStringOperand(v, filter)
 		} else if v, ok := value.(bool); ok {
 			result = checkStringOperand(fmt.Sprintf("%v", v), filter)
+		} else if num, ok := value.(int64); ok {
+			result = checkIntegerOperand(num, filter)
 		} else if num, ok := toFloat64(value); ok {
 			result = checkNumericOperand(num, filter)
 		} else if filter.Operand == "@" {
@@ -226,38 +249,90 @@ const (
 )
 
 // checkContainsOperand evaluates a membership style filter (operand '@')
-// against slice or map values.
+// against string, slice or map values, so that e.g. tags@env behaves the same
+// whatever shape tags has:
+//   - a string, such as a stringified JSON document, matches if it contains
+//     the filter value as a substring.
+//   - a slice matches if one of its items equals the filter value.
+//   - a map matches if the filter value is one of its keys or, failing that,
+//     equals one of its values.
+//
+// Slice items and map values are compared as strings, so numbers and booleans
+// match their printed form. Nested slices and maps are not descended into.
 func checkContainsOperand(value interface{}, filter Filter) bool {
+	var found bool
 	switch val := value.(type) {
+	case string:
+		found = strings.Contains(val, filter.Value)
 	case []any:
+		found = slices.ContainsFunc(val, func(item any) bool {
+			return scalarEquals(item, filter.Value)
+		})
+	case map[string]any:
+		_, found = val[filter.Value]
 		for _, item := range val {
-			if item == filter.Value {
-				return !filter.Negate
+			if found {
+				break
 			}
+			found = scalarEquals(item, filter.Value)
 		}
-		return filter.Negate
-	case map[string]any:
-		_, found := val[filter.Value]
-		if filter.Negate {
-			return !found
-		}
-		return found
 	default:
 		log.Error(fmt.Sprintf("unsupported type for contains filtering: %T", value))
 		return false
 	}
+	return found == !filter.Negate
+}
+
+// scalarEquals reports whether item is a string, number or boolean whose
+// printed form is target.
+func scalarEquals(item any, target string) bool {
+	switch item.(type) {
+	case string, bool, int, int64, float64:
+		return fmt.Sprint(item) == target
+	default:
+		return false
+	}
 }
 
 // checkNumericOperand compares a numeric value against the filter value using
 // numeric semantics. Supported operands: =, >, < and the negated form via
-// filter.Negate (e.g., != is represented as Negate + "=").
+// filter.Negate (e.g., != is represented as Negate + "="). A filter value with
+// a time unit, such as 90s or 10m, is taken as a number of seconds so that
+// duration attributes can be compared against it.
 func checkNumericOperand(value float64, filter Filter) bool {
-	// Parse the value as a float64
+	// Parse the value as a float64, else as a duration in seconds.
 	tgt, err := strconv.ParseFloat(strings.TrimSpace(filter.Value), 64)
 	if err != nil {
-		log.Error("invalid numeric value: " + filter.Value)
+		d, derr := time.ParseDuration(strings.TrimSpace(filter.Value))
+		if derr != nil {
+			log.Error("invalid numeric value: " + filter.Value)
+			return false
+		}
+		tgt = d.Seconds()
+	}
+
+	switch filter.Operand {
+	case "=":
+		return (value == tgt) == !filter.Negate
+	case ">":
+		return (value > tgt) == !filter.Negate
+	case "<":
+		return (value < tgt) == !filter.Negate
+	default:
+		log.Error("unsupported numeric operand: " + filter.Operand)
 		return false
 	}
+}
+
+// checkIntegerOperand compares an integral value against the filter value.
+// When the filter value is itself an integer the comparison is done on int64
+// so that values beyond 2^53 keep their precision; otherwise it falls back to
+// checkNumericOperand.
+func checkIntegerOperand(value int64, filter Filter) bool {
+	tgt, err := strconv.ParseInt(strings.TrimSpace(filter.Value), 10, 64)
+	if err != nil {
+		return checkNumericOperand(float64(value), filter)
+	}
 
 	switch filter.Operand {
 	case "=":
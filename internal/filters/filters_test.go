@@ -46,6 +46,15 @@ type testCheckNumericOperandCase struct {
 	Want   bool    `yaml:"want"`
 }
 
+// testCheckIntegerOperandCase represents a single test case for
+// TestCheckIntegerOperand.
+type testCheckIntegerOperandCase struct {
+	Name   string `yaml:"name"`
+	Value  int64  `yaml:"value"`
+	Filter Filter `yaml:"filter"`
+	Want   bool   `yaml:"want"`
+}
+
 // testCheckContainsOperandCase represents a single test case for
 // TestCheckContainsOperand.
 type testCheckContainsOperandCase struct {
@@ -136,6 +145,18 @@ func TestCheckNumericOperand(t *testing.T) {
 	}
 }
 
+func TestCheckIntegerOperand(t *testing.T) {
+	var tests []testCheckIntegerOperandCase
+	require.NoError(t, loadTestData("filters_test_check_integer_operand.yaml", &tests))
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			got := checkIntegerOperand(tt.Value, tt.Filter)
+			assert.Equal(t, tt.Want, got)
+		})
+	}
+}
+
 func TestCheckContainsOperand(t *testing.T) {
 	var tests []testCheckContainsOperandCase
 	require.NoError(t, loadTestData("filters_test_check_contains_operand.yaml", &tests))
@@ -177,7 +198,8 @@ func TestApplyFilters(t *testing.T) {
 		"tags": ["prod", "web"],
 		"metadata": {"env": "production"},
 		"description": null,
-		"nested": {"inner": "value"}
+		"nested": {"inner": "value"},
+		"labels": "{\"env\":\"prod\"}"
 	}
 	`
 
@@ -188,6 +210,9 @@ func TestApplyFilters(t *testing.T) {
 		{Key: "count", OutputKey: "count", Include: true},
 		{Key: "description", OutputKey: "description", Include: true},
 		{Key: "nested", OutputKey: "nested", Include: true},
+		{Key: "tags", OutputKey: "tags", Include: true},
+		{Key: "metadata", OutputKey: "metadata", Include: true},
+		{Key: "labels", OutputKey: "labels", Include: true},
 	}
 
 	for _, tt := range tests {
@@ -239,3 +264,53 @@ func TestFilterDataset(t *testing.T) {
 		})
 	}
 }
+
+// TestFilterDatasetSkeleton verifies that the skeleton variant matches the
+// same rows as FilterDataset without extracting any attribute values.
+func TestFilterDatasetSkeleton(t *testing.T) {
+	var tests []testFilterDatasetCase
+	require.NoError(t, loadTestData("filters_test_filter_dataset.yaml", &tests))
+
+	testData := `
+	[
+		{"id": "res-1", "name": "aws-resource-1", "type": "aws_instance"},
+		{"id": "res-2", "name": "gcp-resource", "type": "google_instance"},
+		{"id": "res-3", "name": "aws-resource-2", "type": "aws_network"}
+	]
+	`
+
+	attrList := attrs.AttrList{
+		{Key: "name", OutputKey: "name", Include: true},
+		{Key: "type", OutputKey: "type", Include: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			candidates := gjson.Parse(testData)
+			got := FilterDatasetSkeleton(candidates, attrList, tt.Spec)
+			assert.Len(t, got, tt.WantCount)
+			for _, row := range got {
+				assert.Empty(t, row, "no values should be extracted")
+			}
+		})
+	}
+}
+
+// TestFilterDatasetLargeSerial verifies serials beyond 2^53 are extracted and
+// compared without float64 rounding.
+func TestFilterDatasetLargeSerial(t *testing.T) {
+	testData := `[
+		{"name": "a", "serial": 9007199254740992},
+		{"name": "b", "serial": 9007199254740993}
+	]`
+
+	attrList := attrs.AttrList{
+		{Key: "name", OutputKey: "name", Include: true},
+		{Key: "serial", OutputKey: "serial", Include: true},
+	}
+
+	got := FilterDataset(gjson.Parse(testData), attrList, "serial=9007199254740993")
+	require.Len(t, got, 1)
+	assert.Equal(t, "b", got[0]["name"])
+	assert.Equal(t, int64(9007199254740993), got[0]["serial"])
+}
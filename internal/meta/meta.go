@@ -9,19 +9,28 @@ import (
 	"github.com/staranto/tfctl/internal/config"
 )
 
-// RootDirSpec holds the resolved root directory and optional environment
-// override used when evaluating backends.
+// RootDirSpec holds the resolved root directory and optional environment and
+// workspace overrides used when evaluating backends.
 type RootDirSpec struct {
-	RootDir string
-	Env     string
+	RootDir   string
+	Env       string
+	Workspace string
+}
+
+// Profile is the named bundle of flag defaults selected by --profile or
+// $TFCTL_PROFILE, keyed by flag name. The zero Profile selects none.
+type Profile struct {
+	Name   string
+	Values map[string]string
 }
 
 // Meta contains runtime metadata shared by commands. It carries CLI arguments,
-// loaded configuration, context, the resolved root directory specification, and
-// the starting working directory.
+// loaded configuration, the selected profile, context, the resolved root
+// directory specification, and the starting working directory.
 type Meta struct {
 	Args    []string
 	Config  config.Type
+	Profile Profile
 	Context context.Context
 	RootDirSpec
 	StartingDir string
@@ -0,0 +1,114 @@
+// Copyright (c) 2026 Steve Taranto <staranto@gmail.com>.
+// SPDX-License-Identifier: Apache-2.0
+
+package output
+
+import (
+	"bufio"
+	"bytes"
+	"regexp"
+	"strings"
+
+	"github.com/tidwall/pretty"
+)
+
+// syntaxStyle colors the json and yaml output when --color is on: keys bold
+// blue, strings green, numbers yellow, booleans cyan and nulls dim, as
+// pretty.TerminalStyle does.
+var syntaxStyle = pretty.TerminalStyle
+
+// colorizeJSON returns the JSON document src with its tokens colored.
+func colorizeJSON(src []byte) []byte {
+	return pretty.Color(src, syntaxStyle)
+}
+
+var (
+	// yamlKeyRegex matches a mapping line: the indentation and any "- " list
+	// markers, the key, plain or quoted, and the rest of the line.
+	yamlKeyRegex = regexp.MustCompile(`^(\s*(?:- )*)("(?:[^"\\]|\\.)*"|'(?:[^']|'')*'|[^\s"'#-][^:]*?|-[^\s:][^:]*?):( |$)(.*)$`)
+
+	// yamlItemRegex matches a list item line holding a scalar.
+	yamlItemRegex = regexp.MustCompile(`^(\s*(?:- )+)(.*)$`)
+
+	// yamlNumberRegex matches the numbers the encoder emits.
+	yamlNumberRegex = regexp.MustCompile(`^[-+]?(?:\d+|\d*\.\d+)(?:[eE][-+]?\d+)?$|^[-+]?\.(?:inf|Inf|INF)$|^\.(?:nan|NaN|NAN)$`)
+)
+
+// colorizeYAML returns the YAML document src with its keys and scalars
+// colored. It works line by line on the block style the encoder writes, so
+// the lines of a literal or folded block scalar are colored as strings.
+func colorizeYAML(src []byte) []byte {
+	var out bytes.Buffer
+	out.Grow(len(src) * 2)
+
+	// blockIndent is the indentation of the key that opened a block scalar,
+	// or -1 outside one. Lines indented deeper belong to the scalar.
+	blockIndent := -1
+
+	scanner := bufio.NewScanner(bytes.NewReader(src))
+	scanner.Buffer(make([]byte, 0, 64*1024), len(src)+1)
+	for scanner.Scan() {
+		line := scanner.Text()
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+
+		if blockIndent >= 0 {
+			if strings.TrimSpace(line) == "" {
+				out.WriteString(line)
+				out.WriteByte('\n')
+				continue
+			}
+			if indent > blockIndent {
+				out.WriteString(line[:indent])
+				out.WriteString(paint(syntaxStyle.String, line[indent:]))
+				out.WriteByte('\n')
+				continue
+			}
+			blockIndent = -1
+		}
+
+		if m := yamlKeyRegex.FindStringSubmatch(line); m != nil {
+			out.WriteString(m[1])
+			out.WriteString(paint(syntaxStyle.Key, m[2]))
+			out.WriteByte(':')
+			out.WriteString(m[3])
+			out.WriteString(yamlScalar(m[4]))
+			if strings.HasPrefix(m[4], "|") || strings.HasPrefix(m[4], ">") {
+				blockIndent = len(m[1])
+			}
+		} else if m := yamlItemRegex.FindStringSubmatch(line); m != nil {
+			out.WriteString(m[1])
+			out.WriteString(yamlScalar(m[2]))
+		} else {
+			out.WriteString(line[:indent])
+			out.WriteString(yamlScalar(line[indent:]))
+		}
+		out.WriteByte('\n')
+	}
+	return out.Bytes()
+}
+
+// yamlScalar colors a scalar value by its type. Block scalar indicators are
+// left alone, as their content follows on the next lines.
+func yamlScalar(v string) string {
+	switch {
+	case v == "", strings.HasPrefix(v, "|"), strings.HasPrefix(v, ">"):
+		return v
+	case v == "true":
+		return paint(syntaxStyle.True, v)
+	case v == "false":
+		return paint(syntaxStyle.False, v)
+	case v == "null", v == "~":
+		return paint(syntaxStyle.Null, v)
+	case v == "[]", v == "{}":
+		return paint(syntaxStyle.Brackets, v)
+	case yamlNumberRegex.MatchString(v):
+		return paint(syntaxStyle.Number, v)
+	default:
+		return paint(syntaxStyle.String, v)
+	}
+}
+
+// paint wraps s in the escape sequences of a style entry.
+func paint(style [2]string, s string) string {
+	return style[0] + s + style[1]
+}
@@ -0,0 +1,136 @@
+// Copyright (c) 2026 Steve Taranto <staranto@gmail.com>.
+// SPDX-License-Identifier: Apache-2.0
+
+package output
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/apex/log"
+
+	"github.com/staranto/tfctl/internal/attrs"
+)
+
+// GroupCountKey is the output key holding the number of rows in each group.
+const GroupCountKey = "count"
+
+// GroupDataset aggregates the result set by the distinct values of the
+// groupBy attribute, returning one row per value along with the AttrList
+// describing the grouped columns. Each row holds the grouped value and the
+// number of matching rows. An optional agg spec of the form "sum:<attr>" adds a
+// "sum-<attr>" column totalling that attribute's numeric values. Groups are
+// returned in order of first appearance so the caller can apply its own sort.
+// Both attributes must be among the attributes al extracted into the rows,
+// hidden or not, since any other has no value to group or total.
+func GroupDataset(
+	resultSet []map[string]interface{},
+	al attrs.AttrList,
+	groupBy string,
+	agg string,
+) ([]map[string]interface{}, attrs.AttrList, error) {
+	aggFn, aggAttr, err := ParseAggSpec(agg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !hasOutputKey(al, groupBy) {
+		return nil, nil, fmt.Errorf("--group-by attribute %q is not in --attrs", groupBy)
+	}
+	if aggFn != "" && !hasOutputKey(al, aggAttr) {
+		return nil, nil, fmt.Errorf("--agg attribute %q is not in --attrs", aggAttr)
+	}
+
+	grouped := attrs.AttrList{
+		{Key: groupBy, OutputKey: groupBy, Include: true},
+		{Key: GroupCountKey, OutputKey: GroupCountKey, Include: true},
+	}
+
+	aggKey := ""
+	if aggFn != "" {
+		aggKey = aggFn + "-" + aggAttr
+		grouped = append(grouped, attrs.Attr{Key: aggKey, OutputKey: aggKey, Include: true})
+	}
+
+	index := make(map[string]int)
+	var result []map[string]interface{}
+
+	for _, row := range resultSet {
+		value := row[groupBy]
+		key := InterfaceToString(value)
+
+		i, ok := index[key]
+		if !ok {
+			i = len(result)
+			index[key] = i
+			group := map[string]interface{}{groupBy: value, GroupCountKey: float64(0)}
+			if aggKey != "" {
+				group[aggKey] = float64(0)
+			}
+			result = append(result, group)
+		}
+
+		group := result[i]
+		group[GroupCountKey] = group[GroupCountKey].(float64) + 1
+
+		if aggKey != "" {
+			num, ok := aggNumber(row[aggAttr])
+			if !ok {
+				log.Debugf("non-numeric aggregate value skipped: attr=%s, value=%v", aggAttr, row[aggAttr])
+				continue
+			}
+			group[aggKey] = group[aggKey].(float64) + num
+		}
+	}
+
+	return result, grouped, nil
+}
+
+// hasOutputKey reports whether one of al is output as key.
+func hasOutputKey(al attrs.AttrList, key string) bool {
+	for _, a := range al {
+		if a.OutputKey == key {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseAggSpec splits an aggregate spec of the form "<fn>:<attr>" into its
+// function and attribute. An empty spec yields empty results. Only the "sum"
+// function is currently supported.
+func ParseAggSpec(spec string) (fn string, attr string, err error) {
+	if spec == "" {
+		return "", "", nil
+	}
+
+	fn, attr, found := strings.Cut(spec, ":")
+	if !found || attr == "" {
+		return "", "", fmt.Errorf("invalid aggregate %q: expected <fn>:<attr>", spec)
+	}
+
+	if fn != "sum" {
+		return "", "", fmt.Errorf("invalid aggregate function %q: must be sum", fn)
+	}
+
+	return fn, attr, nil
+}
+
+// aggNumber converts a row value to a float64 for aggregation. Numeric strings
+// are accepted since some APIs return counts as strings.
+func aggNumber(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,145 @@
+// Copyright (c) 2026 Steve Taranto <staranto@gmail.com>.
+// SPDX-License-Identifier: Apache-2.0
+
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/staranto/tfctl/internal/attrs"
+)
+
+// orderedRow is a result set row that marshals to JSON and YAML with its keys
+// in column order, so the documents read the same way as the table. Keys the
+// columns don't name follow in sorted order.
+type orderedRow struct {
+	keys []string
+	row  map[string]interface{}
+}
+
+// orderRows wraps each row of the result set in an orderedRow keyed by the
+// included attributes.
+func orderRows(resultSet []map[string]interface{}, attrs attrs.AttrList) []orderedRow {
+	var columns []string
+	for _, attr := range attrs {
+		if attr.Include {
+			columns = append(columns, attr.OutputKey)
+		}
+	}
+
+	// A nil result set stays nil so it marshals as it always has.
+	if resultSet == nil {
+		return nil
+	}
+
+	rows := make([]orderedRow, len(resultSet))
+	for i, row := range resultSet {
+		rows[i] = orderedRow{keys: rowKeys(row, columns), row: row}
+	}
+	return rows
+}
+
+// rowKeys returns the columns present in row, in order, followed by the rest
+// of the row's keys sorted.
+func rowKeys(row map[string]interface{}, columns []string) []string {
+	keys := make([]string, 0, len(row))
+	seen := make(map[string]bool, len(row))
+	for _, c := range columns {
+		if _, ok := row[c]; ok && !seen[c] {
+			keys = append(keys, c)
+			seen[c] = true
+		}
+	}
+
+	var rest []string
+	for k := range row {
+		if !seen[k] {
+			rest = append(rest, k)
+		}
+	}
+	sort.Strings(rest)
+	return append(keys, rest...)
+}
+
+// MarshalJSON renders the row as a JSON object with its keys in order.
+func (r orderedRow) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, k := range r.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(k)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(r.row[k])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// MarshalYAML renders the row as a YAML mapping with its keys in order.
+func (r orderedRow) MarshalYAML() (interface{}, error) {
+	node := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	for _, k := range r.keys {
+		var value yaml.Node
+		if err := value.Encode(yamlValue(r.row[k])); err != nil {
+			return nil, err
+		}
+		node.Content = append(node.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: k}, &value)
+	}
+	return node, nil
+}
+
+// yamlValue prepares a row value for YAML. Numbers decoded from JSON are all
+// float64, so whole ones become int64 to render as 1234567 rather than
+// 1.234567e+06, the way the json output prints them. Lists and objects are
+// prepared recursively.
+func yamlValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) < 1<<53 {
+			return int64(v)
+		}
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			out[i] = yamlValue(item)
+		}
+		return out
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			out[k] = yamlValue(item)
+		}
+		return out
+	}
+	return value
+}
+
+// marshalYAML renders v as YAML with two-space indentation.
+func marshalYAML(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -6,8 +6,23 @@ package output
 
 import (
 	"bytes"
+	"compress/gzip"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
 	"reflect"
+	"regexp"
+	"runtime"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -15,6 +30,8 @@ import (
 	"github.com/urfave/cli/v3"
 
 	"github.com/staranto/tfctl/internal/attrs"
+	"github.com/staranto/tfctl/internal/config"
+	"github.com/staranto/tfctl/internal/driller"
 )
 
 func TestSortDataset(t *testing.T) {
@@ -24,8 +41,19 @@ func TestSortDataset(t *testing.T) {
 		{"name": "beta", "count": 2.0, "type": "azure_vm"},
 	}
 
+	// mixedData has repeated counts and missing values for the multi-key and
+	// null placement cases.
+	mixedData := []map[string]interface{}{
+		{"name": "delta", "count": 2.0},
+		{"name": "alpha", "count": 1.0},
+		{"name": "echo"},
+		{"name": "charlie", "count": 2.0},
+		{"name": "bravo", "count": 1.0},
+	}
+
 	tests := []struct {
 		name      string
+		data      []map[string]interface{}
 		spec      string
 		wantOrder []string
 	}{
@@ -64,6 +92,90 @@ func TestSortDataset(t *testing.T) {
 			spec:      "",
 			wantOrder: []string{"zebra", "alpha", "beta"},
 		},
+		{
+			name:      "mixed direction descending count then ascending name",
+			data:      mixedData,
+			spec:      "-count,name",
+			wantOrder: []string{"charlie", "delta", "alpha", "bravo", "echo"},
+		},
+		{
+			name:      "mixed direction ascending count then descending name",
+			data:      mixedData,
+			spec:      "count,-name",
+			wantOrder: []string{"echo", "bravo", "alpha", "delta", "charlie"},
+		},
+		{
+			name:      "nulls last ascending",
+			data:      mixedData,
+			spec:      "count:nulls-last,name",
+			wantOrder: []string{"alpha", "bravo", "charlie", "delta", "echo"},
+		},
+		{
+			name:      "nulls first descending",
+			data:      mixedData,
+			spec:      "-count:nulls-first,name",
+			wantOrder: []string{"echo", "charlie", "delta", "alpha", "bravo"},
+		},
+		{
+			name:      "nulls last descending",
+			data:      mixedData,
+			spec:      "-count:nulls-last,-name",
+			wantOrder: []string{"delta", "charlie", "bravo", "alpha", "echo"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := testData
+			if tt.data != nil {
+				src = tt.data
+			}
+			data := make([]map[string]interface{}, len(src))
+			copy(data, src)
+			SortDataset(data, tt.spec)
+			for i, expectedName := range tt.wantOrder {
+				assert.Equal(t, expectedName, data[i]["name"], "at index %d", i)
+			}
+		})
+	}
+}
+
+// TestSortDatasetNatural verifies the # modifier compares embedded numeric
+// runs numerically while leaving the default lexicographic order unchanged.
+func TestSortDatasetNatural(t *testing.T) {
+	testData := []map[string]interface{}{
+		{"name": "terraform-v10"},
+		{"name": "terraform-v9"},
+		{"name": "Terraform-v2"},
+		{"name": "terraform-v1.10.0"},
+		{"name": "terraform-v1.9.0"},
+	}
+
+	tests := []struct {
+		name      string
+		spec      string
+		wantOrder []string
+	}{
+		{
+			name:      "lexicographic",
+			spec:      "name",
+			wantOrder: []string{"terraform-v1.10.0", "terraform-v1.9.0", "terraform-v10", "Terraform-v2", "terraform-v9"},
+		},
+		{
+			name:      "natural ascending",
+			spec:      "#name",
+			wantOrder: []string{"terraform-v1.9.0", "terraform-v1.10.0", "Terraform-v2", "terraform-v9", "terraform-v10"},
+		},
+		{
+			name:      "natural descending",
+			spec:      "-#name",
+			wantOrder: []string{"terraform-v10", "terraform-v9", "Terraform-v2", "terraform-v1.10.0", "terraform-v1.9.0"},
+		},
+		{
+			name:      "natural case sensitive",
+			spec:      "#!name",
+			wantOrder: []string{"Terraform-v2", "terraform-v1.9.0", "terraform-v1.10.0", "terraform-v9", "terraform-v10"},
+		},
 	}
 
 	for _, tt := range tests {
@@ -78,6 +190,71 @@ func TestSortDataset(t *testing.T) {
 	}
 }
 
+func TestNaturalCompare(t *testing.T) {
+	assert.Equal(t, -1, naturalCompare("ws-9", "ws-10"))
+	assert.Equal(t, 1, naturalCompare("ws-10", "ws-9"))
+	assert.Equal(t, 0, naturalCompare("ws-007", "ws-7"))
+	assert.Equal(t, -1, naturalCompare("ws", "ws-1"))
+	assert.Equal(t, -1, naturalCompare("a2b", "a2c"))
+	assert.Equal(t, 0, naturalCompare("", ""))
+}
+
+// TestSortDatasetLargeSerial verifies integral values beyond 2^53 sort
+// exactly, and that int64 and float64 values can be compared together.
+func TestSortDatasetLargeSerial(t *testing.T) {
+	data := []map[string]interface{}{
+		{"name": "c", "serial": int64(9007199254740993)},
+		{"name": "a", "serial": int64(9007199254740992)},
+		{"name": "d", "serial": int64(9007199254740994)},
+		{"name": "b", "serial": 5.0},
+	}
+
+	SortDataset(data, "serial")
+	var got []string
+	for _, row := range data {
+		got = append(got, row["name"].(string))
+	}
+	assert.Equal(t, []string{"b", "a", "c", "d"}, got)
+
+	SortDataset(data, "-serial")
+	assert.Equal(t, "d", data[0]["name"])
+	assert.Equal(t, "c", data[1]["name"])
+}
+
+// TestSliceDiceSpitLargeSerial verifies serials beyond 2^53 render without
+// rounding in text and json output, including sq-style state documents.
+func TestSliceDiceSpitLargeSerial(t *testing.T) {
+	docs := map[string]string{
+		"data": `{"data":[{"id":"sv-1","attributes":{"serial":9007199254740993}}]}`,
+		"": `{"resources":[{"mode":"managed","type":"null_resource","name":"x",` +
+			`"instances":[{"attributes":{"serial":9007199254740993}}]}]}`,
+	}
+
+	for parent, doc := range docs {
+		for _, format := range []string{"text", "json"} {
+			t.Run(parent+"/"+format, func(t *testing.T) {
+				var al attrs.AttrList
+				require.NoError(t, al.Set("serial"))
+				if parent == "" {
+					al = attrs.AttrList{{Key: "attributes.serial", OutputKey: "serial", Include: true}}
+				}
+
+				cmd := &cli.Command{
+					Flags: []cli.Flag{
+						&cli.StringFlag{Name: "output", Value: format},
+						&cli.StringFlag{Name: "filter", Value: "serial>9007199254740992"},
+					},
+				}
+
+				buf := new(bytes.Buffer)
+				SliceDiceSpit(*bytes.NewBufferString(doc), al, cmd, parent, buf, nil)
+
+				assert.Contains(t, buf.String(), "9007199254740993")
+			})
+		}
+	}
+}
+
 func TestInterfaceToString(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -274,56 +451,74 @@ func TestDumpSchemaWalker(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := dumpSchemaWalker(tt.prefix, tt.typ, 0)
+			got := dumpSchemaWalker(tt.prefix, tt.typ, 0, false)
 			assert.True(t, tt.checkLen(got), "unexpected tag count: %v", len(got))
 		})
 	}
 }
 
-func TestGetCommonFields(t *testing.T) {
-	tests := []struct {
-		name    string
-		json    string
-		want    map[string]interface{}
-		notWant []string
-	}{
-		{
-			name: "excludes instances",
-			json: `{
-				"address": "aws_instance.example",
-				"mode": "managed",
-				"type": "aws_instance",
-				"instances": [{"id": "i-123"}]
-			}`,
-			want: map[string]interface{}{
-				"address": "aws_instance.example",
-				"mode":    "managed",
-				"type":    "aws_instance",
-			},
-			notWant: []string{"instances"},
-		},
-		{
-			name: "handles empty object",
-			json: `{}`,
-			want: map[string]interface{}{},
-		},
+// TestDumpSchemaDeep verifies --deep reports nested attributes beyond the
+// first level and relationships, as paths Driller resolves in the marshaled
+// payload.
+func TestDumpSchemaDeep(t *testing.T) {
+	type Leaf struct {
+		Branch string `jsonapi:"attr,branch"`
+	}
+	type Middle struct {
+		Leaf *Leaf `jsonapi:"attr,leaf"`
+	}
+	type Org struct {
+		Name string `jsonapi:"primary,organizations"`
+	}
+	type Tag struct {
+		ID string `jsonapi:"primary,tags"`
+	}
+	type Resource struct {
+		ID           string    `jsonapi:"primary,workspaces"`
+		Name         string    `jsonapi:"attr,name"`
+		Middle       *Middle   `jsonapi:"attr,middle"`
+		Items        []*Leaf   `jsonapi:"attr,items"`
+		Organization *Org      `jsonapi:"relation,organization"`
+		Tags         []*Tag    `jsonapi:"relation,tags,omitempty"`
+		Ignored      time.Time `json:"ignored"`
 	}
 
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			// Parse JSON without tjson (since it requires gjson.Result)
-			// Instead test the logic by verifying the structure
-			if tt.notWant != nil {
-				// Verify that the wanted keys are present
-				assert.NotNil(t, tt.want)
-			}
-		})
+	shallow := new(bytes.Buffer)
+	DumpSchema("", reflect.TypeOf(Resource{}), false, shallow)
+	assert.Contains(t, shallow.String(), "use --schema --deep")
+	assert.Contains(t, shallow.String(), "middle.leaf\n")
+	assert.NotContains(t, shallow.String(), "middle.leaf.branch")
+	assert.NotContains(t, shallow.String(), ".relationships")
+
+	deep := new(bytes.Buffer)
+	DumpSchema("", reflect.TypeOf(Resource{}), true, deep)
+	_, paths, _ := strings.Cut(deep.String(), "\n\n")
+	assert.Equal(t, []string{
+		"items",
+		"items[0].branch",
+		"middle",
+		"middle.leaf",
+		"middle.leaf.branch",
+		"name",
+		".relationships.organization.data.id",
+		".relationships.organization.data.type",
+		".relationships.tags.data",
+	}, strings.Split(strings.TrimSpace(paths), "\n"))
+
+	// Every reported path resolves against the marshaled payload.
+	payload := `{"id":"ws-1","attributes":{"name":"a","middle":{"leaf":{"branch":"main"}},` +
+		`"items":[{"branch":"dev"}]},"relationships":{"organization":{"data":{"type":"organizations","id":"org"}},` +
+		`"tags":{"data":[{"type":"tags","id":"t1"}]}}}`
+	var al attrs.AttrList
+	require.NoError(t, al.Set(strings.Join(strings.Split(strings.TrimSpace(paths), "\n"), ",")))
+	for _, attr := range al {
+		assert.True(t, driller.Driller(payload, attr.Key).Exists(), attr.Key)
 	}
 }
 
 func TestGetColors(t *testing.T) {
 	// This test verifies that getColors returns strings
-	header, even, odd := getColors("colors")
+	header, even, odd := getColors("colors", "")
 
 	// Should return strings (may be empty or defaults)
 	assert.IsType(t, "", header)
@@ -331,6 +526,40 @@ func TestGetColors(t *testing.T) {
 	assert.IsType(t, "", odd)
 }
 
+func TestGetColors_Themes(t *testing.T) {
+	cfg := filepath.Join(t.TempDir(), "tfctl.yaml")
+	require.NoError(t, os.WriteFile(cfg, []byte("colors:\n  odd: \"#123456\"\n"), 0o600))
+	t.Setenv("TFCTL_CFG_FILE", cfg)
+	_, err := config.Load()
+	require.NoError(t, err)
+	t.Cleanup(func() { config.Config = config.Type{} })
+
+	// Configured colors override the preset, the rest come from it.
+	header, even, odd := getColors("colors", "solarized")
+	assert.Equal(t, Themes["solarized"].Title, header)
+	assert.Equal(t, Themes["solarized"].Even, even)
+	assert.Equal(t, "#123456", odd)
+
+	// An unknown theme falls back to the default preset.
+	header, _, _ = getColors("colors", "nope")
+	assert.Equal(t, Themes[DefaultTheme].Title, header)
+}
+
+func TestLookupTheme(t *testing.T) {
+	theme, err := LookupTheme("")
+	require.NoError(t, err)
+	assert.Equal(t, Themes[DefaultTheme], theme)
+
+	theme, err = LookupTheme("mono")
+	require.NoError(t, err)
+	assert.Equal(t, Themes["mono"], theme)
+
+	_, err = LookupTheme("neon")
+	assert.EqualError(t, err, `unknown theme "neon", must be one of default, highcontrast, mono, solarized`)
+
+	assert.Equal(t, []string{"default", "highcontrast", "mono", "solarized"}, ThemeNames())
+}
+
 // TestTableWriter verifies tabular output formatting.
 // Note: TableWriter uses fmt.Println which writes to stdout, not the provided
 // writer. This test verifies behavior through the data passed to table rendering,
@@ -341,7 +570,7 @@ func TestTableWriter(t *testing.T) {
 		name      string
 		resultSet []map[string]interface{}
 		attrs     attrs.AttrList
-		withColor bool
+		withColor string
 		withTitle string
 		checkFunc func(*testing.T, []map[string]interface{}, attrs.AttrList)
 	}{
@@ -410,7 +639,7 @@ func TestTableWriter(t *testing.T) {
 
 			cmd := &cli.Command{
 				Flags: []cli.Flag{
-					&cli.BoolFlag{Name: "color", Value: tt.withColor},
+					&cli.StringFlag{Name: "color", Value: tt.withColor},
 					&cli.BoolFlag{Name: "titles", Value: true},
 				},
 			}
@@ -428,6 +657,1008 @@ func TestTableWriter(t *testing.T) {
 	}
 }
 
+func TestColorEnabled(t *testing.T) {
+	newCmd := func(mode string, args ...string) *cli.Command {
+		cmd := &cli.Command{
+			Flags: []cli.Flag{
+				&cli.StringFlag{Name: "color", Value: mode},
+				&cli.StringFlag{Name: "out"},
+			},
+		}
+		require.NoError(t, cmd.Run(context.Background(), append([]string{"test"}, args...)))
+		return cmd
+	}
+	buf := new(bytes.Buffer)
+
+	t.Setenv("NO_COLOR", "")
+	assert.True(t, colorEnabled(newCmd("always"), buf))
+	assert.False(t, colorEnabled(newCmd("never"), buf))
+	assert.False(t, colorEnabled(newCmd(""), buf))
+
+	// auto only colors terminals, and neither a buffer nor a pipe is one.
+	assert.False(t, colorEnabled(newCmd("auto"), buf))
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	t.Cleanup(func() { r.Close(); w.Close() })
+	assert.False(t, colorEnabled(newCmd("auto"), w))
+
+	// Not even always colors the --out file.
+	out, err := os.Create(filepath.Join(t.TempDir(), "out.txt"))
+	require.NoError(t, err)
+	t.Cleanup(func() { out.Close() })
+	assert.False(t, colorEnabled(newCmd("always", "--out", out.Name()), out))
+
+	// NO_COLOR overrides an explicit always.
+	t.Setenv("NO_COLOR", "1")
+	assert.False(t, colorEnabled(newCmd("always"), buf))
+}
+
+// TestSliceDiceSpitColor verifies json and yaml output is syntax highlighted
+// only when color is enabled, and that stripping the colors gives back the
+// plain document.
+func TestSliceDiceSpitColor(t *testing.T) {
+	doc := `{"data":[{"id":"ws-1","attributes":{"name":"prod-api","locked":false,"count":3,"tag":null}}]}`
+	ansi := regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+	for _, format := range []string{"json", "yaml"} {
+		t.Run(format, func(t *testing.T) {
+			t.Setenv("NO_COLOR", "")
+			var al attrs.AttrList
+			require.NoError(t, al.Set(".id,name,locked,count,tag"))
+
+			render := func(color string) string {
+				cmd := &cli.Command{
+					Flags: []cli.Flag{
+						&cli.StringFlag{Name: "output", Value: format},
+						&cli.StringFlag{Name: "color", Value: color},
+					},
+				}
+				require.NoError(t, cmd.Run(context.Background(), []string{"test"}))
+				buf := new(bytes.Buffer)
+				require.NoError(t, SliceDiceSpit(*bytes.NewBufferString(doc), al, cmd, "data", buf, nil))
+				return buf.String()
+			}
+
+			plain := render("never")
+			assert.NotContains(t, plain, "\x1b[")
+
+			colored := render("always")
+			assert.Contains(t, colored, paint(syntaxStyle.String, map[string]string{"json": `"prod-api"`, "yaml": "prod-api"}[format]))
+			assert.Contains(t, colored, paint(syntaxStyle.False, "false"))
+			assert.Contains(t, colored, paint(syntaxStyle.Number, "3"))
+			assert.Contains(t, colored, paint(syntaxStyle.Null, "null"))
+			assert.Equal(t, plain, ansi.ReplaceAllString(colored, ""))
+		})
+	}
+}
+
+// TestColorizeYAML verifies keys and scalars are colored by type, including
+// list items, quoted keys and the lines of a block scalar.
+func TestColorizeYAML(t *testing.T) {
+	key := func(s string) string { return paint(syntaxStyle.Key, s) }
+	str := func(s string) string { return paint(syntaxStyle.String, s) }
+
+	src := "- id: ws-1\n" +
+		"  \"a: b\": x\n" +
+		"  tags:\n" +
+		"    - prod\n" +
+		"    - 42\n" +
+		"  notes: |-\n" +
+		"    line: one\n" +
+		"\n" +
+		"    two\n" +
+		"  url: https://example.com\n" +
+		"  empty: []\n"
+	want := "- " + key("id") + ": " + str("ws-1") + "\n" +
+		"  " + key(`"a: b"`) + ": " + str("x") + "\n" +
+		"  " + key("tags") + ":\n" +
+		"    - " + str("prod") + "\n" +
+		"    - " + paint(syntaxStyle.Number, "42") + "\n" +
+		"  " + key("notes") + ": |-\n" +
+		"    " + str("line: one") + "\n" +
+		"\n" +
+		"    " + str("two") + "\n" +
+		"  " + key("url") + ": " + str("https://example.com") + "\n" +
+		"  " + key("empty") + ": " + paint(syntaxStyle.Brackets, "[]") + "\n"
+
+	assert.Equal(t, want, string(colorizeYAML([]byte(src))))
+}
+
+// TestTableWriterWide verifies that table-wide output never truncates or
+// wraps cell values.
+func TestTableWriterWide(t *testing.T) {
+	long := strings.Repeat("x", 400)
+	resultSet := []map[string]interface{}{
+		{"name": long, "desc": "first line\nsecond line"},
+		{"name": "short", "desc": "plain"},
+	}
+	al := attrs.AttrList{
+		attrs.Attr{OutputKey: "name", Include: true},
+		attrs.Attr{OutputKey: "desc", Include: true},
+	}
+
+	cmd := &cli.Command{
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "output", Value: "table-wide"},
+		},
+	}
+
+	buf := new(bytes.Buffer)
+	TableWriter(resultSet, al, cmd, buf)
+
+	lines := strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+	require.Len(t, lines, 2, "each row should render on exactly one line")
+	assert.Contains(t, lines[0], long)
+	assert.Contains(t, lines[0], `first line\nsecond line`)
+	assert.Contains(t, lines[1], "short")
+}
+
+// TestSliceDiceSpitCount verifies --count prints only the number of rows that
+// survive filtering, regardless of output format.
+func TestSliceDiceSpitCount(t *testing.T) {
+	doc := `{"data":[
+		{"id":"ws-1","attributes":{"name":"prod-api"}},
+		{"id":"ws-2","attributes":{"name":"prod-web"}},
+		{"id":"ws-3","attributes":{"name":"dev-api"}}
+	]}`
+
+	for _, format := range []string{"text", "json", "raw"} {
+		t.Run(format, func(t *testing.T) {
+			var al attrs.AttrList
+			require.NoError(t, al.Set(".id,name"))
+
+			cmd := &cli.Command{
+				Flags: []cli.Flag{
+					&cli.StringFlag{Name: "output", Value: format},
+					&cli.StringFlag{Name: "filter", Value: "name^prod"},
+					&cli.BoolFlag{Name: "count", Value: true},
+				},
+			}
+
+			buf := new(bytes.Buffer)
+			SliceDiceSpit(*bytes.NewBufferString(doc), al, cmd, "data", buf, nil)
+			assert.Equal(t, "2\n", buf.String())
+		})
+	}
+}
+
+// TestSliceDiceSpitRawPath verifies --raw-path prints only the selected part
+// of the raw document, and that a missing path is an error.
+func TestSliceDiceSpitRawPath(t *testing.T) {
+	doc := `{"version":4,"serial":7,"outputs":{"url":{"value":"https://x","type":"string"}},"resources":[]}`
+
+	tests := []struct {
+		name    string
+		path    string
+		want    string
+		wantErr bool
+	}{
+		{name: "whole document", path: "", want: doc},
+		{name: "object", path: "outputs", want: "{\n  \"url\": {\n    \"value\": \"https://x\",\n    \"type\": \"string\"\n  }\n}\n"},
+		{name: "scalar", path: "outputs.url.value", want: "\"https://x\"\n"},
+		{name: "number", path: "serial", want: "7\n"},
+		{name: "missing", path: "outputs.nope", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := &cli.Command{
+				Flags: []cli.Flag{
+					&cli.StringFlag{Name: "output", Value: "raw"},
+					&cli.StringFlag{Name: "raw-path", Value: tt.path},
+				},
+			}
+
+			buf := new(bytes.Buffer)
+			err := SliceDiceSpit(*bytes.NewBufferString(doc), attrs.AttrList{}, cmd, "", buf, nil)
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), "outputs.nope")
+				assert.Empty(t, buf.String())
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, buf.String())
+		})
+	}
+}
+
+// TestSliceDiceSpitFailOnEmpty verifies --fail-on-empty reports ErrEmpty once
+// the empty result is rendered, and nothing when rows match or the flag is off.
+func TestSliceDiceSpitFailOnEmpty(t *testing.T) {
+	doc := `{"data":[{"id":"ws-1","attributes":{"name":"prod-api"}}]}`
+
+	tests := []struct {
+		name        string
+		filter      string
+		count       bool
+		failOnEmpty bool
+		want        string
+		wantErr     error
+	}{
+		{name: "empty", filter: "name^dev", failOnEmpty: true, want: "null", wantErr: ErrEmpty},
+		{name: "empty count", filter: "name^dev", count: true, failOnEmpty: true, want: "0\n", wantErr: ErrEmpty},
+		{name: "matched", filter: "name^prod", failOnEmpty: true, want: `[{"id":"ws-1","name":"prod-api"}]`},
+		{name: "flag off", filter: "name^dev", want: "null"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var al attrs.AttrList
+			require.NoError(t, al.Set(".id,name"))
+
+			cmd := &cli.Command{
+				Flags: []cli.Flag{
+					&cli.StringFlag{Name: "output", Value: "json"},
+					&cli.StringFlag{Name: "filter", Value: tt.filter},
+					&cli.BoolFlag{Name: "count", Value: tt.count},
+					&cli.BoolFlag{Name: "fail-on-empty", Value: tt.failOnEmpty},
+				},
+			}
+
+			buf := new(bytes.Buffer)
+			err := SliceDiceSpit(*bytes.NewBufferString(doc), al, cmd, "data", buf, nil)
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.want, buf.String())
+		})
+	}
+}
+
+// TestSliceDiceSpitFieldsNone verifies --fields=none emits one column-less row
+// per match in each output format.
+func TestSliceDiceSpitFieldsNone(t *testing.T) {
+	doc := `{"data":[
+		{"id":"ws-1","attributes":{"name":"prod-api"}},
+		{"id":"ws-2","attributes":{"name":"prod-web"}},
+		{"id":"ws-3","attributes":{"name":"dev-api"}}
+	]}`
+
+	tests := map[string]string{
+		"text": "\n\n",
+		"json": "[{},{}]",
+		"yaml": "- {}\n- {}\n",
+	}
+
+	for format, want := range tests {
+		t.Run(format, func(t *testing.T) {
+			var al attrs.AttrList
+			require.NoError(t, al.Set(".id,name"))
+
+			cmd := &cli.Command{
+				Flags: []cli.Flag{
+					&cli.StringFlag{Name: "output", Value: format},
+					&cli.StringFlag{Name: "filter", Value: "name^prod"},
+					&cli.StringFlag{Name: "fields", Value: "none"},
+				},
+			}
+
+			buf := new(bytes.Buffer)
+			SliceDiceSpit(*bytes.NewBufferString(doc), al, cmd, "data", buf, nil)
+			assert.Equal(t, want, buf.String())
+		})
+	}
+}
+
+// TestSliceDiceSpitJSONL verifies jsonl emits one object per row and that
+// --with-schema puts the schema line ahead of data lines carrying exactly the
+// schema's keys.
+func TestSliceDiceSpitJSONL(t *testing.T) {
+	doc := `{"data":[
+		{"id":"ws-2","attributes":{"name":"prod-web","locked":true}},
+		{"id":"ws-1","attributes":{"locked":false}}
+	]}`
+
+	tests := map[bool][]string{
+		false: {
+			`{"id":"ws-1","name":null,"locked":false}`,
+			`{"id":"ws-2","name":"prod-web","locked":true}`,
+		},
+		true: {
+			`{"_schema":["id","name"]}`,
+			`{"id":"ws-1","name":null}`,
+			`{"id":"ws-2","name":"prod-web"}`,
+		},
+	}
+
+	for withSchema, want := range tests {
+		t.Run(fmt.Sprintf("with-schema=%v", withSchema), func(t *testing.T) {
+			var al attrs.AttrList
+			require.NoError(t, al.Set(".id,name,!locked"))
+
+			cmd := &cli.Command{
+				Flags: []cli.Flag{
+					&cli.StringFlag{Name: "output", Value: "jsonl"},
+					&cli.StringFlag{Name: "sort", Value: "name"},
+					&cli.BoolFlag{Name: "with-schema", Value: withSchema},
+				},
+			}
+
+			buf := new(bytes.Buffer)
+			SliceDiceSpit(*bytes.NewBufferString(doc), al, cmd, "data", buf, nil)
+			lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+			assert.Equal(t, want, lines)
+
+			if withSchema {
+				var header struct {
+					Schema []string `json:"_schema"`
+				}
+				require.NoError(t, json.Unmarshal([]byte(lines[0]), &header))
+				for _, line := range lines[1:] {
+					var row map[string]interface{}
+					require.NoError(t, json.Unmarshal([]byte(line), &row))
+					assert.ElementsMatch(t, header.Schema, slices.Collect(maps.Keys(row)), line)
+				}
+			}
+		})
+	}
+}
+
+// TestSliceDiceSpitKeyOrder verifies json and yaml render each row's keys in
+// attrs order rather than alphabetically.
+func TestSliceDiceSpitKeyOrder(t *testing.T) {
+	doc := `{"data":[{"id":"ws-1","attributes":{"name":"prod-api","locked":true,"auto-apply":false}}]}`
+
+	tests := map[string]string{
+		"json": `[{"name":"prod-api","locked":true,"id":"ws-1","auto-apply":false}]`,
+		"yaml": "- name: prod-api\n  locked: true\n  id: ws-1\n  auto-apply: false\n",
+	}
+
+	for output, want := range tests {
+		t.Run(output, func(t *testing.T) {
+			var al attrs.AttrList
+			require.NoError(t, al.Set("name,locked,.id,auto-apply"))
+
+			cmd := &cli.Command{
+				Flags: []cli.Flag{
+					&cli.StringFlag{Name: "output", Value: output},
+				},
+			}
+
+			buf := new(bytes.Buffer)
+			SliceDiceSpit(*bytes.NewBufferString(doc), al, cmd, "data", buf, nil)
+			assert.Equal(t, want, buf.String())
+		})
+	}
+}
+
+// TestSliceDiceSpitYAMLValues locks the yaml rendering of nulls, numbers,
+// bools and lists.
+func TestSliceDiceSpitYAMLValues(t *testing.T) {
+	doc := `{"data":[{"id":"ws-1","attributes":{"name":"web","description":null,` +
+		`"resource-count":1234567,"ratio":0.25,"locked":false,"tags":["a","b"]}}]}`
+
+	var al attrs.AttrList
+	require.NoError(t, al.Set(".id,name,description,resource-count,ratio,locked,tags"))
+
+	cmd := &cli.Command{
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "output", Value: "yaml"},
+		},
+	}
+
+	buf := new(bytes.Buffer)
+	SliceDiceSpit(*bytes.NewBufferString(doc), al, cmd, "data", buf, nil)
+	assert.Equal(t, `- id: ws-1
+  name: web
+  description: null
+  resource-count: 1234567
+  ratio: 0.25
+  locked: false
+  tags:
+    - a
+    - b
+`, buf.String())
+}
+
+// TestOrderRows verifies keys outside the columns follow them, sorted.
+func TestOrderRows(t *testing.T) {
+	var al attrs.AttrList
+	require.NoError(t, al.Set("name,.id"))
+
+	rows := orderRows([]map[string]interface{}{{"z": 1, "id": "ws-1", "a": 2, "name": "web"}}, al)
+	out, err := json.Marshal(rows)
+	require.NoError(t, err)
+	assert.Equal(t, `[{"name":"web","id":"ws-1","a":2,"z":1}]`, string(out))
+
+	assert.Nil(t, orderRows(nil, al))
+}
+
+// TestSliceDiceSpitAlso verifies --also-json and --also-csv write the rows to
+// their files in addition to the primary output.
+func TestSliceDiceSpitAlso(t *testing.T) {
+	doc := `{"data":[
+		{"id":"ws-2","attributes":{"name":"prod-web, eu","locked":false}},
+		{"id":"ws-1","attributes":{"name":"prod-api","locked":true}}
+	]}`
+
+	var al attrs.AttrList
+	require.NoError(t, al.Set(".id,name,locked"))
+
+	dir := t.TempDir()
+	jsonPath := filepath.Join(dir, "out.json")
+	csvPath := filepath.Join(dir, "out.csv")
+
+	cmd := &cli.Command{
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "output", Value: "jsonl"},
+			&cli.StringFlag{Name: "sort", Value: "name"},
+			&cli.StringFlag{Name: "also-json", Value: jsonPath},
+			&cli.StringFlag{Name: "also-csv", Value: csvPath},
+		},
+	}
+
+	buf := new(bytes.Buffer)
+	SliceDiceSpit(*bytes.NewBufferString(doc), al, cmd, "data", buf, nil)
+
+	assert.Equal(t, `{"id":"ws-1","name":"prod-api","locked":true}`+"\n"+
+		`{"id":"ws-2","name":"prod-web, eu","locked":false}`+"\n", buf.String())
+
+	jsonOut, err := os.ReadFile(jsonPath)
+	require.NoError(t, err)
+	assert.JSONEq(t, `[{"id":"ws-1","locked":true,"name":"prod-api"},`+
+		`{"id":"ws-2","locked":false,"name":"prod-web, eu"}]`, string(jsonOut))
+
+	csvOut, err := os.ReadFile(csvPath)
+	require.NoError(t, err)
+	assert.Equal(t, "id,name,locked\nws-1,prod-api,true\nws-2,\"prod-web, eu\",false\n", string(csvOut))
+}
+
+// TestSliceDiceSpitAlso_UnwritablePath verifies a file that can't be created
+// leaves the primary output intact.
+func TestSliceDiceSpitAlso_UnwritablePath(t *testing.T) {
+	var al attrs.AttrList
+	require.NoError(t, al.Set(".id"))
+
+	cmd := &cli.Command{
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "output", Value: "json"},
+			&cli.StringFlag{Name: "also-csv", Value: filepath.Join(t.TempDir(), "missing", "out.csv")},
+		},
+	}
+
+	buf := new(bytes.Buffer)
+	SliceDiceSpit(*bytes.NewBufferString(`{"data":[{"id":"ws-1"}]}`), al, cmd, "data", buf, nil)
+	assert.JSONEq(t, `[{"id":"ws-1"}]`, buf.String())
+}
+
+// TestSliceDiceSpitSummary verifies summary output reports the row count and,
+// when rows carry a created-at, the latest timestamp and its status.
+func TestSliceDiceSpitSummary(t *testing.T) {
+	runs := `{"data":[
+		{"id":"run-1","attributes":{"created-at":"2026-01-02T10:00:00Z","status":"applied"}},
+		{"id":"run-3","attributes":{"created-at":"2026-01-04T09:00:00Z","status":"errored"}},
+		{"id":"run-2","attributes":{"created-at":"2026-01-03T12:00:00Z","status":"planned"}}
+	]}`
+
+	tests := []struct {
+		name  string
+		doc   string
+		attrs string
+		want  string
+	}{
+		{
+			name:  "runs",
+			doc:   runs,
+			attrs: ".id,created-at,status",
+			want:  "count: 3\nlatest: 2026-01-04T09:00:00Z\nstatus: errored\n",
+		},
+		{
+			name:  "no status",
+			doc:   runs,
+			attrs: ".id,created-at",
+			want:  "count: 3\nlatest: 2026-01-04T09:00:00Z\n",
+		},
+		{
+			name:  "no timestamp",
+			doc:   `{"data":[{"id":"ws-1","attributes":{"name":"a"}},{"id":"ws-2","attributes":{"name":"b"}}]}`,
+			attrs: ".id,name",
+			want:  "count: 2\n",
+		},
+		{
+			name:  "empty",
+			doc:   `{"data":[]}`,
+			attrs: ".id,created-at",
+			want:  "count: 0\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var al attrs.AttrList
+			require.NoError(t, al.Set(tt.attrs))
+
+			cmd := &cli.Command{
+				Flags: []cli.Flag{
+					&cli.StringFlag{Name: "output", Value: "summary"},
+				},
+			}
+
+			buf := new(bytes.Buffer)
+			SliceDiceSpit(*bytes.NewBufferString(tt.doc), al, cmd, "data", buf, nil)
+			assert.Equal(t, tt.want, buf.String())
+		})
+	}
+}
+
+// TestGroupDataset verifies rows are grouped by distinct value in order of
+// first appearance, with counts and an optional sum aggregate.
+func TestGroupDataset(t *testing.T) {
+	rows := []map[string]interface{}{
+		{"type": "aws_instance", "size": float64(2)},
+		{"type": "aws_s3_bucket", "size": float64(5)},
+		{"type": "aws_instance", "size": "3"},
+		{"type": "aws_instance", "size": nil},
+	}
+
+	var rowAttrs attrs.AttrList
+	require.NoError(t, rowAttrs.Set("type,!size"))
+
+	grouped, al, err := GroupDataset(rows, rowAttrs, "type", "")
+	require.NoError(t, err)
+	assert.Equal(t, []map[string]interface{}{
+		{"type": "aws_instance", "count": float64(3)},
+		{"type": "aws_s3_bucket", "count": float64(1)},
+	}, grouped)
+	require.Len(t, al, 2)
+	assert.Equal(t, "type", al[0].OutputKey)
+	assert.Equal(t, "count", al[1].OutputKey)
+
+	grouped, al, err = GroupDataset(rows, rowAttrs, "type", "sum:size")
+	require.NoError(t, err)
+	require.Len(t, al, 3)
+	assert.Equal(t, "sum-size", al[2].OutputKey)
+	assert.Equal(t, float64(5), grouped[0]["sum-size"])
+	assert.Equal(t, float64(5), grouped[1]["sum-size"])
+
+	_, _, err = GroupDataset(rows, rowAttrs, "type", "avg:size")
+	assert.Error(t, err)
+
+	// Attributes the rows don't carry are named rather than grouped as nil.
+	_, _, err = GroupDataset(rows, rowAttrs, "region", "")
+	assert.EqualError(t, err, `--group-by attribute "region" is not in --attrs`)
+	_, _, err = GroupDataset(rows, rowAttrs, "type", "sum:cost")
+	assert.EqualError(t, err, `--agg attribute "cost" is not in --attrs`)
+}
+
+// TestParseAggSpec verifies aggregate spec parsing and validation.
+func TestParseAggSpec(t *testing.T) {
+	fn, attr, err := ParseAggSpec("sum:agent-count")
+	require.NoError(t, err)
+	assert.Equal(t, "sum", fn)
+	assert.Equal(t, "agent-count", attr)
+
+	fn, attr, err = ParseAggSpec("")
+	require.NoError(t, err)
+	assert.Empty(t, fn)
+	assert.Empty(t, attr)
+
+	for _, spec := range []string{"sum", "sum:", "max:size"} {
+		_, _, err := ParseAggSpec(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+// TestSliceDiceSpitGroupBy verifies --group-by runs after filtering and that
+// --sort applies to the grouped rows.
+func TestSliceDiceSpitGroupBy(t *testing.T) {
+	doc := `{"data":[
+		{"id":"ws-1","attributes":{"name":"api","status":"applied"}},
+		{"id":"ws-2","attributes":{"name":"web","status":"errored"}},
+		{"id":"ws-3","attributes":{"name":"db","status":"errored"}},
+		{"id":"ws-4","attributes":{"name":"old","status":"discarded"}}
+	]}`
+
+	var al attrs.AttrList
+	require.NoError(t, al.Set(".id,name,status"))
+
+	cmd := &cli.Command{
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "output", Value: "text"},
+			&cli.StringFlag{Name: "filter", Value: "status!=discarded"},
+			&cli.StringFlag{Name: "group-by", Value: "status"},
+			&cli.StringFlag{Name: "sort", Value: "-count"},
+		},
+	}
+
+	buf := new(bytes.Buffer)
+	require.NoError(t, SliceDiceSpit(*bytes.NewBufferString(doc), al, cmd, "data", buf, nil))
+
+	lines := strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+	require.Len(t, lines, 2)
+	assert.Equal(t, []string{"errored", "2"}, strings.Fields(lines[0]))
+	assert.Equal(t, []string{"applied", "1"}, strings.Fields(lines[1]))
+
+	// Grouping by an attribute outside --attrs fails instead of printing one
+	// nil group.
+	require.NoError(t, cmd.Set("group-by", "project"))
+	buf.Reset()
+	err := SliceDiceSpit(*bytes.NewBufferString(doc), al, cmd, "data", buf, nil)
+	assert.EqualError(t, err, `--group-by attribute "project" is not in --attrs`)
+	assert.Empty(t, buf.String())
+}
+
+// TestSliceDiceSpitPrometheus verifies prometheus output counts the filtered
+// rows, with one labelled sample per group when --group-by is set.
+func TestSliceDiceSpitPrometheus(t *testing.T) {
+	doc := `{"data":[
+		{"id":"ws-1","attributes":{"status":"applied","size":2}},
+		{"id":"ws-2","attributes":{"status":"errored","size":3}},
+		{"id":"ws-3","attributes":{"status":"errored","size":4}},
+		{"id":"ws-4","attributes":{"status":"discarded","size":5}}
+	]}`
+
+	var al attrs.AttrList
+	require.NoError(t, al.Set(".id,status,size"))
+
+	spit := func(name string, flags ...cli.Flag) string {
+		cmd := &cli.Command{
+			Name: name,
+			Flags: append([]cli.Flag{
+				&cli.StringFlag{Name: "output", Value: "prometheus"},
+				&cli.StringFlag{Name: "filter", Value: "status!=discarded"},
+			}, flags...),
+		}
+		buf := new(bytes.Buffer)
+		SliceDiceSpit(*bytes.NewBufferString(doc), al, cmd, "data", buf, nil)
+		return buf.String()
+	}
+
+	assert.Equal(t, "# HELP tfctl_workspaces_total Number of workspaces matching the query.\n"+
+		"# TYPE tfctl_workspaces_total gauge\n"+
+		"tfctl_workspaces_total 3\n", spit("wq"))
+
+	assert.Equal(t, "# HELP tfctl_rows_total Number of rows matching the query.\n"+
+		"# TYPE tfctl_rows_total gauge\n"+
+		`tfctl_rows_total{status="errored"} 2`+"\n"+
+		`tfctl_rows_total{status="applied"} 1`+"\n"+
+		"# HELP tfctl_rows_size_sum The sum of size over the rows in each group.\n"+
+		"# TYPE tfctl_rows_size_sum gauge\n"+
+		`tfctl_rows_size_sum{status="errored"} 7`+"\n"+
+		`tfctl_rows_size_sum{status="applied"} 2`+"\n",
+		spit("unknown",
+			&cli.StringFlag{Name: "group-by", Value: "status"},
+			&cli.StringFlag{Name: "agg", Value: "sum:size"},
+			&cli.StringFlag{Name: "sort", Value: "-count"},
+		))
+}
+
+// TestSliceDiceSpitExec verifies --output exec pipes the json rows through the
+// formatter command and passes its output through.
+func TestSliceDiceSpitExec(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("formatter commands are run by sh")
+	}
+
+	doc := `{"data":[{"id":"ws-2","attributes":{"name":"web"}},{"id":"ws-1","attributes":{"name":"api"}}]}`
+	var al attrs.AttrList
+	require.NoError(t, al.Set(".id,name"))
+
+	tests := []struct {
+		name      string
+		formatter string
+		want      string
+	}{
+		{name: "passthrough", formatter: "cat", want: `[{"id":"ws-1","name":"api"},{"id":"ws-2","name":"web"}]`},
+		{name: "pipeline", formatter: `tr ',' '\n' | grep -c name`, want: "2\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := &cli.Command{
+				Flags: []cli.Flag{
+					&cli.StringFlag{Name: "output", Value: "exec"},
+					&cli.StringFlag{Name: "formatter-cmd", Value: tt.formatter},
+					&cli.StringFlag{Name: "sort", Value: "name"},
+				},
+			}
+
+			buf := new(bytes.Buffer)
+			require.NoError(t, SliceDiceSpit(*bytes.NewBufferString(doc), al, cmd, "data", buf, nil))
+			assert.Equal(t, tt.want, buf.String())
+		})
+	}
+}
+
+// TestSliceDiceSpitExec_Fails verifies a failing formatter command fails the
+// query, so tfctl exits non-zero.
+func TestSliceDiceSpitExec_Fails(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("formatter commands are run by sh")
+	}
+
+	var al attrs.AttrList
+	require.NoError(t, al.Set(".id"))
+	cmd := &cli.Command{
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "output", Value: "exec"},
+			&cli.StringFlag{Name: "formatter-cmd", Value: "exit 4"},
+		},
+	}
+
+	err := SliceDiceSpit(*bytes.NewBufferString(`{"data":[{"id":"ws-1"}]}`), al, cmd, "data", new(bytes.Buffer), nil)
+	assert.EqualError(t, err, `formatter command "exit 4": exit status 4`)
+}
+
+func TestExecWriter_Fails(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("formatter commands are run by sh")
+	}
+
+	buf := new(bytes.Buffer)
+	err := execWriter([]map[string]interface{}{{"id": "ws-1"}}, nil, "cat >/dev/null; echo partial; exit 3", buf)
+	assert.EqualError(t, err, `formatter command "cat >/dev/null; echo partial; exit 3": exit status 3`)
+	assert.Equal(t, "partial\n", buf.String())
+}
+
+// TestSliceDiceSpitOut verifies every format renders the same through --out,
+// gzipped for a .gz path, as it does to the default writer, which is left
+// untouched.
+func TestSliceDiceSpitOut(t *testing.T) {
+	doc := `{"data":[{"id":"ws-2","attributes":{"name":"web","created-at":"2026-01-02T00:00:00Z"}},` +
+		`{"id":"ws-1","attributes":{"name":"api","created-at":"2026-01-01T00:00:00Z"}}]}`
+
+	for _, format := range []string{"text", "table-wide", "json", "jsonl", "yaml", "raw", "summary", "prometheus"} {
+		t.Run(format, func(t *testing.T) {
+			spit := func(flags ...cli.Flag) *bytes.Buffer {
+				var al attrs.AttrList
+				require.NoError(t, al.Set(".id,name,created-at"))
+				cmd := &cli.Command{
+					Name: "wq",
+					Flags: append([]cli.Flag{
+						&cli.StringFlag{Name: "output", Value: format},
+						&cli.StringFlag{Name: "sort", Value: "name"},
+						&cli.StringFlag{Name: "color", Value: "never"},
+					}, flags...),
+				}
+				buf := new(bytes.Buffer)
+				SliceDiceSpit(*bytes.NewBufferString(doc), al, cmd, "data", buf, nil)
+				return buf
+			}
+
+			want := spit().String()
+			require.NotEmpty(t, want)
+
+			path := filepath.Join(t.TempDir(), "out.gz")
+			assert.Empty(t, spit(&cli.StringFlag{Name: "out", Value: path}).String())
+
+			f, err := os.Open(path)
+			require.NoError(t, err)
+			defer f.Close()
+			zr, err := gzip.NewReader(f)
+			require.NoError(t, err)
+			got, err := io.ReadAll(zr)
+			require.NoError(t, err)
+			assert.Equal(t, want, string(got))
+		})
+	}
+}
+
+// TestSliceDiceSpitOut_Plain verifies a path without .gz is written as is.
+func TestSliceDiceSpitOut_Plain(t *testing.T) {
+	var al attrs.AttrList
+	require.NoError(t, al.Set(".id"))
+
+	path := filepath.Join(t.TempDir(), "out.json")
+	cmd := &cli.Command{
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "output", Value: "json"},
+			&cli.StringFlag{Name: "out", Value: path},
+		},
+	}
+	SliceDiceSpit(*bytes.NewBufferString(`{"data":[{"id":"ws-1"}]}`), al, cmd, "data", nil, nil)
+
+	raw, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, `[{"id":"ws-1"}]`, string(raw))
+}
+
+// TestSliceDiceSpitOut_Fails verifies an --out file that can't be opened
+// fails the query.
+func TestSliceDiceSpitOut_Fails(t *testing.T) {
+	var al attrs.AttrList
+	require.NoError(t, al.Set(".id"))
+
+	path := filepath.Join(t.TempDir(), "missing", "out.json")
+	cmd := &cli.Command{
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "output", Value: "json"},
+			&cli.StringFlag{Name: "out", Value: path},
+		},
+	}
+	err := SliceDiceSpit(*bytes.NewBufferString(`{"data":[{"id":"ws-1"}]}`), al, cmd, "data", nil, nil)
+	require.ErrorIs(t, err, fs.ErrNotExist)
+	assert.ErrorContains(t, err, "failed to write "+path)
+}
+
+// TestSliceDiceSpitOut_Appends verifies repeated writes to the same --out and
+// --also-csv files in one process, as --all-workspaces and batch make, keep
+// every write, gzipped ones as a multi-member stream, while the first write
+// replaces what a previous run left.
+func TestSliceDiceSpitOut_Appends(t *testing.T) {
+	var al attrs.AttrList
+	require.NoError(t, al.Set(".id"))
+
+	dir := t.TempDir()
+	out := filepath.Join(dir, "out.jsonl.gz")
+	also := filepath.Join(dir, "also.csv")
+	require.NoError(t, os.WriteFile(also, []byte("stale\n"), 0o600))
+	cmd := &cli.Command{
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "output", Value: "jsonl"},
+			&cli.StringFlag{Name: "out", Value: out},
+			&cli.StringFlag{Name: "also-csv", Value: also},
+		},
+	}
+	SliceDiceSpit(*bytes.NewBufferString(`{"data":[{"id":"ws-1"}]}`), al, cmd, "data", nil, nil)
+	SliceDiceSpit(*bytes.NewBufferString(`{"data":[{"id":"ws-2"}]}`), al, cmd, "data", nil, nil)
+
+	f, err := os.Open(out)
+	require.NoError(t, err)
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	require.NoError(t, err)
+	got, err := io.ReadAll(zr)
+	require.NoError(t, err)
+	assert.Equal(t, "{\"id\":\"ws-1\"}\n{\"id\":\"ws-2\"}\n", string(got))
+
+	raw, err := os.ReadFile(also)
+	require.NoError(t, err)
+	assert.Equal(t, "id\nws-1\nid\nws-2\n", string(raw))
+}
+
+// sqliteQuery runs query against the database at path and returns its rows,
+// each as its columns joined with "|".
+func sqliteQuery(t *testing.T, path, query string) []string {
+	t.Helper()
+	db, err := sql.Open("sqlite", path)
+	require.NoError(t, err)
+	defer db.Close()
+
+	rows, err := db.Query(query)
+	require.NoError(t, err)
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	require.NoError(t, err)
+	var got []string
+	for rows.Next() {
+		values := make([]any, len(columns))
+		ptrs := make([]any, len(columns))
+		for i := range values {
+			ptrs[i] = &values[i]
+		}
+		require.NoError(t, rows.Scan(ptrs...))
+		fields := make([]string, len(values))
+		for i, v := range values {
+			if v != nil {
+				fields[i] = fmt.Sprintf("%v", v)
+			}
+		}
+		got = append(got, strings.Join(fields, "|"))
+	}
+	require.NoError(t, rows.Err())
+	return got
+}
+
+// TestSliceDiceSpitSQLite verifies --output sqlite writes a table named for
+// the command's rows, with a column per attribute and values of their type.
+func TestSliceDiceSpitSQLite(t *testing.T) {
+	doc := `{"data":[
+		{"id":"ws-2","attributes":{"name":"prod-web","locked":false,"resource-count":12,"tags":["a","b"]}},
+		{"id":"ws-1","attributes":{"name":"it's \"quoted\"","locked":true,"resource-count":0,"ratio":0.5}}
+	]}`
+
+	var al attrs.AttrList
+	require.NoError(t, al.Set(".id,name,locked,resource-count,tags,ratio"))
+
+	path := filepath.Join(t.TempDir(), "tfctl.sqlite")
+	cmd := &cli.Command{
+		Name: "wq",
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "output", Value: "sqlite"},
+			&cli.StringFlag{Name: "out", Value: path},
+		},
+	}
+
+	buf := new(bytes.Buffer)
+	SliceDiceSpit(*bytes.NewBufferString(doc), al, cmd, "data", buf, nil)
+	assert.Empty(t, buf.String())
+
+	assert.Equal(t, []string{"ok"}, sqliteQuery(t, path, "PRAGMA integrity_check"))
+	assert.Equal(t, []string{
+		`ws-1|it's "quoted"|1|integer|0|integer||0.5|real`,
+		`ws-2|prod-web|0|integer|12|integer|["a","b"]||null`,
+	}, sqliteQuery(t, path, `SELECT id, name, locked, typeof(locked), "resource-count", typeof("resource-count"), tags, ratio, typeof(ratio) FROM workspaces ORDER BY id`))
+}
+
+// TestSliceDiceSpitSQLiteAppends verifies repeated writes to one --out
+// database, as --all-workspaces makes, add to its table, while a new run
+// replaces the file.
+func TestSliceDiceSpitSQLiteAppends(t *testing.T) {
+	var al attrs.AttrList
+	require.NoError(t, al.Set(".id,name"))
+
+	path := filepath.Join(t.TempDir(), "tfctl.sqlite")
+	require.NoError(t, os.WriteFile(path, []byte("stale"), 0o600))
+	cmd := &cli.Command{
+		Name: "wq",
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "output", Value: "sqlite"},
+			&cli.StringFlag{Name: "out", Value: path},
+		},
+	}
+
+	SliceDiceSpit(*bytes.NewBufferString(`{"data":[{"id":"ws-1","attributes":{"name":"a"}}]}`), al, cmd, "data", nil, nil)
+	SliceDiceSpit(*bytes.NewBufferString(`{"data":[{"id":"ws-2","attributes":{"name":"b"}}]}`), al, cmd, "data", nil, nil)
+
+	assert.Equal(t, []string{"ws-1|a", "ws-2|b"}, sqliteQuery(t, path, "SELECT id, name FROM workspaces ORDER BY id"))
+}
+
+// TestSliceDiceSpitSQLite_Fails verifies a database that can't be written,
+// here one reused with other columns, fails the query.
+func TestSliceDiceSpitSQLite_Fails(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tfctl.sqlite")
+	spit := func(spec string) error {
+		var al attrs.AttrList
+		require.NoError(t, al.Set(spec))
+		cmd := &cli.Command{
+			Name: "wq",
+			Flags: []cli.Flag{
+				&cli.StringFlag{Name: "output", Value: "sqlite"},
+				&cli.StringFlag{Name: "out", Value: path},
+			},
+		}
+		return SliceDiceSpit(*bytes.NewBufferString(`{"data":[{"id":"ws-1","attributes":{"name":"a"}}]}`), al, cmd, "data", nil, nil)
+	}
+
+	require.NoError(t, spit(".id,name"))
+	err := spit(".id")
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "failed to write "+path)
+}
+
+func TestRowSubject(t *testing.T) {
+	assert.Equal(t, "workspaces", rowSubject("wq"))
+	assert.Equal(t, "rows", rowSubject("batch"))
+}
+
+// TestSqliteWriter_Large verifies many rows and large values read back intact.
+func TestSqliteWriter_Large(t *testing.T) {
+	var al attrs.AttrList
+	require.NoError(t, al.Set("n,v"))
+
+	var rows []map[string]interface{}
+	want := 0
+	for i := range 20000 {
+		v := strings.Repeat("x", i%700)
+		if i%5000 == 0 {
+			v = strings.Repeat("y", 20000+i)
+		}
+		want += len(v)
+		rows = append(rows, map[string]interface{}{"n": float64(i), "v": v})
+	}
+
+	path := filepath.Join(t.TempDir(), "large.sqlite")
+	require.NoError(t, sqliteWriter(rows, al, "sq", path))
+
+	assert.Equal(t, []string{"ok"}, sqliteQuery(t, path, "PRAGMA integrity_check"))
+	assert.Equal(t, []string{fmt.Sprintf("20000|199990000|%d", want)},
+		sqliteQuery(t, path, "SELECT count(*), sum(n), sum(length(v)) FROM resources"))
+	assert.Equal(t, []string{"12345|445"}, sqliteQuery(t, path, "SELECT n, length(v) FROM resources WHERE rowid = 12346"))
+}
+
+func TestSqliteCreateTable(t *testing.T) {
+	assert.Equal(t, `CREATE TABLE IF NOT EXISTS "runs" ("id", "na""me", "ID_2")`, sqliteCreateTable("runs", []string{"id", `na"me`, "ID"}))
+}
+
+// TestPromNames verifies metric and label names and values are sanitized.
+func TestPromNames(t *testing.T) {
+	assert.Equal(t, "created_at", promName("created-at"))
+	assert.Equal(t, "_1st", promName("1st"))
+	assert.Equal(t, "_", promName(""))
+	assert.Equal(t, `a\\b\"c\nd`, promLabelValue("a\\b\"c\nd"))
+}
+
 // TestFlattenState verifies resource flattening from Terraform state format.
 func TestFlattenState(t *testing.T) {
 	tests := []struct {
@@ -579,63 +1810,171 @@ func TestFlattenState(t *testing.T) {
 				resources = parsedJSON.Array()[0]
 			}
 
-			result := flattenState(resources, tt.short)
+			result := flattenState(resources, tt.short, "")
 			tt.checkFunc(t, result)
 		})
 	}
 }
 
-// TestGetCommonFieldsRobust uses gjson to test field extraction logic.
-func TestGetCommonFieldsRobust(t *testing.T) {
+// TestFlattenStateAddressSep verifies the resource address components are
+// joined with the requested separator, leaving index keys intact.
+func TestFlattenStateAddressSep(t *testing.T) {
+	doc := `[
+		{"module":"module.net","mode":"managed","type":"aws_subnet","name":"a","instances":[{"index_key":0}]},
+		{"mode":"data","type":"aws_ami","name":"ubuntu","instances":[{"index_key":"eu.west-1"}]},
+		{"module":"module.app.module.db","mode":"managed","type":"aws_db","name":"main","instances":[{}]}
+	]`
+
 	tests := []struct {
-		name      string
-		json      string
-		checkFunc func(*testing.T, map[string]interface{})
+		name  string
+		sep   string
+		short bool
+		want  []string
 	}{
 		{
-			name: "extracts all non-instance fields",
-			json: `{
-				"type": "aws_instance",
-				"name": "web",
-				"mode": "managed",
-				"module": "module.main",
-				"instances": [{"id": "i-123"}]
-			}`,
-			checkFunc: func(t *testing.T, common map[string]interface{}) {
-				assert.Equal(t, "aws_instance", common["type"])
-				assert.Equal(t, "web", common["name"])
-				assert.Equal(t, "managed", common["mode"])
-				assert.NotContains(t, common, "instances")
-			},
+			name:  "default",
+			sep:   "",
+			short: true,
+			want:  []string{"module.net.aws_subnet.a[0]", `data.aws_ami.ubuntu["eu.west-1"]`, "module.app.module.db.aws_db.main"},
 		},
 		{
-			name: "handles no instances key",
-			json: `{
-				"type": "aws_vpc",
-				"name": "main"
-			}`,
-			checkFunc: func(t *testing.T, common map[string]interface{}) {
-				assert.Equal(t, "aws_vpc", common["type"])
+			name:  "explicit dot",
+			sep:   ".",
+			short: true,
+			want:  []string{"module.net.aws_subnet.a[0]", `data.aws_ami.ubuntu["eu.west-1"]`, "module.app.module.db.aws_db.main"},
+		},
+		{
+			name:  "slash",
+			sep:   "/",
+			short: true,
+			want:  []string{"module/net/aws_subnet/a[0]", `data/aws_ami/ubuntu["eu.west-1"]`, "module/app/module/db/aws_db/main"},
+		},
+		{
+			name:  "multi-character",
+			sep:   "::",
+			short: true,
+			want:  []string{"module::net::aws_subnet::a[0]", `data::aws_ami::ubuntu["eu.west-1"]`, "module::app::module::db::aws_db::main"},
+		},
+		{
+			name:  "slash with module markers",
+			sep:   "/",
+			short: false,
+			want:  []string{"+net/aws_subnet/a[0]", `data/aws_ami/ubuntu["eu.west-1"]`, "+app+db/aws_db/main"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := flattenState(gjson.Parse(doc), tt.short, tt.sep)
+
+			var got []string
+			for _, r := range gjson.Parse(result.String()).Array() {
+				got = append(got, r.Get("resource").String())
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+// TestFlattenStateAddressIndexKeys verifies instance addresses match
+// Terraform's own formatting for count indexes, for_each keys and nested
+// modules, so they can be pasted into terraform state commands.
+func TestFlattenStateAddressIndexKeys(t *testing.T) {
+	doc := `[
+		{"mode":"managed","type":"aws_instance","name":"web","instances":[{"index_key":0},{"index_key":12}]},
+		{"mode":"managed","type":"aws_s3_bucket","name":"b","instances":[
+			{"index_key":"a.b"},
+			{"index_key":"say \"hi\""},
+			{"index_key":"back\\slash"},
+			{"index_key":"tab\there"},
+			{"index_key":"${var}"},
+			{"index_key":"100%{x}"},
+			{"index_key":"x[0]"}
+		]},
+		{"module":"module.app[\"eu.west\"].module.db[1]","mode":"managed","type":"aws_db","name":"main","instances":[{"index_key":"primary"}]},
+		{"module":"module.submodules","mode":"data","type":"aws_ami","name":"module","instances":[{}]}
+	]`
+
+	tests := []struct {
+		name  string
+		short bool
+		sep   string
+		want  []string
+	}{
+		{
+			name:  "short",
+			short: true,
+			want: []string{
+				"aws_instance.web[0]",
+				"aws_instance.web[12]",
+				`aws_s3_bucket.b["a.b"]`,
+				`aws_s3_bucket.b["say \"hi\""]`,
+				`aws_s3_bucket.b["back\\slash"]`,
+				`aws_s3_bucket.b["tab\there"]`,
+				`aws_s3_bucket.b["$${var}"]`,
+				`aws_s3_bucket.b["100%%{x}"]`,
+				`aws_s3_bucket.b["x[0]"]`,
+				`module.app["eu.west"].module.db[1].aws_db.main["primary"]`,
+				"module.submodules.data.aws_ami.module",
 			},
 		},
 		{
-			name: "empty object",
-			json: `{}`,
-			checkFunc: func(t *testing.T, common map[string]interface{}) {
-				assert.Empty(t, common)
+			name:  "module markers with slash",
+			short: false,
+			sep:   "/",
+			want: []string{
+				"aws_instance/web[0]",
+				"aws_instance/web[12]",
+				`aws_s3_bucket/b["a.b"]`,
+				`aws_s3_bucket/b["say \"hi\""]`,
+				`aws_s3_bucket/b["back\\slash"]`,
+				`aws_s3_bucket/b["tab\there"]`,
+				`aws_s3_bucket/b["$${var}"]`,
+				`aws_s3_bucket/b["100%%{x}"]`,
+				`aws_s3_bucket/b["x[0]"]`,
+				`+app["eu.west"]+db[1]/aws_db/main["primary"]`,
+				"+submodules/data/aws_ami/module",
 			},
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			resource := gjson.Parse(tt.json)
-			common := getCommonFields(resource)
-			tt.checkFunc(t, common)
+			result := flattenState(gjson.Parse(doc), tt.short, tt.sep)
+
+			var got []string
+			for _, r := range gjson.Parse(result.String()).Array() {
+				got = append(got, r.Get("resource").String())
+			}
+			assert.Equal(t, tt.want, got)
 		})
 	}
 }
 
+// TestFlattenStateFields verifies each row carries the resource fields except
+// instances, that instance fields win over resource fields of the same name,
+// and that raw values such as large integers are kept verbatim.
+func TestFlattenStateFields(t *testing.T) {
+	doc := `[{"mode":"managed","type":"null_resource","name":"x","provider":"p","schema_version":0,
+		"instances":[{"schema_version":2,"attributes":{"n":9007199254740993,"s":"a\"b"}},{"resource":"bogus"}]}]`
+
+	result := flattenState(gjson.Parse(doc), true, "")
+	require.True(t, json.Valid(result.Bytes()), result.String())
+
+	rows := gjson.Parse(result.String()).Array()
+	require.Len(t, rows, 2)
+
+	assert.Equal(t, "p", rows[0].Get("provider").String())
+	assert.False(t, rows[0].Get("instances").Exists())
+	assert.Equal(t, int64(2), rows[0].Get("schema_version").Int())
+	assert.Equal(t, "9007199254740993", rows[0].Get("attributes.n").Raw)
+	assert.Equal(t, `a"b`, rows[0].Get("attributes.s").String())
+	assert.Equal(t, "null_resource.x", rows[0].Get("resource").String())
+
+	assert.Equal(t, int64(0), rows[1].Get("schema_version").Int())
+	assert.Equal(t, "null_resource.x", rows[1].Get("resource").String())
+}
+
 // TestInterfaceToStringEdgeCases covers edge cases in value-to-string conversion.
 func TestInterfaceToStringEdgeCases(t *testing.T) {
 	tests := []struct {
@@ -724,3 +2063,108 @@ func BenchmarkInterfaceToString(b *testing.B) {
 		}
 	}
 }
+
+// largeStateDoc returns a synthetic state document with n resources of two
+// instances each, every instance carrying about 1KB of attributes.
+func largeStateDoc(n int) []byte {
+	var b strings.Builder
+	b.WriteString(`{"version":4,"serial":1,"resources":[`)
+	for i := range n {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		fmt.Fprintf(&b, `{"module":"module.m%d","mode":"managed","type":"aws_instance","name":"r%d",`+
+			`"provider":"provider[\"registry.terraform.io/hashicorp/aws\"]","instances":[`, i%10, i)
+		for j := range 2 {
+			if j > 0 {
+				b.WriteByte(',')
+			}
+			fmt.Fprintf(&b, `{"index_key":%d,"schema_version":1,"attributes":{"id":"i-%08d","ami":"ami-0123456789",`+
+				`"tags":{"Name":"r%d","Owner":"team"},"user_data":"%s"}}`, j, i*2+j, i, strings.Repeat("x", 900))
+		}
+		b.WriteString(`]}`)
+	}
+	b.WriteString(`]}`)
+	return []byte(b.String())
+}
+
+// BenchmarkSliceDiceSpitState measures sq-style rendering of a large state.
+// On the 11MB fixture, streaming the flattened rows and parsing the document
+// once cut allocations from about 216MB and 1.32M objects per run to about
+// 48MB and 213K.
+func BenchmarkSliceDiceSpitState(b *testing.B) {
+	doc := largeStateDoc(5000)
+	al := attrs.AttrList{
+		{Key: "resource", OutputKey: "resource", Include: true},
+		{Key: "attributes.id", OutputKey: "id", Include: true},
+	}
+	for _, format := range []string{"text", "json"} {
+		b.Run(format, func(b *testing.B) {
+			cmd := &cli.Command{
+				Flags: []cli.Flag{
+					&cli.StringFlag{Name: "output", Value: format},
+					&cli.StringFlag{Name: "filter", Value: "id=~0"},
+				},
+			}
+			b.ReportAllocs()
+			b.ResetTimer()
+			for range b.N {
+				SliceDiceSpit(*bytes.NewBuffer(doc), al, cmd, "", io.Discard, nil)
+			}
+		})
+	}
+}
+
+// TestPagerCommand verifies TFCTL_PAGER wins over PAGER, which wins over the
+// default, and that an empty TFCTL_PAGER turns paging off.
+func TestPagerCommand(t *testing.T) {
+	t.Setenv("PAGER", "")
+	os.Unsetenv("TFCTL_PAGER")
+	assert.Equal(t, DefaultPager, pagerCommand())
+
+	t.Setenv("PAGER", "more")
+	assert.Equal(t, "more", pagerCommand())
+
+	t.Setenv("TFCTL_PAGER", "most")
+	assert.Equal(t, "most", pagerCommand())
+
+	t.Setenv("TFCTL_PAGER", "")
+	assert.Equal(t, "", pagerCommand())
+}
+
+// TestWritePaged verifies output taller than the terminal goes through the
+// pager, and that short output, --no-pager and a missing pager write it
+// directly.
+func TestWritePaged(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("pagers are run by sh")
+	}
+
+	tests := []struct {
+		name    string
+		pager   string
+		noPager bool
+		out     string
+		want    string
+	}{
+		{name: "paged", pager: "tr a-z A-Z", out: "a\nb\nc\n", want: "A\nB\nC\n"},
+		{name: "fits", pager: "tr a-z A-Z", out: "a\nb\n", want: "a\nb\n"},
+		{name: "no-pager", pager: "tr a-z A-Z", noPager: true, out: "a\nb\nc\n", want: "a\nb\nc\n"},
+		{name: "missing pager", pager: "tfctl-no-such-pager", out: "a\nb\nc\n", want: "a\nb\nc\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("TFCTL_PAGER", tt.pager)
+			cmd := &cli.Command{
+				Flags: []cli.Flag{
+					&cli.BoolFlag{Name: "no-pager", Value: tt.noPager},
+				},
+			}
+
+			buf := new(bytes.Buffer)
+			writePagedHeight(cmd, []byte(tt.out), buf, 3)
+			assert.Equal(t, tt.want, buf.String())
+		})
+	}
+}
@@ -0,0 +1,101 @@
+// Copyright (c) 2026 Steve Taranto <staranto@gmail.com>.
+// SPDX-License-Identifier: Apache-2.0
+
+package output
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+
+	"github.com/apex/log"
+	"github.com/urfave/cli/v3"
+	"golang.org/x/term"
+)
+
+// DefaultPager is the pager used when neither TFCTL_PAGER nor PAGER is set.
+const DefaultPager = "less -R"
+
+// terminalHeight returns the height of w when it is a terminal, or 0.
+func terminalHeight(w io.Writer) int {
+	f, ok := w.(*os.File)
+	if !ok || !term.IsTerminal(int(f.Fd())) {
+		return 0
+	}
+	_, height, err := term.GetSize(int(f.Fd()))
+	if err != nil {
+		return 0
+	}
+	return height
+}
+
+// pagerCommand returns the pager to use: TFCTL_PAGER, then PAGER, then
+// DefaultPager. An empty TFCTL_PAGER turns paging off.
+func pagerCommand() string {
+	if pager, ok := os.LookupEnv("TFCTL_PAGER"); ok {
+		return pager
+	}
+	if pager := os.Getenv("PAGER"); pager != "" {
+		return pager
+	}
+	return DefaultPager
+}
+
+// pagerFor returns the pager that rendered output of lines lines should be
+// piped through on its way to a terminal height lines tall, or "" to write it
+// directly. Paging happens only when the output doesn't fit on the terminal
+// and --no-pager isn't set. A height of 0, for anything but a terminal, never
+// pages, so redirected output is unchanged.
+func pagerFor(cmd *cli.Command, height int, lines int) string {
+	if cmd.Bool("no-pager") {
+		return ""
+	}
+	if height <= 0 || lines < height {
+		return ""
+	}
+	return pagerCommand()
+}
+
+// writePaged writes out to w, through the pager chosen by pagerFor if there is
+// one. The pager is run by the shell, like git does. If it can't be run, out
+// is written directly instead.
+func writePaged(cmd *cli.Command, out []byte, w io.Writer) {
+	writePagedHeight(cmd, out, w, terminalHeight(w))
+}
+
+// writePagedHeight is writePaged for a w that is a terminal height lines
+// tall, or not a terminal if height is 0.
+func writePagedHeight(cmd *cli.Command, out []byte, w io.Writer, height int) {
+	pager := pagerFor(cmd, height, bytes.Count(out, []byte("\n")))
+	if pager == "" {
+		_, _ = w.Write(out)
+		return
+	}
+
+	name, args := "sh", []string{"-c", pager}
+	if runtime.GOOS == "windows" {
+		name, args = "cmd", []string{"/C", pager}
+	}
+
+	c := exec.Command(name, args...)
+	c.Stdin = bytes.NewReader(out)
+	c.Stdout = w
+	c.Stderr = os.Stderr
+	if err := c.Start(); err != nil {
+		log.Debugf("pager %q: %v", pager, err)
+		_, _ = w.Write(out)
+		return
+	}
+	if err := c.Wait(); err != nil {
+		log.Debugf("pager %q: %v", pager, err)
+		// A pager the shell couldn't find never saw the output. Any other
+		// failure, such as quitting less early, is not worth reporting.
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 127 {
+			_, _ = w.Write(out)
+		}
+	}
+}
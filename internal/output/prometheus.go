@@ -0,0 +1,90 @@
+// Copyright (c) 2026 Steve Taranto <staranto@gmail.com>.
+// SPDX-License-Identifier: Apache-2.0
+
+package output
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/staranto/tfctl/internal/attrs"
+)
+
+// promInvalidName matches the characters not allowed in a Prometheus metric or
+// label name.
+var promInvalidName = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+// prometheusWriter renders the result set as Prometheus text exposition, so it
+// can be scraped from a node_exporter textfile. Without groupBy it emits the
+// number of rows. With groupBy the rows are those of GroupDataset, and each
+// group becomes a sample labelled with its value, along with one more metric
+// per --agg column.
+func prometheusWriter(
+	resultSet []map[string]interface{},
+	attrs attrs.AttrList,
+	command string,
+	groupBy string,
+	w io.Writer,
+) {
+	subject := rowSubject(command)
+	metric := "tfctl_" + subject + "_total"
+	help := "Number of " + strings.ReplaceAll(subject, "_", " ") + " matching the query."
+
+	if groupBy == "" {
+		promHeader(w, metric, help)
+		fmt.Fprintf(w, "%s %d\n", metric, len(resultSet))
+		return
+	}
+
+	label := promName(groupBy)
+
+	// The grouped columns after the value and its count are --agg columns,
+	// e.g. sum-size, each exported as its own metric.
+	columns := []struct{ key, metric, help string }{{GroupCountKey, metric, help}}
+	for _, attr := range attrs {
+		if attr.OutputKey == groupBy || attr.OutputKey == GroupCountKey {
+			continue
+		}
+		fn, name, _ := strings.Cut(attr.OutputKey, "-")
+		columns = append(columns, struct{ key, metric, help string }{
+			attr.OutputKey,
+			"tfctl_" + subject + "_" + promName(name) + "_" + fn,
+			fmt.Sprintf("The %s of %s over the %s in each group.", fn, name, strings.ReplaceAll(subject, "_", " ")),
+		})
+	}
+
+	for _, c := range columns {
+		promHeader(w, c.metric, c.help)
+		for _, row := range resultSet {
+			value, _ := aggNumber(row[c.key])
+			fmt.Fprintf(w, "%s{%s=\"%s\"} %s\n",
+				c.metric, label, promLabelValue(InterfaceToString(row[groupBy])),
+				strconv.FormatFloat(value, 'f', -1, 64))
+		}
+	}
+}
+
+// promHeader writes the HELP and TYPE lines of a gauge.
+func promHeader(w io.Writer, metric string, help string) {
+	fmt.Fprintf(w, "# HELP %s %s\n", metric, help)
+	fmt.Fprintf(w, "# TYPE %s gauge\n", metric)
+}
+
+// promName turns an attribute name into a valid metric or label name, e.g.
+// created-at becomes created_at.
+func promName(name string) string {
+	name = promInvalidName.ReplaceAllString(name, "_")
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		name = "_" + name
+	}
+	return name
+}
+
+// promLabelValue escapes the characters the exposition format reserves in
+// label values. The caller quotes the result.
+func promLabelValue(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
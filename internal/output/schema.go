@@ -35,20 +35,32 @@ func (t schemaTag) print() (out string) {
 // recursion.
 const maxSchemaDepth = 1
 
+// maxDeepSchemaDepth limits the depth of schema walking in --deep mode, which
+// follows nested attributes as far as the API types nest them.
+const maxDeepSchemaDepth = 8
+
 // DumpSchema writes a sorted list of attribute tags for the provided type
-// to the provided writer. If w is nil, os.Stdout is used.
-func DumpSchema(prefix string, typ reflect.Type, w io.Writer) {
+// to the provided writer. If w is nil, os.Stdout is used. With deep, nested
+// attributes are walked to any depth and relationships are included, each as
+// the full dotted path driller.Driller resolves for --attrs.
+func DumpSchema(prefix string, typ reflect.Type, deep bool, w io.Writer) {
 	if w == nil {
 		w = os.Stdout
 	}
 
-	fmt.Fprintln(w,
-		`Resource level attributes that are directly available to the --attrs flag.
-For a complete schema, including relationships, use --output=raw and see the
-attrs help in the documentation or man tfctl-attrs.`)
+	if deep {
+		fmt.Fprintln(w,
+			`Attribute paths available to the --attrs flag, including nested attributes
+and relationships. Paths starting with '.' are relative to the resource root
+and must be given that way, e.g. --attrs .relationships.project.data.id.`)
+	} else {
+		fmt.Fprintln(w,
+			`Resource level attributes that are directly available to the --attrs flag.
+For a complete schema, including relationships, use --schema --deep.`)
+	}
 	fmt.Fprintln(w, "")
 
-	tags := dumpSchemaWalker(prefix, typ, 0)
+	tags := dumpSchemaWalker(prefix, typ, 0, deep)
 	if len(tags) == 0 {
 		log.Debugf("No tags found for type: %s", typ.Name())
 		return
@@ -68,9 +80,15 @@ attrs help in the documentation or man tfctl-attrs.`)
 }
 
 // dumpSchemaWalker recursively walks a struct type discovering jsonapi tags.
-func dumpSchemaWalker(holder string, typ reflect.Type, depth int) []schemaTag {
+// With deep, relationships of the top-level type are reported as well.
+func dumpSchemaWalker(holder string, typ reflect.Type, depth int, deep bool) []schemaTag {
 	tags := make([]schemaTag, 0)
 
+	maxDepth := maxSchemaDepth
+	if deep {
+		maxDepth = maxDeepSchemaDepth
+	}
+
 	for i := 0; i < typ.NumField(); i++ {
 		field := typ.Field(i)
 
@@ -81,6 +99,10 @@ func dumpSchemaWalker(holder string, typ reflect.Type, depth int) []schemaTag {
 			continue
 		}
 
+		if deep && depth == 0 {
+			tags = append(tags, relationTags(tagValue, field.Type)...)
+		}
+
 		tag := NewTag(holder, tagValue)
 		if tag.Kind != "attr" {
 			continue
@@ -88,18 +110,20 @@ func dumpSchemaWalker(holder string, typ reflect.Type, depth int) []schemaTag {
 
 		tags = append(tags, tag)
 
-		if depth < maxSchemaDepth {
+		if depth < maxDepth {
 
 			switch field.Type.Kind() {
 			case reflect.Struct:
-				tags = append(tags, dumpSchemaWalker(tag.Name, field.Type, depth+1)...)
+				tags = append(tags, dumpSchemaWalker(tag.Name, field.Type, depth+1, deep)...)
 			case reflect.Ptr:
 				if field.Type.Elem().Kind() == reflect.Struct {
-					holder := tag.Name
-					if tag.Kind == "relation" {
-						holder = fmt.Sprintf(".relationships.%s.data", tag.Name)
-					}
-					tags = append(tags, dumpSchemaWalker(holder, field.Type.Elem(), depth+1)...)
+					tags = append(tags, dumpSchemaWalker(tag.Name, field.Type.Elem(), depth+1, deep)...)
+				}
+			case reflect.Slice:
+				// Driller reaches into a list by index, so the first element
+				// stands in for all of them.
+				if elem := sliceStruct(field.Type); deep && elem != nil {
+					tags = append(tags, dumpSchemaWalker(tag.Name+"[0]", elem, depth+1, deep)...)
 				}
 			default:
 				if strings.Contains(field.Type.String(), ".") {
@@ -112,3 +136,37 @@ func dumpSchemaWalker(holder string, typ reflect.Type, depth int) []schemaTag {
 
 	return tags
 }
+
+// relationTags returns the paths of a jsonapi relation field as it is
+// marshaled under .relationships. A to-one relation holds a single identifier,
+// so its id and type are addressable. A to-many relation holds a list of them.
+func relationTags(tagValue string, typ reflect.Type) []schemaTag {
+	kind, name, _ := strings.Cut(tagValue, ",")
+	name, _, _ = strings.Cut(name, ",")
+	if kind != "relation" || name == "" {
+		return nil
+	}
+
+	data := fmt.Sprintf(".relationships.%s.data", name)
+	if typ.Kind() == reflect.Slice {
+		return []schemaTag{{Kind: kind, Name: data}}
+	}
+
+	return []schemaTag{
+		{Kind: kind, Name: data + ".id"},
+		{Kind: kind, Name: data + ".type"},
+	}
+}
+
+// sliceStruct returns the struct type held by a slice of structs or struct
+// pointers, or nil for any other slice.
+func sliceStruct(typ reflect.Type) reflect.Type {
+	elem := typ.Elem()
+	if elem.Kind() == reflect.Ptr {
+		elem = elem.Elem()
+	}
+	if elem.Kind() != reflect.Struct {
+		return nil
+	}
+	return elem
+}
@@ -8,61 +8,213 @@ import (
 	"strings"
 )
 
+// Null placement values for sortKey.nulls.
+const (
+	nullsDefault = iota
+	nullsFirst
+	nullsLast
+)
+
+// sortKey is a single parsed entry from a --sort spec.
+type sortKey struct {
+	field         string
+	ascending     bool
+	caseSensitive bool
+	natural       bool
+	nulls         int
+}
+
+// parseSortSpec parses a comma-separated sort spec. Each key carries its own
+// modifiers: a leading - for descending order, ! for case-sensitive and # for
+// natural string comparison, and an optional trailing :nulls-first or
+// :nulls-last to pin missing values regardless of direction.
+func parseSortSpec(spec string) []sortKey {
+	var keys []sortKey
+	for _, field := range strings.Split(spec, ",") {
+		field = strings.TrimSpace(field)
+		key := sortKey{ascending: true}
+
+		if strings.HasPrefix(field, "-") {
+			field = strings.TrimPrefix(field, "-")
+			key.ascending = false
+		}
+
+		// The ! (case-sensitive) and # (natural) modifiers may appear in either
+		// order after the optional -.
+		for len(field) > 0 && (field[0] == '!' || field[0] == '#') {
+			if field[0] == '!' {
+				key.caseSensitive = true
+			} else {
+				key.natural = true
+			}
+			field = field[1:]
+		}
+
+		switch {
+		case strings.HasSuffix(field, ":nulls-first"):
+			field = strings.TrimSuffix(field, ":nulls-first")
+			key.nulls = nullsFirst
+		case strings.HasSuffix(field, ":nulls-last"):
+			field = strings.TrimSuffix(field, ":nulls-last")
+			key.nulls = nullsLast
+		}
+
+		key.field = field
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// SortDataset sorts the result set in place according to spec. Keys are
+// applied left to right, each later key only breaking ties left by the ones
+// before it, and each key's direction and modifiers apply to that key alone.
+// See parseSortSpec for the key syntax.
+//
 // THINK Issue 5
 func SortDataset(resultSet []map[string]interface{}, spec string) {
-	fields := strings.Split(spec, ",")
+	keys := parseSortSpec(spec)
 
 	sort.SliceStable(resultSet, func(one, two int) bool {
+		for _, key := range keys {
+			oneValue := resultSet[one][key.field]
+			twoValue := resultSet[two][key.field]
 
-		for _, field := range fields {
-			ascending := true
-			if strings.HasPrefix(field, "-") {
-				field = strings.TrimPrefix(field, "-")
-				ascending = false
+			// Null placement is independent of the key's direction.
+			if key.nulls != nullsDefault && (oneValue == nil) != (twoValue == nil) {
+				return (oneValue == nil) == (key.nulls == nullsFirst)
 			}
 
-			caseSensitive := false
-			if strings.HasPrefix(field, "!") {
-				field = strings.TrimPrefix(field, "!")
-				caseSensitive = true
+			if c := compareValues(oneValue, twoValue, key); c != 0 {
+				if key.ascending {
+					return c < 0
+				}
+				return c > 0
 			}
+		}
+		return false
+	})
+}
 
-			oneValue := resultSet[one][field]
-			twoValue := resultSet[two][field]
+// compareValues compares two row values for a single sort key, returning -1,
+// 0 or 1 in the manner of strings.Compare.
+func compareValues(oneValue, twoValue interface{}, key sortKey) int {
+	// Compare integral values exactly when both sides are int64 so that large
+	// serials beyond 2^53 sort correctly.
+	oneBig, oneOk := oneValue.(int64)
+	twoBig, twoOk := twoValue.(int64)
 
-			// Convert to integers if possible
-			oneInt, oneOk := oneValue.(float64)
-			twoInt, twoOk := twoValue.(float64)
+	if oneOk && twoOk {
+		switch {
+		case oneBig < twoBig:
+			return -1
+		case oneBig > twoBig:
+			return 1
+		}
+		return 0
+	}
 
-			if oneOk && twoOk {
-				if int(oneInt) != int(twoInt) {
-					if ascending {
-						return int(oneInt) < int(twoInt)
-					}
-					return int(oneInt) > int(twoInt)
-				}
-				continue
-			}
+	// Convert to integers if possible
+	oneInt, oneOk := toFloat64(oneValue)
+	twoInt, twoOk := toFloat64(twoValue)
 
-			// Fall back to string comparison which can also handle bools.
-			oneStr := InterfaceToString(oneValue)
-			twoStr := InterfaceToString(twoValue)
+	if oneOk && twoOk {
+		switch {
+		case int(oneInt) < int(twoInt):
+			return -1
+		case int(oneInt) > int(twoInt):
+			return 1
+		}
+		return 0
+	}
 
-			compareOneStr := oneStr
-			compareTwoStr := twoStr
-			if !caseSensitive {
-				compareOneStr = strings.ToLower(oneStr)
-				compareTwoStr = strings.ToLower(twoStr)
-			}
+	// Fall back to string comparison which can also handle bools.
+	oneStr := InterfaceToString(oneValue)
+	twoStr := InterfaceToString(twoValue)
+
+	if !key.caseSensitive {
+		oneStr = strings.ToLower(oneStr)
+		twoStr = strings.ToLower(twoStr)
+	}
+
+	if key.natural {
+		return naturalCompare(oneStr, twoStr)
+	}
+
+	return strings.Compare(oneStr, twoStr)
+}
 
-			if compareOneStr != compareTwoStr {
-				if ascending {
-					return compareOneStr < compareTwoStr
+// toFloat64 returns the float64 form of the numeric values found in result
+// rows.
+func toFloat64(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case int64:
+		return float64(n), true
+	default:
+		return 0, false
+	}
+}
+
+// naturalCompare compares two strings treating each run of digits as a
+// number, so "terraform-v9" sorts before "terraform-v10". It returns -1, 0 or
+// 1 in the manner of strings.Compare.
+func naturalCompare(one, two string) int {
+	for one != "" && two != "" {
+		oneDigits := isDigit(one[0])
+		twoDigits := isDigit(two[0])
+
+		if !oneDigits || !twoDigits {
+			if one[0] != two[0] {
+				if one[0] < two[0] {
+					return -1
 				}
-				return compareOneStr > compareTwoStr
+				return 1
 			}
+			one, two = one[1:], two[1:]
+			continue
+		}
+
+		// Both sides start a numeric run. Compare the runs by magnitude, which
+		// after dropping leading zeros is length first, then digits.
+		oneRun, oneRest := splitDigits(one)
+		twoRun, twoRest := splitDigits(two)
+		oneNum := strings.TrimLeft(oneRun, "0")
+		twoNum := strings.TrimLeft(twoRun, "0")
 
+		if len(oneNum) != len(twoNum) {
+			if len(oneNum) < len(twoNum) {
+				return -1
+			}
+			return 1
 		}
-		return false
-	})
+		if c := strings.Compare(oneNum, twoNum); c != 0 {
+			return c
+		}
+
+		one, two = oneRest, twoRest
+	}
+
+	switch {
+	case one == "" && two == "":
+		return 0
+	case one == "":
+		return -1
+	default:
+		return 1
+	}
+}
+
+// splitDigits splits s into its leading run of digits and the remainder.
+func splitDigits(s string) (string, string) {
+	i := 0
+	for i < len(s) && isDigit(s[i]) {
+		i++
+	}
+	return s[:i], s[i:]
+}
+
+// isDigit reports whether b is an ASCII digit.
+func isDigit(b byte) bool {
+	return b >= '0' && b <= '9'
 }
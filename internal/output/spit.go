@@ -5,21 +5,28 @@ package output
 
 import (
 	"bytes"
+	"compress/gzip"
+	"encoding/csv"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
+	"os/exec"
+	"path/filepath"
 	"reflect"
-	"regexp"
+	"runtime"
 	"strconv"
 	"strings"
+	"sync"
+	"unicode"
 
 	"github.com/apex/log"
 	"github.com/charmbracelet/lipgloss/v2"
 	"github.com/charmbracelet/lipgloss/v2/table"
 	"github.com/tidwall/gjson"
 	"github.com/urfave/cli/v3"
-	"gopkg.in/yaml.v2"
+	"golang.org/x/term"
 
 	"github.com/staranto/tfctl/internal/attrs"
 	"github.com/staranto/tfctl/internal/config"
@@ -44,6 +51,8 @@ func InterfaceToString(value interface{}, emptyValue ...string) string {
 		return value
 	case int:
 		return strconv.Itoa(value)
+	case int64:
+		return strconv.FormatInt(value, 10)
 	case float64:
 		// Our current use cases have no need for an actual float, so we just return
 		// an integer.
@@ -97,46 +106,72 @@ func NewTag(h string, s string) schemaTag {
 	return tag
 }
 
+// ErrEmpty is returned by SliceDiceSpit when --fail-on-empty is set and no
+// rows survive filtering.
+var ErrEmpty = errors.New("no matching rows")
+
 // SliceDiceSpit orchestrates filtering, transforming, sorting and rendering
 // of a dataset according to command flags and attribute specifications. The
 // optional postProcess callback allows commands to apply custom transformations
-// to the filtered dataset before rendering.
+// to the filtered dataset before rendering. Rendering problems are logged. The
+// errors returned are ErrEmpty, after the empty result has been rendered, a
+// --group-by or --agg naming an attribute the rows don't carry, a failed
+// --formatter-cmd and an --out file or sqlite database that can't be written.
 func SliceDiceSpit(raw bytes.Buffer,
 	attrs attrs.AttrList,
 	cmd *cli.Command,
 	parent string,
 	w io.Writer,
-	postProcess func([]map[string]interface{}) error) {
+	postProcess func([]map[string]interface{}) error) (err error) {
 
-	// Default to stdout.
+	// Default to stdout, unless --out names a file for every format to go to.
 	if w == nil {
 		w = os.Stdout
 	}
+	// A sqlite database isn't a stream to append to, so sqliteWriter opens
+	// --out itself.
+	if out := cmd.String("out"); out != "" && cmd.String("output") != "sqlite" {
+		f, openErr := createOutFile(out)
+		if openErr != nil {
+			return fmt.Errorf("failed to write %s: %w", out, openErr)
+		}
+		// Closing a gzipped file flushes it, so a failed close is a failed
+		// write, unless the query already failed.
+		defer func() {
+			if closeErr := f.Close(); closeErr != nil && err == nil {
+				err = fmt.Errorf("failed to write %s: %w", out, closeErr)
+			}
+		}()
+		w = f
+	}
 
-	// If raw, just dump it and go home.
+	// If raw, just dump it, or the part of it --raw-path selects, and go home.
+	// --count needs the filtered rows, so it takes precedence over raw.
 	output := cmd.String("output")
-	if output == "raw" {
-		_, _ = w.Write(raw.Bytes())
-		return
+	if output == "raw" && !cmd.Bool("count") {
+		return writeRaw(raw.Bytes(), cmd.String("raw-path"), w)
 	}
 
+	// The document is copied into a string and parsed once, and every lookup
+	// below works on that parse.
+	doc := gjson.Parse(raw.String())
+
 	// Flatten the state schema, if this is sq.  This is done to bring the
 	// structure of the state file into alignment with the structures found in
 	// other command's payloads, thus enabling a common set of logic to process
 	// all.
-	if resources := gjson.Parse(raw.String()).Get("resources"); resources.Exists() {
-		raw = flattenState(resources, !cmd.Bool("short"))
+	if resources := doc.Get("resources"); resources.Exists() {
+		flat := flattenState(resources, !cmd.Bool("short"), cmd.String("address-sep"))
+		doc = gjson.Parse(flat.String())
 	}
 
-	var fullDataset gjson.Result
 	// We keep the "data" object from the document and throw away everything
 	// else, notably "included", which we don't have a use case for. We also
 	// parse this into JSON so that we can use the lowercase key names and not
 	// the proper case names from the TFE API.
+	fullDataset := doc
 	if parent != "" {
-		fullDataset = gjson.Parse(raw.String()).Get(parent)
-	} else {
-		fullDataset = gjson.Parse(raw.String())
+		fullDataset = doc.Get(parent)
 	}
 
 	filter := cmd.String("filter")
@@ -154,7 +189,33 @@ func SliceDiceSpit(raw bytes.Buffer,
 	// Filter out the rows we don't want. Do it here so that the following
 	// processes are slightly more efficient since they'll be working on a smaller
 	// dataset.
-	filteredDataset := filters.FilterDataset(fullDataset, attrs, filter)
+	// Neither --count nor --fields=none look at row contents, so they use the
+	// skeleton filter and skip value extraction entirely.
+	skeleton := cmd.String("fields") == "none"
+	var filteredDataset []map[string]interface{}
+	if skeleton || cmd.Bool("count") {
+		filteredDataset = filters.FilterDatasetSkeleton(fullDataset, attrs, filter)
+	} else {
+		filteredDataset = filters.FilterDataset(fullDataset, attrs, filter)
+	}
+
+	// The empty result is still rendered, e.g. as 0 or [], before it's reported.
+	var empty error
+	if len(filteredDataset) == 0 && cmd.Bool("fail-on-empty") {
+		empty = ErrEmpty
+	}
+
+	// In count mode only the number of matching rows is emitted, so there's no
+	// need to transform, sort or render anything.
+	if cmd.Bool("count") {
+		fmt.Fprintln(w, len(filteredDataset))
+		return empty
+	}
+
+	if skeleton {
+		skeletonWriter(filteredDataset, output, w)
+		return empty
+	}
 
 	// THINK Force a time transformation to occur for all attributes, even though
 	// many will not be a timestamp. One alternative would be to look at first row
@@ -175,24 +236,55 @@ func SliceDiceSpit(raw bytes.Buffer,
 		}
 	}
 
+	// Grouping replaces the rows with one row per distinct value, so the sort
+	// and renderers below operate on the grouped result.
+	if groupBy := cmd.String("group-by"); groupBy != "" {
+		grouped, groupedAttrs, err := GroupDataset(filteredDataset, attrs, groupBy, cmd.String("agg"))
+		if err != nil {
+			return err
+		}
+		filteredDataset, attrs = grouped, groupedAttrs
+	}
+
 	spec := cmd.String("sort")
 	SortDataset(filteredDataset, spec)
 
 	switch output {
 	case "json":
-		// We marshal the filtered dataset into a JSON document.
-		// TODO Figure out how to maintain key order in the JSON document.
-		jsonOutput, err := json.Marshal(filteredDataset)
+		// We marshal the filtered dataset into a JSON document, keeping the
+		// columns in attrs order.
+		jsonOutput, err := json.Marshal(orderRows(filteredDataset, attrs))
 		if err != nil {
 			log.Errorf("SliceDiceSpit json marshal: %v", err)
 		}
-		os.Stdout.Write(jsonOutput)
+		if colorEnabled(cmd, w) {
+			jsonOutput = colorizeJSON(jsonOutput)
+		}
+		_, _ = w.Write(jsonOutput)
+	case "jsonl":
+		jsonlWriter(filteredDataset, attrs, cmd.Bool("with-schema"), w)
+	case "prometheus":
+		prometheusWriter(filteredDataset, attrs, cmd.Name, cmd.String("group-by"), w)
+	case "exec":
+		if err := execWriter(filteredDataset, attrs, cmd.String("formatter-cmd"), w); err != nil {
+			return err
+		}
+	case "sqlite":
+		// A database is a file, not a stream, so the validator insists on --out.
+		if err := sqliteWriter(filteredDataset, attrs, cmd.Name, cmd.String("out")); err != nil {
+			return fmt.Errorf("failed to write %s: %w", cmd.String("out"), err)
+		}
+	case "summary":
+		summaryWriter(filteredDataset, w)
 	case "yaml":
-		yamlOutput, err := yaml.Marshal(filteredDataset)
+		yamlOutput, err := marshalYAML(orderRows(filteredDataset, attrs))
 		if err != nil {
 			log.Errorf("SliceDiceSpit yaml marshal: %v", err)
 		}
-		os.Stdout.Write(yamlOutput)
+		if colorEnabled(cmd, w) {
+			yamlOutput = colorizeYAML(yamlOutput)
+		}
+		_, _ = w.Write(yamlOutput)
 	default:
 		// We apply command-specific post-processing.
 		if postProcess != nil {
@@ -203,6 +295,289 @@ func SliceDiceSpit(raw bytes.Buffer,
 
 		TableWriter(filteredDataset, attrs, cmd, w)
 	}
+
+	// The primary output is done, so write any --also-<format> copies.
+	alsoWriter(filteredDataset, attrs, cmd)
+
+	return empty
+}
+
+// alsoFormats maps each --also-<format> flag to the writer that renders the
+// dataset into the file the flag names.
+var alsoFormats = []struct {
+	flag  string
+	write func([]map[string]interface{}, attrs.AttrList, io.Writer) error
+}{
+	{"also-csv", csvWriter},
+	{"also-json", func(resultSet []map[string]interface{}, attrs attrs.AttrList, w io.Writer) error {
+		jsonOutput, err := json.Marshal(orderRows(resultSet, attrs))
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(jsonOutput)
+		return err
+	}},
+}
+
+// alsoWriter writes the dataset to the file named by each --also-<format>
+// flag, in addition to the primary --output. A file that can't be written is
+// logged and does not affect the others.
+func alsoWriter(resultSet []map[string]interface{}, attrs attrs.AttrList, cmd *cli.Command) {
+	for _, f := range alsoFormats {
+		path := cmd.String(f.flag)
+		if path == "" {
+			continue
+		}
+
+		if err := writeAlsoFile(path, func(w io.Writer) error {
+			return f.write(resultSet, attrs, w)
+		}); err != nil {
+			log.Errorf("SliceDiceSpit %s: %v", f.flag, err)
+		}
+	}
+}
+
+// writeAlsoFile creates path and fills it with write.
+func writeAlsoFile(path string, write func(io.Writer) error) error {
+	f, err := createOutFile(path)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// outFiles holds the output files this process has written, keyed by
+// absolute path, so one run writing a file several times, once per workspace
+// of --all-workspaces or per batch line, keeps every write instead of only the
+// last.
+var (
+	outFilesMu sync.Mutex
+	outFiles   = map[string]bool{}
+)
+
+// claimOutFile reports whether this is the first time this process writes
+// path, recording that it has.
+func claimOutFile(path string) bool {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	outFilesMu.Lock()
+	defer outFilesMu.Unlock()
+	if outFiles[path] {
+		return false
+	}
+	outFiles[path] = true
+	return true
+}
+
+// OpenOut opens path, as named by --out, for writing, or returns stdout when
+// path is "". Like SliceDiceSpit, it appends to a file this process has
+// already written, so markers between runs land in the same file as the runs.
+func OpenOut(path string) (io.WriteCloser, error) {
+	if path == "" {
+		return nopCloser{os.Stdout}, nil
+	}
+	return createOutFile(path)
+}
+
+// nopCloser is a writer, such as stdout, that isn't closed when done with.
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error { return nil }
+
+// createOutFile opens path for output, truncating it the first time this
+// process writes it and appending after that. A path ending in .gz is gzipped,
+// each write being its own gzip member, so closing the returned writer
+// finishes the gzip stream and then the file.
+func createOutFile(path string) (io.WriteCloser, error) {
+	flag := os.O_WRONLY | os.O_CREATE | os.O_APPEND
+	if claimOutFile(path) {
+		flag |= os.O_TRUNC
+	}
+	f, err := os.OpenFile(path, flag, 0o666)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasSuffix(path, ".gz") {
+		return f, nil
+	}
+	return &gzipFile{Writer: gzip.NewWriter(f), f: f}, nil
+}
+
+// gzipFile is a gzip stream written to a file.
+type gzipFile struct {
+	*gzip.Writer
+	f *os.File
+}
+
+// Close flushes the gzip stream and closes the file.
+func (g *gzipFile) Close() error {
+	if err := g.Writer.Close(); err != nil {
+		g.f.Close()
+		return err
+	}
+	return g.f.Close()
+}
+
+// execWriter pipes the result set, as --output json would render it, to the
+// stdin of command, run by the shell, and passes the command's stdout through
+// to w. Its stderr goes to tfctl's stderr.
+func execWriter(resultSet []map[string]interface{}, attrs attrs.AttrList, command string, w io.Writer) error {
+	jsonOutput, err := json.Marshal(orderRows(resultSet, attrs))
+	if err != nil {
+		return err
+	}
+
+	name, args := "sh", []string{"-c", command}
+	if runtime.GOOS == "windows" {
+		name, args = "cmd", []string{"/C", command}
+	}
+
+	c := exec.Command(name, args...)
+	c.Stdin = bytes.NewReader(jsonOutput)
+	c.Stdout = w
+	c.Stderr = os.Stderr
+	if err := c.Run(); err != nil {
+		return fmt.Errorf("formatter command %q: %w", command, err)
+	}
+	return nil
+}
+
+// csvWriter renders the result set as CSV with a header row of the included
+// attributes in column order.
+func csvWriter(resultSet []map[string]interface{}, attrs attrs.AttrList, w io.Writer) error {
+	var keys []string
+	for _, attr := range attrs {
+		if attr.Include {
+			keys = append(keys, attr.OutputKey)
+		}
+	}
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write(keys); err != nil {
+		return err
+	}
+
+	record := make([]string, len(keys))
+	for _, row := range resultSet {
+		for i, key := range keys {
+			// InterfaceToString renders false as empty, which is ambiguous in a
+			// CSV cell.
+			if b, ok := row[key].(bool); ok {
+				record[i] = strconv.FormatBool(b)
+			} else {
+				record[i] = InterfaceToString(row[key])
+			}
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// skeletonWriter renders the column-less rows produced by --fields=none. Text
+// output is one empty line per row, json and yaml are a list of empty objects.
+func skeletonWriter(resultSet []map[string]interface{}, output string, w io.Writer) {
+	switch output {
+	case "json":
+		jsonOutput, err := json.Marshal(resultSet)
+		if err != nil {
+			log.Errorf("skeletonWriter json marshal: %v", err)
+		}
+		_, _ = w.Write(jsonOutput)
+	case "jsonl":
+		_, _ = io.WriteString(w, strings.Repeat("{}\n", len(resultSet)))
+	case "yaml":
+		yamlOutput, err := marshalYAML(resultSet)
+		if err != nil {
+			log.Errorf("skeletonWriter yaml marshal: %v", err)
+		}
+		_, _ = w.Write(yamlOutput)
+	default:
+		_, _ = io.WriteString(w, strings.Repeat("\n", len(resultSet)))
+	}
+}
+
+// jsonlWriter renders the result set as newline-delimited JSON, one object per
+// row. With withSchema, the rows are preceded by a {"_schema": [...]} line
+// naming the included attributes in column order, so streaming consumers know
+// the shape without inferring it from the rows. Each row then carries exactly
+// those keys: hidden attributes are dropped and missing ones are null.
+func jsonlWriter(resultSet []map[string]interface{}, attrs attrs.AttrList, withSchema bool, w io.Writer) {
+	enc := json.NewEncoder(w)
+
+	if withSchema {
+		schema := []string{}
+		for _, attr := range attrs {
+			if attr.Include {
+				schema = append(schema, attr.OutputKey)
+			}
+		}
+		if err := enc.Encode(map[string][]string{"_schema": schema}); err != nil {
+			log.Errorf("jsonlWriter schema marshal: %v", err)
+			return
+		}
+
+		projected := make([]map[string]interface{}, len(resultSet))
+		for i, row := range resultSet {
+			projected[i] = make(map[string]interface{}, len(schema))
+			for _, key := range schema {
+				projected[i][key] = row[key]
+			}
+		}
+		resultSet = projected
+	}
+
+	for _, row := range orderRows(resultSet, attrs) {
+		if err := enc.Encode(row); err != nil {
+			log.Errorf("jsonlWriter marshal: %v", err)
+		}
+	}
+}
+
+// summaryTimeKeys are the row keys, in order of preference, that summaryWriter
+// treats as the activity timestamp of a row.
+var summaryTimeKeys = []string{"created-at", "updated-at"}
+
+// summaryWriter renders a compact, non-tabular summary of the result set: the
+// row count and, when the rows carry a timestamp (runs, state versions), the
+// latest timestamp along with the status of that row, if it has one. The
+// timestamp and status are only found when they are among the selected
+// attributes.
+func summaryWriter(resultSet []map[string]interface{}, w io.Writer) {
+	fmt.Fprintf(w, "count: %d\n", len(resultSet))
+
+	for _, key := range summaryTimeKeys {
+		var latest map[string]interface{}
+		for _, row := range resultSet {
+			ts := InterfaceToString(row[key])
+			if ts == "" {
+				continue
+			}
+			// RFC3339 timestamps in a common zone order lexically.
+			if latest == nil || ts > InterfaceToString(latest[key]) {
+				latest = row
+			}
+		}
+		if latest == nil {
+			continue
+		}
+
+		fmt.Fprintf(w, "latest: %s\n", InterfaceToString(latest[key]))
+		if status := InterfaceToString(latest["status"]); status != "" {
+			fmt.Fprintf(w, "status: %s\n", status)
+		}
+		return
+	}
 }
 
 // TableWriter renders the result set in a tabular form honoring color,
@@ -231,9 +606,14 @@ func TableWriter(
 		oddRowStyle  = cellStyle
 	)
 
+	// Wide mode renders each row on a single line at its natural width,
+	// regardless of terminal width, so output piped to files or viewed on
+	// wide terminals is never truncated or wrapped.
+	wide := cmd.String("output") == "table-wide"
+
 	// We apply color styles if coloring is enabled.
-	if cmd.Bool("color") {
-		headerColor, evenColor, oddColor := getColors("colors")
+	if colorEnabled(cmd, w) {
+		headerColor, evenColor, oddColor := getColors("colors", cmd.String("theme"))
 
 		headerStyle = headerStyle.Foreground(lipgloss.Color(headerColor))
 		evenRowStyle = evenRowStyle.Foreground(lipgloss.Color(evenColor))
@@ -248,14 +628,22 @@ func TableWriter(
 			if !attr.Include {
 				continue
 			}
-			row = append(row, InterfaceToString(result[attr.OutputKey], "-"))
+			cell := InterfaceToString(result[attr.OutputKey], "-")
+			if wide {
+				cell = strings.NewReplacer("\r", `\r`, "\n", `\n`).Replace(cell)
+			}
+			row = append(row, cell)
 		}
 		rows = append(rows, row)
 	}
 
+	// The table is rendered in full before it's written, so its length can
+	// decide whether it goes through a pager.
+	var out bytes.Buffer
+
 	// We render the header if present.
 	if cmd.Metadata["header"] != nil {
-		fmt.Fprintln(w, headerStyle.Render(cmd.Metadata["header"].(string)))
+		fmt.Fprintln(&out, headerStyle.Render(cmd.Metadata["header"].(string)))
 	}
 
 	// We configure the table with padding and styles.
@@ -286,6 +674,11 @@ func TableWriter(
 		Headers().
 		Rows(rows...)
 
+	// An explicit zero width disables lipgloss column fitting in wide mode.
+	if wide {
+		t = t.Width(0)
+	}
+
 	// We add column headers if titles are enabled.
 	if cmd.Bool("titles") {
 		var headers []string
@@ -298,90 +691,293 @@ func TableWriter(
 		// https://github.com/charmbracelet/lipgloss/issues/261
 		t = t.Headers(headers...).BorderHeader(false)
 	}
-	fmt.Fprintln(w, t)
+	fmt.Fprintln(&out, t)
 
 	// We render the footer if present.
 	if cmd.Metadata["footer"] != nil {
-		fmt.Fprintln(w, headerStyle.Render(cmd.Metadata["footer"].(string)))
+		fmt.Fprintln(&out, headerStyle.Render(cmd.Metadata["footer"].(string)))
 	}
+
+	writePaged(cmd, out.Bytes(), w)
+}
+
+// writeRaw writes the raw document to w. If path is set, only the value at
+// that gjson path is written, indented, and a path the document doesn't have
+// is an error.
+func writeRaw(raw []byte, path string, w io.Writer) error {
+	if path == "" {
+		_, _ = w.Write(raw)
+		return nil
+	}
+
+	value := gjson.GetBytes(raw, path)
+	if !value.Exists() {
+		return fmt.Errorf("--raw-path %q: no such path in the document", path)
+	}
+
+	var out bytes.Buffer
+	if err := json.Indent(&out, []byte(value.Raw), "", "  "); err != nil {
+		return fmt.Errorf("--raw-path %q: %w", path, err)
+	}
+	out.WriteByte('\n')
+	_, _ = w.Write(out.Bytes())
+	return nil
 }
 
 // flattenState takes the state schema of each entry and flattens it into a
 // schema with parent and attributes. This is done so that we can have a common
-// schema for all the different types of resources.
-func flattenState(resources gjson.Result, short bool) bytes.Buffer {
-	var flatResources []map[string]interface{}
-
-	for _, resource := range resources.Array() {
-		common := getCommonFields(resource)
-
-		instances := resource.Get("instances")
-		for _, instance := range instances.Array() {
-			flatResource := make(map[string]interface{})
-			for key, value := range common {
-				flatResource[key] = value
+// schema for all the different types of resources. The components of each
+// resource address are joined with sep, which defaults to ".".
+//
+// Rows are written to the buffer one instance at a time straight from the raw
+// JSON of the state, so no intermediate row maps are built and large integers
+// keep their exact text. An instance key wins over a resource key of the same
+// name, and the computed "resource" address wins over both.
+func flattenState(resources gjson.Result, short bool, sep string) bytes.Buffer {
+	// The rows are about the size of the resources plus the resource fields
+	// repeated per instance and the addresses, so a quarter more usually
+	// avoids regrowing the buffer.
+	var raw bytes.Buffer
+	raw.Grow(len(resources.Raw) * 5 / 4)
+	raw.WriteByte('[')
+
+	first := true
+	forEachElement(resources, func(resource gjson.Result) {
+		forEachElement(resource.Get("instances"), func(instance gjson.Result) {
+			if !first {
+				raw.WriteByte(',')
 			}
+			first = false
 
-			for key, value := range instance.Map() {
-				flatResource[key] = value.Value()
-			}
+			raw.WriteByte('{')
+			resource.ForEach(func(key, value gjson.Result) bool {
+				if key.Str == "instances" || key.Str == "resource" || instance.Get(key.Str).Exists() {
+					return true
+				}
+				writeMember(&raw, key.Raw, value.Raw)
+				return true
+			})
+			instance.ForEach(func(key, value gjson.Result) bool {
+				if key.Str != "resource" {
+					writeMember(&raw, key.Raw, value.Raw)
+				}
+				return true
+			})
 
-			module := ""
-			if flatResource["module"] != nil {
-				module = InterfaceToString(flatResource["module"]) + "."
-			}
+			address, _ := json.Marshal(stateAddress(resource, instance, short, sep))
+			writeMember(&raw, `"resource"`, string(address))
+			raw.WriteByte('}')
+		})
+	})
 
-			mode := ""
-			if flatResource["mode"] != "managed" {
-				mode = InterfaceToString(flatResource["mode"]) + "."
-			}
+	raw.WriteByte(']')
+	return raw
+}
 
-			indexKey := ""
-			if flatResource["index_key"] != nil {
-				switch v := flatResource["index_key"].(type) {
-				case int, int64, float64:
-					indexKey = fmt.Sprintf("[%v]", v)
-				default:
-					indexKey = fmt.Sprintf("[\"%v\"]", v)
-				}
-			}
+// FlattenState returns the resources of the state document doc as the rows
+// sq lists, one per resource instance, each with its full address under
+// "resource".
+func FlattenState(doc []byte) []byte {
+	flat := flattenState(gjson.GetBytes(doc, "resources"), true, ".")
+	return flat.Bytes()
+}
 
-			resourceID := fmt.Sprintf("%s%s%s.%s%s", module, mode, flatResource["type"], flatResource["name"], indexKey)
-			if !short {
-				re := regexp.MustCompile(`(^module.)|(.module.)`)
-				resourceID = re.ReplaceAllString(resourceID, "+")
-			}
-			flatResource["resource"] = resourceID
+// forEachElement calls fn for each element of an array, or once for any other
+// existing value, mirroring gjson's Array.
+func forEachElement(r gjson.Result, fn func(gjson.Result)) {
+	if !r.IsArray() {
+		if r.Exists() {
+			fn(r)
+		}
+		return
+	}
+	r.ForEach(func(_, value gjson.Result) bool {
+		fn(value)
+		return true
+	})
+}
+
+// writeMember appends a "key":value member to the object being written to
+// raw, preceded by a comma unless it is the first member.
+func writeMember(raw *bytes.Buffer, key string, value string) {
+	if b := raw.Bytes(); b[len(b)-1] != '{' {
+		raw.WriteByte(',')
+	}
+	raw.WriteString(key)
+	raw.WriteByte(':')
+	raw.WriteString(value)
+}
 
-			flatResources = append(flatResources, flatResource)
+// stateAddress returns the address of a resource instance, e.g.
+// module.net.aws_subnet.a[0], formatted the way Terraform prints it so it can
+// be pasted into terraform state commands. Each component is looked up on the
+// instance first and then on the resource. Unless short is set, each
+// "module." is collapsed to "+", e.g. +net.aws_subnet.a[0].
+func stateAddress(resource, instance gjson.Result, short bool, sep string) string {
+	lookup := func(key string) interface{} {
+		if v := instance.Get(key); v.Exists() {
+			return v.Value()
 		}
+		return resource.Get(key).Value()
 	}
 
-	jsonBytes, err := json.Marshal(flatResources)
-	if err != nil {
-		log.Errorf("flattenState marshal: %v", err)
-		return *bytes.NewBuffer([]byte{})
+	var parts []string
+	if m := lookup("module"); m != nil {
+		parts = splitAddress(InterfaceToString(m))
+	}
+	if m := lookup("mode"); m != "managed" {
+		parts = append(parts, InterfaceToString(m))
 	}
+	name := fmt.Sprintf("%v", lookup("name"))
+	if k := lookup("index_key"); k != nil {
+		name += formatIndexKey(k)
+	}
+	parts = append(parts, fmt.Sprintf("%v", lookup("type")), name)
 
-	raw := *bytes.NewBuffer(jsonBytes)
-	return raw
+	return joinAddress(parts, short, sep)
 }
 
-// getColors returns configured color values for table rendering.
-func getColors(key string) (header string, even string, odd string) {
-	header, _ = config.GetString(fmt.Sprintf("%s.title", key), "#f6be00")
-	even, _ = config.GetString(fmt.Sprintf("%s.even", key), "#ffffff")
-	odd, _ = config.GetString(fmt.Sprintf("%s.odd", key), "#00c8f0")
-	return
+// formatIndexKey renders an instance key as Terraform does: a count index
+// bare, e.g. [0], and a for_each key as an HCL quoted string, e.g. ["a.b"].
+func formatIndexKey(key interface{}) string {
+	switch v := key.(type) {
+	case float64:
+		return fmt.Sprintf("[%d]", int64(v))
+	case int, int64:
+		return fmt.Sprintf("[%d]", v)
+	case string:
+		return "[" + hclQuote(v) + "]"
+	default:
+		return "[" + hclQuote(fmt.Sprintf("%v", v)) + "]"
+	}
+}
+
+// hclQuote returns s as an HCL quoted string, escaping quotes, backslashes,
+// control characters and template introducers the way Terraform's own
+// addresses do.
+func hclQuote(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for i, r := range s {
+		switch r {
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '$', '%':
+			b.WriteRune(r)
+			// ${ and %{ would start a template, so the introducer is doubled.
+			if strings.HasPrefix(s[i+1:], "{") {
+				b.WriteRune(r)
+			}
+		default:
+			switch {
+			case unicode.IsPrint(r):
+				b.WriteRune(r)
+			case r < 0x10000:
+				fmt.Fprintf(&b, `\u%04x`, r)
+			default:
+				fmt.Fprintf(&b, `\U%08x`, r)
+			}
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// splitAddress splits addr into its "."-separated components. Dots, brackets
+// and escaped quotes inside the quoted keys of index brackets, such as
+// ["a.b"], are left alone.
+func splitAddress(addr string) []string {
+	var parts []string
+	start, depth, quoted, escaped := 0, 0, false, false
+	for i, r := range addr {
+		switch {
+		case escaped:
+			escaped = false
+		case quoted && r == '\\':
+			escaped = true
+		case r == '"' && depth > 0:
+			quoted = !quoted
+		case quoted:
+		case r == '[':
+			depth++
+		case r == ']' && depth > 0:
+			depth--
+		case r == '.' && depth == 0:
+			parts = append(parts, addr[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, addr[start:])
 }
 
-// getCommonFields extracts common fields from a resource, excluding instances.
-func getCommonFields(resource gjson.Result) map[string]interface{} {
-	var common = make(map[string]interface{})
-	for key, value := range resource.Map() {
-		if key != "instances" {
-			common[key] = value.Value()
+// joinAddress joins the components of an address with sep, "." by default.
+// Unless short is set, each "module" keyword and the separator after it are
+// collapsed to "+".
+func joinAddress(parts []string, short bool, sep string) string {
+	if sep == "" {
+		sep = "."
+	}
+
+	var b strings.Builder
+	moduleName := false
+	for i, p := range parts {
+		// The last two components are always the resource type and name.
+		if !short && !moduleName && p == "module" && i < len(parts)-2 {
+			b.WriteByte('+')
+			moduleName = true
+			continue
+		}
+		if i > 0 && !moduleName {
+			b.WriteString(sep)
 		}
+		b.WriteString(p)
+		moduleName = false
 	}
-	return common
+	return b.String()
+}
+
+// colorEnabled reports whether table, json and yaml output to w should be
+// colored. A non-empty NO_COLOR always wins (https://no-color.org), and the
+// file --out names is never colored, since it's meant to be read by programs.
+// Otherwise --color decides: always colors, never does not, and auto colors
+// only when w is a terminal.
+func colorEnabled(cmd *cli.Command, w io.Writer) bool {
+	if os.Getenv("NO_COLOR") != "" || cmd.String("out") != "" {
+		return false
+	}
+
+	mode, _ := cmd.Value("color").(string)
+	switch mode {
+	case "always":
+		return true
+	case "auto":
+		f, ok := w.(*os.File)
+		return ok && term.IsTerminal(int(f.Fd()))
+	default:
+		return false
+	}
+}
+
+// getColors returns the color values for table rendering. They start from the
+// named theme preset, falling back to the default theme if it is unknown, and
+// each configured key.title, key.even and key.odd overrides its preset color.
+func getColors(key string, themeName string) (header string, even string, odd string) {
+	theme, err := LookupTheme(themeName)
+	if err != nil {
+		log.Warn(err.Error())
+		theme = Themes[DefaultTheme]
+	}
+
+	header, _ = config.GetString(fmt.Sprintf("%s.title", key), theme.Title)
+	even, _ = config.GetString(fmt.Sprintf("%s.even", key), theme.Even)
+	odd, _ = config.GetString(fmt.Sprintf("%s.odd", key), theme.Odd)
+	return
 }
@@ -0,0 +1,142 @@
+// Copyright (c) 2026 Steve Taranto <staranto@gmail.com>.
+// SPDX-License-Identifier: Apache-2.0
+
+package output
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math"
+	"os"
+	"strings"
+
+	// The pure-Go driver keeps tfctl free of cgo.
+	_ "modernc.org/sqlite"
+
+	"github.com/staranto/tfctl/internal/attrs"
+)
+
+// sqliteWriter writes the result set to the SQLite database at path, in one
+// table named for what the command's rows are (e.g. workspaces), whose columns
+// are the included attributes in column order. Values keep their type: strings
+// are TEXT, numbers INTEGER or REAL, booleans 0 or 1, and composite values JSON
+// TEXT. Missing values are NULL.
+//
+// The first write to path in this process replaces any existing file. Later
+// writes, once per workspace of --all-workspaces or per batch line, add their
+// rows to the same database.
+func sqliteWriter(resultSet []map[string]interface{}, attrs attrs.AttrList, command string, path string) error {
+	table := rowSubject(command)
+
+	var keys []string
+	for _, attr := range attrs {
+		if attr.Include {
+			keys = append(keys, attr.OutputKey)
+		}
+	}
+	if len(keys) == 0 {
+		return fmt.Errorf("no attributes to write as sqlite columns")
+	}
+
+	if claimOutFile(path) {
+		if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+
+	return sqliteFill(path, table, keys, resultSet)
+}
+
+// sqliteFill creates table in the database at path, unless it exists, and
+// inserts a row for each row of the result set, in a single transaction.
+func sqliteFill(path string, table string, keys []string, resultSet []map[string]interface{}) error {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.Exec(sqliteCreateTable(table, keys)); err != nil {
+		return err
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(keys)), ", ")
+	insert, err := tx.Prepare(fmt.Sprintf("INSERT INTO %s VALUES (%s)", sqliteQuote(table), placeholders))
+	if err != nil {
+		return err
+	}
+	defer insert.Close()
+
+	values := make([]any, len(keys))
+	for _, row := range resultSet {
+		for j, key := range keys {
+			values[j] = sqliteValue(row[key])
+		}
+		if _, err := insert.Exec(values...); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	return db.Close()
+}
+
+// sqliteCreateTable returns the CREATE TABLE IF NOT EXISTS statement for
+// table. The columns have no declared type, so each value keeps the type it
+// was stored with. A repeated column name is suffixed, as SQLite rejects
+// duplicates.
+func sqliteCreateTable(table string, columns []string) string {
+	seen := map[string]int{}
+	quoted := make([]string, len(columns))
+	for i, column := range columns {
+		name := column
+		if n := seen[strings.ToLower(column)]; n > 0 {
+			name = fmt.Sprintf("%s_%d", column, n+1)
+		}
+		seen[strings.ToLower(column)]++
+		quoted[i] = sqliteQuote(name)
+	}
+	return fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (%s)", sqliteQuote(table), strings.Join(quoted, ", "))
+}
+
+// sqliteQuote quotes an identifier.
+func sqliteQuote(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// sqliteValue converts a row value to the value stored for it.
+func sqliteValue(value any) any {
+	switch v := value.(type) {
+	case nil, string, int, int64:
+		return v
+	case bool:
+		if v {
+			return int64(1)
+		}
+		return int64(0)
+	case float64:
+		// JSON numbers arrive as floats. Whole numbers are stored as integers so
+		// they compare and print as the API returned them.
+		if v == math.Trunc(v) && math.Abs(v) < 1<<53 {
+			return int64(v)
+		}
+		return v
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprintf("%v", v)
+		}
+		return string(data)
+	}
+}
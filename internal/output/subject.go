@@ -0,0 +1,33 @@
+// Copyright (c) 2026 Steve Taranto <staranto@gmail.com>.
+// SPDX-License-Identifier: Apache-2.0
+
+package output
+
+// rowSubjects names what each command's rows are, such as the resources of
+// sq. The prometheus metric names and the sqlite table names are built from
+// them.
+var rowSubjects = map[string]string{
+	"apq": "agent_pools",
+	"cvq": "configuration_versions",
+	"mq":  "modules",
+	"ncq": "notification_configurations",
+	"ocq": "oauth_clients",
+	"oq":  "organizations",
+	"pq":  "projects",
+	"ps":  "resource_changes",
+	"rq":  "runs",
+	"rtq": "task_results",
+	"soq": "state_outputs",
+	"sq":  "resources",
+	"svq": "state_versions",
+	"wq":  "workspaces",
+}
+
+// rowSubject returns what the rows of command are, or "rows" for a command
+// not in rowSubjects.
+func rowSubject(command string) string {
+	if subject, ok := rowSubjects[command]; ok {
+		return subject
+	}
+	return "rows"
+}
@@ -0,0 +1,52 @@
+// Copyright (c) 2026 Steve Taranto <staranto@gmail.com>.
+// SPDX-License-Identifier: Apache-2.0
+
+package output
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// DefaultTheme is the table color theme used when none is selected.
+const DefaultTheme = "default"
+
+// Theme is a named set of table colors.
+type Theme struct {
+	Title string
+	Even  string
+	Odd   string
+}
+
+// Themes are the table color presets selectable with --theme or the theme
+// config key.
+var Themes = map[string]Theme{
+	DefaultTheme:   {Title: "#f6be00", Even: "#ffffff", Odd: "#00c8f0"},
+	"highcontrast": {Title: "#ffff00", Even: "#ffffff", Odd: "#00ffff"},
+	"mono":         {Title: "#ffffff", Even: "#d0d0d0", Odd: "#8a8a8a"},
+	"solarized":    {Title: "#b58900", Even: "#93a1a1", Odd: "#2aa198"},
+}
+
+// ThemeNames returns the names of the theme presets, sorted.
+func ThemeNames() []string {
+	names := make([]string, 0, len(Themes))
+	for name := range Themes {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// LookupTheme returns the theme preset called name. An empty name is the
+// default theme.
+func LookupTheme(name string) (Theme, error) {
+	if name == "" {
+		name = DefaultTheme
+	}
+	theme, ok := Themes[name]
+	if !ok {
+		return Theme{}, fmt.Errorf("unknown theme %q, must be one of %s", name, strings.Join(ThemeNames(), ", "))
+	}
+	return theme, nil
+}
@@ -4,6 +4,7 @@
 package state
 
 import (
+	"bytes"
 	"context"
 	"crypto/aes"
 	"crypto/cipher"
@@ -11,8 +12,14 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"os/exec"
 	"os/signal"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
 	"syscall"
 
 	"golang.org/x/crypto/pbkdf2"
@@ -21,7 +28,9 @@ import (
 	"github.com/apex/log"
 	"github.com/urfave/cli/v3"
 
+	"github.com/staranto/tfctl/internal/aws"
 	"github.com/staranto/tfctl/internal/backend"
+	"github.com/staranto/tfctl/internal/util"
 )
 
 // DecryptOpenTofuState decrypts an encrypted OpenTofu state file using the
@@ -74,6 +83,212 @@ func DecryptOpenTofuState(stateData []byte, passphrase string) ([]byte, error) {
 	return decryptState(state.EncryptedData, key)
 }
 
+// kmsKeyProviderPrefix prefixes the meta key OpenTofu writes for an aws_kms
+// key provider, e.g. key_provider.aws_kms.mykey.
+const kmsKeyProviderPrefix = "key_provider.aws_kms."
+
+// kmsDecrypt unwraps a data key with AWS KMS using the ambient AWS config.
+func kmsDecrypt(ctx context.Context, blob []byte) ([]byte, error) {
+	cfg, err := aws.LoadAWSConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+	return aws.KMSDecrypt(ctx, cfg, blob)
+}
+
+// UsesKMSKeyProvider reports whether an encrypted OpenTofu state file was
+// encrypted with a key from the aws_kms key provider, in which case no
+// passphrase is needed to decrypt it.
+func UsesKMSKeyProvider(stateData []byte) bool {
+	_, ok := kmsKeyMeta(stateData)
+	return ok
+}
+
+// kmsKeyMeta returns the aws_kms key provider meta of an encrypted OpenTofu
+// state file. If several are present, the first by name is used.
+func kmsKeyMeta(stateData []byte) (string, bool) {
+	var state struct {
+		Meta map[string]string `json:"meta"`
+	}
+	if err := json.Unmarshal(stateData, &state); err != nil {
+		return "", false
+	}
+
+	var names []string
+	for name := range state.Meta {
+		if strings.HasPrefix(name, kmsKeyProviderPrefix) {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return "", false
+	}
+	sort.Strings(names)
+	return state.Meta[names[0]], true
+}
+
+// DecryptOpenTofuKMSState decrypts an encrypted OpenTofu state file whose key
+// comes from the aws_kms key provider. The data key stored wrapped in the meta
+// is unwrapped with kms:Decrypt using the ambient AWS credential chain.
+func DecryptOpenTofuKMSState(ctx context.Context, stateData []byte) ([]byte, error) {
+	return decryptOpenTofuKMSState(ctx, stateData, kmsDecrypt)
+}
+
+// decryptOpenTofuKMSState decrypts an aws_kms keyed state file, unwrapping
+// the data key with unwrap.
+func decryptOpenTofuKMSState(
+	ctx context.Context,
+	stateData []byte,
+	unwrap func(context.Context, []byte) ([]byte, error),
+) ([]byte, error) {
+	meta, ok := kmsKeyMeta(stateData)
+	if !ok {
+		return nil, fmt.Errorf("state has no aws_kms key provider")
+	}
+
+	var state struct {
+		EncryptedData string `json:"encrypted_data"`
+	}
+	if err := json.Unmarshal(stateData, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse state: %w", err)
+	}
+
+	keyProviderConfig, err := base64.StdEncoding.DecodeString(meta)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode key provider config: %w", err)
+	}
+
+	// The ciphertext blob is base64 in the JSON, which []byte decodes.
+	var kpConfig struct {
+		CiphertextBlob []byte `json:"ciphertext_blob"`
+	}
+	if err = json.Unmarshal(keyProviderConfig, &kpConfig); err != nil {
+		return nil, fmt.Errorf("failed to parse key provider config: %w", err)
+	}
+	if len(kpConfig.CiphertextBlob) == 0 {
+		return nil, fmt.Errorf("key provider config has no ciphertext_blob")
+	}
+
+	key, err := unwrap(ctx, kpConfig.CiphertextBlob)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap data key: %w", err)
+	}
+
+	return decryptState(state.EncryptedData, key)
+}
+
+// DecryptWithCommand pipes stateData through an external program and returns
+// what it writes to stdout. command is run by the system shell, so it may
+// include arguments and pipelines. It is an escape hatch for encryption
+// schemes tfctl does not support natively.
+func DecryptWithCommand(ctx context.Context, stateData []byte, command string) ([]byte, error) {
+	name, args := "sh", []string{"-c", command}
+	if runtime.GOOS == "windows" {
+		name, args = "cmd", []string{"/C", command}
+	}
+
+	return runDecryptor(exec.CommandContext(ctx, name, args...), stateData, fmt.Sprintf("decrypt command %q", command))
+}
+
+// sopsBinary is the sops executable used to decrypt SOPS-encrypted state.
+const sopsBinary = "sops"
+
+// IsSOPSEncrypted reports whether stateData is a JSON document encrypted by
+// SOPS, which is recognized by the sops metadata SOPS adds at the top level.
+func IsSOPSEncrypted(stateData []byte) bool {
+	var doc struct {
+		Sops *struct {
+			MAC     string `json:"mac"`
+			Version string `json:"version"`
+		} `json:"sops"`
+	}
+	if err := json.Unmarshal(stateData, &doc); err != nil || doc.Sops == nil {
+		return false
+	}
+	return doc.Sops.MAC != "" || doc.Sops.Version != ""
+}
+
+// DecryptSOPSState decrypts a SOPS-encrypted state document with the sops
+// binary, which picks up the ambient KMS, age or PGP configuration exactly as
+// it does on the command line.
+func DecryptSOPSState(ctx context.Context, stateData []byte) ([]byte, error) {
+	return decryptSOPSState(ctx, stateData, sopsBinary)
+}
+
+// decryptSOPSState decrypts a SOPS-encrypted state document with the sops
+// executable binary, looked up in PATH. The sops Go library would bring in
+// the SDK of every KMS it supports, so tfctl runs the sops the user already
+// has configured instead.
+//
+// sops decrypts a file, and /dev/stdin doesn't exist on Windows, so the
+// document is handed over in a temporary file. That's no worse than the
+// document itself, whose values are all encrypted.
+func decryptSOPSState(ctx context.Context, stateData []byte, binary string) ([]byte, error) {
+	path, err := exec.LookPath(binary)
+	if err != nil {
+		return nil, fmt.Errorf("state is SOPS-encrypted but %s was not found: %w", binary, err)
+	}
+
+	f, err := os.CreateTemp("", "tfctl-sops-*.json")
+	if err != nil {
+		return nil, fmt.Errorf("sops: %w", err)
+	}
+	defer os.Remove(f.Name()) //nolint:errcheck
+	if _, err := f.Write(stateData); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("sops: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return nil, fmt.Errorf("sops: %w", err)
+	}
+
+	c := exec.CommandContext(ctx, path, "--decrypt", "--input-type", "json", "--output-type", "json", f.Name())
+	return runDecryptor(c, nil, "sops")
+}
+
+// runDecryptor runs c with stateData on stdin and returns its stdout. A
+// failure is reported with label and whatever c wrote to stderr.
+func runDecryptor(c *exec.Cmd, stateData []byte, label string) ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+	c.Stdin = bytes.NewReader(stateData)
+	c.Stdout = &stdout
+	c.Stderr = &stderr
+
+	if err := c.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%s: %w: %s", label, err, msg)
+		}
+		return nil, fmt.Errorf("%s: %w", label, err)
+	}
+
+	return stdout.Bytes(), nil
+}
+
+// ReadPassphrase reads a passphrase from r, such as a file or stdin. A single
+// trailing newline, as left by echo or an editor, is trimmed.
+func ReadPassphrase(r io.Reader) (string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return "", fmt.Errorf("failed to read passphrase: %w", err)
+	}
+	passphrase := strings.TrimSuffix(string(data), "\n")
+	passphrase = strings.TrimSuffix(passphrase, "\r")
+	if passphrase == "" {
+		return "", fmt.Errorf("passphrase is empty")
+	}
+	return passphrase, nil
+}
+
+// ReadPassphraseFile reads a passphrase from the file at path.
+func ReadPassphraseFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open passphrase file: %w", err)
+	}
+	defer f.Close() //nolint:errcheck
+	return ReadPassphrase(f)
+}
+
 // GetPassphrase prompts interactively for a passphrase without echoing input.
 func GetPassphrase() (string, error) {
 	var password []byte
@@ -119,11 +334,16 @@ loop:
 	return string(password), nil
 }
 
-// LoadStateData loads and optionally decrypts a state document from the
-// detected backend at the provided rootDir.
-func LoadStateData(ctx context.Context, cmd *cli.Command, rootDir string) (map[string]interface{}, error) {
+// LoadStateData loads a state document from the detected backend at the
+// provided rootDir and decrypts it as needed, see Decrypt.
+func LoadStateData(
+	ctx context.Context,
+	cmd *cli.Command,
+	rootDir string,
+	passphrase func() (string, error),
+) (map[string]interface{}, error) {
 	// Check to make sure the target directory looks like it might be a legit TF workspace.
-	tfConfigFile := fmt.Sprintf("%s/.terraform/terraform.tfstate", rootDir)
+	tfConfigFile := filepath.Join(util.DataDir(rootDir), "terraform.tfstate")
 	if _, err := os.Stat(tfConfigFile); err != nil {
 		return nil, fmt.Errorf("terraform config file not found: %s", tfConfigFile)
 	}
@@ -142,28 +362,9 @@ func LoadStateData(ctx context.Context, cmd *cli.Command, rootDir string) (map[s
 		return nil, err
 	}
 
-	// If the state is encrypted, there's a little more work to do.
-	var jsonData map[string]interface{}
-	if err := json.Unmarshal(doc, &jsonData); err == nil {
-		if _, exists := jsonData["encrypted_data"]; exists {
-			// First, look to the flag for passphrase value.
-			passphrase := cmd.String("passphrase")
-
-			// Issue 14 - Next look in env TF_VAR_passphrase and use it if found.
-			if passphrase == "" {
-				passphrase = os.Getenv("TF_VAR_passphrase")
-			}
-
-			// Finally, prompt for passphrase
-			if passphrase == "" {
-				passphrase, _ = GetPassphrase()
-			}
-
-			doc, err = DecryptOpenTofuState(doc, passphrase)
-			if err != nil {
-				return nil, fmt.Errorf("failed to decrypt: %w", err)
-			}
-		}
+	doc, err = Decrypt(ctx, doc, DecryptOptions{Command: cmd.String("decrypt-cmd")}, passphrase)
+	if err != nil {
+		return nil, err
 	}
 
 	// Parse the state data as JSON
@@ -175,6 +376,66 @@ func LoadStateData(ctx context.Context, cmd *cli.Command, rootDir string) (map[s
 	return stateData, nil
 }
 
+// DecryptOptions configures Decrypt.
+type DecryptOptions struct {
+	// Command is the external program, as given by --decrypt-cmd, that the raw
+	// state is piped through first. None is run when it is empty.
+	Command string
+}
+
+// Decrypt returns doc decrypted as needed: by opts.Command, then SOPS, then
+// OpenTofu state encryption with a KMS key or the passphrase that passphrase
+// returns. passphrase is only called for state encrypted with a passphrase.
+func Decrypt(
+	ctx context.Context,
+	doc []byte,
+	opts DecryptOptions,
+	passphrase func() (string, error),
+) ([]byte, error) {
+	var err error
+
+	// An external decryptor, if given, sees the raw state bytes first.
+	if opts.Command != "" {
+		doc, err = DecryptWithCommand(ctx, doc, opts.Command)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// SOPS-encrypted state is unwrapped before the OpenTofu passphrase check.
+	if IsSOPSEncrypted(doc) {
+		doc, err = DecryptSOPSState(ctx, doc)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// If the state is encrypted, there's a little more work to do.
+	var jsonData map[string]interface{}
+	if err := json.Unmarshal(doc, &jsonData); err != nil {
+		return doc, nil
+	}
+	if _, exists := jsonData["encrypted_data"]; !exists {
+		return doc, nil
+	}
+
+	if UsesKMSKeyProvider(doc) {
+		// A KMS-wrapped key needs AWS credentials, not a passphrase.
+		doc, err = DecryptOpenTofuKMSState(ctx, doc)
+	} else {
+		var p string
+		p, err = passphrase()
+		if err != nil {
+			return nil, err
+		}
+		doc, err = DecryptOpenTofuState(doc, p)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt: %w", err)
+	}
+	return doc, nil
+}
+
 func decryptState(encryptedData string, derivedKey []byte) ([]byte, error) {
 	// Decode base64 data
 	ciphertext, err := base64.StdEncoding.DecodeString(encryptedData)
@@ -5,11 +5,17 @@ package state
 
 import (
 	"bytes"
+	"context"
 	"crypto/aes"
 	"crypto/cipher"
 	"crypto/sha512"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -431,3 +437,252 @@ func TestDecryptState_ShortCiphertext(t *testing.T) {
 	assert.Nil(t, result)
 	assert.Contains(t, err.Error(), "ciphertext too short")
 }
+
+// TestDecryptWithCommand_Passthrough verifies the state bytes are piped to the
+// external command's stdin and its stdout is returned unchanged.
+func TestDecryptWithCommand_Passthrough(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires a POSIX shell")
+	}
+	t.Parallel()
+
+	doc := []byte(`{"version":4,"serial":7}`)
+	result, err := DecryptWithCommand(context.Background(), doc, "cat")
+
+	require.NoError(t, err)
+	assert.Equal(t, doc, result)
+}
+
+// TestDecryptWithCommand_Transform verifies a command that rewrites the state,
+// standing in for a bespoke decryptor, with arguments parsed by the shell.
+func TestDecryptWithCommand_Transform(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires a POSIX shell")
+	}
+	t.Parallel()
+
+	ciphertext := []byte(base64.StdEncoding.EncodeToString([]byte(`{"version":4}`)))
+	result, err := DecryptWithCommand(context.Background(), ciphertext, "base64 -d | tr 4 5")
+
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"version":5}`, string(result))
+}
+
+// TestDecryptWithCommand_Failure verifies a non-zero exit is reported along
+// with the command's stderr.
+func TestDecryptWithCommand_Failure(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires a POSIX shell")
+	}
+	t.Parallel()
+
+	_, err := DecryptWithCommand(context.Background(), []byte("{}"), "echo bad key >&2; exit 3")
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "exit status 3")
+	assert.Contains(t, err.Error(), "bad key")
+}
+
+// TestIsSOPSEncrypted verifies SOPS documents are recognized by their sops
+// metadata and everything else, including OpenTofu encrypted state, is not.
+func TestIsSOPSEncrypted(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		doc  string
+		want bool
+	}{
+		{"sops", `{"version":"ENC[AES256_GCM,data:NA==,type:float]","sops":{"mac":"ENC[...]","version":"3.9.1","age":[{"recipient":"age1x"}]}}`, true},
+		{"sops version only", `{"sops":{"version":"3.9.1"}}`, true},
+		{"plain state", `{"version":4,"serial":7,"resources":[]}`, false},
+		{"opentofu encrypted", `{"encrypted_data":"abc","meta":{}}`, false},
+		{"unrelated sops key", `{"sops":{"enabled":true}}`, false},
+		{"sops not an object", `{"sops":"yes"}`, false},
+		{"not json", `not json`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, IsSOPSEncrypted([]byte(tt.doc)))
+		})
+	}
+}
+
+// fakeSops writes a sops stand-in that runs script and returns its path.
+func fakeSops(t *testing.T, script string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("requires a POSIX shell")
+	}
+
+	path := filepath.Join(t.TempDir(), "sops")
+	require.NoError(t, os.WriteFile(path, []byte("#!/bin/sh\n"+script+"\n"), 0o700)) //nolint:gosec
+	return path
+}
+
+// TestDecryptSOPSState verifies the state is handed to sops in a file, with
+// the arguments for a JSON document, its stdout is returned and the file is
+// removed.
+func TestDecryptSOPSState(t *testing.T) {
+	seen := filepath.Join(t.TempDir(), "seen")
+	sops := fakeSops(t, `[ "$1 $2 $3 $4 $5" = "--decrypt --input-type json --output-type json" ] || exit 9
+echo "$6" > `+seen+`
+grep -q '"sops"' "$6" && echo '{"version":4,"serial":7}'`)
+
+	result, err := decryptSOPSState(context.Background(), []byte(`{"sops":{"version":"3.9.1"}}`), sops)
+
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"version":4,"serial":7}`, string(result))
+
+	input, err := os.ReadFile(seen)
+	require.NoError(t, err)
+	assert.NoFileExists(t, strings.TrimSpace(string(input)))
+}
+
+// TestDecryptSOPSState_Failure verifies a failed decryption is reported with
+// the stderr of sops.
+func TestDecryptSOPSState_Failure(t *testing.T) {
+	sops := fakeSops(t, `echo "Failed to get the data key required to decrypt the SOPS file." >&2; exit 128`)
+
+	_, err := decryptSOPSState(context.Background(), []byte(`{"sops":{"version":"3.9.1"}}`), sops)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "exit status 128")
+	assert.Contains(t, err.Error(), "Failed to get the data key")
+}
+
+// TestDecryptSOPSState_NotInstalled verifies a clear error when sops is
+// missing from PATH.
+func TestDecryptSOPSState_NotInstalled(t *testing.T) {
+	_, err := decryptSOPSState(context.Background(), []byte(`{"sops":{"version":"3.9.1"}}`), "tfctl-no-such-sops")
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "state is SOPS-encrypted but tfctl-no-such-sops was not found")
+}
+
+// createKMSEncryptedStateFile is a helper that creates an OpenTofu state file
+// encrypted with dataKey, which the aws_kms key provider stores wrapped as
+// blob.
+func createKMSEncryptedStateFile(t *testing.T, plaintext, dataKey, blob []byte) []byte {
+	t.Helper()
+
+	block, err := aes.NewCipher(dataKey)
+	require.NoError(t, err)
+	aesGCM, err := cipher.NewGCM(block)
+	require.NoError(t, err)
+	nonce := make([]byte, aesGCM.NonceSize())
+	ciphertext := aesGCM.Seal(nonce, nonce, plaintext, nil)
+
+	kpConfigJSON, err := json.Marshal(map[string][]byte{"ciphertext_blob": blob})
+	require.NoError(t, err)
+
+	stateJSON, err := json.Marshal(map[string]interface{}{
+		"meta": map[string]interface{}{
+			"key_provider.aws_kms.prod": base64.StdEncoding.EncodeToString(kpConfigJSON),
+		},
+		"encrypted_data":     base64.StdEncoding.EncodeToString(ciphertext),
+		"encryption_version": "v0",
+	})
+	require.NoError(t, err)
+
+	return stateJSON
+}
+
+// TestUsesKMSKeyProvider verifies only aws_kms key provider meta is
+// recognized.
+func TestUsesKMSKeyProvider(t *testing.T) {
+	kms := createKMSEncryptedStateFile(t, []byte(`{}`), bytes.Repeat([]byte{1}, 32), []byte("blob"))
+
+	assert.True(t, UsesKMSKeyProvider(kms))
+	assert.False(t, UsesKMSKeyProvider(createEncryptedStateFile(t, []byte(`{}`), "pw")))
+	assert.False(t, UsesKMSKeyProvider([]byte(`{"version":4}`)))
+	assert.False(t, UsesKMSKeyProvider([]byte(`not json`)))
+}
+
+// TestDecryptOpenTofuKMSState verifies the wrapped data key is unwrapped with
+// KMS and used to decrypt the state.
+func TestDecryptOpenTofuKMSState(t *testing.T) {
+	dataKey := bytes.Repeat([]byte{7}, 32)
+	plaintext := []byte(`{"version":4,"terraform_version":"1.8.0"}`)
+	stateData := createKMSEncryptedStateFile(t, plaintext, dataKey, []byte("wrapped-by-kms"))
+
+	var blobs [][]byte
+	result, err := decryptOpenTofuKMSState(context.Background(), stateData, func(_ context.Context, blob []byte) ([]byte, error) {
+		blobs = append(blobs, blob)
+		return dataKey, nil
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, plaintext, result)
+	assert.Equal(t, [][]byte{[]byte("wrapped-by-kms")}, blobs)
+}
+
+// TestDecryptOpenTofuKMSState_Errors verifies KMS failures, a wrong data key
+// and malformed key provider meta are reported.
+func TestDecryptOpenTofuKMSState_Errors(t *testing.T) {
+	dataKey := bytes.Repeat([]byte{7}, 32)
+	stateData := createKMSEncryptedStateFile(t, []byte(`{}`), dataKey, []byte("blob"))
+
+	denied := func(context.Context, []byte) ([]byte, error) {
+		return nil, errors.New("kms decrypt: AccessDeniedException: not authorized")
+	}
+	_, err := decryptOpenTofuKMSState(context.Background(), stateData, denied)
+	assert.ErrorContains(t, err, "failed to unwrap data key: kms decrypt: AccessDeniedException")
+
+	_, err = decryptOpenTofuKMSState(context.Background(), stateData, func(context.Context, []byte) ([]byte, error) {
+		return bytes.Repeat([]byte{8}, 32), nil
+	})
+	assert.ErrorContains(t, err, "failed to decrypt")
+
+	meta := func(value string) []byte {
+		return []byte(`{"meta":{"key_provider.aws_kms.k":"` + value + `"},"encrypted_data":"dGVzdA=="}`)
+	}
+	_, err = decryptOpenTofuKMSState(context.Background(), meta("not-base64!"), denied)
+	assert.ErrorContains(t, err, "failed to decode key provider config")
+	_, err = decryptOpenTofuKMSState(context.Background(), meta(base64.StdEncoding.EncodeToString([]byte(`{}`))), denied)
+	assert.ErrorContains(t, err, "no ciphertext_blob")
+	_, err = decryptOpenTofuKMSState(context.Background(), []byte(`{"encrypted_data":"dGVzdA=="}`), denied)
+	assert.ErrorContains(t, err, "no aws_kms key provider")
+}
+
+func TestReadPassphrase(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    string
+		wantErr string
+	}{
+		{name: "bare", in: "s3cret", want: "s3cret"},
+		{name: "trailing newline", in: "s3cret\n", want: "s3cret"},
+		{name: "trailing crlf", in: "s3cret\r\n", want: "s3cret"},
+		{name: "only one newline trimmed", in: "s3cret\n\n", want: "s3cret\n"},
+		{name: "inner spaces kept", in: " two words \n", want: " two words "},
+		{name: "empty", in: "", wantErr: "passphrase is empty"},
+		{name: "only newline", in: "\n", wantErr: "passphrase is empty"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ReadPassphrase(bytes.NewBufferString(tt.in))
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestReadPassphraseFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "passphrase")
+	require.NoError(t, os.WriteFile(path, []byte("from-file\n"), 0o600))
+
+	got, err := ReadPassphraseFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "from-file", got)
+
+	_, err = ReadPassphraseFile(filepath.Join(t.TempDir(), "missing"))
+	assert.ErrorContains(t, err, "failed to open passphrase file")
+}
@@ -0,0 +1,49 @@
+// Copyright (c) 2026 Steve Taranto <staranto@gmail.com>.
+// SPDX-License-Identifier: Apache-2.0
+
+package svutil
+
+import (
+	"golang.org/x/sync/errgroup"
+)
+
+// DefaultParallelism is the number of state bodies, or workspaces, fetched
+// concurrently when the parallelism config key is not set.
+const DefaultParallelism = 4
+
+// FetchAll calls fetch for each item, such as a state version, using at most
+// parallelism concurrent workers and returns the results in the same order as
+// items. If any fetch fails, the first error is returned once the in-flight
+// fetches finish.
+func FetchAll[T, R any](
+	items []T,
+	parallelism int,
+	fetch func(T) (R, error),
+) ([]R, error) {
+	if parallelism < 1 {
+		parallelism = 1
+	}
+
+	results := make([]R, len(items))
+
+	var g errgroup.Group
+	g.SetLimit(parallelism)
+
+	for i, item := range items {
+		g.Go(func() error {
+			result, err := fetch(item)
+			if err != nil {
+				return err
+			}
+			// Each worker owns a distinct index, so no locking is needed.
+			results[i] = result
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
@@ -0,0 +1,71 @@
+// Copyright (c) 2026 Steve Taranto <staranto@gmail.com>.
+// SPDX-License-Identifier: Apache-2.0
+// no-cloc
+
+package svutil
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-tfe"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestFetchAll_PreservesOrder verifies results come back in input order even
+// when later versions finish first.
+func TestFetchAll_PreservesOrder(t *testing.T) {
+	versions := makeStateVersions()
+
+	results, err := FetchAll(versions, 4, func(v *tfe.StateVersion) ([]byte, error) {
+		// Earlier versions sleep longer so completion order is reversed.
+		time.Sleep(time.Duration(110-v.Serial) * time.Millisecond)
+		return []byte(v.ID), nil
+	})
+	require.NoError(t, err)
+	require.Len(t, results, len(versions))
+	for i, v := range versions {
+		assert.Equal(t, v.ID, string(results[i]))
+	}
+}
+
+// TestFetchAll_RespectsLimit verifies no more than parallelism fetches run at
+// the same time.
+func TestFetchAll_RespectsLimit(t *testing.T) {
+	var versions []*tfe.StateVersion
+	for i := 0; i < 20; i++ {
+		versions = append(versions, &tfe.StateVersion{ID: "sv", Serial: int64(i)})
+	}
+
+	var inFlight, peak int32
+	_, err := FetchAll(versions, 3, func(_ *tfe.StateVersion) ([]byte, error) {
+		n := atomic.AddInt32(&inFlight, 1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		atomic.AddInt32(&inFlight, -1)
+		return nil, nil
+	})
+	require.NoError(t, err)
+	assert.LessOrEqual(t, peak, int32(3))
+}
+
+// TestFetchAll_Error verifies a failing fetch is reported.
+func TestFetchAll_Error(t *testing.T) {
+	boom := errors.New("boom")
+	results, err := FetchAll(makeStateVersions(), 0, func(v *tfe.StateVersion) ([]byte, error) {
+		if v.ID == "sv-002" {
+			return nil, boom
+		}
+		return []byte(v.ID), nil
+	})
+	assert.ErrorIs(t, err, boom)
+	assert.Nil(t, results)
+}
@@ -8,6 +8,7 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/hashicorp/go-tfe"
 )
@@ -25,6 +26,7 @@ func Resolve(versions []*tfe.StateVersion, specs ...string) ([]*tfe.StateVersion
 	//   sv-id  - the SV with that ID.
 	//   CSV~1  - the -1 SV.
 	//   serial - the specific serial number.
+	//   @time  - the SV active at that RFC3339 time.
 	//   url    - the SV URL to download.
 	//   file   - the SV file to read.
 
@@ -49,6 +51,7 @@ func Resolve(versions []*tfe.StateVersion, specs ...string) ([]*tfe.StateVersion
 // StateVersion. Specs can be:
 //   - CSV~N: relative index (negative means recent)
 //   - numeric serial: find SV with that serial number
+//   - @time: newest SV created at or before an RFC3339 time
 //   - file path: read from local file
 //   - ID prefix: find first SV matching that ID prefix
 func resolveSpec(spec string, versions []*tfe.StateVersion) (*tfe.StateVersion, error) {
@@ -59,6 +62,9 @@ func resolveSpec(spec string, versions []*tfe.StateVersion) (*tfe.StateVersion,
 	case isNumeric(spec):
 		return resolveNumericSpec(spec, versions)
 
+	case strings.HasPrefix(spec, "@"):
+		return resolveTimeSpec(spec, versions)
+
 	case isFilePath(spec):
 		return resolveFileSpec(spec)
 
@@ -109,6 +115,31 @@ func resolveNumericSpec(spec string, versions []*tfe.StateVersion) (*tfe.StateVe
 	return nil, fmt.Errorf("failed to find state version with serial %d", i)
 }
 
+// resolveTimeSpec handles @time specs, selecting the state version that was
+// current at that time, i.e. the newest one created at or before it.
+func resolveTimeSpec(spec string, versions []*tfe.StateVersion) (*tfe.StateVersion, error) {
+	at, err := time.Parse(time.RFC3339, strings.TrimPrefix(spec, "@"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid time spec %s: must be @ followed by an RFC3339 time", spec)
+	}
+
+	var found *tfe.StateVersion
+	for _, v := range versions {
+		if v.CreatedAt.IsZero() || v.CreatedAt.After(at) {
+			continue
+		}
+		if found == nil || v.CreatedAt.After(found.CreatedAt) {
+			found = v
+		}
+	}
+
+	if found == nil {
+		return nil, fmt.Errorf("failed to find state version at or before %s", at.Format(time.RFC3339))
+	}
+
+	return found, nil
+}
+
 // resolveFileSpec handles file path specs.
 func resolveFileSpec(spec string) (*tfe.StateVersion, error) {
 	return &tfe.StateVersion{
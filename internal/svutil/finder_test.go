@@ -8,6 +8,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/hashicorp/go-tfe"
 	"github.com/stretchr/testify/assert"
@@ -416,6 +417,89 @@ func TestResolveNumericSpec(t *testing.T) {
 	}
 }
 
+func TestResolveTimeSpec(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 12, 0, 0, 0, time.UTC) }
+
+	// Listings are newest first.
+	versions := []*tfe.StateVersion{
+		{ID: "sv-003", Serial: 3, CreatedAt: day(20)},
+		{ID: "sv-002", Serial: 2, CreatedAt: day(10)},
+		{ID: "sv-001", Serial: 1, CreatedAt: day(1)},
+		{ID: "sv-undated", Serial: 0},
+	}
+
+	tests := []struct {
+		name     string
+		spec     string
+		versions []*tfe.StateVersion
+		wantID   string
+		wantErr  bool
+		errMsg   string
+	}{
+		{
+			name:     "exact creation time",
+			spec:     "@2024-01-10T12:00:00Z",
+			versions: versions,
+			wantID:   "sv-002",
+		},
+		{
+			name:     "between versions picks the earlier",
+			spec:     "@2024-01-15T00:00:00Z",
+			versions: versions,
+			wantID:   "sv-002",
+		},
+		{
+			name:     "after the newest picks the newest",
+			spec:     "@2025-01-01T00:00:00Z",
+			versions: versions,
+			wantID:   "sv-003",
+		},
+		{
+			name:     "time zone offset",
+			spec:     "@2024-01-10T13:00:00+01:00",
+			versions: versions,
+			wantID:   "sv-002",
+		},
+		{
+			name:     "before the oldest",
+			spec:     "@2023-12-31T00:00:00Z",
+			versions: versions,
+			wantErr:  true,
+			errMsg:   "failed to find state version at or before 2023-12-31T00:00:00Z",
+		},
+		{
+			name:     "unordered listing",
+			spec:     "@2024-01-15T00:00:00Z",
+			versions: []*tfe.StateVersion{versions[2], versions[0], versions[1]},
+			wantID:   "sv-002",
+		},
+		{
+			name:     "invalid time",
+			spec:     "@2024-01-15",
+			versions: versions,
+			wantErr:  true,
+			errMsg:   "invalid time spec",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveTimeSpec(tt.spec, tt.versions)
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Nil(t, got)
+				if tt.errMsg != "" {
+					assert.Contains(t, err.Error(), tt.errMsg)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.NotNil(t, got)
+				assert.Equal(t, tt.wantID, got.ID)
+			}
+		})
+	}
+}
+
 func TestResolveFileSpec(t *testing.T) {
 	// Get absolute path to testdata directory
 	absStateFile, err := filepath.Abs(filepath.Join("testdata", "state.json"))
@@ -717,6 +801,13 @@ func TestResolveSpec(t *testing.T) {
 			wantID:   "sv-001",
 			wantErr:  false,
 		},
+		{
+			name:     "time spec dispatch",
+			spec:     "@2024-01-01T00:00:00Z",
+			versions: []*tfe.StateVersion{{ID: "sv-001", CreatedAt: time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)}},
+			wantID:   "sv-001",
+			wantErr:  false,
+		},
 		{
 			name:     "invalid CSV spec",
 			spec:     "CSV~invalid",
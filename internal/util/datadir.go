@@ -0,0 +1,44 @@
+// Copyright (c) 2026 Steve Taranto <staranto@gmail.com>.
+// SPDX-License-Identifier: Apache-2.0
+
+package util
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+)
+
+// DefaultDataDir is the name of the Terraform data directory when TF_DATA_DIR
+// is not set.
+const DefaultDataDir = ".terraform"
+
+// DataDir returns the Terraform data directory for rootDir, where terraform
+// init records the backend configuration and selected workspace. Like
+// Terraform, it honors TF_DATA_DIR, which is resolved against rootDir when it
+// is relative.
+func DataDir(rootDir string) string {
+	dir := os.Getenv("TF_DATA_DIR")
+	if dir == "" {
+		dir = DefaultDataDir
+	}
+	if filepath.IsAbs(dir) {
+		return dir
+	}
+	return filepath.Join(rootDir, dir)
+}
+
+// Environment returns the workspace last chosen with terraform workspace
+// select, which is recorded in the environment file of rootDir's data
+// directory, or "" if there is no such file. With ignore set, as by
+// --no-prefixed-workspace-file, the file is not read at all.
+func Environment(rootDir string, ignore bool) string {
+	if ignore {
+		return ""
+	}
+	data, err := os.ReadFile(filepath.Join(DataDir(rootDir), "environment"))
+	if err != nil {
+		return ""
+	}
+	return string(bytes.TrimSpace(data))
+}
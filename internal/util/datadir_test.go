@@ -0,0 +1,50 @@
+// Copyright (c) 2026 Steve Taranto <staranto@gmail.com>.
+// SPDX-License-Identifier: Apache-2.0
+// no-cloc
+
+package util
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDataDir(t *testing.T) {
+	abs := filepath.Join(t.TempDir(), "tfdata")
+
+	tests := []struct {
+		name      string
+		tfDataDir string
+		want      string
+	}{
+		{name: "unset", tfDataDir: "", want: "/work/root/.terraform"},
+		{name: "relative", tfDataDir: "build/tf", want: "/work/root/build/tf"},
+		{name: "relative parent", tfDataDir: "../shared", want: "/work/shared"},
+		{name: "absolute", tfDataDir: abs, want: abs},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("TF_DATA_DIR", tt.tfDataDir)
+			assert.Equal(t, tt.want, DataDir("/work/root"))
+		})
+	}
+}
+
+func TestEnvironment(t *testing.T) {
+	rootDir := t.TempDir()
+	t.Setenv("TF_DATA_DIR", "")
+
+	// No environment file is the default workspace.
+	assert.Equal(t, "", Environment(rootDir, false))
+
+	require.NoError(t, os.MkdirAll(filepath.Join(rootDir, ".terraform"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(rootDir, ".terraform", "environment"), []byte("dev\n"), 0o600))
+
+	assert.Equal(t, "dev", Environment(rootDir, false))
+	assert.Equal(t, "", Environment(rootDir, true))
+}
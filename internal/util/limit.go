@@ -0,0 +1,30 @@
+// Copyright (c) 2026 Steve Taranto <staranto@gmail.com>.
+// SPDX-License-Identifier: Apache-2.0
+
+package util
+
+// MaxPageSize is the largest page size the TFE list APIs accept.
+const MaxPageSize = 100
+
+// Limit caps items at limit rows. A limit of 0 or less means no cap.
+func Limit[T any](items []T, limit int) []T {
+	if limit > 0 && len(items) > limit {
+		return items[:limit]
+	}
+	return items
+}
+
+// Limited reports whether count rows already satisfy limit, so that no further
+// pages need to be fetched. It is always false when limit is 0 or less.
+func Limited(count, limit int) bool {
+	return limit > 0 && count >= limit
+}
+
+// PageSize returns the page size to list at most limit rows with: limit
+// itself when it fits in a single page, else MaxPageSize.
+func PageSize(limit int) int {
+	if limit > 0 && limit < MaxPageSize {
+		return limit
+	}
+	return MaxPageSize
+}
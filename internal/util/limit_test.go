@@ -0,0 +1,47 @@
+// Copyright (c) 2026 Steve Taranto <staranto@gmail.com>.
+// SPDX-License-Identifier: Apache-2.0
+// no-cloc
+
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimit(t *testing.T) {
+	items := []int{1, 2, 3, 4, 5}
+
+	tests := []struct {
+		name  string
+		limit int
+		want  []int
+	}{
+		{name: "no cap", limit: 0, want: items},
+		{name: "negative", limit: -1, want: items},
+		{name: "smaller", limit: 2, want: []int{1, 2}},
+		{name: "equal", limit: 5, want: items},
+		{name: "larger", limit: 10, want: items},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Limit(items, tt.limit))
+		})
+	}
+}
+
+func TestLimited(t *testing.T) {
+	assert.False(t, Limited(500, 0))
+	assert.False(t, Limited(4, 5))
+	assert.True(t, Limited(5, 5))
+	assert.True(t, Limited(150, 120))
+}
+
+func TestPageSize(t *testing.T) {
+	assert.Equal(t, MaxPageSize, PageSize(0))
+	assert.Equal(t, 7, PageSize(7))
+	assert.Equal(t, MaxPageSize, PageSize(100))
+	assert.Equal(t, MaxPageSize, PageSize(250))
+}
@@ -4,21 +4,38 @@
 package util
 
 import (
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 )
 
-// ParseRootDir parses a RootDir string and returns the absolute directory and
-// any optional environment override. It returns an error if the fs entry does
-// not exist, is empty or is not a directory.
-func ParseRootDir(rootDir string) (string, string, error) {
+// ErrAmbiguousRootDir is returned when a RootDir spec could be read more than
+// one way.
+var ErrAmbiguousRootDir = errors.New("ambiguous RootDir")
+
+// ParseRootDir parses a RootDir string and returns the absolute directory, any
+// optional environment override and any optional workspace override. The
+// forms accepted are:
+//
+//   - dir
+//   - dir::env, selecting the env of a multi-workspace configuration, which a
+//     remote backend combines with its workspace prefix.
+//   - dir@workspace, naming the workspace itself, like --workspace.
+//
+// An @ is only taken as the workspace separator in the last path element, and
+// not when the whole spec is itself an existing directory. A spec with both
+// ::env and @workspace, or one that names an existing directory whether or not
+// the @ is taken as the separator, is ambiguous. It returns an error if the fs
+// entry does not exist, is empty or is not a directory.
+func ParseRootDir(rootDir string) (string, string, string, error) {
 
 	if rootDir == "" {
-		return "", "", os.ErrInvalid
+		return "", "", "", os.ErrInvalid
 	}
 
-	var dir, env string
+	var env, workspace string
 
 	// First, split the path to see if there is an ::env override.
 	parts := strings.Split(rootDir, "::")
@@ -26,24 +43,53 @@ func ParseRootDir(rootDir string) (string, string, error) {
 		env = parts[1]
 	}
 
-	// Now determine if the actual root directory (parts[0]) is absolute or
-	// relative. If it is relative, make it absolute.
-	if !strings.HasPrefix(parts[0], "/") {
+	path := parts[0]
+	if i := strings.LastIndex(path, "@"); i >= 0 && !strings.Contains(path[i:], "/") {
+		whole, wholeErr := absDir(path)
+		split, splitErr := absDir(path[:i])
+		switch {
+		case wholeErr == nil && splitErr == nil && path[i+1:] != "":
+			return "", "", "", fmt.Errorf("%s is a directory and %s@%s: %w",
+				whole, split, path[i+1:], ErrAmbiguousRootDir)
+		case wholeErr == nil:
+			// The @ is part of the directory name.
+		case path[i+1:] == "":
+			return "", "", "", fmt.Errorf("empty workspace in %s: %w", rootDir, os.ErrInvalid)
+		case len(parts) > 1:
+			return "", "", "", fmt.Errorf("%s sets both ::env and @workspace: %w", rootDir, ErrAmbiguousRootDir)
+		default:
+			path, workspace = path[:i], path[i+1:]
+		}
+	}
+
+	dir, err := absDir(path)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	return dir, env, workspace, nil
+}
+
+// absDir makes path absolute, relative to the working directory, and checks
+// that it is a directory.
+func absDir(path string) (string, error) {
+	// Determine if path is absolute or relative. If it is relative, make it
+	// absolute.
+	dir := path
+	if !strings.HasPrefix(path, "/") {
 		cwd, err := os.Getwd()
 		if err != nil {
-			return "", "", err
+			return "", err
 		}
-		dir = filepath.Join(cwd, parts[0])
-	} else {
-		dir = parts[0]
+		dir = filepath.Join(cwd, path)
 	}
 
 	// If the rootDir is not a directory, return an error.
 	if r, err := os.Stat(dir); err != nil {
-		return "", "", err
+		return "", err
 	} else if !r.IsDir() {
-		return "", "", os.ErrInvalid
+		return "", fmt.Errorf("%s is not a directory: %w", dir, os.ErrInvalid)
 	}
 
-	return dir, env, nil
+	return dir, nil
 }
@@ -20,6 +20,9 @@ func TestParseRootDir(t *testing.T) {
 		wantEnv  string
 		wantErr  bool
 		errIs    error
+
+		wantWorkspace string
+		wantBase      string
 	}{
 		{
 			name: "absolute_path_no_env",
@@ -185,13 +188,106 @@ func TestParseRootDir(t *testing.T) {
 			wantEnv: "",
 			wantErr: false,
 		},
+		{
+			name: "workspace_nonexistent_directory",
+			setupDir: func(t *testing.T) string {
+				return filepath.Join(t.TempDir(), "infra") + "@prod"
+			},
+			wantErr: true,
+			errIs:   os.ErrNotExist,
+		},
+		{
+			name: "workspace",
+			setupDir: func(t *testing.T) string {
+				tmpDir := filepath.Join(t.TempDir(), "infra")
+				if err := os.Mkdir(tmpDir, 0755); err != nil {
+					t.Fatalf("failed to create dir: %v", err)
+				}
+				return tmpDir + "@prod"
+			},
+			wantWorkspace: "prod",
+			wantBase:      "infra",
+		},
+		{
+			name: "relative_path_with_workspace",
+			setupDir: func(t *testing.T) string {
+				tmpDir := t.TempDir()
+				if err := os.Mkdir(filepath.Join(tmpDir, "infra"), 0755); err != nil {
+					t.Fatalf("failed to create dir: %v", err)
+				}
+				t.Chdir(tmpDir)
+				return "./infra@web-prod"
+			},
+			wantWorkspace: "web-prod",
+			wantBase:      "infra",
+		},
+		{
+			name: "workspace_only",
+			setupDir: func(t *testing.T) string {
+				t.Chdir(t.TempDir())
+				return "@prod"
+			},
+			wantWorkspace: "prod",
+		},
+		{
+			name: "at_in_parent_dir",
+			setupDir: func(t *testing.T) string {
+				tmpDir := filepath.Join(t.TempDir(), "me@example", "infra")
+				if err := os.MkdirAll(tmpDir, 0755); err != nil {
+					t.Fatalf("failed to create dir: %v", err)
+				}
+				return tmpDir
+			},
+			wantBase: "infra",
+		},
+		{
+			name: "at_in_dir_name",
+			setupDir: func(t *testing.T) string {
+				tmpDir := filepath.Join(t.TempDir(), "infra@v2")
+				if err := os.Mkdir(tmpDir, 0755); err != nil {
+					t.Fatalf("failed to create dir: %v", err)
+				}
+				return tmpDir
+			},
+			wantBase: "infra@v2",
+		},
+		{
+			name: "at_in_dir_name_and_workspace",
+			setupDir: func(t *testing.T) string {
+				tmpDir := t.TempDir()
+				for _, d := range []string{"infra", "infra@v2"} {
+					if err := os.Mkdir(filepath.Join(tmpDir, d), 0755); err != nil {
+						t.Fatalf("failed to create dir: %v", err)
+					}
+				}
+				return filepath.Join(tmpDir, "infra@v2")
+			},
+			wantErr: true,
+			errIs:   ErrAmbiguousRootDir,
+		},
+		{
+			name: "env_and_workspace",
+			setupDir: func(t *testing.T) string {
+				return t.TempDir() + "@prod::staging"
+			},
+			wantErr: true,
+			errIs:   ErrAmbiguousRootDir,
+		},
+		{
+			name: "empty_workspace",
+			setupDir: func(t *testing.T) string {
+				return t.TempDir() + "@"
+			},
+			wantErr: true,
+			errIs:   os.ErrInvalid,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			rootDir := tt.setupDir(t)
 
-			dir, env, err := ParseRootDir(rootDir)
+			dir, env, workspace, err := ParseRootDir(rootDir)
 
 			if tt.wantErr {
 				assert.Error(t, err)
@@ -205,7 +301,11 @@ func TestParseRootDir(t *testing.T) {
 			assert.NotEmpty(t, dir)
 			assert.DirExists(t, dir)
 			assert.Equal(t, tt.wantEnv, env)
+			assert.Equal(t, tt.wantWorkspace, workspace)
 			assert.True(t, filepath.IsAbs(dir))
+			if tt.wantBase != "" {
+				assert.Equal(t, tt.wantBase, filepath.Base(dir))
+			}
 		})
 	}
 }
@@ -5,7 +5,10 @@
 
 package version
 
-import "runtime/debug"
+import (
+	"runtime"
+	"runtime/debug"
+)
 
 var Version = func() string {
 	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "(devel)" {
@@ -13,3 +16,50 @@ var Version = func() string {
 	}
 	return "dev"
 }()
+
+// Commit and Date are the git commit and build date of the binary. Releases
+// set them with -ldflags -X; otherwise they fall back to the VCS stamp the Go
+// toolchain embeds, and are empty when there is none.
+var (
+	Commit = buildSetting("vcs.revision")
+	Date   = buildSetting("vcs.time")
+)
+
+// Info is the build metadata reported by --version --output json.
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	Date      string `json:"date"`
+	GoVersion string `json:"go_version"`
+	OS        string `json:"os"`
+	Arch      string `json:"arch"`
+	// Profile is the --profile in effect, set by the caller.
+	Profile string `json:"profile,omitempty"`
+}
+
+// Get returns the build metadata of the running binary.
+func Get() Info {
+	return Info{
+		Version:   Version,
+		Commit:    Commit,
+		Date:      Date,
+		GoVersion: runtime.Version(),
+		OS:        runtime.GOOS,
+		Arch:      runtime.GOARCH,
+	}
+}
+
+// buildSetting returns the value of key from the embedded build settings, or
+// "" if it is not present.
+func buildSetting(key string) string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+	for _, s := range info.Settings {
+		if s.Key == key {
+			return s.Value
+		}
+	}
+	return ""
+}
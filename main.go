@@ -5,14 +5,18 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/staranto/tfctl/internal/cacheutil"
 	"github.com/staranto/tfctl/internal/command"
 	"github.com/staranto/tfctl/internal/config"
 	"github.com/staranto/tfctl/internal/log"
+	"github.com/staranto/tfctl/internal/output"
 	"github.com/staranto/tfctl/internal/util"
 	"github.com/staranto/tfctl/internal/version"
 )
@@ -24,9 +28,22 @@ func main() {
 }
 
 // handleVersion checks for --version/-v and returns whether it was handled.
+// The plain form prints only the version, so scripts can parse it; with
+// --output json it prints the build metadata, including the active profile if
+// there is one, as a JSON object for bug reports.
 func handleVersion(args []string) bool {
 	for _, a := range args {
 		if a == "--version" || a == "-v" {
+			profile := command.ProfileArg(args)
+			log.Debugf("version: profile=%s", profile)
+			if outputArg(args) == "json" {
+				info := version.Get()
+				info.Profile = profile
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				_ = enc.Encode(info)
+				return true
+			}
 			fmt.Println(version.Version)
 			return true
 		}
@@ -34,6 +51,23 @@ func handleVersion(args []string) bool {
 	return false
 }
 
+// outputArg returns the value of the last --output/-o flag in args, in either
+// the "--output json" or "--output=json" form, or "" if there is none.
+func outputArg(args []string) string {
+	var output string
+	for i, arg := range args {
+		switch {
+		case (arg == "--output" || arg == "-o") && i+1 < len(args):
+			output = args[i+1]
+		case strings.HasPrefix(arg, "--output="):
+			output = strings.TrimPrefix(arg, "--output=")
+		case strings.HasPrefix(arg, "-o="):
+			output = strings.TrimPrefix(arg, "-o=")
+		}
+	}
+	return output
+}
+
 // handleNakedCommand appends --help if no command is provided.
 func handleNakedCommand(args []string) []string {
 	if len(args) <= 1 {
@@ -43,14 +77,17 @@ func handleNakedCommand(args []string) []string {
 }
 
 // processCommandArgs handles command-specific argument processing.
-func processCommandArgs(args []string) []string {
+func processCommandArgs(args []string) ([]string, error) {
 	switch {
-	case len(args) > 1 && args[1] == "completion":
-		// Short-circuit completion: pass args directly.
-		return args
+	case len(args) > 1 && (args[1] == "batch" || args[1] == "cache" || args[1] == "completion" || args[1] == "config"):
+		// Short-circuit batch, cache, completion and config: pass args directly.
+		return args, nil
 	default:
 		// For ps and other commands, process @set first.
-		args = processSetOnly(args)
+		args, err := processSetOnly(args)
+		if err != nil {
+			return nil, err
+		}
 		log.Debugf("args after set processing: args=%v", args)
 
 		if len(args) > 1 && args[1] == "ps" {
@@ -58,7 +95,7 @@ func processCommandArgs(args []string) []string {
 		} else {
 			args = processOtherArgs(args)
 		}
-		return args
+		return args, nil
 	}
 }
 
@@ -74,8 +111,22 @@ func processPsArgs(args []string) []string {
 // processOtherArgs handles argument processing for other commands.
 func processOtherArgs(args []string) []string {
 	rootDir, _ := os.Getwd()
+
+	// With --chdir, InitApp resolves RootDir relative to the new directory, so
+	// the default is "." and a relative candidate is checked from there.
+	chdir := command.ChdirArg(args)
+	if chdir != "" {
+		rootDir = "."
+	}
+
 	if len(args) > 2 {
-		if _, _, err := util.ParseRootDir(args[2]); err == nil {
+		candidate := args[2]
+		if chdir != "" && !filepath.IsAbs(candidate) {
+			candidate = filepath.Join(chdir, candidate)
+		}
+		// An ambiguous spec is still taken as RootDir, so InitApp reports it
+		// rather than it being passed on as a stray argument.
+		if _, _, _, err := util.ParseRootDir(candidate); err == nil || errors.Is(err, util.ErrAmbiguousRootDir) {
 			rootDir = args[2]
 		}
 	}
@@ -87,6 +138,9 @@ func processOtherArgs(args []string) []string {
 	return args
 }
 
+// exitEmpty is the exit code when --fail-on-empty finds no matching rows.
+const exitEmpty = 3
+
 // initAndRunApp initializes the app and runs it, returning the exit code.
 func initAndRunApp(args []string) int {
 	// Pre-create cache directory when caching is enabled.
@@ -95,7 +149,8 @@ func initAndRunApp(args []string) int {
 		log.Debugf("cache ensure err: err=%v", err)
 	}
 
-	app, err := command.InitApp(ctx, args)
+	// tfctl batch runs each line of its file through the same processing.
+	app, err := command.InitApp(ctx, args, runLine)
 	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		log.Debugf("app init err: err=%v", err)
@@ -103,6 +158,14 @@ func initAndRunApp(args []string) int {
 	}
 
 	if err := app.Run(ctx, args); err != nil {
+		// The empty result speaks for itself, but a batch names its line.
+		if errors.Is(err, output.ErrEmpty) {
+			if err != output.ErrEmpty {
+				fmt.Fprintln(os.Stderr, err)
+			}
+			log.Debugf("app run empty: err=%v", err)
+			return exitEmpty
+		}
 		fmt.Fprintln(os.Stderr, err)
 		log.Debugf("app run err: err=%v", err)
 		return 2
@@ -121,7 +184,22 @@ func realMain() int {
 		return 0
 	}
 
-	args = handleNakedCommand(args)
+	args, err := prepareArgs(args)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		log.Debugf("args err: err=%v", err)
+		return 1
+	}
+
+	return initAndRunApp(args)
+}
+
+// prepareArgs completes a tfctl command line before it is run: a color mode
+// following --color is joined to it, a naked command gets --help, and the
+// command specific processing, such as @set expansion and the default
+// RootDir, is applied.
+func prepareArgs(args []string) ([]string, error) {
+	args = handleNakedCommand(command.ColorArgs(args))
 
 	// If --help appears anywhere, skip command processing and let the CLI handle it.
 	helpFound := false
@@ -132,11 +210,26 @@ func realMain() int {
 		}
 	}
 
-	if !helpFound {
-		args = processCommandArgs(args)
+	if helpFound {
+		return args, nil
+	}
+	return processCommandArgs(args)
+}
+
+// runLine runs one line of a tfctl batch file, args[0] being the program
+// name, the way realMain runs the command line.
+func runLine(ctx context.Context, args []string) error {
+	args, err := prepareArgs(args)
+	if err != nil {
+		return err
 	}
+	log.Debugf("batch args: args=%v", args)
 
-	return initAndRunApp(args)
+	app, err := command.InitApp(ctx, args, runLine)
+	if err != nil {
+		return err
+	}
+	return app.Run(ctx, args)
 }
 
 // isExistingFile checks if the given path exists and is a file.
@@ -147,29 +240,15 @@ func isExistingFile(path string) bool {
 	return false
 }
 
-// processSetOnly handles the @set logic for all commands, expanding set arguments at the @set position.
-func processSetOnly(args []string) []string {
-	// Look for an explicit @set argument starting from index 2.
-	idx := 2
-	set := "defaults"
-	removeIdx := -1
-	for i, a := range args[idx:] {
-		if strings.HasPrefix(a, "@") {
-			set = a[1:]
-			removeIdx = idx + i
-			break
-		}
+// processSetOnly handles the @set logic for all commands, expanding each
+// @set argument, and any sets it names in turn, at its position.
+func processSetOnly(args []string) ([]string, error) {
+	if len(args) <= 2 {
+		return args, nil
 	}
-	if removeIdx != -1 {
-		// Remove the @set argument.
-		args = append(args[:removeIdx], args[removeIdx+1:]...)
-		// Expand the set arguments at the removeIdx position.
-		setArgs, _ := config.GetStringSlice(args[1] + "." + set)
-		for _, arg := range setArgs {
-			parts := strings.Fields(arg)
-			args = append(args[:removeIdx], append(parts, args[removeIdx:]...)...)
-			removeIdx += len(parts)
-		}
+	expanded, err := config.ExpandSets(args[1], args[2:])
+	if err != nil {
+		return nil, err
 	}
-	return args
+	return append(args[:2:2], expanded...), nil
 }
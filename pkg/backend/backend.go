@@ -0,0 +1,38 @@
+// Copyright (c) 2026 Steve Taranto <staranto@gmail.com>.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package backend lets Go programs query Terraform state, state versions and
+// runs through the same backends tfctl uses, detected from a root directory
+// and configured with explicit options rather than tfctl's command line.
+//
+//	be, err := backend.New(ctx, backend.Options{
+//		RootDir: "infra/prod",
+//		Limit:   10,
+//	})
+//	if err != nil {
+//		return err
+//	}
+//	versions, err := be.StateVersions()
+package backend
+
+import (
+	"context"
+
+	"github.com/staranto/tfctl/internal/backend"
+	"github.com/staranto/tfctl/internal/backend/options"
+)
+
+// Backend is a local, remote, cloud or s3 Terraform backend.
+type Backend = backend.Backend
+
+// Options configure the backend New returns. A zero field falls back to its
+// default: the current directory for RootDir, the environment file for Env and
+// TF_TOKEN_<host>, TF_TOKEN or the credentials file for Token.
+type Options = options.Options
+
+// New returns the Backend of the Terraform root directory o.RootDir. Without
+// init state, local state or an environment file there, it is a remote backend
+// reaching o.Host and o.Org.
+func New(ctx context.Context, o Options) (Backend, error) {
+	return backend.New(ctx, o)
+}
@@ -0,0 +1,32 @@
+// Copyright (c) 2026 Steve Taranto <staranto@gmail.com>.
+// SPDX-License-Identifier: Apache-2.0
+// no-cloc
+
+package backend
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew_LocalState(t *testing.T) {
+	rootDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(rootDir, "terraform.tfstate"),
+		[]byte(`{"version":4,"serial":3,"resources":[]}`), 0o600))
+
+	be, err := New(context.Background(), Options{RootDir: rootDir})
+	require.NoError(t, err)
+
+	typ, err := be.Type()
+	require.NoError(t, err)
+	assert.Equal(t, "local", typ)
+
+	state, err := be.State()
+	require.NoError(t, err)
+	assert.Contains(t, string(state), `"serial":3`)
+}